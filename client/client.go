@@ -0,0 +1,274 @@
+// Package client is the Go client of the payment gateway API. The requests and the responses are the models of
+// the API, the request ids are generated when not set so that the retries of a request are idempotent.
+//
+//	c, err := client.New("https://gateway.example.com", client.WithToken(token))
+//	transaction, err := c.Authorize(ctx, client.AuthorizeRequest{...})
+//	if errors.Is(err, client.ReasonError(client.ReasonDeclinedByFraudScreening)) { ... }
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+)
+
+const (
+	// APIVersionPrefix is the prefix of the paths of the endpoints.
+	APIVersionPrefix = "/v1"
+	// APIVersion is the version of the API the responses are decoded with, it is sent in the X-API-Version header.
+	APIVersion = "2021-11-01"
+
+	apiVersionHeaderKey         = "X-API-Version"
+	signatureMerchantHeaderKey  = "X-Signature-Merchant"
+	signatureTimestampHeaderKey = "X-Signature-Timestamp"
+	signatureHeaderKey          = "X-Signature"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries of a failed idempotent request unless configured with WithRetries.
+	DefaultMaxRetries = 2
+	// DefaultBackoff is the wait before the first retry, it doubles on every retry.
+	DefaultBackoff = 100 * time.Millisecond
+	// DefaultTimeout is the timeout of the requests of the default http client.
+	DefaultTimeout = 30 * time.Second
+)
+
+// Client calls the endpoints of the gateway API on behalf of a merchant.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	// token is the api key or the token of the merchant sent in the Authorization header.
+	token string
+	// merchant and secret sign the requests when set, see signRequest.
+	merchant string
+	secret   string
+	// maxRetries is the number of retries of a failed idempotent request.
+	maxRetries int
+	// backoff is the wait before the first retry.
+	backoff time.Duration
+	// now returns the timestamp of the signed requests.
+	now func() time.Time
+}
+
+// Option is a function configuration of the Client.
+type Option func(c *Client)
+
+// WithToken authenticates the requests with the api key or the token of the merchant.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithRequestSigning authenticates the requests with their HMAC-SHA256 signature by the secret of the merchant.
+func WithRequestSigning(merchant, secret string) Option {
+	return func(c *Client) {
+		c.merchant = merchant
+		c.secret = secret
+	}
+}
+
+// WithHTTPClient is the http client the requests are sent with, a client with DefaultTimeout if not set.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithRetries is the number of retries of a failed idempotent request and the wait before the first retry,
+// DefaultMaxRetries and DefaultBackoff if not set. The retries are disabled with 0 retries.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// New returns a client of the gateway at the base URL, the requests must either be authenticated with a token
+// or signed.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultBackoff,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.token == "" && c.secret == "" {
+		return nil, errors.New("either a token or a request signing secret is required")
+	}
+	if c.maxRetries < 0 {
+		return nil, errors.New("max retries must not be negative")
+	}
+	return c, nil
+}
+
+// call is a request to an endpoint of the API.
+type call struct {
+	method string
+	path   string
+	// request is encoded as the request body, the request has no body if nil.
+	request interface{}
+	// response is where the response body is decoded into, the response body is ignored if nil.
+	response interface{}
+	// idempotent calls are retried when they fail with a network error, a server error or a rate limit.
+	idempotent bool
+}
+
+// do sends the request of the call and decodes its response, it is retried if idempotent. A response other than
+// 2xx is returned as an *Error.
+func (c *Client) do(ctx context.Context, cl call) error {
+	var body []byte
+	if cl.request != nil {
+		var err error
+		if body, err = json.Marshal(cl.request); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		wait, err := c.send(ctx, cl, body)
+		if err == nil || !cl.idempotent || attempt >= c.maxRetries || wait < 0 {
+			return err
+		}
+		if wait == 0 {
+			wait = c.backoff << attempt
+		}
+		// the signature of a request sent again within the same second is the same one, rejected as a replay
+		if c.secret != "" && wait < time.Second {
+			wait = time.Second
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// send sends the request once, it returns the error and how long to wait before retrying the request:
+// negative if the request must not be retried, zero to back off.
+func (c *Client) send(ctx context.Context, cl call, body []byte) (time.Duration, error) {
+	req, err := c.newRequest(ctx, cl.method, cl.path, body)
+	if err != nil {
+		return -1, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if cl.response == nil || resp.StatusCode == http.StatusNoContent {
+			return -1, nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(cl.response); err != nil {
+			return -1, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return -1, nil
+	}
+
+	apiErr := decodeError(resp)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests && apiErr.Code == CodeRateLimit:
+		return retryAfter(resp), apiErr
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return retryAfter(resp), apiErr
+	default:
+		return -1, apiErr
+	}
+}
+
+// newRequest returns the request to the path with the body, it is authenticated by the token and signed
+// if configured.
+func (c *Client) newRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
+	u := *c.baseURL
+	u.Path += APIVersionPrefix + path
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// the responses are decoded into the models of the latest version
+	req.Header.Set(apiVersionHeaderKey, APIVersion)
+	if c.token != "" {
+		req.Header.Set("Authorization", c.token)
+	}
+	if c.secret != "" {
+		now := c.now()
+		req.Header.Set(signatureMerchantHeaderKey, c.merchant)
+		req.Header.Set(signatureTimestampHeaderKey, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(signatureHeaderKey, signRequest(c.secret, method, req.URL.RequestURI(), now, body))
+	}
+	return req, nil
+}
+
+// signRequest returns the hex encoded HMAC-SHA256 signature of the method, the request URI, the unix timestamp
+// and the body of a request, each separated by a new line, as verified by the gateway.
+func signRequest(secret, method, requestURI string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n", method, requestURI, timestamp.Unix())
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryAfter returns the wait of the Retry-After header in seconds, zero if not set.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// decodeError decodes the ServerError of the response, a response without one is an error of the status.
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil || json.Unmarshal(b, &apiErr.ServerError) != nil || apiErr.Code == "" {
+		apiErr.ServerError = ServerError{Code: CodeUnknownFailure, Message: http.StatusText(resp.StatusCode)}
+	}
+	return apiErr
+}
+
+// withRequestID returns the request id or a new one if not set.
+func withRequestID(id uuid.UUID) uuid.UUID {
+	if id == uuid.Nil {
+		return uuid.NewV4()
+	}
+	return id
+}
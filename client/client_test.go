@@ -0,0 +1,293 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/client"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+const (
+	merchantID = "merchant-1"
+	token      = "token-1"
+)
+
+var (
+	authorizationID = uuid.NewV4()
+	customerID      = uuid.NewV4()
+	createdDate     = time.Date(2021, 6, 18, 12, 0, 0, 0, time.UTC)
+
+	transaction = &domain.Transaction{
+		ID:               uuid.NewV4(),
+		AuthorizationID:  authorizationID,
+		MerchantID:       merchantID,
+		Amount:           domain.Amount{MinorUnits: 1000, Currency: "GBP", Exponent: 2},
+		AuthorizedAmount: domain.Amount{MinorUnits: 1000, Currency: "GBP", Exponent: 2},
+	}
+	amount = client.Amount{MinorUnits: 1000, Currency: "GBP", Exponent: 2}
+)
+
+// newServer serves the real handler with the mocked service, the merchant is authenticated by the token.
+func newServer(t *testing.T, srv *mocks.MockService, opts ...transporthttp.MiddlewareFunc) *httptest.Server {
+	t.Helper()
+	srv.EXPECT().ValidateMerchant(gomock.Any(), merchantID).Return(nil).AnyTimes()
+	srv.EXPECT().Now().Return(time.Now()).AnyTimes()
+
+	h, err := transporthttp.NewHTTPHandler(srv, append(opts, transporthttp.WithAuth(map[string]string{token: merchantID}))...)
+	require.NoError(t, err)
+	s := httptest.NewServer(httplistener.HTTPHandler(h))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newClient(t *testing.T, s *httptest.Server, opts ...client.Option) *client.Client {
+	t.Helper()
+	opts = append([]client.Option{client.WithToken(token), client.WithRetries(2, time.Millisecond)}, opts...)
+	c, err := client.New(s.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockService(ctrl)
+	c := newClient(t, newServer(t, srv))
+
+	srv.EXPECT().Authorize(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *domain.Authorization) (*domain.Transaction, error) {
+		assert.NotEqual(t, uuid.Nil, a.RequestID, "the request id is generated")
+		assert.Equal(t, merchantID, a.MerchantID)
+		assert.Equal(t, "4242424242424242", a.PaymentSource.PAN)
+		return transaction, nil
+	})
+
+	resp, err := c.Authorize(context.Background(), client.AuthorizeRequest{
+		PaymentSource: client.PaymentSource{PAN: "4242424242424242", CVV: "123", ExpiryMonth: 1, ExpiryYear: 30},
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, authorizationID, resp.AuthorizationID)
+	assert.Equal(t, amount, resp.AuthorizedAmount)
+	assert.Equal(t, client.TransactionStateDeclined, resp.State, "the client gets the latest version")
+}
+
+func TestClient_Retries(t *testing.T) {
+	t.Run("should retry a capture with the same request id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		srv := mocks.NewMockService(ctrl)
+		c := newClient(t, newServer(t, srv))
+
+		var requestIDs []uuid.UUID
+		capture := func(_ context.Context, capture *domain.Capture) (*domain.Transaction, error) {
+			requestIDs = append(requestIDs, capture.RequestID)
+			if len(requestIDs) == 1 {
+				return nil, errors.New("kaboom")
+			}
+			return transaction, nil
+		}
+		srv.EXPECT().Capture(gomock.Any(), gomock.Any()).DoAndReturn(capture).Times(2)
+
+		resp, err := c.Capture(context.Background(), client.CaptureRequest{AuthorizationID: authorizationID, Amount: amount})
+		require.NoError(t, err)
+		assert.Equal(t, authorizationID, resp.AuthorizationID)
+		require.Len(t, requestIDs, 2)
+		assert.Equal(t, requestIDs[0], requestIDs[1])
+	})
+
+	t.Run("should give up after the max retries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		srv := mocks.NewMockService(ctrl)
+		c := newClient(t, newServer(t, srv))
+
+		srv.EXPECT().GetCustomer(gomock.Any(), merchantID, customerID).Return(nil, errors.New("kaboom")).Times(3)
+
+		_, err := c.GetCustomer(context.Background(), customerID)
+		var apiErr *client.Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+		assert.Equal(t, client.CodeUnknownFailure, apiErr.Code)
+	})
+
+	t.Run("should not retry a request without request id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		srv := mocks.NewMockService(ctrl)
+		c := newClient(t, newServer(t, srv))
+
+		srv.EXPECT().CreateCustomer(gomock.Any(), gomock.Any()).Return(nil, errors.New("kaboom")).Times(1)
+
+		_, err := c.CreateCustomer(context.Background(), client.CustomerRequest{Email: "jane@example.com"})
+		assert.Error(t, err)
+	})
+
+	t.Run("should retry a rate limited request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		srv := mocks.NewMockService(ctrl)
+		c := newClient(t, newServer(t, srv, transporthttp.WithRateLimit(&limitOnce{})))
+
+		srv.EXPECT().DeleteCustomer(gomock.Any(), merchantID, customerID).Return(nil).Times(1)
+
+		assert.NoError(t, c.DeleteCustomer(context.Background(), customerID))
+	})
+}
+
+func TestClient_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockService(ctrl)
+	c := newClient(t, newServer(t, srv))
+	ctx := context.Background()
+
+	t.Run("should return the reason of an unprocessable request", func(t *testing.T) {
+		srv.EXPECT().Void(gomock.Any(), gomock.Any()).Return(nil, domain.ErrAlreadyVoided).Times(1)
+
+		_, err := c.Void(ctx, client.VoidRequest{AuthorizationID: authorizationID})
+		assert.True(t, errors.Is(err, client.ErrUnprocessable))
+		assert.True(t, errors.Is(err, client.ReasonError(client.ReasonAlreadyVoided)))
+		assert.False(t, errors.Is(err, client.ReasonError(client.ReasonAlreadyCaptured)))
+
+		var apiErr *client.Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+		assert.Equal(t, "transaction is already voided", apiErr.Message)
+		assert.NotEmpty(t, apiErr.CorrelationID)
+	})
+
+	t.Run("should return the field errors of an invalid request", func(t *testing.T) {
+		_, err := c.Refund(ctx, client.RefundRequest{AuthorizationID: authorizationID, Amount: client.Amount{Currency: "GBP"}})
+		assert.True(t, errors.Is(err, client.ErrBadRequest))
+
+		var apiErr *client.Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, []client.FieldError{{Field: "amount.minor_units", Message: "must be greater than 0"}}, apiErr.Errors)
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		srv.EXPECT().GetSubscription(gomock.Any(), merchantID, gomock.Any()).Return(nil, domain.ErrSubscriptionNotFound)
+
+		_, err := c.GetSubscription(ctx, uuid.NewV4())
+		assert.True(t, errors.Is(err, client.ErrNotFound))
+	})
+
+	t.Run("should reject an invalid token", func(t *testing.T) {
+		s := newServer(t, srv)
+		srv.EXPECT().AuthenticateAPIKey(gomock.Any(), "token-2").Return(nil, domain.ErrAPIKeyInvalid)
+		c, err := client.New(s.URL, client.WithToken("token-2"))
+		require.NoError(t, err)
+
+		_, err = c.ListPaymentMethods(ctx, customerID)
+		var apiErr *client.Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, client.CodeForbidden, apiErr.Code)
+	})
+}
+
+func TestClient_RequestSigning(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockService(ctrl)
+	s := newServer(t, srv, transporthttp.WithRequestSigning(map[string]transporthttp.SigningConfig{
+		merchantID: {Secret: "secret-1", Required: true},
+	}, 0))
+
+	srv.EXPECT().ListPaymentMethods(gomock.Any(), merchantID, customerID).Return([]*domain.PaymentMethod{{
+		ID: uuid.NewV4(), CustomerID: customerID, MerchantID: merchantID, CreatedDate: createdDate,
+		PaymentSource: domain.PaymentSource{PAN: "4242424242424242", Expiry: domain.Expiry{Month: 1, Year: 30}},
+	}}, nil)
+	srv.EXPECT().CreatePaymentMethod(gomock.Any(), gomock.Any()).Return(&domain.PaymentMethod{ID: uuid.NewV4(), CustomerID: customerID}, nil)
+
+	c, err := client.New(s.URL, client.WithRequestSigning(merchantID, "secret-1"))
+	require.NoError(t, err)
+
+	paymentMethods, err := c.ListPaymentMethods(context.Background(), customerID)
+	require.NoError(t, err)
+	require.Len(t, paymentMethods, 1)
+	assert.Equal(t, "4242", paymentMethods[0].Card.Last4)
+
+	_, err = c.CreatePaymentMethod(context.Background(), customerID, client.PaymentMethodRequest{
+		PaymentSource: client.PaymentSource{PAN: "4242424242424242", ExpiryMonth: 1, ExpiryYear: 30},
+	})
+	require.NoError(t, err, "the body is signed")
+
+	c, err = client.New(s.URL, client.WithRequestSigning(merchantID, "secret-2"))
+	require.NoError(t, err)
+	_, err = c.ListPaymentMethods(context.Background(), customerID)
+	assert.True(t, errors.Is(err, &client.Error{ServerError: client.ServerError{Code: client.CodeForbidden}}))
+}
+
+func TestNew(t *testing.T) {
+	_, err := client.New("localhost:8080", client.WithToken(token))
+	assert.Error(t, err, "the base url must be absolute")
+
+	_, err = client.New("http://localhost:8080")
+	assert.Error(t, err, "a token or a signing secret is required")
+
+	_, err = client.New("http://localhost:8080", client.WithToken(token), client.WithRetries(-1, 0))
+	assert.Error(t, err)
+}
+
+func TestClient_MatchesGateway(t *testing.T) {
+	assert.Equal(t, transporthttp.APIVersionPrefix, client.APIVersionPrefix)
+	assert.Equal(t, transporthttp.LatestAPIVersion, client.APIVersion)
+
+	for _, code := range [][2]string{
+		{transporthttp.CodeUnauthorized, client.CodeUnauthorized},
+		{transporthttp.CodeForbidden, client.CodeForbidden},
+		{transporthttp.CodeNotFound, client.CodeNotFound},
+		{transporthttp.CodeConflict, client.CodeConflict},
+		{transporthttp.CodeBadRequest, client.CodeBadRequest},
+		{transporthttp.CodeUnprocessable, client.CodeUnprocessable},
+		{transporthttp.CodeVelocityLimit, client.CodeVelocityLimit},
+		{transporthttp.CodeRateLimit, client.CodeRateLimit},
+		{transporthttp.CodePayloadTooLarge, client.CodePayloadTooLarge},
+		{transporthttp.CodeUnknownFailure, client.CodeUnknownFailure},
+		{string(domain.ReasonInvalidAmount), client.ReasonInvalidAmount},
+		{string(domain.ReasonAlreadyVoided), client.ReasonAlreadyVoided},
+		{string(domain.ReasonAlreadyCaptured), client.ReasonAlreadyCaptured},
+		{string(domain.ReasonAlreadyRefunded), client.ReasonAlreadyRefunded},
+		{string(domain.ReasonCurrencyMismatch), client.ReasonCurrencyMismatch},
+		{string(domain.ReasonAmountExceedsAuthorized), client.ReasonAmountExceedsAuthorized},
+		{string(domain.ReasonAmountExceedsCaptured), client.ReasonAmountExceedsCaptured},
+		{string(domain.ReasonCurrencyNotAllowed), client.ReasonCurrencyNotAllowed},
+		{string(domain.ReasonInvalidCard), client.ReasonInvalidCard},
+		{string(domain.ReasonDeclinedByFraudScreening), client.ReasonDeclinedByFraudScreening},
+		{string(domain.ReasonNotAwaitingAuthentication), client.ReasonNotAwaitingAuthentication},
+		{string(domain.ReasonAuthenticationNotCompleted), client.ReasonAuthenticationNotCompleted},
+		{string(domain.ReasonAuthenticationFailed), client.ReasonAuthenticationFailed},
+	} {
+		assert.Equal(t, code[0], code[1])
+	}
+}
+
+// limitOnce limits the first request with a retry right away.
+type limitOnce struct {
+	mu      sync.Mutex
+	limited bool
+}
+
+func (l *limitOnce) Allow(string, string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limited {
+		return true, 0
+	}
+	l.limited = true
+	return false, 0
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	uuid "github.com/kevinburke/go.uuid"
+)
+
+// paths of the endpoints of the API, under APIVersionPrefix
+const (
+	pathAuthorize              = "/authorize"
+	pathAuthorizeComplete      = "/authorize/{authorization_id}/complete"
+	pathCapture                = "/capture"
+	pathRefund                 = "/refund"
+	pathVoid                   = "/void"
+	pathCustomers              = "/customers"
+	pathCustomer               = "/customers/{customer_id}"
+	pathCustomerPaymentMethods = "/customers/{customer_id}/payment_methods"
+	pathCustomerPaymentMethod  = "/customers/{customer_id}/payment_methods/{payment_method_id}"
+	pathCustomerTransactions   = "/customers/{customer_id}/transactions"
+	pathPlans                  = "/plans"
+	pathSubscriptions          = "/subscriptions"
+	pathSubscription           = "/subscriptions/{subscription_id}"
+	pathSubscriptionCancel     = "/subscriptions/{subscription_id}/cancel"
+	pathAPIKeyRotate           = "/api_keys/rotate"
+)
+
+// Authorize authorizes a transaction, the request id is generated if not set.
+func (c *Client) Authorize(ctx context.Context, req AuthorizeRequest) (*Transaction, error) {
+	req.RequestID = withRequestID(req.RequestID)
+	var resp Transaction
+	err := c.do(ctx, call{method: http.MethodPost, path: pathAuthorize, request: req, response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CompleteAuthorization completes the authorization once the cardholder has completed the 3-D Secure challenge.
+func (c *Client) CompleteAuthorization(ctx context.Context, authorizationID uuid.UUID) (*Transaction, error) {
+	var resp Transaction
+	err := c.do(ctx, call{method: http.MethodPost, path: endpointPath(pathAuthorizeComplete, authorizationID),
+		response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Capture captures an authorized transaction, the request id is generated if not set.
+func (c *Client) Capture(ctx context.Context, req CaptureRequest) (*Transaction, error) {
+	req.RequestID = withRequestID(req.RequestID)
+	var resp Transaction
+	err := c.do(ctx, call{method: http.MethodPost, path: pathCapture, request: req, response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Refund refunds a captured transaction, the request id is generated if not set.
+func (c *Client) Refund(ctx context.Context, req RefundRequest) (*Transaction, error) {
+	req.RequestID = withRequestID(req.RequestID)
+	var resp Transaction
+	err := c.do(ctx, call{method: http.MethodPost, path: pathRefund, request: req, response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Void voids an authorized transaction, the request id is generated if not set.
+func (c *Client) Void(ctx context.Context, req VoidRequest) (*Transaction, error) {
+	req.RequestID = withRequestID(req.RequestID)
+	var resp Transaction
+	err := c.do(ctx, call{method: http.MethodPost, path: pathVoid, request: req, response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateCustomer creates a customer, it is not retried as the customers have no request id.
+func (c *Client) CreateCustomer(ctx context.Context, req CustomerRequest) (*Customer, error) {
+	var resp Customer
+	err := c.do(ctx, call{method: http.MethodPost, path: pathCustomers, request: req, response: &resp})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetCustomer gets a customer.
+func (c *Client) GetCustomer(ctx context.Context, customerID uuid.UUID) (*Customer, error) {
+	var resp Customer
+	err := c.do(ctx, call{method: http.MethodGet, path: endpointPath(pathCustomer, customerID),
+		response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateCustomer updates the email and the name of a customer.
+func (c *Client) UpdateCustomer(ctx context.Context, customerID uuid.UUID, req CustomerRequest) (*Customer, error) {
+	var resp Customer
+	err := c.do(ctx, call{method: http.MethodPut, path: endpointPath(pathCustomer, customerID),
+		request: req, response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteCustomer deletes a customer and its payment methods.
+func (c *Client) DeleteCustomer(ctx context.Context, customerID uuid.UUID) error {
+	return c.do(ctx, call{method: http.MethodDelete, path: endpointPath(pathCustomer, customerID), idempotent: true})
+}
+
+// ListCustomerTransactions lists the transactions of a customer.
+func (c *Client) ListCustomerTransactions(ctx context.Context, customerID uuid.UUID) ([]Transaction, error) {
+	var resp []Transaction
+	err := c.do(ctx, call{method: http.MethodGet, path: endpointPath(pathCustomerTransactions, customerID),
+		response: &resp, idempotent: true})
+	return resp, err
+}
+
+// CreatePaymentMethod stores a payment method of a customer, it is not retried as the payment methods have no request id.
+func (c *Client) CreatePaymentMethod(ctx context.Context, customerID uuid.UUID, req PaymentMethodRequest) (*PaymentMethod, error) {
+	var resp PaymentMethod
+	err := c.do(ctx, call{method: http.MethodPost, path: endpointPath(pathCustomerPaymentMethods, customerID),
+		request: req, response: &resp})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListPaymentMethods lists the payment methods of a customer.
+func (c *Client) ListPaymentMethods(ctx context.Context, customerID uuid.UUID) ([]PaymentMethod, error) {
+	var resp []PaymentMethod
+	err := c.do(ctx, call{method: http.MethodGet, path: endpointPath(pathCustomerPaymentMethods, customerID),
+		response: &resp, idempotent: true})
+	return resp, err
+}
+
+// DeletePaymentMethod deletes a payment method of a customer.
+func (c *Client) DeletePaymentMethod(ctx context.Context, customerID, paymentMethodID uuid.UUID) error {
+	return c.do(ctx, call{method: http.MethodDelete,
+		path: endpointPath(pathCustomerPaymentMethod, customerID, paymentMethodID), idempotent: true})
+}
+
+// CreatePlan creates a plan, it is not retried as the plans have no request id.
+func (c *Client) CreatePlan(ctx context.Context, req PlanRequest) (*Plan, error) {
+	var resp Plan
+	err := c.do(ctx, call{method: http.MethodPost, path: pathPlans, request: req, response: &resp})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateSubscription subscribes a customer to a plan, it is not retried as the subscriptions have no request id.
+func (c *Client) CreateSubscription(ctx context.Context, req SubscriptionRequest) (*Subscription, error) {
+	var resp Subscription
+	err := c.do(ctx, call{method: http.MethodPost, path: pathSubscriptions, request: req, response: &resp})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetSubscription gets a subscription.
+func (c *Client) GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*Subscription, error) {
+	var resp Subscription
+	err := c.do(ctx, call{method: http.MethodGet, path: endpointPath(pathSubscription, subscriptionID),
+		response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CancelSubscription cancels a subscription.
+func (c *Client) CancelSubscription(ctx context.Context, subscriptionID uuid.UUID) (*Subscription, error) {
+	var resp Subscription
+	err := c.do(ctx, call{method: http.MethodPost, path: endpointPath(pathSubscriptionCancel, subscriptionID),
+		response: &resp, idempotent: true})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RotateAPIKey rotates the api key the client is authenticated with, which requires the keys scope, the new key is
+// returned. It is not retried as a key can only be rotated once.
+func (c *Client) RotateAPIKey(ctx context.Context, req RotateAPIKeyRequest) (*APIKey, error) {
+	var resp APIKey
+	err := c.do(ctx, call{method: http.MethodPost, path: pathAPIKeyRotate, request: req, response: &resp})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// endpointPath returns the path of the endpoint with its variables replaced by the ids in order,
+// e.g. /customers/{customer_id} with the id of the customer.
+func endpointPath(endpoint string, ids ...uuid.UUID) string {
+	for _, id := range ids {
+		start, end := strings.Index(endpoint, "{"), strings.Index(endpoint, "}")
+		if start < 0 || end < start {
+			break
+		}
+		endpoint = endpoint[:start] + id.String() + endpoint[end+1:]
+	}
+	return endpoint
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"fmt"
+)
+
+// codes of the errors of the API
+const (
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "permission_denied"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeBadRequest      = "bad_request"
+	CodeUnprocessable   = "unprocessable"
+	CodeVelocityLimit   = "velocity_limit_exceeded"
+	CodeRateLimit       = "rate_limit_exceeded"
+	CodePayloadTooLarge = "payload_too_large"
+	CodeUnknownFailure  = "unknown_failure"
+)
+
+// reasons of the unprocessable requests
+const (
+	ReasonInvalidAmount              = "invalid_amount"
+	ReasonAlreadyVoided              = "already_voided"
+	ReasonAlreadyCaptured            = "already_captured"
+	ReasonAlreadyRefunded            = "already_refunded"
+	ReasonCurrencyMismatch           = "currency_mismatch"
+	ReasonAmountExceedsAuthorized    = "amount_exceeds_authorized"
+	ReasonAmountExceedsCaptured      = "amount_exceeds_captured"
+	ReasonCurrencyNotAllowed         = "currency_not_allowed"
+	ReasonInvalidCard                = "invalid_card"
+	ReasonDeclinedByFraudScreening   = "declined_by_fraud_screening"
+	ReasonNotAwaitingAuthentication  = "not_awaiting_authentication"
+	ReasonAuthenticationNotCompleted = "authentication_not_completed"
+	ReasonAuthenticationFailed       = "authentication_failed"
+)
+
+var (
+	// ErrNotFound is the error of a resource which is not found.
+	ErrNotFound = &Error{ServerError: ServerError{Code: CodeNotFound}}
+	// ErrUnprocessable is the error of an unprocessable request, its reason tells why.
+	ErrUnprocessable = &Error{ServerError: ServerError{Code: CodeUnprocessable}}
+	// ErrBadRequest is the error of an invalid request, its field errors tell which fields are invalid.
+	ErrBadRequest = &Error{ServerError: ServerError{Code: CodeBadRequest}}
+	// ErrRateLimit is the error of a request over the rate limit of the merchant.
+	ErrRateLimit = &Error{ServerError: ServerError{Code: CodeRateLimit}}
+)
+
+// Error is the error response of the API.
+type Error struct {
+	ServerError
+	StatusCode int
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("payment gateway: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	return msg
+}
+
+// Is reports whether the target is an *Error of the same code and reason, the ones of the target are matched
+// unless empty, e.g. errors.Is(err, client.ErrNotFound) or errors.Is(err, client.ReasonError(client.ReasonAlreadyVoided)).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return (t.Code == "" || t.Code == e.Code) && (t.Reason == "" || t.Reason == e.Reason)
+}
+
+// ReasonError returns an error matching the errors of the reason with errors.Is.
+func ReasonError(reason string) error {
+	return &Error{ServerError: ServerError{Code: CodeUnprocessable, Reason: reason}}
+}
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+)
+
+// AuthorizeRequest is the request of an authorization.
+type AuthorizeRequest struct {
+	PaymentSource PaymentSource `json:"payment_source"`
+	Amount        Amount        `json:"amount"`
+	RequestID     uuid.UUID     `json:"request_id"`
+	Description   string        `json:"description"`
+	ThreeDSecure  *ThreeDSecure `json:"three_d_secure,omitempty"`
+	// Initiator is customer (default) or merchant for a merchant-initiated authorization with a stored payment method.
+	Initiator string `json:"initiator,omitempty"`
+	// PaymentMethodID is the stored payment method to authorize instead of the payment source.
+	PaymentMethodID uuid.UUID `json:"payment_method_id"`
+	// StorePaymentMethod stores the payment source for the customer once authorized.
+	StorePaymentMethod bool `json:"store_payment_method,omitempty"`
+	// CustomerID is the customer the transaction is linked to, it is required to store the payment source.
+	CustomerID uuid.UUID `json:"customer_id"`
+	// ShopperIP is the IP address of the device of the shopper, the authorizations are limited per shopper IP with it.
+	ShopperIP string `json:"shopper_ip,omitempty"`
+}
+
+// ThreeDSecure enables the 3-D Secure authentication of the cardholder.
+type ThreeDSecure struct {
+	Enabled bool `json:"enabled"`
+}
+
+// PaymentSource is the card of an authorization.
+type PaymentSource struct {
+	PAN         string `json:"pan"`
+	CVV         string `json:"cvv"`
+	ExpiryMonth int    `json:"expiry_month"`
+	ExpiryYear  int    `json:"expiry_year"`
+}
+
+// Amount is an amount in the minor units of its currency.
+type Amount struct {
+	MinorUnits uint64 `json:"minor_units"`
+	Exponent   uint8  `json:"exponent"`
+	Currency   string `json:"currency"`
+}
+
+// CaptureRequest is the request of a capture of an authorized transaction.
+type CaptureRequest struct {
+	AuthorizationID uuid.UUID `json:"authorization_id"`
+	RequestID       uuid.UUID `json:"request_id"`
+	Amount          Amount    `json:"amount"`
+}
+
+// RefundRequest is the request of a refund of a captured transaction.
+type RefundRequest struct {
+	AuthorizationID uuid.UUID `json:"authorization_id"`
+	RequestID       uuid.UUID `json:"request_id"`
+	Amount          Amount    `json:"amount"`
+}
+
+// VoidRequest is the request of a void of an authorized transaction.
+type VoidRequest struct {
+	AuthorizationID uuid.UUID `json:"authorization_id"`
+	RequestID       uuid.UUID `json:"request_id"`
+}
+
+// CustomerRequest is the request of a creation or an update of a customer.
+type CustomerRequest struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// PaymentMethodRequest is the request of a payment method stored for a customer.
+type PaymentMethodRequest struct {
+	PaymentSource PaymentSource `json:"payment_source"`
+}
+
+// PlanRequest is the request of a creation of a plan.
+type PlanRequest struct {
+	Name          string `json:"name"`
+	Amount        Amount `json:"amount"`
+	Interval      string `json:"interval"`
+	IntervalCount int    `json:"interval_count"`
+}
+
+// SubscriptionRequest is the request of a subscription of a customer to a plan.
+type SubscriptionRequest struct {
+	CustomerID      uuid.UUID `json:"customer_id"`
+	PlanID          uuid.UUID `json:"plan_id"`
+	PaymentMethodID uuid.UUID `json:"payment_method_id"`
+	// StartDate is the date of the first billing, now if not provided.
+	StartDate *time.Time `json:"start_date,omitempty"`
+}
+
+// RotateAPIKeyRequest is the request of a rotation of the api key.
+type RotateAPIKeyRequest struct {
+	// OverlapSeconds is the time the rotated key stays valid for, one day if not provided.
+	OverlapSeconds int64 `json:"overlap_seconds,omitempty"`
+}
+
+// Transaction is the response of the payment actions, in the latest version of the API.
+type Transaction struct {
+	ID               uuid.UUID       `json:"id"`
+	AuthorizationID  uuid.UUID       `json:"authorization_id"`
+	AuthorizedTime   *time.Time      `json:"authorization_date,omitempty"`
+	AuthorizedAmount Amount          `json:"authorized_amount"`
+	CapturedAmount   Amount          `json:"captured_amount"`
+	RefundedAmount   Amount          `json:"refunded_amount"`
+	FeeAmount        Amount          `json:"fee_amount"`
+	NetAmount        Amount          `json:"net_amount"`
+	FXRate           *FXRate         `json:"fx_rate,omitempty"`
+	Risk             *Risk           `json:"risk,omitempty"`
+	Authentication   *Authentication `json:"authentication,omitempty"`
+	Initiator        string          `json:"initiator,omitempty"`
+	PaymentMethodID  *uuid.UUID      `json:"payment_method_id,omitempty"`
+	CustomerID       *uuid.UUID      `json:"customer_id,omitempty"`
+	// NetworkTransactionID is the reference of the authorization given by the card network.
+	NetworkTransactionID string `json:"network_transaction_id,omitempty"`
+	Status               string `json:"status"`
+	IsVoided             bool   `json:"is_voided"`
+	// State is the state of the lifecycle of the transaction.
+	State string `json:"state"`
+}
+
+// statuses of the transactions
+const (
+	TransactionStatusAuthorized     = "authorized"
+	TransactionStatusRequiresAction = "requires_action"
+	TransactionStatusDeclined       = "declined"
+)
+
+// states of the lifecycle of the transactions
+const (
+	TransactionStateRequiresAction    = "requires_action"
+	TransactionStateDeclined          = "declined"
+	TransactionStateAuthorized        = "authorized"
+	TransactionStateVoided            = "voided"
+	TransactionStatePartiallyCaptured = "partially_captured"
+	TransactionStateCaptured          = "captured"
+	TransactionStatePartiallyRefunded = "partially_refunded"
+	TransactionStateRefunded          = "refunded"
+)
+
+// Authentication is the 3-D Secure authentication of the cardholder.
+type Authentication struct {
+	Status         string `json:"status"`
+	ECI            string `json:"eci,omitempty"`
+	LiabilityShift bool   `json:"liability_shift"`
+	ChallengeURL   string `json:"challenge_url,omitempty"`
+}
+
+// Risk is the decision of the fraud screening of an authorization.
+type Risk struct {
+	Decision string   `json:"decision"`
+	Score    int      `json:"score"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+// FXRate is the rate the authorized amount has been converted to the settlement currency with.
+type FXRate struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Rate       string    `json:"rate"`
+	LockedDate time.Time `json:"locked_date"`
+}
+
+// Customer is a customer of the merchant.
+type Customer struct {
+	ID          uuid.UUID `json:"id"`
+	Email       string    `json:"email,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+// PaymentMethod is a payment method stored for a customer.
+type PaymentMethod struct {
+	ID          uuid.UUID `json:"id"`
+	CustomerID  uuid.UUID `json:"customer_id"`
+	Card        Card      `json:"card"`
+	CreatedDate time.Time `json:"created_date"`
+	// MerchantInitiated indicates that the payment method can be used for merchant-initiated authorizations.
+	MerchantInitiated bool `json:"merchant_initiated"`
+}
+
+// Card is the card of a stored payment method, without its number.
+type Card struct {
+	Last4       string `json:"last4"`
+	ExpiryMonth int    `json:"expiry_month"`
+	ExpiryYear  int    `json:"expiry_year"`
+	Scheme      string `json:"scheme,omitempty"`
+	Country     string `json:"country,omitempty"`
+}
+
+// Plan is a plan the customers are subscribed to.
+type Plan struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Amount        Amount    `json:"amount"`
+	Interval      string    `json:"interval"`
+	IntervalCount int       `json:"interval_count"`
+	CreatedDate   time.Time `json:"created_date"`
+}
+
+// Subscription is a subscription of a customer to a plan.
+type Subscription struct {
+	ID              uuid.UUID `json:"id"`
+	CustomerID      uuid.UUID `json:"customer_id"`
+	PlanID          uuid.UUID `json:"plan_id"`
+	PaymentMethodID uuid.UUID `json:"payment_method_id"`
+	Status          string    `json:"status"`
+	PeriodStart     time.Time `json:"period_start"`
+	NextBillingDate time.Time `json:"next_billing_date"`
+	FailedAttempts  int       `json:"failed_attempts"`
+	CreatedDate     time.Time `json:"created_date"`
+}
+
+// APIKey is an api key of the merchant, its key is only returned when it is issued.
+type APIKey struct {
+	ID          uuid.UUID  `json:"id"`
+	Key         string     `json:"key,omitempty"`
+	Scopes      []string   `json:"scopes"`
+	CreatedDate time.Time  `json:"created_date"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RotatedAt   *time.Time `json:"rotated_at,omitempty"`
+}
+
+// ServerError is the body of the error responses.
+type ServerError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+	// Reason is the stable reason of an unprocessable request, e.g. already_voided, to match on rather than the message.
+	Reason string `json:"reason,omitempty"`
+	// CorrelationID is the id of the request in the log lines of the gateway.
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Errors are the errors of the fields of an invalid request.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is the error of a field of an invalid request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,90 @@
+// Command apikey issues and revokes the API keys of a merchant, the key is only printed when it is issued.
+//
+//	go run ./cmd/apikey -merchant merchant-1 -scopes authorize,capture,read -expires 2160h
+//	go run ./cmd/apikey -merchant merchant-1 -revoke 3f1c1b4e-9c39-4b69-8f0b-1f0e6a3f5c2a
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	uuid "github.com/kevinburke/go.uuid"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/config"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/service"
+	"github.com/jeffreyyong/payment-gateway/internal/store"
+)
+
+func main() {
+	merchantID := flag.String("merchant", "", "merchant owning the key")
+	scopes := flag.String("scopes", string(domain.ScopeAll), "comma separated scopes of the issued key")
+	expires := flag.Duration("expires", 0, "lifetime of the issued key, it does not expire if zero")
+	revoke := flag.String("revoke", "", "id of the key to revoke instead of issuing one")
+	configFiles := config.Flag(flag.CommandLine)
+	flag.Parse()
+
+	if *merchantID == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(context.Background(), *configFiles, *merchantID, *scopes, *expires, *revoke); err != nil {
+		logging.Error(context.Background(), "apikey", zap.Error(err))
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, configFiles, merchantID, scopes string, expires time.Duration, revoke string) error {
+	cfg, err := config.Load(configFiles)
+	if err != nil {
+		return err
+	}
+
+	store, err := store.New(cfg.PostgresDSN, store.WithPool(store.Pool(cfg.Postgres)))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	clock := clockwork.NewRealClock()
+	svc, err := service.NewService(store, service.WithClock(clock), service.WithAuditLog(store))
+	if err != nil {
+		return err
+	}
+
+	if revoke != "" {
+		keyID, err := uuid.FromString(revoke)
+		if err != nil {
+			return err
+		}
+		if err := svc.RevokeAPIKey(ctx, merchantID, keyID); err != nil {
+			return err
+		}
+		fmt.Printf("revoked api key %s\n", keyID)
+		return nil
+	}
+
+	var keyScopes []domain.Scope
+	for _, s := range strings.Split(scopes, ",") {
+		keyScopes = append(keyScopes, domain.Scope(strings.TrimSpace(s)))
+	}
+	var expiresAt *time.Time
+	if expires > 0 {
+		t := clock.Now().Add(expires)
+		expiresAt = &t
+	}
+
+	key, secret, err := svc.CreateAPIKey(ctx, merchantID, keyScopes, expiresAt)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("id: %s\nkey: %s\n", key.ID, secret)
+	return nil
+}
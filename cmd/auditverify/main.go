@@ -0,0 +1,63 @@
+// Command auditverify verifies the hash chain of the audit log and exits with status 1 when it has been tampered with.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/config"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/service"
+	"github.com/jeffreyyong/payment-gateway/internal/store"
+)
+
+// exit statuses of the command
+const (
+	exitTampered = 1
+	exitFailure  = 2
+)
+
+func main() {
+	configFiles := config.Flag(flag.CommandLine)
+	flag.Parse()
+	os.Exit(run(context.Background(), *configFiles))
+}
+
+func run(ctx context.Context, configFiles string) int {
+	cfg, err := config.Load(configFiles)
+	if err != nil {
+		logging.Error(ctx, "loading_config", zap.Error(err))
+		return exitFailure
+	}
+
+	store, err := store.New(cfg.PostgresDSN, store.WithPool(store.Pool(cfg.Postgres)))
+	if err != nil {
+		logging.Error(ctx, "initialising store", zap.Error(err))
+		return exitFailure
+	}
+	defer store.Close()
+
+	svc, err := service.NewService(store, service.WithAuditLog(store))
+	if err != nil {
+		logging.Error(ctx, "creating_service", zap.Error(err))
+		return exitFailure
+	}
+
+	verified, err := svc.VerifyAuditLog(ctx)
+	switch {
+	case errors.Is(err, domain.ErrAuditLogTampered):
+		fmt.Printf("audit log tampered after %d verified entries: %v\n", verified, err)
+		return exitTampered
+	case err != nil:
+		logging.Error(ctx, "verifying_audit_log", zap.Error(err))
+		return exitFailure
+	}
+	fmt.Printf("audit log verified: %d entries\n", verified)
+	return 0
+}
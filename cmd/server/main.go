@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
+	"os/signal"
 	"path"
+	"syscall"
+	"time"
 
 	"github.com/jonboulle/clockwork"
 
@@ -12,11 +16,22 @@ import (
 
 	"github.com/jeffreyyong/payment-gateway/internal/app"
 	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/schedulerlistener"
+	"github.com/jeffreyyong/payment-gateway/internal/audit"
+	"github.com/jeffreyyong/payment-gateway/internal/bin"
 	"github.com/jeffreyyong/payment-gateway/internal/config"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/fx"
 	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/pricing"
+	"github.com/jeffreyyong/payment-gateway/internal/ratelimit"
+	"github.com/jeffreyyong/payment-gateway/internal/risk"
 	"github.com/jeffreyyong/payment-gateway/internal/service"
 	"github.com/jeffreyyong/payment-gateway/internal/store"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
 	transporthttp "github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/velocity"
 )
 
 const (
@@ -24,7 +39,24 @@ const (
 )
 
 func main() {
-	if err := app.Run(serviceName, setup); err != nil {
+	configFiles := config.Flag(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.Load(*configFiles)
+	if err != nil {
+		logging.Error(context.Background(), "loading_config",
+			zap.String("service", serviceName),
+			zap.Error(err),
+		)
+		panic(err)
+	}
+
+	err = app.Run(serviceName, newSetup(*configFiles, cfg),
+		app.WithTracing(tracingConfig(cfg.Tracing)),
+		app.WithHealthAddr(cfg.HealthAddr),
+		app.WithShutdownTimeout(cfg.ShutdownTimeout),
+	)
+	if err != nil {
 		logging.Error(context.Background(), "failed to start service",
 			zap.String("service", serviceName),
 			zap.Error(err),
@@ -33,20 +65,22 @@ func main() {
 	}
 }
 
-func setup(ctx context.Context, s *app.Service) ([]app.Listener, context.Context, error) {
+// newSetup returns the setup of the service with the config loaded from the config files, they are reloaded on SIGHUP
+// and when they change.
+func newSetup(configFiles string, cfg config.Config) app.SetupFunc {
+	return func(ctx context.Context, s *app.Service) ([]app.Listener, context.Context, error) {
+		return setup(ctx, s, configFiles, cfg)
+	}
+}
+
+func setup(ctx context.Context, s *app.Service, configFiles string, cfg config.Config) ([]app.Listener, context.Context, error) {
 	s.OnShutdown(func() {
 		logging.Print(ctx, "shutdown",
 			zap.String("service", serviceName),
 		)
 	})
 
-	cfg, err := config.Load()
-	if err != nil {
-		logging.Error(ctx, "loading_config", zap.Error(err))
-		return nil, ctx, err
-	}
-
-	store, err := store.New(cfg.PostgresDSN)
+	store, err := store.New(cfg.PostgresDSN, store.WithPool(store.Pool(cfg.Postgres)))
 	if err != nil {
 		logging.Error(ctx, "initialising store", zap.Error(err))
 		return nil, ctx, errors.Wrap(err, "initialising store")
@@ -60,21 +94,146 @@ func setup(ctx context.Context, s *app.Service) ([]app.Listener, context.Context
 		logging.Error(ctx, "unable to migrate")
 		return nil, ctx, errors.Wrap(err, "unable to migrate repository")
 	}
+	if err = metrics.RegisterDB(store.DB); err != nil {
+		logging.Error(ctx, "unable to register database metrics", zap.Error(err))
+		return nil, ctx, errors.Wrap(err, "unable to register database metrics")
+	}
+
+	clock := clockwork.NewRealClock()
+	auditQueue := audit.NewQueue(store, audit.WithClock(clock))
+	privilegedTokens := transporthttp.NewPrivilegedTokens(cfg.PrivilegedTokens)
+	rateLimiter := ratelimit.NewLimiter(clock, rateLimit(cfg.RateLimit), rateLimits(cfg.Merchants))
+	clientRateLimiter := ratelimit.NewLimiter(clock, rateLimit(cfg.ClientRateLimit), nil)
+	reloaders := []reloader{
+		{section: "privileged_tokens", apply: func(cfg config.Config) error {
+			privilegedTokens.Store(cfg.PrivilegedTokens)
+			return nil
+		}},
+		{section: "rate_limit", apply: func(cfg config.Config) error {
+			rateLimiter.SetLimits(rateLimit(cfg.RateLimit), rateLimits(cfg.Merchants))
+			return nil
+		}},
+		{section: "client_rate_limit", apply: func(cfg config.Config) error {
+			clientRateLimiter.SetLimits(rateLimit(cfg.ClientRateLimit), nil)
+			return nil
+		}},
+	}
+
+	svcOpts := []service.Option{
+		service.WithClock(clock),
+		service.WithCardLookup(binTable(cfg.BINRanges)),
+		service.WithFeeCalculator(feeCalculator(cfg.Merchants)),
+		service.WithSettlementCurrencies(settlementCurrencies(cfg.Merchants)),
+		service.WithAuditLog(auditQueue),
+		service.WithMerchants(store),
+	}
+	if cfg.FXRatesFile != "" {
+		rates, err := fx.NewFileProvider(cfg.FXRatesFile)
+		if err != nil {
+			logging.Error(ctx, "loading_fx_rates", zap.Error(err))
+			return nil, ctx, errors.Wrap(err, "loading fx rates")
+		}
+		svcOpts = append(svcOpts, service.WithRateProvider(rates))
+	}
+	if cfg.Risk != nil {
+		engine, err := risk.NewEngine(store, riskConfig(*cfg.Risk))
+		if err != nil {
+			logging.Error(ctx, "creating_risk_engine", zap.Error(err))
+			return nil, ctx, errors.Wrap(err, "creating risk engine")
+		}
+		// the risk rules are applied first as they are the only section which can be rejected.
+		reloaders = append([]reloader{{section: "risk", apply: func(cfg config.Config) error {
+			if cfg.Risk == nil {
+				return errors.New("risk config removed, the risk rules can't be removed without a restart")
+			}
+			return engine.Configure(riskConfig(*cfg.Risk))
+		}}}, reloaders...)
+		svcOpts = append(svcOpts, service.WithRiskAssessor(engine))
+	}
+	if cfg.Velocity != nil {
+		var counter velocity.Counter = store
+		if cfg.Velocity.Store == "memory" {
+			counter = velocity.NewMemoryCounter()
+		}
+		limiter, err := velocity.NewLimiter(counter, clock, velocityLimits(cfg.Velocity.Limits)...)
+		if err != nil {
+			logging.Error(ctx, "creating_velocity_limiter", zap.Error(err))
+			return nil, ctx, errors.Wrap(err, "creating velocity limiter")
+		}
+		svcOpts = append(svcOpts, service.WithVelocityLimiter(limiter))
+	}
+
+	if cfg.Recurring != nil && len(cfg.Recurring.RetryIntervals) > 0 {
+		svcOpts = append(svcOpts, service.WithRetryIntervals(cfg.Recurring.RetryIntervals...))
+	}
 
-	svc, err := service.NewService(store, service.WithClock(clockwork.NewRealClock()))
+	svc, err := service.NewService(store, svcOpts...)
 
 	if err != nil {
 		logging.Error(ctx, "creating_service", zap.Error(err))
 		return nil, ctx, err
 	}
 
-	h, err := transporthttp.NewHTTPHandler(svc, transporthttp.WithAuth(cfg.PrivilegedTokens))
+	handlerOpts := []transporthttp.MiddlewareFunc{
+		transporthttp.WithReloadableAuth(privilegedTokens),
+		transporthttp.WithRequestSigning(signingConfigs(cfg.Merchants), cfg.SignatureWindow),
+		transporthttp.WithClientCertificates(clientCertificates(cfg.Merchants)),
+		transporthttp.WithRateLimit(rateLimiter),
+		transporthttp.WithClientRateLimit(clientRateLimiter),
+		transporthttp.WithAuditLog(),
+		transporthttp.WithAPIVersions(apiVersions(cfg.Merchants)),
+	}
+	if cfg.SimulatedACS {
+		handlerOpts = append(handlerOpts, transporthttp.WithSimulatedACS())
+	}
+	if cfg.MaxBodyBytes > 0 {
+		handlerOpts = append(handlerOpts, transporthttp.WithMaxBodyBytes(cfg.MaxBodyBytes))
+	}
+	h, err := transporthttp.NewHTTPHandler(svc, handlerOpts...)
 	if err != nil {
 		logging.Error(ctx, "creating_http_handler", zap.Error(err))
 		return nil, ctx, err
 	}
 
-	return []app.Listener{httplistener.New(h)}, ctx, nil
+	var listenerOpts []httplistener.Option
+	if cfg.TLS != nil {
+		tlsConfig, err := httplistener.LoadTLSConfig(httplistener.TLSConfig{
+			CertFile:                 cfg.TLS.CertFile,
+			KeyFile:                  cfg.TLS.KeyFile,
+			MinVersion:               cfg.TLS.MinVersion,
+			CipherPolicy:             cfg.TLS.CipherPolicy,
+			ClientCAFile:             cfg.TLS.ClientCAFile,
+			RequireClientCertificate: cfg.TLS.RequireClientCertificate,
+		})
+		if err != nil {
+			logging.Error(ctx, "loading_tls_config", zap.Error(err))
+			return nil, ctx, err
+		}
+		listenerOpts = append(listenerOpts, httplistener.WithTLS(tlsConfig))
+	}
+
+	listenerOpts = append(listenerOpts, httplistener.WithAddr(cfg.HTTP.Addr), listenerTimeouts(cfg.HTTP))
+	listeners := []app.Listener{httplistener.New(h, listenerOpts...), auditQueue}
+	if cfg.Admin != nil {
+		adminHandler, err := transporthttp.NewAdminHandler(svc, cfg.Admin.Tokens)
+		if err != nil {
+			logging.Error(ctx, "creating_admin_handler", zap.Error(err))
+			return nil, ctx, err
+		}
+		listeners = append(listeners, httplistener.New(adminHandler,
+			httplistener.WithAddr(cfg.Admin.Addr), listenerTimeouts(cfg.Admin.Listener)))
+	}
+	if cfg.Recurring != nil {
+		var schedulerOpts []schedulerlistener.Option
+		if cfg.Recurring.BillingInterval > 0 {
+			schedulerOpts = append(schedulerOpts, schedulerlistener.WithInterval(cfg.Recurring.BillingInterval))
+		}
+		listeners = append(listeners, schedulerlistener.New("subscription-billing", svc.BillDueSubscriptions, schedulerOpts...))
+	}
+
+	reloadConfig(ctx, s, configFiles, cfg.ReloadInterval, reloaders)
+
+	return listeners, ctx, nil
 }
 
 const (
@@ -88,3 +247,216 @@ func migrationPath() (string, error) {
 	}
 	return path.Join(wd, defaultMigrationPath), nil
 }
+
+func listenerTimeouts(l config.Listener) httplistener.Option {
+	return httplistener.WithTimeouts(l.ReadTimeout, l.WriteTimeout, l.IdleTimeout)
+}
+
+func tracingConfig(t config.Tracing) tracing.Config {
+	return tracing.Config{
+		Exporter:    t.Exporter,
+		Endpoint:    t.Endpoint,
+		Insecure:    t.Insecure,
+		SampleRatio: t.SampleRatio,
+	}
+}
+
+func binTable(ranges []config.BINRange) *bin.Table {
+	binRanges := make([]bin.Range, 0, len(ranges))
+	for _, r := range ranges {
+		binRanges = append(binRanges, bin.Range{
+			Prefix:  r.Prefix,
+			Scheme:  domain.CardScheme(r.Scheme),
+			Country: r.Country,
+		})
+	}
+	return bin.NewTable(binRanges...)
+}
+
+func feeCalculator(merchants map[string]config.Merchant) *pricing.Calculator {
+	schedules := make(map[string]pricing.Schedule, len(merchants))
+	for merchantID, m := range merchants {
+		rules := make([]pricing.Rule, 0, len(m.Fees))
+		for _, f := range m.Fees {
+			rules = append(rules, pricing.Rule{
+				Action:      domain.PaymentActionType(f.Action),
+				Scheme:      domain.CardScheme(f.Scheme),
+				Currency:    f.Currency,
+				Region:      pricing.Region(f.Region),
+				BasisPoints: f.BasisPoints,
+				Fixed:       f.FixedMinorUnits,
+			})
+		}
+		schedules[merchantID] = pricing.Schedule{
+			Country: m.Country,
+			Rules:   rules,
+		}
+	}
+	return pricing.NewCalculator(schedules)
+}
+
+func settlementCurrencies(merchants map[string]config.Merchant) map[string]string {
+	currencies := make(map[string]string, len(merchants))
+	for merchantID, m := range merchants {
+		if m.SettlementCurrency != "" {
+			currencies[merchantID] = m.SettlementCurrency
+		}
+	}
+	return currencies
+}
+
+func signingConfigs(merchants map[string]config.Merchant) map[string]transporthttp.SigningConfig {
+	configs := make(map[string]transporthttp.SigningConfig, len(merchants))
+	for merchantID, m := range merchants {
+		if m.SigningSecret != "" {
+			configs[merchantID] = transporthttp.SigningConfig{Secret: m.SigningSecret, Required: m.RequireSignedRequests}
+		}
+	}
+	return configs
+}
+
+func clientCertificates(merchants map[string]config.Merchant) map[string]string {
+	subjects := make(map[string]string, len(merchants))
+	for merchantID, m := range merchants {
+		if m.ClientCertificateSubject != "" {
+			subjects[m.ClientCertificateSubject] = merchantID
+		}
+	}
+	return subjects
+}
+
+func apiVersions(merchants map[string]config.Merchant) map[string]string {
+	versions := make(map[string]string, len(merchants))
+	for merchantID, m := range merchants {
+		if m.APIVersion != "" {
+			versions[merchantID] = m.APIVersion
+		}
+	}
+	return versions
+}
+
+func rateLimit(r *config.RateLimit) ratelimit.Limit {
+	if r == nil {
+		return ratelimit.Limit{}
+	}
+	return ratelimit.Limit{RequestsPerSecond: r.RequestsPerSecond, Burst: r.Burst}
+}
+
+func rateLimits(merchants map[string]config.Merchant) map[string]ratelimit.Limit {
+	limits := make(map[string]ratelimit.Limit, len(merchants))
+	for merchantID, m := range merchants {
+		if m.RateLimit != nil {
+			limits[merchantID] = rateLimit(m.RateLimit)
+		}
+	}
+	return limits
+}
+
+func riskConfig(r config.Risk) risk.Config {
+	velocity := func(v *config.VelocityRule) *risk.VelocityConfig {
+		if v == nil {
+			return nil
+		}
+		return &risk.VelocityConfig{Max: v.Max, Window: v.Window, Score: v.Score}
+	}
+
+	cfg := risk.Config{
+		ReviewScore:      r.ReviewScore,
+		BlockScore:       r.BlockScore,
+		CardVelocity:     velocity(r.CardVelocity),
+		RepeatedDeclines: velocity(r.RepeatedDeclines),
+	}
+	if a := r.AmountThreshold; a != nil {
+		cfg.AmountThreshold = &risk.AmountThresholdConfig{Limits: a.Limits, Score: a.Score}
+	}
+	if b := r.BINCountryBlocklist; b != nil {
+		cfg.BINCountry = &risk.BINCountryConfig{Countries: b.Countries, Score: b.Score}
+	}
+	return cfg
+}
+
+func velocityLimits(limits []config.VelocityLimit) []velocity.Limit {
+	out := make([]velocity.Limit, 0, len(limits))
+	for _, l := range limits {
+		out = append(out, velocity.Limit{
+			Dimension: velocity.Dimension(l.Dimension),
+			Window:    l.Window,
+			MaxCount:  l.MaxCount,
+			MaxAmount: l.MaxAmount,
+		})
+	}
+	return out
+}
+
+// reloader applies a section of the config which is reloaded without a restart.
+type reloader struct {
+	section string
+	apply   func(cfg config.Config) error
+}
+
+// reloadConfig reloads the config files on SIGHUP and when they change, checked every interval if not 0, and applies
+// the reloadable sections in order. A config which is invalid or rejected by a section is logged and the sections
+// from it on keep their current config, the other sections require a restart.
+func reloadConfig(ctx context.Context, s *app.Service, configFiles string, interval time.Duration, reloaders []reloader) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	var tick <-chan time.Time
+	stopTicker := func() {}
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		tick, stopTicker = ticker.C, ticker.Stop
+	}
+	modTime, _ := config.ModTime(configFiles)
+
+	done := make(chan struct{})
+	s.OnShutdown(func() {
+		signal.Stop(hup)
+		stopTicker()
+		close(done)
+	})
+
+	sections := make([]string, 0, len(reloaders))
+	for _, r := range reloaders {
+		sections = append(sections, r.section)
+	}
+
+	go func() {
+		for {
+			trigger := "signal"
+			select {
+			case <-done:
+				return
+			case <-hup:
+				modTime, _ = config.ModTime(configFiles)
+			case <-tick:
+				latest, err := config.ModTime(configFiles)
+				if err != nil || latest.Equal(modTime) {
+					continue
+				}
+				trigger, modTime = "file_change", latest
+			}
+
+			if err := applyConfig(configFiles, reloaders); err != nil {
+				metrics.ObserveConfigReload(metrics.ReloadFailure)
+				logging.Error(ctx, "reloading_config", zap.String("trigger", trigger), zap.Error(err))
+				continue
+			}
+			metrics.ObserveConfigReload(metrics.ReloadSuccess)
+			logging.Print(ctx, "config reloaded", zap.String("trigger", trigger), zap.Strings("sections", sections))
+		}
+	}()
+}
+
+func applyConfig(configFiles string, reloaders []reloader) error {
+	cfg, err := config.Load(configFiles)
+	if err != nil {
+		return err
+	}
+	for _, r := range reloaders {
+		if err := r.apply(cfg); err != nil {
+			return errors.Wrapf(err, "reloading %s", r.section)
+		}
+	}
+	return nil
+}
@@ -6,17 +6,19 @@ require (
 	github.com/brianvoe/gofakeit/v6 v6.5.0
 	github.com/golang-migrate/migrate/v4 v4.14.1
 	github.com/golang/mock v1.4.4
-	github.com/google/go-cmp v0.5.6 // indirect
 	github.com/gorilla/mux v1.7.4
 	github.com/jonboulle/clockwork v0.2.2
 	github.com/kevinburke/go.uuid v1.2.0
 	github.com/lib/pq v1.10.2
-	github.com/opentracing/opentracing-go v1.2.0 // indirect
-	github.com/philhofer/fwd v1.1.1 // indirect
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.11.0
 	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.0.1
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.0.1
+	go.opentelemetry.io/otel/sdk v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
 	go.uber.org/automaxprocs v1.4.0
 	go.uber.org/zap v1.17.0
-	gopkg.in/DataDog/dd-trace-go.v1 v1.31.1
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
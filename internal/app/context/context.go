@@ -5,8 +5,13 @@ import "context"
 type CtxKey string
 
 const (
-	ContextAPI     CtxKey = "api"
-	ContextService CtxKey = "service"
+	ContextAPI      CtxKey = "api"
+	ContextService  CtxKey = "service"
+	ContextMerchant CtxKey = "merchant"
+	// ContextCorrelationID is the id correlating the log lines and the responses of a request.
+	ContextCorrelationID CtxKey = "correlation_id"
+	// ContextTokenID identifies the token the request has been authenticated with.
+	ContextTokenID CtxKey = "token_id"
 )
 
 func WithAPI(ctx context.Context, api string) context.Context {
@@ -24,3 +29,45 @@ func GetAPI(ctx context.Context) string {
 func WithService(ctx context.Context, service string) context.Context {
 	return context.WithValue(ctx, ContextService, service)
 }
+
+// WithMerchant adds the authenticated merchant to the context.
+func WithMerchant(ctx context.Context, merchant string) context.Context {
+	return context.WithValue(ctx, ContextMerchant, merchant)
+}
+
+// GetMerchant returns the authenticated merchant, empty if the request is not authenticated.
+func GetMerchant(ctx context.Context) string {
+	merchant := ctx.Value(ContextMerchant)
+	if merchant != nil {
+		return merchant.(string)
+	}
+	return ""
+}
+
+// WithCorrelationID adds the correlation id of the request to the context.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextCorrelationID, id)
+}
+
+// GetCorrelationID returns the correlation id of the request, empty if there is none.
+func GetCorrelationID(ctx context.Context) string {
+	id := ctx.Value(ContextCorrelationID)
+	if id != nil {
+		return id.(string)
+	}
+	return ""
+}
+
+// WithTokenID adds the id of the token the request has been authenticated with to the context.
+func WithTokenID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextTokenID, id)
+}
+
+// GetTokenID returns the id of the token the request has been authenticated with, empty if the request is not authenticated.
+func GetTokenID(ctx context.Context) string {
+	id := ctx.Value(ContextTokenID)
+	if id != nil {
+		return id.(string)
+	}
+	return ""
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	gmux "github.com/gorilla/mux"
-	"gopkg.in/DataDog/dd-trace-go.v1/contrib/gorilla/mux"
 
 	"github.com/jeffreyyong/payment-gateway/internal/logging"
 )
@@ -18,10 +17,14 @@ type Listener struct {
 	handler                  Handler
 	addr                     string
 	isRequestLoggingDisabled bool
+	isRequestMetricsDisabled bool
+	isRequestTracingDisabled bool
 }
 
 type handlerOptions struct {
 	isRequestLoggingDisabled bool
+	isRequestMetricsDisabled bool
+	isRequestTracingDisabled bool
 }
 
 type handlerOptsFunc func(h *handlerOptions)
@@ -30,11 +33,19 @@ func withRequestLoggingDisabled() handlerOptsFunc {
 	return func(h *handlerOptions) { h.isRequestLoggingDisabled = true }
 }
 
+func withRequestMetricsDisabled() handlerOptsFunc {
+	return func(h *handlerOptions) { h.isRequestMetricsDisabled = true }
+}
+
+func withRequestTracingDisabled() handlerOptsFunc {
+	return func(h *handlerOptions) { h.isRequestTracingDisabled = true }
+}
+
 // HTTPHandler is exposed for convince for writing tests, it should
 // not be used for creating HTTP servers directly.
 func HTTPHandler(h Handler, opts ...handlerOptsFunc) http.Handler {
-	t := mux.NewRouter()
-	m := &Mux{t.Router}
+	t := gmux.NewRouter()
+	m := &Mux{t}
 	h.ApplyRoutes(m)
 
 	opt := &handlerOptions{}
@@ -59,10 +70,17 @@ func HTTPHandler(h Handler, opts ...handlerOptsFunc) http.Handler {
 
 		middleware := []MiddlewareFunc{
 			APIMiddleware(name),
-			ContextMiddleware(),
+			CorrelationMiddleware,
+		}
+		if !opt.isRequestTracingDisabled {
+			middleware = append(middleware, TracingMiddleware) // depends on APIMiddleware and CorrelationMiddleware
+		}
+		middleware = append(middleware, ContextMiddleware())
+		if !opt.isRequestMetricsDisabled {
+			middleware = append(middleware, MetricsMiddleware) // depends on APIMiddleware
 		}
 		if !opt.isRequestLoggingDisabled {
-			middleware = append(middleware, LoggingMiddleware) // depends on SpanMiddleware and ContextMiddleware
+			middleware = append(middleware, LoggingMiddleware) // depends on TracingMiddleware and ContextMiddleware
 		}
 
 		f := h.ServeHTTP
@@ -100,11 +118,30 @@ func WithAddr(addr string) Option {
 	return func(l *Listener) { l.addr = addr }
 }
 
+// WithTimeouts sets the read, write and idle timeouts of the server, 5s, 10s and 120s if not set.
+func WithTimeouts(read, write, idle time.Duration) Option {
+	return func(l *Listener) {
+		l.server.ReadTimeout = read
+		l.server.WriteTimeout = write
+		l.server.IdleTimeout = idle
+	}
+}
+
 // WithRequestLoggingDisabled explicitly disables the logging middleware.
 func WithRequestLoggingDisabled() Option {
 	return func(l *Listener) { l.isRequestLoggingDisabled = true }
 }
 
+// WithRequestMetricsDisabled explicitly disables the metrics middleware.
+func WithRequestMetricsDisabled() Option {
+	return func(l *Listener) { l.isRequestMetricsDisabled = true }
+}
+
+// WithRequestTracingDisabled explicitly disables the tracing middleware.
+func WithRequestTracingDisabled() Option {
+	return func(l *Listener) { l.isRequestTracingDisabled = true }
+}
+
 func New(h Handler, opts ...Option) *Listener {
 	l := &Listener{
 		server: &http.Server{
@@ -132,19 +169,24 @@ func New(h Handler, opts ...Option) *Listener {
 func (l *Listener) Name() string { return "http" }
 
 func (l *Listener) Serve(ctx context.Context) error {
-	// creating this here so that the DataDog tracing setup pulls in
-	// the service name from the global config. If it was done in New
-	// it is possible for users to call it before app.Run has been called
-	// which is what will setup the DataDog tracer and inturn its global config.
-	// This means users will not get their traces in APM and not be sure why.
-
 	opts := []handlerOptsFunc{}
 	if l.isRequestLoggingDisabled {
 		opts = append(opts, withRequestLoggingDisabled())
 	}
+	if l.isRequestMetricsDisabled {
+		opts = append(opts, withRequestMetricsDisabled())
+	}
+	if l.isRequestTracingDisabled {
+		opts = append(opts, withRequestTracingDisabled())
+	}
 
 	l.server.Handler = HTTPHandler(l.handler, opts...)
-	if err := l.server.ListenAndServe(); err != nil {
+	serve := l.server.ListenAndServe
+	if l.server.TLSConfig != nil {
+		// the certificates are in the TLS config
+		serve = func() error { return l.server.ListenAndServeTLS("", "") }
+	}
+	if err := serve(); err != nil {
 		if errors.Is(err, http.ErrServerClosed) {
 			return nil
 		}
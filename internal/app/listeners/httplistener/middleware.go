@@ -6,14 +6,33 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	uuid "github.com/kevinburke/go.uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
 	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
 )
 
+// headers of the correlation id of the request
+const (
+	HeaderRequestID     = "X-Request-ID"
+	HeaderCorrelationID = "X-Correlation-ID"
+)
+
+// maxCorrelationIDLength is the max length of a correlation id accepted from the request headers.
+const maxCorrelationIDLength = 128
+
 // MiddlewareFunc defines a middleware type
 type MiddlewareFunc func(h http.HandlerFunc) http.HandlerFunc
 
@@ -28,6 +47,66 @@ func APIMiddleware(api string) MiddlewareFunc {
 	}
 }
 
+// CorrelationMiddleware accepts the correlation id of the X-Request-ID or X-Correlation-ID request header,
+// or generates one, adds it to the context and the logger and echoes it in the response headers
+func CorrelationMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := HeaderRequestID
+		id := r.Header.Get(HeaderRequestID)
+		if id == "" && r.Header.Get(HeaderCorrelationID) != "" {
+			header = HeaderCorrelationID
+			id = r.Header.Get(HeaderCorrelationID)
+		}
+		if !validCorrelationID(id) {
+			id = uuid.NewV4().String()
+		}
+
+		w.Header().Set(HeaderRequestID, id)
+		if header == HeaderCorrelationID {
+			w.Header().Set(HeaderCorrelationID, id)
+		}
+
+		ctx := appcontext.WithCorrelationID(r.Context(), id)
+		ctx = logging.WithFields(ctx, zap.String(logging.CorrelationID, id))
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// validCorrelationID checks that the correlation id from the request headers is safe to log and echo.
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.ContainsRune("-_.:", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// TracingMiddleware starts the server span of the api, continuing the trace of the W3C trace context headers if any
+func TracingMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		api := appcontext.GetAPI(r.Context())
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, api,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", api, r)...),
+			trace.WithAttributes(attribute.String(logging.CorrelationID, appcontext.GetCorrelationID(ctx))),
+		)
+		defer span.End()
+
+		lw := newResponseRecorder(w)
+		next(lw, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(lw.StatusCode))
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(lw.StatusCode))
+	}
+}
+
 // ContextMiddleware adds service context to zapcontext and the context
 func ContextMiddleware() MiddlewareFunc {
 	service := os.Getenv("SERVICE")
@@ -72,7 +151,7 @@ func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
 	}
 }
 
-// LoggingMiddleware logs the request and response events using the default logger and Jaeger
+// LoggingMiddleware logs the request and response events using the logger of the context
 func LoggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -99,3 +178,22 @@ func LoggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		logging.Print(r.Context(), "app__httplistener__response_sent", fields...)
 	}
 }
+
+// MetricsMiddleware observes the requests in flight and the latency of the handler by api, method and status code
+func MetricsMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		api := appcontext.GetAPI(r.Context())
+
+		inFlight := metrics.HTTPRequestsInFlight.WithLabelValues(api)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		lw := newResponseRecorder(w)
+		next(lw, r)
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(api, r.Method, strconv.Itoa(lw.StatusCode)).
+			Observe(time.Since(start).Seconds())
+	}
+}
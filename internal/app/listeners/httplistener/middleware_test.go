@@ -0,0 +1,112 @@
+package httplistener_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+type handlerFunc func(m *httplistener.Mux)
+
+func (f handlerFunc) ApplyRoutes(m *httplistener.Mux) { f(m) }
+
+func TestTracingMiddleware(t *testing.T) {
+	_, err := tracing.Init(context.Background(), "payment-gateway", tracing.Config{})
+	require.NoError(t, err)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	var spanContext trace.SpanContext
+	h := httplistener.HTTPHandler(handlerFunc(func(m *httplistener.Mux) {
+		m.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+			spanContext = trace.SpanContextFromContext(r.Context())
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/authorize", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "/authorize", ended[0].Name())
+	assert.Equal(t, trace.SpanKindServer, ended[0].SpanKind())
+	assert.Equal(t, "00f067aa0ba902b7", ended[0].Parent().SpanID().String())
+	assert.Equal(t, spanContext.SpanID(), ended[0].SpanContext().SpanID())
+}
+
+func TestCorrelationMiddleware(t *testing.T) {
+	testCases := []struct {
+		description          string
+		headers              map[string]string
+		expectedID           string
+		expectedEchoedHeader string
+	}{
+		{
+			description:          "accepts the request id",
+			headers:              map[string]string{httplistener.HeaderRequestID: "req-123"},
+			expectedID:           "req-123",
+			expectedEchoedHeader: httplistener.HeaderRequestID,
+		},
+		{
+			description:          "accepts the correlation id",
+			headers:              map[string]string{httplistener.HeaderCorrelationID: "corr-123"},
+			expectedID:           "corr-123",
+			expectedEchoedHeader: httplistener.HeaderCorrelationID,
+		},
+		{
+			description: "generates an id without header",
+		},
+		{
+			description: "generates an id instead of an unsafe one",
+			headers:     map[string]string{httplistener.HeaderRequestID: "req\n123"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			var correlationID string
+			h := httplistener.HTTPHandler(handlerFunc(func(m *httplistener.Mux) {
+				m.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+					correlationID = appcontext.GetCorrelationID(r.Context())
+				})
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/authorize", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			h.ServeHTTP(w, r)
+
+			require.NotEmpty(t, correlationID)
+			if tt.expectedID != "" {
+				assert.Equal(t, tt.expectedID, correlationID)
+			} else {
+				_, err := uuid.FromString(correlationID)
+				assert.NoError(t, err, "the generated id is a uuid")
+			}
+			assert.Equal(t, correlationID, w.Header().Get(httplistener.HeaderRequestID))
+			if tt.expectedEchoedHeader != "" {
+				assert.Equal(t, correlationID, w.Header().Get(tt.expectedEchoedHeader))
+			}
+		})
+	}
+}
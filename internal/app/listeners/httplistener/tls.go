@@ -0,0 +1,107 @@
+package httplistener
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// cipher policies
+const (
+	// CipherPolicyIntermediate accepts TLS 1.2 with the forward secret AEAD cipher suites, and TLS 1.3.
+	CipherPolicyIntermediate = "intermediate"
+	// CipherPolicyModern only accepts TLS 1.3, whose cipher suites are all forward secret AEAD.
+	CipherPolicyModern = "modern"
+)
+
+// intermediateCipherSuites are the TLS 1.2 cipher suites of the intermediate policy, the TLS 1.3 ones are not configurable.
+var intermediateCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig is the configuration of the TLS termination of a listener.
+type TLSConfig struct {
+	// CertFile and KeyFile are the PEM encoded certificate chain and private key of the server.
+	CertFile string
+	KeyFile  string
+	// MinVersion is the minimum TLS version, 1.2 or 1.3, 1.2 if empty.
+	MinVersion string
+	// CipherPolicy is CipherPolicyIntermediate or CipherPolicyModern, CipherPolicyIntermediate if empty.
+	CipherPolicy string
+	// ClientCAFile is the PEM encoded CA certificates the client certificates are verified against,
+	// the client certificates are not requested if empty.
+	ClientCAFile string
+	// RequireClientCertificate rejects the connections without a client certificate, otherwise a client
+	// certificate is only verified if given, so that the clients without one can authenticate with a token.
+	RequireClientCertificate bool
+}
+
+// LoadTLSConfig loads the certificates of the configuration into a tls.Config.
+func LoadTLSConfig(c TLSConfig) (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New("tls: cert file and key file are required")
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: loading key pair: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.MinVersion != "" {
+		version, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("tls: unsupported min version %q", c.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	switch c.CipherPolicy {
+	case "", CipherPolicyIntermediate:
+		config.CipherSuites = intermediateCipherSuites
+	case CipherPolicyModern:
+		config.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("tls: unknown cipher policy %q", c.CipherPolicy)
+	}
+
+	if c.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: reading client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificate in client CA file %s", c.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+		if c.RequireClientCertificate {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if c.RequireClientCertificate {
+		return nil, errors.New("tls: client CA file is required to require client certificates")
+	}
+
+	return config, nil
+}
+
+// WithTLS serves HTTPS with the TLS configuration, see LoadTLSConfig.
+func WithTLS(config *tls.Config) Option {
+	return func(l *Listener) { l.server.TLSConfig = config }
+}
@@ -0,0 +1,192 @@
+package httplistener_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+)
+
+func TestLoadTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newCertificate(t, "ca", nil, nil)
+	server, serverKey := newCertificate(t, "localhost", ca, caKey)
+	certFile, keyFile := writeCertificate(t, dir, "server", server, serverKey)
+	caFile, _ := writeCertificate(t, dir, "ca", ca, caKey)
+
+	testCases := []struct {
+		description   string
+		config        httplistener.TLSConfig
+		expectedError string
+		assertConfig  func(t *testing.T, config *tls.Config)
+	}{
+		{
+			description: "should default to TLS 1.2 with the intermediate cipher suites",
+			config:      httplistener.TLSConfig{CertFile: certFile, KeyFile: keyFile},
+			assertConfig: func(t *testing.T, config *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+				assert.NotEmpty(t, config.CipherSuites)
+				assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+			},
+		},
+		{
+			description: "should only accept TLS 1.3 with the modern policy",
+			config:      httplistener.TLSConfig{CertFile: certFile, KeyFile: keyFile, CipherPolicy: httplistener.CipherPolicyModern},
+			assertConfig: func(t *testing.T, config *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+			},
+		},
+		{
+			description: "should verify the client certificates against the client CA",
+			config:      httplistener.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, RequireClientCertificate: true},
+			assertConfig: func(t *testing.T, config *tls.Config) {
+				assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+				assert.NotNil(t, config.ClientCAs)
+			},
+		},
+		{
+			description:   "should fail without key pair",
+			config:        httplistener.TLSConfig{CertFile: certFile},
+			expectedError: "tls: cert file and key file are required",
+		},
+		{
+			description:   "should fail with an unsupported min version",
+			config:        httplistener.TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"},
+			expectedError: `tls: unsupported min version "1.0"`,
+		},
+		{
+			description:   "should fail with an unknown cipher policy",
+			config:        httplistener.TLSConfig{CertFile: certFile, KeyFile: keyFile, CipherPolicy: "legacy"},
+			expectedError: `tls: unknown cipher policy "legacy"`,
+		},
+		{
+			description:   "should fail to require client certificates without client CA",
+			config:        httplistener.TLSConfig{CertFile: certFile, KeyFile: keyFile, RequireClientCertificate: true},
+			expectedError: "tls: client CA file is required to require client certificates",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			config, err := httplistener.LoadTLSConfig(tt.config)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			tt.assertConfig(t, config)
+		})
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newCertificate(t, "ca", nil, nil)
+	server, serverKey := newCertificate(t, "localhost", ca, caKey)
+	client, clientKey := newCertificate(t, "merchant-1.example.com", ca, caKey)
+	certFile, keyFile := writeCertificate(t, dir, "server", server, serverKey)
+	caFile, _ := writeCertificate(t, dir, "ca", ca, caKey)
+
+	config, err := httplistener.LoadTLSConfig(httplistener.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	require.NoError(t, err)
+
+	var subject string
+	srv := httptest.NewUnstartedServer(httplistener.HTTPHandler(handlerFunc(func(m *httplistener.Mux) {
+		m.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+			subject = ""
+			if len(r.TLS.VerifiedChains) > 0 {
+				subject = r.TLS.VerifiedChains[0][0].Subject.CommonName
+			}
+		})
+	})))
+	srv.TLS = config
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	// the client certificate is always sent even if it is not issued by the client CA
+	clientWith := func(certificate *tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs: roots,
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if certificate == nil {
+					return &tls.Certificate{}, nil
+				}
+				return certificate, nil
+			},
+		}}}
+	}
+
+	resp, err := clientWith(&tls.Certificate{Certificate: [][]byte{client.Raw}, PrivateKey: clientKey}).Get(srv.URL + "/authorize")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "merchant-1.example.com", subject)
+
+	resp, err = clientWith(nil).Get(srv.URL + "/authorize")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, subject)
+
+	other, otherKey := newCertificate(t, "other-ca", nil, nil)
+	_, err = clientWith(&tls.Certificate{Certificate: [][]byte{other.Raw}, PrivateKey: otherKey}).Get(srv.URL + "/authorize")
+	assert.Error(t, err)
+}
+
+// newCertificate returns a certificate of the common name signed by the parent, a self-signed CA without parent.
+func newCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{commonName},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// writeCertificate writes the certificate and its key PEM encoded in the directory and returns their paths.
+func writeCertificate(t *testing.T, dir, name string, cert *x509.Certificate, key *ecdsa.PrivateKey) (string, string) {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+	return certFile, keyFile
+}
@@ -0,0 +1,96 @@
+package schedulerlistener
+
+import (
+	"context"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+// Job is run on schedule by the Listener.
+type Job func(ctx context.Context) error
+
+// Listener runs a Job at every interval until it is closed, the runs don't overlap.
+type Listener struct {
+	name     string
+	job      Job
+	interval time.Duration
+	clock    clockwork.Clock
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+type Option func(*Listener)
+
+// WithInterval sets the interval between the runs of the job.
+func WithInterval(interval time.Duration) Option {
+	return func(l *Listener) { l.interval = interval }
+}
+
+// WithClock sets the clock the runs are scheduled with.
+func WithClock(clock clockwork.Clock) Option {
+	return func(l *Listener) { l.clock = clock }
+}
+
+func New(name string, job Job, opts ...Option) *Listener {
+	l := &Listener{
+		name:     name,
+		job:      job,
+		interval: time.Minute,
+		clock:    clockwork.NewRealClock(),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+func (l *Listener) Name() string { return l.name }
+
+// Serve runs the job straight away and then at every interval, an error of the job is logged
+// and the job is run again at the next interval.
+func (l *Listener) Serve(ctx context.Context) error {
+	defer close(l.stopped)
+
+	ctx = logging.WithFields(ctx, zap.String("scheduler", l.name))
+	ticker := l.clock.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := l.job(ctx); err != nil {
+			logging.Error(ctx, "scheduled job failed", zap.Error(err))
+		}
+
+		select {
+		case <-l.done:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-ticker.Chan():
+		}
+	}
+}
+
+// Close stops the scheduling and waits for the running job to finish.
+func (l *Listener) Close(ctx context.Context) error {
+	select {
+	case <-l.done:
+	default:
+		close(l.done)
+	}
+
+	select {
+	case <-l.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
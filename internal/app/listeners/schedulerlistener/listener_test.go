@@ -0,0 +1,47 @@
+package schedulerlistener_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/schedulerlistener"
+)
+
+func TestListener_Serve(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	runs := make(chan struct{}, 10)
+	job := func(context.Context) error {
+		runs <- struct{}{}
+		return errors.New("kaboom")
+	}
+
+	l := schedulerlistener.New("billing", job, schedulerlistener.WithInterval(time.Minute), schedulerlistener.WithClock(clock))
+	assert.Equal(t, "billing", l.Name())
+
+	served := make(chan error, 1)
+	go func() { served <- l.Serve(context.Background()) }()
+
+	// runs straight away, then at every interval even after a failed run
+	waitForRun(t, runs)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	waitForRun(t, runs)
+
+	require.NoError(t, l.Close(context.Background()))
+	assert.NoError(t, <-served)
+}
+
+func waitForRun(t *testing.T, runs <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("job has not run")
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/jeffreyyong/payment-gateway/internal/app/healthcheck"
 	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
 	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
 )
 
 type Listener interface {
@@ -47,12 +49,28 @@ type options struct {
 	timeout         time.Duration
 	healthAddr      string
 	shutdownTimeout time.Duration
+	tracing         tracing.Config
 
 	hooks hooks
 }
 
 type Option func(*options)
 
+// WithTracing sets the config of the tracing initialised by Run.
+func WithTracing(cfg tracing.Config) Option {
+	return func(o *options) { o.tracing = cfg }
+}
+
+// WithHealthAddr sets the address of the listener of the health checks, the metrics and pprof, :8082 if not set.
+func WithHealthAddr(addr string) Option {
+	return func(o *options) { o.healthAddr = addr }
+}
+
+// WithShutdownTimeout sets how long the listeners are given to shut down, 5s if not set.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.shutdownTimeout = timeout }
+}
+
 func defaultOpts() options {
 	return options{
 		timeout:         1 * time.Minute,
@@ -83,7 +101,12 @@ func Run(name string, setup SetupFunc, opts ...Option) error {
 }
 
 func (s *Service) earlyInit(ctx context.Context, errs chan<- error) []Listener {
-	health := httplistener.New(&s.healthHandler, httplistener.WithAddr(s.opts.healthAddr), httplistener.WithRequestLoggingDisabled())
+	health := httplistener.New(&s.healthHandler,
+		httplistener.WithAddr(s.opts.healthAddr),
+		httplistener.WithRequestLoggingDisabled(),
+		httplistener.WithRequestMetricsDisabled(),
+		httplistener.WithRequestTracingDisabled(),
+	)
 	s.launch(ctx, health, errs)
 	return []Listener{health}
 }
@@ -207,6 +230,18 @@ func (s *Service) run(ctx context.Context, setupFunc SetupFunc) error {
 
 	logger.Info("set GOMAXPROCS", zap.Int("num_cpu", runtime.NumCPU()), zap.Int("GOMAXPROCS", runtime.GOMAXPROCS(0)))
 
+	shutdownTracing, err := tracing.Init(ctx, s.name, s.opts.tracing)
+	if err != nil {
+		return fmt.Errorf("app: unable to initialise tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(Context(), s.opts.shutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("error shutting down tracing", zap.Error(err))
+		}
+	}()
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -259,6 +294,7 @@ func (h *healthHandler) ApplyRoutes(m *httplistener.Mux) {
 	m.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	m.NewRoute().PathPrefix("/debug/pprof").HandlerFunc(pprof.Index)
 	m.Handle("/debug/vars", expvar.Handler())
+	m.Handle("/metrics", metrics.Handler())
 }
 func (h *healthHandler) LivenessHandler(rw http.ResponseWriter, r *http.Request) {
 	handler, _ := h.liveness.Load().(http.Handler)
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+)
+
+const (
+	defaultInterval    = time.Second
+	defaultMaxPending  = 10000
+	defaultMaxAttempts = 3
+)
+
+// Log is the append-only audit log the entries are written to.
+type Log interface {
+	AppendAuditEntries(ctx context.Context, entries []*domain.AuditEntry) error
+	ListAuditEntries(ctx context.Context, afterSequence int64, limit int) ([]*domain.AuditEntry, error)
+}
+
+// Queue queues the entries of the audit log and appends them in batches at every interval, off the path of
+// the requests, so that the audit log is locked once per batch rather than once per entry. It is run as a
+// listener of the app and appends the remaining entries when it is closed. The entries are appended
+// directly when the queue is closed or full. A batch which cannot be appended after the max attempts is
+// dropped, the dropped entries are logged and counted in the audit_entries_dropped_total metric.
+type Queue struct {
+	log         Log
+	clock       clockwork.Clock
+	interval    time.Duration
+	maxPending  int
+	maxAttempts int
+
+	mu       sync.Mutex
+	pending  []*domain.AuditEntry
+	attempts int
+	closed   bool
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+type Option func(*Queue)
+
+// WithInterval sets the interval between the appends of the queued entries.
+func WithInterval(interval time.Duration) Option {
+	return func(q *Queue) { q.interval = interval }
+}
+
+// WithClock sets the clock the appends are scheduled with.
+func WithClock(clock clockwork.Clock) Option {
+	return func(q *Queue) { q.clock = clock }
+}
+
+// WithMaxPending sets the number of queued entries over which the entries are appended directly.
+func WithMaxPending(maxPending int) Option {
+	return func(q *Queue) { q.maxPending = maxPending }
+}
+
+// WithMaxAttempts sets the number of failed appends after which the queued entries are dropped.
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(q *Queue) { q.maxAttempts = maxAttempts }
+}
+
+// NewQueue initialises the queue of the entries appended to the log.
+func NewQueue(log Log, opts ...Option) *Queue {
+	q := &Queue{
+		log:         log,
+		clock:       clockwork.NewRealClock(),
+		interval:    defaultInterval,
+		maxPending:  defaultMaxPending,
+		maxAttempts: defaultMaxAttempts,
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
+}
+
+// AppendAuditEntry queues the entry, it is chained and appended to the log by the next batch.
+func (q *Queue) AppendAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	q.mu.Lock()
+	if !q.closed && len(q.pending) < q.maxPending {
+		q.pending = append(q.pending, entry)
+		q.mu.Unlock()
+		return nil
+	}
+	q.mu.Unlock()
+
+	if err := q.log.AppendAuditEntries(ctx, []*domain.AuditEntry{entry}); err != nil {
+		metrics.ObserveAuditEntriesDropped(1)
+		return err
+	}
+	return nil
+}
+
+// ListAuditEntries returns up to limit entries of the log after the sequence, the queued entries are not listed.
+func (q *Queue) ListAuditEntries(ctx context.Context, afterSequence int64, limit int) ([]*domain.AuditEntry, error) {
+	return q.log.ListAuditEntries(ctx, afterSequence, limit)
+}
+
+func (q *Queue) Name() string { return "audit-log" }
+
+// Serve appends the queued entries at every interval until the queue is closed, the entries of a failed
+// append are kept queued and appended by the next batch until the max attempts.
+func (q *Queue) Serve(ctx context.Context) error {
+	defer close(q.stopped)
+
+	ticker := q.clock.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.done:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-ticker.Chan():
+			if err := q.flush(ctx, false); err != nil {
+				logging.Error(ctx, "unable to append audit entries", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Close stops the batches and appends the remaining entries with the shutdown context, the entries queued
+// from then on are appended directly. The remaining entries are appended even if the batches have not
+// stopped by the deadline of the context, they are dropped if the append fails.
+func (q *Queue) Close(ctx context.Context) error {
+	select {
+	case <-q.done:
+	default:
+		close(q.done)
+	}
+	select {
+	case <-q.stopped:
+	case <-ctx.Done():
+	}
+	return q.flush(ctx, true)
+}
+
+// flush appends the queued entries. If the append fails they are queued again, up to the max pending
+// entries, unless the queue is closed or the entries have failed the max attempts, in which case they are
+// dropped.
+func (q *Queue) flush(ctx context.Context, closing bool) error {
+	q.mu.Lock()
+	entries := q.pending
+	q.pending = nil
+	q.closed = q.closed || closing
+	q.mu.Unlock()
+
+	if len(entries) == 0 {
+		return nil
+	}
+	err := q.log.AppendAuditEntries(ctx, entries)
+
+	q.mu.Lock()
+	if err == nil {
+		q.attempts = 0
+		q.mu.Unlock()
+		return nil
+	}
+	q.attempts++
+	var dropped []*domain.AuditEntry
+	if q.closed || q.attempts >= q.maxAttempts {
+		q.attempts = 0
+		dropped = entries
+	} else {
+		pending := append(entries, q.pending...)
+		if len(pending) > q.maxPending {
+			dropped = pending[q.maxPending:]
+			pending = pending[:q.maxPending]
+		}
+		q.pending = pending
+	}
+	q.mu.Unlock()
+
+	if len(dropped) > 0 {
+		metrics.ObserveAuditEntriesDropped(len(dropped))
+		logging.Error(ctx, "audit entries dropped", zap.Int("audit.dropped", len(dropped)), zap.Error(err))
+	}
+	return err
+}
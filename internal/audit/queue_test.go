@@ -0,0 +1,165 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/audit"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+)
+
+// fakeLog records the batches appended, the next append fails with err if set, or the next failures appends
+// if failures is set.
+type fakeLog struct {
+	mu       sync.Mutex
+	batches  [][]*domain.AuditEntry
+	err      error
+	failures int
+	appends  chan struct{}
+}
+
+func newFakeLog() *fakeLog {
+	return &fakeLog{appends: make(chan struct{}, 10)}
+}
+
+func (l *fakeLog) AppendAuditEntries(_ context.Context, entries []*domain.AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer func() { l.appends <- struct{}{} }()
+
+	if l.err != nil {
+		err := l.err
+		if l.failures--; l.failures <= 0 {
+			l.err = nil
+		}
+		return err
+	}
+	l.batches = append(l.batches, entries)
+	return nil
+}
+
+func (l *fakeLog) ListAuditEntries(context.Context, int64, int) ([]*domain.AuditEntry, error) {
+	return nil, nil
+}
+
+func (l *fakeLog) appended() [][]*domain.AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.batches
+}
+
+func entry(operation string) *domain.AuditEntry {
+	return &domain.AuditEntry{Operation: operation, Outcome: "200"}
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should append the queued entries in batches and the remaining ones when closed", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		log := newFakeLog()
+		log.err = errors.New("kaboom")
+		q := audit.NewQueue(log, audit.WithInterval(time.Second), audit.WithClock(clock))
+		assert.Equal(t, "audit-log", q.Name())
+
+		served := make(chan error, 1)
+		go func() { served <- q.Serve(ctx) }()
+
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("POST /authorize")))
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("capture")))
+		assert.Empty(t, log.appended())
+
+		// the entries of the failed append are appended by the next batch
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		waitForAppend(t, log)
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("POST /capture")))
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		waitForAppend(t, log)
+		require.Len(t, log.appended(), 1)
+		assert.Equal(t, []*domain.AuditEntry{entry("POST /authorize"), entry("capture"), entry("POST /capture")}, log.appended()[0])
+
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("POST /void")))
+		require.NoError(t, q.Close(ctx))
+		assert.NoError(t, <-served)
+		require.Len(t, log.appended(), 2)
+		assert.Equal(t, []*domain.AuditEntry{entry("POST /void")}, log.appended()[1])
+
+		// the entries are appended directly once the queue is closed
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("void")))
+		require.Len(t, log.appended(), 3)
+	})
+
+	t.Run("should append the entries directly when the queue is full", func(t *testing.T) {
+		log := newFakeLog()
+		q := audit.NewQueue(log, audit.WithMaxPending(1))
+
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("POST /authorize")))
+		assert.Empty(t, log.appended())
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("POST /capture")))
+		require.Len(t, log.appended(), 1)
+		assert.Equal(t, []*domain.AuditEntry{entry("POST /capture")}, log.appended()[0])
+	})
+
+	t.Run("should drop the entries which failed the max attempts", func(t *testing.T) {
+		dropped := testutil.ToFloat64(metrics.AuditEntriesDropped)
+		clock := clockwork.NewFakeClock()
+		log := newFakeLog()
+		log.err = errors.New("kaboom")
+		log.failures = 2
+		q := audit.NewQueue(log, audit.WithClock(clock), audit.WithMaxAttempts(2))
+
+		served := make(chan error, 1)
+		go func() { served <- q.Serve(ctx) }()
+
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("POST /authorize")))
+		for i := 0; i < 2; i++ {
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+			waitForAppend(t, log)
+		}
+		assert.Equal(t, dropped+1, testutil.ToFloat64(metrics.AuditEntriesDropped))
+
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("POST /capture")))
+		require.NoError(t, q.Close(ctx))
+		assert.NoError(t, <-served)
+		require.Len(t, log.appended(), 1)
+		assert.Equal(t, []*domain.AuditEntry{entry("POST /capture")}, log.appended()[0])
+	})
+
+	t.Run("should append the remaining entries when closed before the batches are served", func(t *testing.T) {
+		dropped := testutil.ToFloat64(metrics.AuditEntriesDropped)
+		log := newFakeLog()
+		q := audit.NewQueue(log)
+
+		require.NoError(t, q.AppendAuditEntry(ctx, entry("POST /authorize")))
+		closeCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		require.NoError(t, q.Close(closeCtx))
+		require.Len(t, log.appended(), 1)
+
+		// the entries appended directly once the queue is closed are counted as dropped when the append fails
+		log.err = errors.New("kaboom")
+		assert.Error(t, q.AppendAuditEntry(ctx, entry("POST /capture")))
+		assert.Equal(t, dropped+1, testutil.ToFloat64(metrics.AuditEntriesDropped))
+	})
+}
+
+func waitForAppend(t *testing.T, log *fakeLog) {
+	t.Helper()
+	select {
+	case <-log.appends:
+	case <-time.After(time.Second):
+		t.Fatal("entries have not been appended")
+	}
+}
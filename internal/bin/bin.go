@@ -0,0 +1,78 @@
+package bin
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+// Range maps a BIN prefix to the card information of the cards issued under it.
+// Scheme is optional and derived from the PAN when empty.
+type Range struct {
+	Prefix  string
+	Scheme  domain.CardScheme
+	Country string
+}
+
+// Table looks up the card information of a PAN by its longest matching BIN prefix.
+type Table struct {
+	ranges []Range
+}
+
+// NewTable initialises a new Table with the given ranges.
+func NewTable(ranges ...Range) *Table {
+	sorted := make([]Range, len(ranges))
+	copy(sorted, ranges)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+	return &Table{ranges: sorted}
+}
+
+// Lookup returns the card information of the PAN. The country is empty
+// when no range matches the PAN.
+func (t *Table) Lookup(pan string) domain.CardInfo {
+	pan = strings.ReplaceAll(pan, " ", "")
+	info := domain.CardInfo{Scheme: Scheme(pan)}
+	for _, r := range t.ranges {
+		if !strings.HasPrefix(pan, r.Prefix) {
+			continue
+		}
+		if r.Scheme != "" {
+			info.Scheme = r.Scheme
+		}
+		info.Country = r.Country
+		break
+	}
+	return info
+}
+
+// Scheme derives the card scheme from the leading digits of the PAN.
+func Scheme(pan string) domain.CardScheme {
+	pan = strings.ReplaceAll(pan, " ", "")
+	switch {
+	case strings.HasPrefix(pan, "4"):
+		return domain.CardSchemeVisa
+	case strings.HasPrefix(pan, "34"), strings.HasPrefix(pan, "37"):
+		return domain.CardSchemeAmex
+	case strings.HasPrefix(pan, "6011"), strings.HasPrefix(pan, "65"):
+		return domain.CardSchemeDiscover
+	case inRange(pan, 2, 51, 55), inRange(pan, 4, 2221, 2720):
+		return domain.CardSchemeMastercard
+	}
+	return domain.CardSchemeUnknown
+}
+
+// inRange checks if the first n digits of the pan are within [lo, hi].
+func inRange(pan string, n, lo, hi int) bool {
+	if len(pan) < n {
+		return false
+	}
+	prefix, err := strconv.Atoi(pan[:n])
+	if err != nil {
+		return false
+	}
+	return prefix >= lo && prefix <= hi
+}
@@ -1,38 +1,194 @@
 package config
 
 import (
-	"os"
-
-	"gopkg.in/yaml.v3"
-
-	"github.com/pkg/errors"
-)
-
-const (
-	defaultConfigFilePath = "config.yaml"
+	"time"
 )
 
 // Config variables for the application
 type Config struct {
-	PostgresDSN      string            `yaml:"POSTGRES_DSN"`
-	PrivilegedTokens map[string]string `yaml:"privileged_tokens"`
+	// PostgresDSN is the secret of the connection to the database, it is better set with the POSTGRES_DSN or
+	// POSTGRES_DSN_FILE environment variables than in a config file.
+	PostgresDSN string `yaml:"POSTGRES_DSN"`
+	// Postgres is the configuration of the pool of connections to the database.
+	Postgres Postgres `yaml:"postgres"`
+	// HTTP is the listener of the API.
+	HTTP Listener `yaml:"http"`
+	// HealthAddr is the address of the listener of the health checks, the metrics and pprof.
+	HealthAddr string `yaml:"health_addr"`
+	// ShutdownTimeout is how long the listeners are given to drain their requests on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ReloadInterval is how often the config files are checked for changes to reload the privileged tokens, the rate
+	// limits and the risk rules, they are only reloaded on SIGHUP if 0.
+	ReloadInterval   time.Duration       `yaml:"reload_interval"`
+	PrivilegedTokens map[string]string   `yaml:"privileged_tokens"`
+	Merchants        map[string]Merchant `yaml:"merchants"`
+	BINRanges        []BINRange          `yaml:"bin_ranges"`
+	FXRatesFile      string              `yaml:"fx_rates_file"`
+	Risk             *Risk               `yaml:"risk"`
+	Velocity         *Velocity           `yaml:"velocity"`
+	// SimulatedACS serves a simulated ACS to complete the 3-D Secure challenges, it must not be enabled in production.
+	SimulatedACS bool       `yaml:"simulated_acs"`
+	Recurring    *Recurring `yaml:"recurring"`
+	Tracing      Tracing    `yaml:"tracing"`
+	// Admin serves the admin API on its own listener, it is not served if not set.
+	Admin *Admin `yaml:"admin"`
+	// SignatureWindow is how far the timestamp of a signed request may be from the clock, 5 minutes if not set.
+	SignatureWindow time.Duration `yaml:"signature_window"`
+	// TLS serves the API over HTTPS, it is served over plain HTTP if not set.
+	TLS *TLS `yaml:"tls"`
+	// RateLimit is the rate limit of the merchants without one, the requests are not limited if not set.
+	RateLimit *RateLimit `yaml:"rate_limit"`
+	// ClientRateLimit limits the requests of each client address before they are authenticated,
+	// the requests are not limited if not set.
+	ClientRateLimit *RateLimit `yaml:"client_rate_limit"`
+	// MaxBodyBytes is the maximum size of a request body, 1 MiB if not set.
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+}
+
+// Listener is the configuration of an HTTP listener.
+type Listener struct {
+	Addr         string        `yaml:"addr"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+}
+
+// Postgres is the configuration of the pool of connections to the database.
+type Postgres struct {
+	MaxOpenConnections int `yaml:"max_open_connections"`
+	// MaxIdleConnections must not be more than MaxOpenConnections.
+	MaxIdleConnections    int           `yaml:"max_idle_connections"`
+	MaxConnectionLifetime time.Duration `yaml:"max_connection_lifetime"`
+}
+
+// TLS is the configuration of the TLS termination of the API, with mutual TLS if ClientCAFile is set.
+type TLS struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// MinVersion is 1.2 or 1.3, 1.2 if empty.
+	MinVersion string `yaml:"min_version"`
+	// CipherPolicy is intermediate or modern (TLS 1.3 only), intermediate if empty.
+	CipherPolicy string `yaml:"cipher_policy"`
+	// ClientCAFile is the CA the client certificates of the merchants are verified against.
+	ClientCAFile string `yaml:"client_ca_file"`
+	// RequireClientCertificate rejects the connections without a client certificate.
+	RequireClientCertificate bool `yaml:"require_client_certificate"`
+}
+
+// Admin is the configuration of the admin API managing the merchants and their API keys.
+type Admin struct {
+	// Listener is the listener of the admin API, its address must not be exposed to the merchants. The timeouts
+	// of the HTTP listener apply if not set.
+	Listener `yaml:",inline"`
+	// Tokens are the names of the admins keyed by their token.
+	Tokens map[string]string `yaml:"tokens"`
+}
+
+// Tracing is the configuration of the OpenTelemetry tracing, it is disabled without an exporter.
+type Tracing struct {
+	// Exporter is where the spans are exported, otlp or stdout.
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the host:port of the OTLP/HTTP collector.
+	Endpoint string `yaml:"endpoint"`
+	// Insecure exports to the OTLP/HTTP collector without TLS.
+	Insecure bool `yaml:"insecure"`
+	// SampleRatio is the ratio of the new traces that are sampled, all of them if not set.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Recurring is the configuration of the subscription billing.
+type Recurring struct {
+	// BillingInterval is how often the due subscriptions are billed.
+	BillingInterval time.Duration `yaml:"billing_interval"`
+	// RetryIntervals are the delays before retrying each failed billing, the subscription is unpaid once they are exhausted.
+	RetryIntervals []time.Duration `yaml:"retry_intervals"`
+}
+
+// Velocity is the configuration of the velocity limits on authorizations.
+type Velocity struct {
+	// Store is where the authorizations are counted, postgres (default) or memory.
+	Store  string          `yaml:"store"`
+	Limits []VelocityLimit `yaml:"limits"`
+}
+
+// VelocityLimit limits the count and the amount per currency in minor units of the authorizations
+// of a card, merchant or ip within the window.
+type VelocityLimit struct {
+	Dimension string            `yaml:"dimension"`
+	Window    time.Duration     `yaml:"window"`
+	MaxCount  int               `yaml:"max_count"`
+	MaxAmount map[string]uint64 `yaml:"max_amount"`
 }
 
-// Load loads the configuration for the application.
-func Load() (Config, error) {
-	var config Config
+// Risk is the configuration of the fraud screening rules, a rule without config is disabled.
+// The fraud screening is disabled without a risk config.
+type Risk struct {
+	ReviewScore         int                  `yaml:"review_score"`
+	BlockScore          int                  `yaml:"block_score"`
+	CardVelocity        *VelocityRule        `yaml:"card_velocity"`
+	RepeatedDeclines    *VelocityRule        `yaml:"repeated_declines"`
+	AmountThreshold     *AmountThresholdRule `yaml:"amount_threshold"`
+	BINCountryBlocklist *BINCountryRule      `yaml:"bin_country_blocklist"`
+}
 
-	file, err := os.Open(defaultConfigFilePath)
-	if err != nil {
-		return Config{}, errors.Wrap(err, "can't open file config file")
-	}
-	defer file.Close()
+// VelocityRule scores an authorization when the card has been authorized or declined at least max times within the window.
+type VelocityRule struct {
+	Max    int           `yaml:"max"`
+	Window time.Duration `yaml:"window"`
+	Score  int           `yaml:"score"`
+}
 
-	d := yaml.NewDecoder(file)
+// AmountThresholdRule scores an authorization over the limit in minor units of its currency.
+type AmountThresholdRule struct {
+	Limits map[string]uint64 `yaml:"limits"`
+	Score  int               `yaml:"score"`
+}
 
-	if err := d.Decode(&config); err != nil {
-		return Config{}, errors.Wrap(err, "failed to decode config")
-	}
+// BINCountryRule scores an authorization of a card issued in one of the countries.
+type BINCountryRule struct {
+	Countries []string `yaml:"countries"`
+	Score     int      `yaml:"score"`
+}
+
+// Merchant is the configuration of a merchant keyed by the merchant ID.
+type Merchant struct {
+	Country string `yaml:"country"`
+	// SettlementCurrency is the currency the merchant settles in, authorizations in
+	// other currencies are converted with a locked FX rate.
+	SettlementCurrency string    `yaml:"settlement_currency"`
+	Fees               []FeeRule `yaml:"fees"`
+	// SigningSecret is the HMAC-SHA256 key the merchant may sign its requests with instead of sending a token.
+	SigningSecret string `yaml:"signing_secret"`
+	// RequireSignedRequests rejects the requests of the merchant that are not signed.
+	RequireSignedRequests bool `yaml:"require_signed_requests"`
+	// ClientCertificateSubject is the subject common name of the client certificate the merchant connects with over mutual TLS.
+	ClientCertificateSubject string `yaml:"client_certificate_subject"`
+	// RateLimit limits the requests of the merchant to each endpoint, the default rate limit applies if not set.
+	RateLimit *RateLimit `yaml:"rate_limit"`
+	// APIVersion is the version of the responses the merchant is pinned to, the default version if not set.
+	APIVersion string `yaml:"api_version"`
+}
+
+// RateLimit is a token bucket refilled at RequestsPerSecond up to Burst requests, a zero RequestsPerSecond doesn't limit the requests.
+type RateLimit struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+// FeeRule is a rule of the merchant fee schedule, empty action, scheme, currency
+// and region match any value.
+type FeeRule struct {
+	Action          string `yaml:"action"`
+	Scheme          string `yaml:"scheme"`
+	Currency        string `yaml:"currency"`
+	Region          string `yaml:"region"`
+	BasisPoints     uint64 `yaml:"basis_points"`
+	FixedMinorUnits uint64 `yaml:"fixed_minor_units"`
+}
 
-	return config, nil
+// BINRange maps a BIN prefix to the issuing country and optionally the scheme of the card.
+type BINRange struct {
+	Prefix  string `yaml:"prefix"`
+	Scheme  string `yaml:"scheme"`
+	Country string `yaml:"country"`
 }
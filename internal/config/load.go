@@ -0,0 +1,250 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvConfigFile is the environment variable of the config files when the -config flag is not set.
+	EnvConfigFile         = "CONFIG_FILE"
+	defaultConfigFilePath = "config.yaml"
+	// secretFileSuffix suffixes the environment variables of the settings read from a file, e.g. POSTGRES_DSN_FILE
+	// for a secret mounted as a file.
+	secretFileSuffix = "_FILE"
+	// defaultAdminAddr is the address of the admin API when it is enabled by ADMIN_TOKENS.
+	defaultAdminAddr = ":8083"
+)
+
+// Default returns the configuration the config files and the environment variables are layered over.
+func Default() Config {
+	return Config{
+		Postgres: Postgres{
+			MaxOpenConnections:    50,
+			MaxIdleConnections:    20,
+			MaxConnectionLifetime: 30 * time.Minute,
+		},
+		HTTP: Listener{
+			Addr:         ":8080",
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  120 * time.Second,
+		},
+		HealthAddr:      ":8082",
+		ShutdownTimeout: 5 * time.Second,
+		ReloadInterval:  10 * time.Second,
+	}
+}
+
+// Flag registers the -config flag of the config files on the flag set, its value is the files of Load.
+func Flag(fs *flag.FlagSet) *string {
+	return fs.String("config", "", "config files separated like $PATH, the later files override the earlier ones, $"+
+		EnvConfigFile+" or "+defaultConfigFilePath+" if not set")
+}
+
+// Load loads the configuration for the application in layers: the defaults, the config files in order, the
+// environment variables and the files of the environment variables suffixed with _FILE. The files are the config
+// files of the -config flag separated like $PATH, or else of $CONFIG_FILE, or else config.yaml. The loaded
+// configuration is validated.
+func Load(files string) (Config, error) {
+	config := Default()
+	for _, path := range filePaths(files) {
+		if err := decodeFile(path, &config); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := applyEnv(&config); err != nil {
+		return Config{}, err
+	}
+	if config.Admin != nil {
+		config.Admin.Listener = withTimeouts(config.Admin.Listener, config.HTTP)
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// ModTime returns the latest modification time of the config files of Load, a reload is due when it changes.
+func ModTime(files string) (time.Time, error) {
+	var modTime time.Time
+	for _, path := range filePaths(files) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "can't stat config file")
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return modTime, nil
+}
+
+// filePaths returns the paths of the config files, of $CONFIG_FILE or else config.yaml if files is not set.
+func filePaths(files string) []string {
+	if files == "" {
+		files = os.Getenv(EnvConfigFile)
+	}
+	if files == "" {
+		files = defaultConfigFilePath
+	}
+	return filepath.SplitList(files)
+}
+
+// decodeFile decodes the config file over the config, the settings which are not in the file are kept and the maps
+// are merged. The unknown settings are rejected so that a typo doesn't go unnoticed.
+func decodeFile(path string, config *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "can't open config file")
+	}
+	defer file.Close()
+
+	d := yaml.NewDecoder(file)
+	d.KnownFields(true)
+	if err := d.Decode(config); err != nil && err != io.EOF {
+		return errors.Wrapf(err, "failed to decode config file %s", path)
+	}
+	return nil
+}
+
+// withTimeouts returns the listener with the timeouts of the defaults when not set.
+func withTimeouts(l, defaults Listener) Listener {
+	if l.ReadTimeout == 0 {
+		l.ReadTimeout = defaults.ReadTimeout
+	}
+	if l.WriteTimeout == 0 {
+		l.WriteTimeout = defaults.WriteTimeout
+	}
+	if l.IdleTimeout == 0 {
+		l.IdleTimeout = defaults.IdleTimeout
+	}
+	return l
+}
+
+// envOverride overrides a setting with an environment variable.
+type envOverride struct {
+	name string
+	set  func(c *Config, v string) error
+}
+
+// envOverrides are the settings which can be set by environment variables, over the config files.
+var envOverrides = []envOverride{
+	{"POSTGRES_DSN", stringSetting(func(c *Config) *string { return &c.PostgresDSN })},
+	{"POSTGRES_MAX_OPEN_CONNECTIONS", intSetting(func(c *Config) *int { return &c.Postgres.MaxOpenConnections })},
+	{"POSTGRES_MAX_IDLE_CONNECTIONS", intSetting(func(c *Config) *int { return &c.Postgres.MaxIdleConnections })},
+	{"POSTGRES_MAX_CONNECTION_LIFETIME", durationSetting(func(c *Config) *time.Duration { return &c.Postgres.MaxConnectionLifetime })},
+	{"HTTP_ADDR", stringSetting(func(c *Config) *string { return &c.HTTP.Addr })},
+	{"HTTP_READ_TIMEOUT", durationSetting(func(c *Config) *time.Duration { return &c.HTTP.ReadTimeout })},
+	{"HTTP_WRITE_TIMEOUT", durationSetting(func(c *Config) *time.Duration { return &c.HTTP.WriteTimeout })},
+	{"HTTP_IDLE_TIMEOUT", durationSetting(func(c *Config) *time.Duration { return &c.HTTP.IdleTimeout })},
+	{"HEALTH_ADDR", stringSetting(func(c *Config) *string { return &c.HealthAddr })},
+	{"SHUTDOWN_TIMEOUT", durationSetting(func(c *Config) *time.Duration { return &c.ShutdownTimeout })},
+	{"RELOAD_INTERVAL", durationSetting(func(c *Config) *time.Duration { return &c.ReloadInterval })},
+	{"ADMIN_TOKENS", mapSetting(func(c *Config) *map[string]string {
+		if c.Admin == nil {
+			c.Admin = &Admin{Listener: Listener{Addr: defaultAdminAddr}}
+		}
+		return &c.Admin.Tokens
+	})},
+	{"ADMIN_ADDR", func(c *Config, v string) error {
+		if c.Admin == nil {
+			return errors.New("the admin API is not configured")
+		}
+		c.Admin.Addr = v
+		return nil
+	}},
+	{"FX_RATES_FILE", stringSetting(func(c *Config) *string { return &c.FXRatesFile })},
+	{"SIMULATED_ACS", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		c.SimulatedACS = b
+		return nil
+	}},
+	{"TRACING_EXPORTER", stringSetting(func(c *Config) *string { return &c.Tracing.Exporter })},
+	{"TRACING_ENDPOINT", stringSetting(func(c *Config) *string { return &c.Tracing.Endpoint })},
+}
+
+// applyEnv overrides the settings set by an environment variable, or by the file of the environment variable
+// suffixed with _FILE.
+func applyEnv(config *Config) error {
+	for _, o := range envOverrides {
+		v, ok := os.LookupEnv(o.name)
+		if path, isFile := os.LookupEnv(o.name + secretFileSuffix); isFile {
+			if ok {
+				return errors.Errorf("both %s and %s%s are set", o.name, o.name, secretFileSuffix)
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return errors.Wrapf(err, "can't read %s%s", o.name, secretFileSuffix)
+			}
+			v, ok = strings.TrimSpace(string(b)), true
+		}
+		if !ok {
+			continue
+		}
+		if err := o.set(config, v); err != nil {
+			return errors.Wrapf(err, "invalid %s", o.name)
+		}
+	}
+	return nil
+}
+
+func stringSetting(field func(c *Config) *string) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+// mapSetting replaces the map with the key=value pairs separated by commas or new lines, e.g.
+// ADMIN_TOKENS=token-1=ops,token-2=support.
+func mapSetting(field func(c *Config) *map[string]string) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		m := make(map[string]string)
+		for _, pair := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '\n' }) {
+			kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+				// the value is not quoted as it is a secret
+				return errors.New("must be key=value pairs separated by commas or new lines")
+			}
+			m[kv[0]] = kv[1]
+		}
+		*field(c) = m
+		return nil
+	}
+}
+
+func intSetting(field func(c *Config) *int) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = i
+		return nil
+	}
+}
+
+func durationSetting(field func(c *Config) *time.Duration) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+	"github.com/jeffreyyong/payment-gateway/internal/velocity"
+)
+
+var currencyRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// problems are the invalid settings of a configuration, prefixed by their path in the config file.
+type problems []string
+
+// check adds the problem of the setting if not ok.
+func (p *problems) check(ok bool, setting, format string, args ...interface{}) {
+	if !ok {
+		*p = append(*p, setting+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+// Validate returns an error listing every invalid setting of the configuration, by their path in the config file.
+func (c Config) Validate() error {
+	var p problems
+	p.check(c.PostgresDSN != "", "POSTGRES_DSN", "is required, e.g. by the POSTGRES_DSN or POSTGRES_DSN_FILE environment variables")
+	p.check(c.Postgres.MaxOpenConnections > 0, "postgres.max_open_connections", "must be greater than 0")
+	p.check(c.Postgres.MaxIdleConnections >= 0 && c.Postgres.MaxIdleConnections <= c.Postgres.MaxOpenConnections,
+		"postgres.max_idle_connections", "must be between 0 and max_open_connections (%d)", c.Postgres.MaxOpenConnections)
+	p.check(c.Postgres.MaxConnectionLifetime >= 0, "postgres.max_connection_lifetime", "must not be negative")
+
+	c.HTTP.validate(&p, "http")
+	p.check(c.HealthAddr != "", "health_addr", "is required")
+	p.check(c.HealthAddr != c.HTTP.Addr, "health_addr", "must not be the address of http (%s)", c.HTTP.Addr)
+	p.check(c.ShutdownTimeout > 0, "shutdown_timeout", "must be greater than 0")
+	p.check(c.ReloadInterval >= 0, "reload_interval", "must not be negative")
+	if c.Admin != nil {
+		c.Admin.Listener.validate(&p, "admin")
+		p.check(c.Admin.Addr != c.HTTP.Addr && c.Admin.Addr != c.HealthAddr, "admin.addr",
+			"must not be the address of http (%s) or health_addr (%s)", c.HTTP.Addr, c.HealthAddr)
+		p.check(len(c.Admin.Tokens) > 0, "admin.tokens", "at least one token is required")
+	}
+	if c.TLS != nil {
+		p.check(c.TLS.CertFile != "" && c.TLS.KeyFile != "", "tls", "cert_file and key_file are required")
+		p.check(!c.TLS.RequireClientCertificate || c.TLS.ClientCAFile != "", "tls.require_client_certificate",
+			"requires client_ca_file")
+	}
+
+	_, emptyToken := c.PrivilegedTokens[""]
+	p.check(!emptyToken, "privileged_tokens", "the tokens must not be empty")
+	c.RateLimit.validate(&p, "rate_limit")
+	c.ClientRateLimit.validate(&p, "client_rate_limit")
+	merchantIDs := make([]string, 0, len(c.Merchants))
+	for merchantID := range c.Merchants {
+		merchantIDs = append(merchantIDs, merchantID)
+	}
+	sort.Strings(merchantIDs)
+	for _, merchantID := range merchantIDs {
+		c.Merchants[merchantID].validate(&p, "merchants."+merchantID)
+	}
+	if c.Velocity != nil {
+		c.Velocity.validate(&p, "velocity")
+	}
+	if c.Recurring != nil {
+		p.check(c.Recurring.BillingInterval >= 0, "recurring.billing_interval", "must not be negative")
+		for i, interval := range c.Recurring.RetryIntervals {
+			p.check(interval > 0, fmt.Sprintf("recurring.retry_intervals[%d]", i), "must be greater than 0")
+		}
+	}
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	default:
+		p.check(false, "tracing.exporter", "must be %s or %s", tracing.ExporterOTLP, tracing.ExporterStdout)
+	}
+	p.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+	p.check(c.SignatureWindow >= 0, "signature_window", "must not be negative")
+	p.check(c.MaxBodyBytes >= 0, "max_body_bytes", "must not be negative")
+
+	if len(p) > 0 {
+		return errors.Errorf("invalid config: %s", strings.Join(p, "; "))
+	}
+	return nil
+}
+
+func (l Listener) validate(p *problems, setting string) {
+	p.check(l.Addr != "", setting+".addr", "is required")
+	p.check(l.ReadTimeout > 0, setting+".read_timeout", "must be greater than 0")
+	p.check(l.WriteTimeout > 0, setting+".write_timeout", "must be greater than 0")
+	p.check(l.IdleTimeout > 0, setting+".idle_timeout", "must be greater than 0")
+}
+
+func (r *RateLimit) validate(p *problems, setting string) {
+	if r == nil {
+		return
+	}
+	p.check(r.RequestsPerSecond >= 0, setting+".requests_per_second", "must not be negative")
+	p.check(r.Burst >= 0, setting+".burst", "must not be negative")
+}
+
+func (m Merchant) validate(p *problems, setting string) {
+	p.check(m.SettlementCurrency == "" || currencyRegexp.MatchString(m.SettlementCurrency), setting+".settlement_currency",
+		"must be an ISO 4217 currency code, e.g. GBP")
+	p.check(!m.RequireSignedRequests || m.SigningSecret != "", setting+".require_signed_requests", "requires signing_secret")
+	m.RateLimit.validate(p, setting+".rate_limit")
+	for i, f := range m.Fees {
+		switch domain.PaymentActionType(f.Action) {
+		case "", domain.PaymentActionTypeAuthorization, domain.PaymentActionTypeCapture, domain.PaymentActionTypeRefund,
+			domain.PaymentActionTypeVoid:
+		default:
+			p.check(false, fmt.Sprintf("%s.fees[%d].action", setting, i), "unknown action %q", f.Action)
+		}
+	}
+}
+
+func (v Velocity) validate(p *problems, setting string) {
+	p.check(v.Store == "" || v.Store == "postgres" || v.Store == "memory", setting+".store", "must be postgres or memory")
+	for i, l := range v.Limits {
+		limit := fmt.Sprintf("%s.limits[%d]", setting, i)
+		switch velocity.Dimension(l.Dimension) {
+		case velocity.DimensionCard, velocity.DimensionMerchant, velocity.DimensionIP:
+		default:
+			p.check(false, limit+".dimension", "must be %s, %s or %s",
+				velocity.DimensionCard, velocity.DimensionMerchant, velocity.DimensionIP)
+		}
+		p.check(l.Window > 0, limit+".window", "must be greater than 0")
+	}
+}
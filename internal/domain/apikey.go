@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+)
+
+var (
+	// ErrAPIKeyNotFound indicates that the API key is not found in the db.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyInvalid indicates that the API key is unknown, expired or revoked.
+	ErrAPIKeyInvalid = errors.New("invalid api key")
+	// ErrInvalidScope indicates that the scope of an API key is unknown.
+	ErrInvalidScope = errors.New("invalid api key scope")
+	// ErrAPIKeyRotated indicates that the API key is already rotated, it is only valid until its overlap ends.
+	ErrAPIKeyRotated = errors.New("api key is already rotated")
+)
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	// ScopeAll grants every permission, it is the scope of the privileged tokens of the config.
+	ScopeAll Scope = "all"
+	// ScopeAuthorize allows to authorize transactions and to complete their authorization.
+	ScopeAuthorize Scope = "authorize"
+	ScopeCapture   Scope = "capture"
+	ScopeRefund    Scope = "refund"
+	ScopeVoid      Scope = "void"
+	// ScopeRead allows to read the customers, payment methods, transactions and subscriptions.
+	ScopeRead Scope = "read"
+	// ScopeManage allows to create, update and delete the customers, payment methods, plans and subscriptions.
+	ScopeManage Scope = "manage"
+	// ScopeKeys allows to rotate the API key of the request.
+	ScopeKeys Scope = "keys"
+)
+
+// Valid indicates that the scope is known.
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeAll, ScopeAuthorize, ScopeCapture, ScopeRefund, ScopeVoid, ScopeRead, ScopeManage, ScopeKeys:
+		return true
+	}
+	return false
+}
+
+// APIKey is an API key of a merchant, only the hash of the key is stored.
+// A key expiring at ExpiresAt is valid until then, e.g. a key being rotated is valid until its replacement is rolled out.
+type APIKey struct {
+	ID         uuid.UUID
+	MerchantID string
+	// Hash is the hex encoded sha256 of the key.
+	Hash        string
+	Scopes      []Scope
+	CreatedDate time.Time
+	ExpiresAt   *time.Time
+	RevokedAt   *time.Time
+	LastUsedAt  *time.Time
+	// RotatedAt is when the key has been replaced, it is valid until the end of the overlap and can't be rotated again.
+	RotatedAt *time.Time
+}
+
+// HasScope indicates that the key is granted the scope.
+func (k APIKey) HasScope(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAt indicates that the key is neither expired nor revoked at the time.
+func (k APIKey) ValidAt(t time.Time) bool {
+	if k.RevokedAt != nil && !t.Before(*k.RevokedAt) {
+		return false
+	}
+	if k.ExpiresAt != nil && !t.Before(*k.ExpiresAt) {
+		return false
+	}
+	return true
+}
+
+// HashAPIKey returns the hex encoded sha256 of the key, the key is random hence it does not need to be salted.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAuditLogTampered indicates that an entry of the audit log has been modified, deleted or inserted.
+var ErrAuditLogTampered = errors.New("audit log tampered")
+
+// AuditEntry is an entry of the append-only audit log of the API requests and of the operations of the service.
+// Each entry is chained to the previous one by its hash so that any modification of the log is detected.
+type AuditEntry struct {
+	// Sequence is the position of the entry in the log starting at 1.
+	Sequence int64
+	Time     time.Time
+	// MerchantID is the merchant the request has been authenticated as, empty for the background operations.
+	MerchantID string
+	// TokenID identifies the token the request has been authenticated with without revealing it.
+	TokenID string
+	// Operation is the endpoint of the API request, e.g. "POST /capture", or the operation of the service, e.g. "capture".
+	Operation string
+	// ResourceID is the resource of the operation, e.g. the authorization id.
+	ResourceID string
+	// RequestID is the correlation id of the request.
+	RequestID string
+	// Outcome is the HTTP status code of the API request or the status of the operation of the service.
+	Outcome  string
+	PrevHash string
+	Hash     string
+}
+
+// Chain sets the sequence of the entry after the previous one, nil for the first entry, and hashes the entry
+// with the hash of the previous one. The time is truncated to the precision of the store.
+func (e *AuditEntry) Chain(prev *AuditEntry) {
+	e.Sequence, e.PrevHash = 1, ""
+	if prev != nil {
+		e.Sequence, e.PrevHash = prev.Sequence+1, prev.Hash
+	}
+	e.Time = e.Time.UTC().Truncate(time.Microsecond)
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash returns the hex encoded sha256 of the previous hash and the fields of the entry.
+func (e AuditEntry) ComputeHash() string {
+	// the fields are encoded in the order of the struct hence the encoding is stable
+	fields, _ := json.Marshal(struct {
+		Sequence   int64  `json:"sequence"`
+		Time       string `json:"time"`
+		MerchantID string `json:"merchant_id"`
+		TokenID    string `json:"token_id"`
+		Operation  string `json:"operation"`
+		ResourceID string `json:"resource_id"`
+		RequestID  string `json:"request_id"`
+		Outcome    string `json:"outcome"`
+	}{
+		Sequence:   e.Sequence,
+		Time:       e.Time.UTC().Format(time.RFC3339Nano),
+		MerchantID: e.MerchantID,
+		TokenID:    e.TokenID,
+		Operation:  e.Operation,
+		ResourceID: e.ResourceID,
+		RequestID:  e.RequestID,
+		Outcome:    e.Outcome,
+	})
+
+	h := sha256.New()
+	h.Write([]byte(e.PrevHash))
+	h.Write(fields)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify checks that the entry follows the previous one, nil for the first entry, and that it has not been modified.
+func (e AuditEntry) Verify(prev *AuditEntry) error {
+	want := AuditEntry{Sequence: 1}
+	if prev != nil {
+		want = AuditEntry{Sequence: prev.Sequence + 1, PrevHash: prev.Hash}
+	}
+
+	switch {
+	case e.Sequence != want.Sequence:
+		return fmt.Errorf("%w: entry %d follows entry %d", ErrAuditLogTampered, e.Sequence, want.Sequence-1)
+	case e.PrevHash != want.PrevHash:
+		return fmt.Errorf("%w: entry %d is not chained to the previous entry", ErrAuditLogTampered, e.Sequence)
+	case e.Hash != e.ComputeHash():
+		return fmt.Errorf("%w: entry %d has been modified", ErrAuditLogTampered, e.Sequence)
+	}
+	return nil
+}
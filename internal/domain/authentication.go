@@ -0,0 +1,62 @@
+package domain
+
+// AuthenticationStatus is the status of the 3-D Secure cardholder authentication of an authorization.
+type AuthenticationStatus string
+
+const (
+	// AuthenticationStatusChallengeRequired indicates that the cardholder has to complete the challenge of the ACS.
+	AuthenticationStatusChallengeRequired AuthenticationStatus = "challenge_required"
+	// AuthenticationStatusAuthenticated indicates that the cardholder has been authenticated, 3-D Secure trans status Y.
+	AuthenticationStatusAuthenticated AuthenticationStatus = "authenticated"
+	// AuthenticationStatusAttempted indicates that the authentication has been attempted but the issuer
+	// doesn't support it, 3-D Secure trans status A.
+	AuthenticationStatusAttempted AuthenticationStatus = "attempted"
+	// AuthenticationStatusFailed indicates that the cardholder failed the challenge, 3-D Secure trans status N.
+	AuthenticationStatusFailed AuthenticationStatus = "failed"
+	// AuthenticationStatusUnavailable indicates that the authentication could not be performed, 3-D Secure trans status U.
+	AuthenticationStatusUnavailable AuthenticationStatus = "unavailable"
+)
+
+// Authentication is the 3-D Secure cardholder authentication of an authorization.
+// The ECI (Electronic Commerce Indicator) and LiabilityShift are only set once the result of the challenge is known.
+type Authentication struct {
+	Status         AuthenticationStatus
+	ECI            string
+	LiabilityShift bool
+}
+
+// NewAuthenticationResult returns the Authentication for the result of the challenge, the ECI values
+// depend on the scheme of the card and the liability for fraud shifts to the issuer when the cardholder
+// has been authenticated or the authentication has been attempted.
+func NewAuthenticationResult(scheme CardScheme, status AuthenticationStatus) Authentication {
+	a := Authentication{
+		Status:         status,
+		LiabilityShift: status == AuthenticationStatusAuthenticated || status == AuthenticationStatusAttempted,
+	}
+
+	switch {
+	case scheme == CardSchemeMastercard && status == AuthenticationStatusAuthenticated:
+		a.ECI = "02"
+	case scheme == CardSchemeMastercard && status == AuthenticationStatusAttempted:
+		a.ECI = "01"
+	case scheme == CardSchemeMastercard:
+		a.ECI = "00"
+	case status == AuthenticationStatusAuthenticated:
+		a.ECI = "05"
+	case status == AuthenticationStatusAttempted:
+		a.ECI = "06"
+	default:
+		a.ECI = "07"
+	}
+	return a
+}
+
+// ChallengeRequired indicates that the result of the challenge is not known yet.
+func (a *Authentication) ChallengeRequired() bool {
+	return a != nil && a.Status == AuthenticationStatusChallengeRequired
+}
+
+// Succeeded indicates that the authorization can go ahead after the authentication.
+func (a *Authentication) Succeeded() bool {
+	return a != nil && (a.Status == AuthenticationStatusAuthenticated || a.Status == AuthenticationStatusAttempted)
+}
@@ -0,0 +1,29 @@
+package domain
+
+// CardScheme is the card network that the card belongs to.
+type CardScheme string
+
+// String() returns the string form and makes CardScheme to be a stringer.
+func (c CardScheme) String() string {
+	return string(c)
+}
+
+const (
+	// CardSchemeVisa is the Visa card network.
+	CardSchemeVisa CardScheme = "visa"
+	// CardSchemeMastercard is the Mastercard card network.
+	CardSchemeMastercard CardScheme = "mastercard"
+	// CardSchemeAmex is the American Express card network.
+	CardSchemeAmex CardScheme = "amex"
+	// CardSchemeDiscover is the Discover card network.
+	CardSchemeDiscover CardScheme = "discover"
+	// CardSchemeUnknown is used when the card network can't be derived from the PAN.
+	CardSchemeUnknown CardScheme = "unknown"
+)
+
+// CardInfo is the non sensitive information of a card derived from its BIN.
+type CardInfo struct {
+	Scheme CardScheme
+	// Country is the ISO 3166-1 alpha-2 code of the issuing country, empty if unknown.
+	Country string
+}
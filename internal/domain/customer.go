@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+)
+
+var (
+	// ErrCustomerNotFound indicates that the customer is not found in the db.
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrPaymentMethodNotFound indicates that the stored payment method is not found in the db.
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+)
+
+// Customer is the customer of a merchant that the payment methods are stored for.
+type Customer struct {
+	ID          uuid.UUID
+	MerchantID  string
+	Email       string
+	Name        string
+	CreatedDate time.Time
+}
+
+// PaymentMethod is a card stored as a credential of a customer, the CVV is never stored.
+// The NetworkTransactionID is the reference of the initial customer-initiated authorization,
+// it is required for the merchant-initiated authorizations.
+type PaymentMethod struct {
+	ID                   uuid.UUID
+	CustomerID           uuid.UUID
+	MerchantID           string
+	PaymentSource        PaymentSource
+	Card                 CardInfo
+	NetworkTransactionID string
+	CreatedDate          time.Time
+}
+
+// Last4 returns the last 4 digits of the PAN, the only digits that are shown of a stored card.
+func (p PaymentSource) Last4() string {
+	pan := strings.ReplaceAll(p.PAN, " ", "")
+	if len(pan) < 4 {
+		return pan
+	}
+	return pan[len(pan)-4:]
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+)
+
+// currencyExponents are the ISO 4217 exponents of the currencies that don't have 2 minor unit digits.
+var currencyExponents = map[string]uint8{
+	"BHD": 3,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// CurrencyExponent returns the number of minor unit digits of the currency.
+func CurrencyExponent(currency string) uint8 {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// FXRatePrecision is the number of decimal places FX rates are locked with.
+const FXRatePrecision = 12
+
+// FXRate is the exchange rate to convert an amount from one currency to another.
+// It is locked on a transaction at authorization so that all the payment actions
+// of the transaction are converted consistently.
+type FXRate struct {
+	From       string
+	To         string
+	Rate       *big.Rat
+	LockedDate time.Time
+}
+
+// Convert converts the amount from the From currency to the To currency,
+// rounding half up to the nearest minor unit of the To currency.
+func (r FXRate) Convert(a Amount) Amount {
+	toExponent := CurrencyExponent(r.To)
+
+	v := new(big.Rat).SetInt(new(big.Int).SetUint64(a.MinorUnits))
+	v.Mul(v, r.Rate)
+	v.Mul(v, new(big.Rat).SetFrac(pow10(toExponent), pow10(a.Exponent)))
+
+	// round half up: floor((num * 2 + denom) / (denom * 2))
+	num := new(big.Int).Mul(v.Num(), big.NewInt(2))
+	num.Add(num, v.Denom())
+	denom := new(big.Int).Mul(v.Denom(), big.NewInt(2))
+
+	return Amount{
+		MinorUnits: new(big.Int).Quo(num, denom).Uint64(),
+		Currency:   r.To,
+		Exponent:   toExponent,
+	}
+}
+
+// Invert returns the rate to convert from the To currency back to the From currency.
+func (r FXRate) Invert() FXRate {
+	return FXRate{
+		From:       r.To,
+		To:         r.From,
+		Rate:       new(big.Rat).Inv(r.Rate),
+		LockedDate: r.LockedDate,
+	}
+}
+
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMerchantNotFound indicates that the merchant is not found in the db.
+	ErrMerchantNotFound = errors.New("merchant not found")
+	// ErrMerchantExists indicates that a merchant with the same id already exists.
+	ErrMerchantExists = errors.New("merchant already exists")
+	// ErrMerchantSuspended indicates that the merchant is suspended hence its requests are forbidden.
+	ErrMerchantSuspended = errors.New("merchant suspended")
+)
+
+// MerchantStatus is the status of a merchant.
+type MerchantStatus string
+
+const (
+	MerchantStatusActive    MerchantStatus = "active"
+	MerchantStatusSuspended MerchantStatus = "suspended"
+)
+
+// CapturePolicy is the way the authorizations of a merchant are captured.
+type CapturePolicy string
+
+const (
+	// CapturePolicyManual leaves the authorizations to be captured by the merchant.
+	CapturePolicyManual CapturePolicy = "manual"
+	// CapturePolicyAutomatic captures the full amount of an authorization as soon as it is authorized.
+	CapturePolicyAutomatic CapturePolicy = "automatic"
+)
+
+// Merchant is a merchant managed with the admin API. The merchants only configured in the config are active
+// without restriction.
+type Merchant struct {
+	ID          string
+	Name        string
+	Status      MerchantStatus
+	Settings    MerchantSettings
+	CreatedDate time.Time
+	UpdatedDate time.Time
+}
+
+// MerchantSettings are the settings of a merchant.
+type MerchantSettings struct {
+	// AllowedCurrencies are the currencies the merchant can authorize in, any currency if empty.
+	AllowedCurrencies []string
+	CapturePolicy     CapturePolicy
+}
+
+// Suspended indicates that the merchant is suspended.
+func (m Merchant) Suspended() bool {
+	return m.Status == MerchantStatusSuspended
+}
+
+// AllowsCurrency indicates that the merchant can authorize in the currency.
+func (s MerchantSettings) AllowsCurrency(currency string) bool {
+	if len(s.AllowedCurrencies) == 0 {
+		return true
+	}
+	for _, c := range s.AllowedCurrencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the capture policy and the allowed currencies, the capture policy defaults to manual.
+func (s *MerchantSettings) Validate() error {
+	if s.CapturePolicy == "" {
+		s.CapturePolicy = CapturePolicyManual
+	}
+	if s.CapturePolicy != CapturePolicyManual && s.CapturePolicy != CapturePolicyAutomatic {
+		return errors.New("capture policy must be manual or automatic")
+	}
+	for _, c := range s.AllowedCurrencies {
+		if !ValidCurrency(c) {
+			return errors.New("allowed currencies must be ISO 4217 codes")
+		}
+	}
+	return nil
+}
+
+// ValidCurrency checks that the currency is made of 3 upper case letters.
+func ValidCurrency(c string) bool {
+	if len(c) != 3 {
+		return false
+	}
+	for _, r := range c {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
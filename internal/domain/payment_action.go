@@ -33,25 +33,55 @@ const (
 	PaymentActionStatusSuccess PaymentActionStatus = "success"
 	// PaymentActionStatusFailed indicates that the payment action has failed.
 	PaymentActionStatusFailed PaymentActionStatus = "failed"
+	// PaymentActionStatusPending indicates that the payment action is waiting for the cardholder authentication.
+	PaymentActionStatusPending PaymentActionStatus = "pending"
 )
 
 // Authorization is the domain for making authorization request.
 type Authorization struct {
-	RequestID     uuid.UUID
+	RequestID  uuid.UUID
+	MerchantID string
+	// ShopperIP is the IP address of the device of the shopper given by the merchant, empty if unknown.
+	ShopperIP     string
 	PaymentSource PaymentSource
 	Amount        Amount
+	Card          CardInfo
+	// FXRate is the rate locked to convert the Amount to the settlement currency of the merchant,
+	// nil if the merchant settles in the currency of the Amount.
+	FXRate *FXRate
+	// Risk is the fraud screening result, nil if the authorization has not been screened.
+	Risk *RiskAssessment
+	// Authenticate requests the 3-D Secure authentication of the cardholder before the authorization.
+	Authenticate bool
+	// Authentication is the 3-D Secure authentication, nil if it has not been requested.
+	Authentication *Authentication
+	// Initiator is who initiated the authorization, the customer unless it is a merchant-initiated
+	// authorization with a stored payment method.
+	Initiator Initiator
+	// PaymentMethodID is the stored payment method the PaymentSource is taken from, uuid.Nil if none.
+	PaymentMethodID uuid.UUID
+	// StorePaymentMethod stores the PaymentSource as a payment method of the customer of CustomerID
+	// once the authorization has succeeded.
+	StorePaymentMethod bool
+	// CustomerID is the customer the transaction is linked to, uuid.Nil if none. It is the customer
+	// of the payment method for an authorization with a stored payment method.
+	CustomerID uuid.UUID
 }
 
 // Capture is the domain for making capture request.
 type Capture struct {
-	RequestID       uuid.UUID
+	RequestID uuid.UUID
+	// MerchantID is the merchant of the transaction, the transactions of the other merchants are not found.
+	MerchantID      string
 	AuthorizationID uuid.UUID
 	Amount          Amount
 }
 
 // Refund is the domain for making refund request.
 type Refund struct {
-	RequestID       uuid.UUID
+	RequestID uuid.UUID
+	// MerchantID is the merchant of the transaction, the transactions of the other merchants are not found.
+	MerchantID      string
 	AuthorizationID uuid.UUID
 	Amount          Amount
 }
@@ -59,7 +89,9 @@ type Refund struct {
 // Void is the domain for making void request.
 // It does not take in Amount as void is for the whole transaction.
 type Void struct {
-	RequestID       uuid.UUID
+	RequestID uuid.UUID
+	// MerchantID is the merchant of the transaction, the transactions of the other merchants are not found.
+	MerchantID      string
 	AuthorizationID uuid.UUID
 }
 
@@ -69,7 +101,10 @@ type PaymentAction struct {
 	Status        PaymentActionStatus
 	ProcessedDate time.Time
 	Amount        *Amount
-	RequestID     uuid.UUID
+	// SettlementAmount is the Amount converted to the settlement currency with the locked FXRate of the transaction.
+	SettlementAmount *Amount
+	Fee              *Amount
+	RequestID        uuid.UUID
 }
 
 // AuthorizationSuccess means the authorization has succeeded.
@@ -77,6 +112,11 @@ func (p PaymentAction) AuthorizationSuccess() bool {
 	return p.Type == PaymentActionTypeAuthorization && p.Status == PaymentActionStatusSuccess
 }
 
+// AuthorizationPending means the authorization is waiting for the cardholder authentication.
+func (p PaymentAction) AuthorizationPending() bool {
+	return p.Type == PaymentActionTypeAuthorization && p.Status == PaymentActionStatusPending
+}
+
 // VoidSuccess means the void has succeeded.
 func (p PaymentAction) VoidSuccess() bool {
 	return p.Type == PaymentActionTypeVoid && p.Status == PaymentActionStatusSuccess
@@ -91,3 +131,12 @@ func (p PaymentAction) CaptureSuccess() bool {
 func (p PaymentAction) RefundSuccess() bool {
 	return p.Type == PaymentActionTypeRefund && p.Status == PaymentActionStatusSuccess
 }
+
+// settlementMinorUnits returns the minor units of the SettlementAmount, falling back to
+// the Amount for payment actions that are not converted.
+func (p PaymentAction) settlementMinorUnits() uint64 {
+	if p.SettlementAmount != nil {
+		return p.SettlementAmount.MinorUnits
+	}
+	return p.Amount.minorUnits()
+}
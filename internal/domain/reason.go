@@ -0,0 +1,79 @@
+package domain
+
+// Reason is the stable, machine-readable reason of an unprocessable request, unlike the message it never changes.
+type Reason string
+
+const (
+	ReasonInvalidAmount              Reason = "invalid_amount"
+	ReasonAlreadyVoided              Reason = "already_voided"
+	ReasonAlreadyCaptured            Reason = "already_captured"
+	ReasonAlreadyRefunded            Reason = "already_refunded"
+	ReasonCurrencyMismatch           Reason = "currency_mismatch"
+	ReasonAmountExceedsAuthorized    Reason = "amount_exceeds_authorized"
+	ReasonAmountExceedsCaptured      Reason = "amount_exceeds_captured"
+	ReasonCurrencyNotAllowed         Reason = "currency_not_allowed"
+	ReasonInvalidCard                Reason = "invalid_card"
+	ReasonDeclinedByFraudScreening   Reason = "declined_by_fraud_screening"
+	ReasonNotAwaitingAuthentication  Reason = "not_awaiting_authentication"
+	ReasonAuthenticationNotCompleted Reason = "authentication_not_completed"
+	ReasonAuthenticationFailed       Reason = "authentication_failed"
+)
+
+// Reasons are all the reasons of the unprocessable requests.
+var Reasons = []Reason{
+	ReasonInvalidAmount, ReasonAlreadyVoided, ReasonAlreadyCaptured, ReasonAlreadyRefunded, ReasonCurrencyMismatch,
+	ReasonAmountExceedsAuthorized, ReasonAmountExceedsCaptured, ReasonCurrencyNotAllowed, ReasonInvalidCard,
+	ReasonDeclinedByFraudScreening, ReasonNotAwaitingAuthentication, ReasonAuthenticationNotCompleted, ReasonAuthenticationFailed,
+}
+
+var (
+	// ErrInvalidAmount indicates that the amount of a capture or a refund is zero.
+	ErrInvalidAmount = NewReasonError(ReasonInvalidAmount, "amount must be greater than 0")
+	// ErrAlreadyVoided indicates that the transaction is voided.
+	ErrAlreadyVoided = NewReasonError(ReasonAlreadyVoided, "transaction is already voided")
+	// ErrAlreadyCaptured indicates that the transaction is captured so that it can't be voided.
+	ErrAlreadyCaptured = NewReasonError(ReasonAlreadyCaptured, "transaction is already captured")
+	// ErrAlreadyRefunded indicates that the transaction is refunded so that it can't be captured.
+	ErrAlreadyRefunded = NewReasonError(ReasonAlreadyRefunded, "transaction is already refunded")
+	// ErrCurrencyMismatch indicates that the currency is different from the one of the transaction.
+	ErrCurrencyMismatch = NewReasonError(ReasonCurrencyMismatch, "currency is different")
+	// ErrAmountExceedsAuthorized indicates that the captured amount would be greater than the authorized amount.
+	ErrAmountExceedsAuthorized = NewReasonError(ReasonAmountExceedsAuthorized, "amount to be captured > authorized amount")
+	// ErrAmountExceedsCaptured indicates that the refunded amount would be greater than the captured amount.
+	ErrAmountExceedsCaptured = NewReasonError(ReasonAmountExceedsCaptured, "amount to be refunded > captured amount")
+	// ErrCurrencyNotAllowed indicates that the currency is not allowed by the settings of the merchant.
+	ErrCurrencyNotAllowed = NewReasonError(ReasonCurrencyNotAllowed, "currency is not allowed for the merchant")
+	// ErrInvalidCard indicates that the PAN of the card is invalid.
+	ErrInvalidCard = NewReasonError(ReasonInvalidCard, "invalid card")
+	// ErrDeclinedByFraudScreening indicates that the authorization is blocked by the fraud screening.
+	ErrDeclinedByFraudScreening = NewReasonError(ReasonDeclinedByFraudScreening, "authorization declined by fraud screening")
+	// ErrNotAwaitingAuthentication indicates that the transaction does not wait for the cardholder authentication.
+	ErrNotAwaitingAuthentication = NewReasonError(ReasonNotAwaitingAuthentication, "transaction is not waiting for authentication")
+	// ErrAuthenticationNotCompleted indicates that the cardholder has not completed the authentication challenge.
+	ErrAuthenticationNotCompleted = NewReasonError(ReasonAuthenticationNotCompleted, "authentication challenge is not completed")
+	// ErrAuthenticationFailed indicates that the cardholder authentication failed or was rejected.
+	ErrAuthenticationFailed = NewReasonError(ReasonAuthenticationFailed, "cardholder authentication failed")
+)
+
+// ReasonError is an unprocessable error with its reason, it is ErrUnprocessable and any ReasonError of the same reason
+// for errors.Is so that the message can be specific, e.g. NewReasonError(ReasonCurrencyNotAllowed, "currency USD is not allowed").
+type ReasonError struct {
+	Reason  Reason
+	Message string
+}
+
+// NewReasonError returns an unprocessable error with the reason and the message.
+func NewReasonError(reason Reason, message string) *ReasonError {
+	return &ReasonError{Reason: reason, Message: message}
+}
+
+func (e *ReasonError) Error() string { return e.Message }
+
+// Is reports whether the target is ErrUnprocessable or a ReasonError of the same reason.
+func (e *ReasonError) Is(target error) bool {
+	if target == ErrUnprocessable {
+		return true
+	}
+	t, ok := target.(*ReasonError)
+	return ok && t.Reason == e.Reason
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+)
+
+var (
+	// ErrPlanNotFound indicates that the subscription plan is not found in the db.
+	ErrPlanNotFound = errors.New("plan not found")
+	// ErrSubscriptionNotFound indicates that the subscription is not found in the db.
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
+// Initiator is who initiated the authorization with a stored credential.
+type Initiator string
+
+const (
+	// InitiatorCustomer is a customer-initiated transaction (CIT), the cardholder is present.
+	InitiatorCustomer Initiator = "customer"
+	// InitiatorMerchant is a merchant-initiated transaction (MIT), e.g. a recurring payment
+	// without the cardholder, it references the network transaction of the initial CIT.
+	InitiatorMerchant Initiator = "merchant"
+)
+
+// Interval is the unit of the billing interval of a plan.
+type Interval string
+
+const (
+	// IntervalDay bills daily.
+	IntervalDay Interval = "day"
+	// IntervalWeek bills weekly.
+	IntervalWeek Interval = "week"
+	// IntervalMonth bills monthly.
+	IntervalMonth Interval = "month"
+	// IntervalYear bills yearly.
+	IntervalYear Interval = "year"
+)
+
+// Plan is a subscription plan billing the Amount every IntervalCount Intervals.
+type Plan struct {
+	ID            uuid.UUID
+	MerchantID    string
+	Name          string
+	Amount        Amount
+	Interval      Interval
+	IntervalCount int
+	CreatedDate   time.Time
+}
+
+// Validate checks the amount and the billing interval of the plan.
+func (p Plan) Validate() error {
+	if p.Amount.MinorUnits == 0 || p.Amount.Currency == "" {
+		return errors.New("plan amount is required")
+	}
+	if p.IntervalCount < 1 {
+		return errors.New("plan interval count must be at least 1")
+	}
+	switch p.Interval {
+	case IntervalDay, IntervalWeek, IntervalMonth, IntervalYear:
+		return nil
+	default:
+		return errors.New("plan interval must be one of day, week, month or year")
+	}
+}
+
+// NextPeriodStart returns the start of the billing period following the one starting at from.
+func (p Plan) NextPeriodStart(from time.Time) time.Time {
+	switch p.Interval {
+	case IntervalDay:
+		return from.AddDate(0, 0, p.IntervalCount)
+	case IntervalWeek:
+		return from.AddDate(0, 0, 7*p.IntervalCount)
+	case IntervalYear:
+		return from.AddDate(p.IntervalCount, 0, 0)
+	default:
+		return from.AddDate(0, p.IntervalCount, 0)
+	}
+}
+
+// SubscriptionStatus is the status of a subscription.
+type SubscriptionStatus string
+
+const (
+	// SubscriptionStatusActive is a subscription that is billed on schedule.
+	SubscriptionStatusActive SubscriptionStatus = "active"
+	// SubscriptionStatusPastDue is a subscription whose last billing failed and is being retried.
+	SubscriptionStatusPastDue SubscriptionStatus = "past_due"
+	// SubscriptionStatusUnpaid is a subscription whose billing retries have all failed, it is not billed anymore.
+	SubscriptionStatusUnpaid SubscriptionStatus = "unpaid"
+	// SubscriptionStatusCanceled is a subscription that has been canceled.
+	SubscriptionStatusCanceled SubscriptionStatus = "canceled"
+)
+
+// Subscription bills the plan with the stored payment method of the customer on schedule.
+// PeriodStart is the start of the next billing period to be paid for, NextBillingDate is when
+// the next billing is attempted, it is later than the PeriodStart while the billing is retried.
+type Subscription struct {
+	ID              uuid.UUID
+	MerchantID      string
+	CustomerID      uuid.UUID
+	PlanID          uuid.UUID
+	PaymentMethodID uuid.UUID
+	Status          SubscriptionStatus
+	PeriodStart     time.Time
+	NextBillingDate time.Time
+	FailedAttempts  int
+	CreatedDate     time.Time
+}
+
+// Billable indicates that the subscription is billed on schedule or retried.
+func (s Subscription) Billable() bool {
+	return s.Status == SubscriptionStatusActive || s.Status == SubscriptionStatusPastDue
+}
+
+// BillingSucceeded moves the subscription on to the next billing period of the plan.
+func (s *Subscription) BillingSucceeded(plan Plan) {
+	s.Status = SubscriptionStatusActive
+	s.PeriodStart = plan.NextPeriodStart(s.PeriodStart)
+	s.NextBillingDate = s.PeriodStart
+	s.FailedAttempts = 0
+}
+
+// BillingFailed schedules the retry of the billing after the retry interval of the failed attempt,
+// the subscription becomes unpaid once all the retries have failed.
+func (s *Subscription) BillingFailed(now time.Time, retryIntervals []time.Duration) {
+	s.FailedAttempts++
+	if s.FailedAttempts > len(retryIntervals) {
+		s.Status = SubscriptionStatusUnpaid
+		return
+	}
+	s.Status = SubscriptionStatusPastDue
+	s.NextBillingDate = now.Add(retryIntervals[s.FailedAttempts-1])
+}
+
+// BillingPostponed schedules the billing again after the delay when it could not be attempted,
+// it is not a failed attempt and the status of the subscription is unchanged.
+func (s *Subscription) BillingPostponed(now time.Time, delay time.Duration) {
+	s.NextBillingDate = now.Add(delay)
+}
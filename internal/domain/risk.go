@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// RiskDecision is the outcome of the fraud screening of an authorization.
+type RiskDecision string
+
+const (
+	// RiskDecisionAllow indicates that the authorization can go ahead.
+	RiskDecisionAllow RiskDecision = "allow"
+	// RiskDecisionReview indicates that the authorization can go ahead but should be reviewed.
+	RiskDecisionReview RiskDecision = "review"
+	// RiskDecisionBlock indicates that the authorization must be declined.
+	RiskDecisionBlock RiskDecision = "block"
+)
+
+// RiskAssessment is the result of the fraud screening of an authorization,
+// the Reasons are the names of the rules that contributed to the Score.
+type RiskAssessment struct {
+	Decision RiskDecision
+	Score    int
+	Reasons  []string
+}
+
+// Blocked indicates that the authorization must be declined.
+func (r *RiskAssessment) Blocked() bool {
+	return r != nil && r.Decision == RiskDecisionBlock
+}
+
+// PANHash returns the SHA-256 hash of the PAN, used to identify a card without the PAN.
+func (p PaymentSource) PANHash() string {
+	sum := sha256.Sum256([]byte(strings.ReplaceAll(p.PAN, " ", "")))
+	return hex.EncodeToString(sum[:])
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	uuid "github.com/kevinburke/go.uuid"
+	"go.uber.org/zap/zapcore"
 )
 
 var (
@@ -12,6 +13,8 @@ var (
 	ErrTransactionNotFound = errors.New("transaction not found")
 	// ErrUnprocessable indicates that the request is unprocessable, e.g. due to the wrong state of the transaction.
 	ErrUnprocessable = errors.New("unprocessable")
+	// ErrVelocityLimitExceeded indicates that there are too many authorizations, e.g. for a card within a window.
+	ErrVelocityLimitExceeded = errors.New("velocity limit exceeded")
 )
 
 // Amount is the canonical amount domain.
@@ -21,13 +24,30 @@ type Amount struct {
 	Exponent   uint8
 }
 
+// minorUnits returns the minor units of the amount, zero if the amount is nil.
+func (a *Amount) minorUnits() uint64 {
+	if a == nil {
+		return 0
+	}
+	return a.MinorUnits
+}
+
 // PaymentSource is the payment source that the client making payment with.
+// It must only be logged masked, it is masked when logged as a zap object.
 type PaymentSource struct {
 	PAN    string
 	CVV    string
 	Expiry Expiry
 }
 
+// MarshalLogObject logs the payment source with only the last 4 digits of the PAN and without the CVV.
+func (p PaymentSource) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("last4", p.Last4())
+	enc.AddInt("expiry_month", p.Expiry.Month)
+	enc.AddInt("expiry_year", p.Expiry.Year)
+	return nil
+}
+
 // Expiry date of the payment source.
 type Expiry struct {
 	Month int
@@ -38,14 +58,26 @@ type Expiry struct {
 // It also contains PaymentActionSummary to show all the PaymentAction that has
 // happened to the transaction so far.
 type Transaction struct {
-	ID                   uuid.UUID
-	RequestID            uuid.UUID
-	AuthorizationID      uuid.UUID
-	PaymentSource        PaymentSource
-	Amount               Amount
+	ID              uuid.UUID
+	RequestID       uuid.UUID
+	AuthorizationID uuid.UUID
+	MerchantID      string
+	PaymentSource   PaymentSource
+	Card            CardInfo
+	Amount          Amount
+	FXRate          *FXRate
+	Risk            *RiskAssessment
+	Authentication  *Authentication
+	Initiator       Initiator
+	PaymentMethodID uuid.UUID
+	CustomerID      uuid.UUID
+	// NetworkTransactionID is the reference of the authorization given by the card network.
+	NetworkTransactionID string
 	AuthorizedAmount     Amount
 	CapturedAmount       Amount
 	RefundedAmount       Amount
+	FeeAmount            Amount
+	NetAmount            Amount
 	PaymentActionSummary []*PaymentAction
 }
 
@@ -61,6 +93,17 @@ func (t Transaction) AuthorizationDate() *time.Time {
 	return nil
 }
 
+// RequiresAction indicates that the authorization is waiting for the cardholder
+// to complete the 3-D Secure challenge.
+func (t Transaction) RequiresAction() bool {
+	for _, pa := range t.PaymentActionSummary {
+		if pa.AuthorizationPending() {
+			return true
+		}
+	}
+	return false
+}
+
 // IsRequestIDIdempotent checks if the requestID is already been used for a particular
 // PaymentActionType and normally will trigger a no op for idempotency.
 func (t Transaction) IsRequestIDIdempotent(pat PaymentActionType, requestID uuid.UUID) bool {
@@ -102,11 +145,31 @@ func (t Transaction) Captured() bool {
 	return false
 }
 
-// Amounts calculates the main amounts e.g. authorized, captured and refunded amounts
+// SettlementAmount converts the amount in the currency of the transaction to the settlement
+// currency with the locked FXRate, it returns the amount as is if there is no FXRate.
+func (t Transaction) SettlementAmount(a Amount) Amount {
+	if t.FXRate == nil || a.Currency != t.FXRate.From {
+		return a
+	}
+	return t.FXRate.Convert(a)
+}
+
+// PresentmentAmount converts an amount in the settlement currency back to the currency of
+// the transaction with the locked FXRate, any other amount is returned as is.
+func (t Transaction) PresentmentAmount(a Amount) Amount {
+	if t.FXRate == nil || a.Currency != t.FXRate.To || a.Currency == t.Amount.Currency {
+		return a
+	}
+	return t.FXRate.Invert().Convert(a)
+}
+
+// Amounts calculates the main amounts e.g. authorized, captured, refunded, fee and net amounts
 // based on the PaymentActionSummary.
+// The fee and net amounts are in the settlement currency. The net amount is the captured amount
+// less the refunded amount and fees, it is floored at zero.
 // This is normally called after PaymentActionSummary has been populated.
 func (t *Transaction) Amounts() {
-	var authorized, captured, refunded uint64
+	var authorized, captured, refunded, settledCaptured, settledRefunded, fees uint64
 	for _, pa := range t.PaymentActionSummary {
 		if pa.AuthorizationSuccess() {
 			authorized = pa.Amount.MinorUnits
@@ -114,12 +177,22 @@ func (t *Transaction) Amounts() {
 
 		if pa.CaptureSuccess() {
 			captured += pa.Amount.MinorUnits
+			settledCaptured += pa.settlementMinorUnits()
+			fees += pa.Fee.minorUnits()
 		}
 
 		if pa.RefundSuccess() {
 			refunded += pa.Amount.MinorUnits
+			settledRefunded += pa.settlementMinorUnits()
+			fees += pa.Fee.minorUnits()
 		}
 	}
+
+	var net uint64
+	if settledCaptured > settledRefunded+fees {
+		net = settledCaptured - settledRefunded - fees
+	}
+
 	currency := t.Amount.Currency
 	exponent := t.Amount.Exponent
 	t.AuthorizedAmount = Amount{
@@ -138,44 +211,67 @@ func (t *Transaction) Amounts() {
 		Currency:   currency,
 		Exponent:   exponent,
 	}
+
+	if t.FXRate != nil {
+		currency = t.FXRate.To
+		exponent = CurrencyExponent(t.FXRate.To)
+	}
+	t.FeeAmount = Amount{
+		MinorUnits: fees,
+		Currency:   currency,
+		Exponent:   exponent,
+	}
+	t.NetAmount = Amount{
+		MinorUnits: net,
+		Currency:   currency,
+		Exponent:   exponent,
+	}
 }
 
 // ValidateCapture rejects if a transaction has been Voided or Refunded before,
 // checks the currency is the same and
-// rejects if the amount the be captured is greater than the authorized amount.
+// rejects if the amount the be captured is zero or greater than the authorized amount.
 func (t Transaction) ValidateCapture(a Amount) error {
+	if a.MinorUnits == 0 {
+		return NewReasonError(ReasonInvalidAmount, "amount to be captured must be greater than 0")
+	}
+
 	if t.Voided() {
-		return errors.New("transaction is already voided")
+		return ErrAlreadyVoided
 	}
 
 	if t.Refunded() {
-		return errors.New("transaction is already refunded")
+		return ErrAlreadyRefunded
 	}
 
 	if t.Amount.Currency != a.Currency {
-		return errors.New("currency is different")
+		return ErrCurrencyMismatch
 	}
 
 	if (t.CapturedAmount.MinorUnits + a.MinorUnits) > t.AuthorizedAmount.MinorUnits {
-		return errors.New("amount to be captured > authorized amount")
+		return ErrAmountExceedsAuthorized
 	}
 	return nil
 }
 
 // ValidateRefund rejects if a transaction has been Voided before,
 // checks the currency is the same and
-// rejects if the amount the be refunded is greater than the captured amount.
+// rejects if the amount the be refunded is zero or greater than the captured amount.
 func (t Transaction) ValidateRefund(a Amount) error {
+	if a.MinorUnits == 0 {
+		return NewReasonError(ReasonInvalidAmount, "amount to be refunded must be greater than 0")
+	}
+
 	if t.Voided() {
-		return errors.New("transaction is already voided")
+		return ErrAlreadyVoided
 	}
 
 	if t.Amount.Currency != a.Currency {
-		return errors.New("currency is different")
+		return ErrCurrencyMismatch
 	}
 
 	if (t.RefundedAmount.MinorUnits + a.MinorUnits) > t.CapturedAmount.MinorUnits {
-		return errors.New("amount to be refunded > captured amount")
+		return ErrAmountExceedsCaptured
 	}
 	return nil
 }
@@ -183,11 +279,11 @@ func (t Transaction) ValidateRefund(a Amount) error {
 // ValidateVoid rejects if a transaction has been Voided and Captured before.
 func (t Transaction) ValidateVoid() error {
 	if t.Voided() {
-		return errors.New("transaction is already voided")
+		return ErrAlreadyVoided
 	}
 
 	if t.Captured() {
-		return errors.New("transaction is already captured")
+		return ErrAlreadyCaptured
 	}
 	return nil
 }
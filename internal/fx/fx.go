@@ -0,0 +1,84 @@
+package fx
+
+import (
+	"context"
+	"math/big"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+// ErrRateNotFound indicates that there is no exchange rate between the currencies.
+var ErrRateNotFound = errors.New("fx rate not found")
+
+// FileProvider provides the exchange rates loaded from a YAML file of the form:
+//
+//	base: GBP
+//	rates:
+//	  EUR: 1.1650
+//	  USD: 1.3800
+//
+// where the rates are the amount of the currency for one unit of the base currency.
+// Rates between two non base currencies are derived as cross rates.
+type FileProvider struct {
+	base  string
+	rates map[string]*big.Rat
+}
+
+type rateFile struct {
+	Base  string            `yaml:"base"`
+	Rates map[string]string `yaml:"rates"`
+}
+
+// NewFileProvider loads the exchange rates from the file at path.
+func NewFileProvider(path string) (*FileProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't open fx rates file")
+	}
+	defer file.Close()
+
+	var f rateFile
+	if err := yaml.NewDecoder(file).Decode(&f); err != nil {
+		return nil, errors.Wrap(err, "failed to decode fx rates")
+	}
+
+	if f.Base == "" {
+		return nil, errors.New("fx rates base currency is not provided")
+	}
+
+	rates := make(map[string]*big.Rat, len(f.Rates)+1)
+	rates[f.Base] = big.NewRat(1, 1)
+	for currency, value := range f.Rates {
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, errors.Errorf("invalid fx rate %q for %s", value, currency)
+		}
+		rates[currency] = rate
+	}
+
+	return &FileProvider{base: f.Base, rates: rates}, nil
+}
+
+// Rate returns the exchange rate to convert from one currency to another,
+// rounded to domain.FXRatePrecision decimal places.
+func (p *FileProvider) Rate(_ context.Context, from, to string) (domain.FXRate, error) {
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return domain.FXRate{}, errors.Wrapf(ErrRateNotFound, "%s to %s", from, to)
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return domain.FXRate{}, errors.Wrapf(ErrRateNotFound, "%s to %s", from, to)
+	}
+
+	rate, _ := new(big.Rat).SetString(new(big.Rat).Quo(toRate, fromRate).FloatString(domain.FXRatePrecision))
+	return domain.FXRate{
+		From: from,
+		To:   to,
+		Rate: rate,
+	}, nil
+}
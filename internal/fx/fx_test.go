@@ -0,0 +1,105 @@
+package fx_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/fx"
+)
+
+func writeRates(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "fx")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "fx_rates.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestFileProvider_Rate(t *testing.T) {
+	p, err := fx.NewFileProvider(writeRates(t, `
+base: GBP
+rates:
+  EUR: 1.25
+  USD: 1.50
+  JPY: 150
+`))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description      string
+		from, to         string
+		amount           domain.Amount
+		expectedAmount   domain.Amount
+		expectedRateText string
+	}{
+		{
+			"from base currency",
+			"GBP", "EUR",
+			domain.Amount{MinorUnits: 10000, Currency: "GBP", Exponent: 2},
+			domain.Amount{MinorUnits: 12500, Currency: "EUR", Exponent: 2},
+			"1.250000000000",
+		},
+		{
+			"to base currency",
+			"EUR", "GBP",
+			domain.Amount{MinorUnits: 10000, Currency: "EUR", Exponent: 2},
+			domain.Amount{MinorUnits: 8000, Currency: "GBP", Exponent: 2},
+			"0.800000000000",
+		},
+		{
+			"cross rate",
+			"EUR", "USD",
+			domain.Amount{MinorUnits: 10001, Currency: "EUR", Exponent: 2},
+			domain.Amount{MinorUnits: 12001, Currency: "USD", Exponent: 2},
+			"1.200000000000",
+		},
+		{
+			"to currency with different exponent",
+			"EUR", "JPY",
+			domain.Amount{MinorUnits: 1001, Currency: "EUR", Exponent: 2},
+			domain.Amount{MinorUnits: 1201, Currency: "JPY", Exponent: 0},
+			"120.000000000000",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rate, err := p.Rate(context.Background(), tc.from, tc.to)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRateText, rate.Rate.FloatString(domain.FXRatePrecision))
+			assert.Equal(t, tc.expectedAmount, rate.Convert(tc.amount))
+		})
+	}
+
+	t.Run("rate not found", func(t *testing.T) {
+		_, err := p.Rate(context.Background(), "GBP", "CHF")
+		assert.ErrorIs(t, err, fx.ErrRateNotFound)
+	})
+}
+
+func TestNewFileProvider_Invalid(t *testing.T) {
+	testCases := []struct {
+		description string
+		content     string
+	}{
+		{"missing base", "rates:\n  EUR: 1.25\n"},
+		{"non numeric rate", "base: GBP\nrates:\n  EUR: abc\n"},
+		{"zero rate", "base: GBP\nrates:\n  EUR: 0\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := fx.NewFileProvider(writeRates(t, tc.content))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -4,4 +4,9 @@ const (
 	RequestID       = "request.id"
 	PaymentAction   = "payment.action"
 	AuthorizationID = "authorization.id"
+	MerchantID      = "merchant.id"
+	SubscriptionID  = "subscription.id"
+	CustomerID      = "customer.id"
+	TraceID         = "trace.id"
+	CorrelationID   = "correlation.id"
 )
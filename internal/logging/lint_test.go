@@ -0,0 +1,218 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+const (
+	modulePath    = "github.com/jeffreyyong/payment-gateway"
+	domainPath    = modulePath + "/internal/domain"
+	loggingPath   = modulePath + "/internal/logging"
+	zapPath       = "go.uber.org/zap"
+	paymentSource = "PaymentSource"
+)
+
+// TestPaymentSourceNeverLoggedUnmasked fails if a domain.PaymentSource, its PAN or its CVV, or a value
+// containing one, is passed to a logger. Only the masked zap object of the PaymentSource can be logged.
+func TestPaymentSourceNeverLoggedUnmasked(t *testing.T) {
+	assert.Empty(t, unmaskedPaymentSources(t, modulePath+"/..."))
+}
+
+func TestPaymentSourceNeverLoggedUnmasked_Lint(t *testing.T) {
+	assert.Len(t, unmaskedPaymentSources(t, "./testdata/lint"), 4, "the unmasked payment sources of the testdata are found")
+}
+
+// unmaskedPaymentSources returns the positions where a payment source is logged unmasked in the packages.
+func unmaskedPaymentSources(t *testing.T, patterns ...string) []string {
+	fset := token.NewFileSet()
+
+	var findings []string
+	for _, pkg := range loadPackages(t, fset, patterns...) {
+		for _, file := range pkg.files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok || !isLoggingCall(pkg.info, call) {
+					return true
+				}
+				for _, arg := range call.Args {
+					for _, pos := range unmaskedIn(pkg.info, call, arg) {
+						findings = append(findings, fset.Position(pos).String())
+					}
+				}
+				return false
+			})
+		}
+	}
+	return findings
+}
+
+type checkedPackage struct {
+	files []*ast.File
+	info  *types.Info
+}
+
+// loadPackages type checks the packages with the export data of their dependencies built by go list.
+func loadPackages(t *testing.T, fset *token.FileSet, patterns ...string) []checkedPackage {
+	args := append([]string{"list", "-export", "-deps", "-json=ImportPath,Dir,GoFiles,Export,DepOnly"}, patterns...)
+	out, err := exec.Command("go", args...).Output()
+	require.NoError(t, err)
+
+	type listedPackage struct {
+		ImportPath string
+		Dir        string
+		GoFiles    []string
+		Export     string
+		DepOnly    bool
+	}
+	var listed []listedPackage
+	exports := map[string]string{}
+	for dec := json.NewDecoder(bytes.NewReader(out)); dec.More(); {
+		var p listedPackage
+		require.NoError(t, dec.Decode(&p))
+		exports[p.ImportPath] = p.Export
+		if !p.DepOnly {
+			listed = append(listed, p)
+		}
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+			return os.Open(exports[path])
+		}),
+	}
+
+	var pkgs []checkedPackage
+	for _, p := range listed {
+		var files []*ast.File
+		for _, name := range p.GoFiles {
+			f, err := parser.ParseFile(fset, filepath.Join(p.Dir, name), nil, 0)
+			require.NoError(t, err)
+			files = append(files, f)
+		}
+
+		info := &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+		}
+		_, err := conf.Check(p.ImportPath, fset, files, info)
+		require.NoError(t, err)
+		pkgs = append(pkgs, checkedPackage{files: files, info: info})
+	}
+	return pkgs
+}
+
+// unmaskedIn returns the positions in the argument of the call where a payment source is unmasked.
+func unmaskedIn(info *types.Info, call *ast.CallExpr, arg ast.Expr) []token.Pos {
+	var positions []token.Pos
+	if containsPaymentSource(info.TypeOf(arg), map[types.Type]bool{}) && !isMaskedObject(info, call, arg) {
+		positions = append(positions, arg.Pos())
+	}
+
+	ast.Inspect(arg, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			sel := info.Selections[n]
+			if sel != nil && sel.Kind() == types.FieldVal && isPaymentSource(sel.Recv()) &&
+				(n.Sel.Name == "PAN" || n.Sel.Name == "CVV") {
+				positions = append(positions, n.Pos())
+			}
+		case *ast.CallExpr:
+			for _, a := range n.Args {
+				positions = append(positions, unmaskedIn(info, n, a)...)
+			}
+			return false
+		}
+		return true
+	})
+	return positions
+}
+
+// isLoggingCall checks if the callee is a function or a method of zap or of the logging package.
+func isLoggingCall(info *types.Info, call *ast.CallExpr) bool {
+	f := callee(info, call)
+	return f != nil && (f.Pkg().Path() == zapPath || f.Pkg().Path() == loggingPath)
+}
+
+// callee returns the function or the method called, nil if it is not a declared one.
+func callee(info *types.Info, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	f, ok := info.Uses[ident].(*types.Func)
+	if !ok || f.Pkg() == nil {
+		return nil
+	}
+	return f
+}
+
+// isMaskedObject checks if the argument is logged as a zap.ObjectMarshaler, e.g. the masked PaymentSource.
+func isMaskedObject(info *types.Info, call *ast.CallExpr, arg ast.Expr) bool {
+	f := callee(info, call)
+	if f == nil || f.Pkg().Path() != zapPath || (f.Name() != "Object" && f.Name() != "Any") {
+		return false
+	}
+	return isPaymentSource(info.TypeOf(arg))
+}
+
+func isPaymentSource(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == domainPath && n.Obj().Name() == paymentSource
+}
+
+func containsPaymentSource(t types.Type, seen map[types.Type]bool) bool {
+	if t == nil || seen[t] {
+		return false
+	}
+	seen[t] = true
+	if isPaymentSource(t) {
+		return true
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return containsPaymentSource(u.Elem(), seen)
+	case *types.Slice:
+		return containsPaymentSource(u.Elem(), seen)
+	case *types.Array:
+		return containsPaymentSource(u.Elem(), seen)
+	case *types.Map:
+		return containsPaymentSource(u.Elem(), seen)
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if containsPaymentSource(u.Field(i).Type(), seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// the PaymentSource is masked when logged as a zap object
+var _ zapcore.ObjectMarshaler = domain.PaymentSource{}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"os"
 
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// defaultLogger redacts the sensitive data of the log entries, e.g. the PANs, see Redact.
 var defaultLogger = zap.New(zapcore.NewCore(
-	zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+	NewRedactingEncoder(zapcore.NewJSONEncoder(zapcore.EncoderConfig{
 		TimeKey:        "@timestamp",
 		LevelKey:       "level",
 		NameKey:        "logger",
@@ -21,7 +24,7 @@ var defaultLogger = zap.New(zapcore.NewCore(
 		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
 		EncodeDuration: zapcore.NanosDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
-	}),
+	})),
 	zapcore.AddSync(os.Stdout),
 	zap.NewAtomicLevelAt(zapcore.InfoLevel),
 ))
@@ -63,9 +66,12 @@ func WithOptions(ctx context.Context, opts ...zap.Option) context.Context {
 }
 
 // Error will log at the error level using the logger associated with the
-// context.
+// context, the span of the context if any is marked as failed with the msg.
 func Error(ctx context.Context, msg string, fields ...zap.Field) {
 	From(ctx).Error(msg, fields...)
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetStatus(codes.Error, msg)
+	}
 }
 
 // Print will log at the info level using the logger associated with the
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"regexp"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	// contiguousPAN matches 13 to 19 digits, they are always masked.
+	contiguousPAN = regexp.MustCompile(`\b\d{13,19}\b`)
+	// separatedPAN matches 13 to 19 digits separated by spaces or dashes, they are masked when they pass the
+	// Luhn check to not mask e.g. the uuids made of digits only.
+	separatedPAN = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// cvv matches the value of a cvv key, including the escaped json keys.
+	cvv = regexp.MustCompile(`(?i)((?:cvv|cvc|cvv2|cvc2|security_code)(?:\\?")?\s*[:=]\s*(?:\\?")?)\d{3,4}`)
+	// authorization matches the value of the Authorization header, e.g. a dumped http.Header, or of an authorization json key.
+	authorization = regexp.MustCompile(`((?:Authorization(?:\\?")?|(?:\\?")authorization(?:\\?"))\s*[:=]\s*\[?(?:\\?")?)[^\s"\\\],}]+`)
+
+	bufferPool = buffer.NewPool()
+)
+
+// Redact masks anything resembling a PAN, keeping its last 4 digits, the values of the cvv keys
+// and the values of the Authorization header.
+func Redact(s string) string {
+	return string(redact([]byte(s)))
+}
+
+func redact(b []byte) []byte {
+	b = contiguousPAN.ReplaceAllFunc(b, maskPAN)
+	b = separatedPAN.ReplaceAllFunc(b, func(m []byte) []byte {
+		if !luhnValid(m) {
+			return m
+		}
+		return maskPAN(m)
+	})
+	b = cvv.ReplaceAll(b, []byte("${1}***"))
+	b = authorization.ReplaceAll(b, []byte("${1}"+redacted))
+	return b
+}
+
+// maskPAN masks all the digits but the last 4, the separators are kept.
+func maskPAN(pan []byte) []byte {
+	masked := make([]byte, len(pan))
+	digits := 0
+	for i := len(pan) - 1; i >= 0; i-- {
+		c := pan[i]
+		if c >= '0' && c <= '9' {
+			digits++
+			if digits > 4 {
+				c = '*'
+			}
+		}
+		masked[i] = c
+	}
+	return masked
+}
+
+func luhnValid(pan []byte) bool {
+	sum, double := 0, false
+	for i := len(pan) - 1; i >= 0; i-- {
+		c := pan[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactingEncoder redacts the encoded log entries, including the fields of the logger and the objects
+// encoded by reflection.
+type redactingEncoder struct {
+	zapcore.Encoder
+}
+
+// NewRedactingEncoder wraps the encoder to redact the sensitive data of the encoded log entries with Redact.
+func NewRedactingEncoder(enc zapcore.Encoder) zapcore.Encoder {
+	return redactingEncoder{enc}
+}
+
+func (e redactingEncoder) Clone() zapcore.Encoder {
+	return redactingEncoder{e.Encoder.Clone()}
+}
+
+func (e redactingEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+
+	out := bufferPool.Get()
+	_, _ = out.Write(redact(buf.Bytes()))
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package logging_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+func TestRedact(t *testing.T) {
+	testCases := []struct {
+		description string
+		in          string
+		expected    string
+	}{
+		{"pan", "card 4242424242424242 declined", "card ************4242 declined"},
+		{"pan with spaces", "card 4242 4242 4242 4242 declined", "card **** **** **** 4242 declined"},
+		{"pan with dashes", "card 4242-4242-4242-4242", "card ****-****-****-4242"},
+		{"digits with separators failing luhn are kept", "id 12345678-1234-1234-1234", "id 12345678-1234-1234-1234"},
+		{"short numbers are kept", "amount 100000", "amount 100000"},
+		{"cvv json key", `{"pan":"4242424242424242","cvv":"123"}`, `{"pan":"************4242","cvv":"***"}`},
+		{"cvv escaped json key", `{\"CVV\":\"123\"}`, `{\"CVV\":\"***\"}`},
+		{"cvv assignment", "cvv=1234", "cvv=***"},
+		{"authorization header", "map[Authorization:[checkout-token-1] Content-Type:[application/json]]",
+			"map[Authorization:[[REDACTED]] Content-Type:[application/json]]"},
+		{"authorization json key", `{"authorization":"checkout-token-1"}`, `{"authorization":"[REDACTED]"}`},
+		{"authorization id is kept", `{"authorization.id":"8b6e7c1a-5f3e-4c2b-9d1e-0a1b2c3d4e5f"}`,
+			`{"authorization.id":"8b6e7c1a-5f3e-4c2b-9d1e-0a1b2c3d4e5f"}`},
+		{"authorization in messages is kept", "unable to complete authorization: unprocessable",
+			"unable to complete authorization: unprocessable"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.expected, logging.Redact(tt.in))
+		})
+	}
+}
+
+func TestNewRedactingEncoder(t *testing.T) {
+	var out bytes.Buffer
+	enc := logging.NewRedactingEncoder(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}))
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&out), zapcore.InfoLevel)).
+		With(zap.String("http.header", "map[Authorization:[checkout-token-1]]"))
+
+	logger.Info("pq: invalid input 4242424242424242",
+		zap.Reflect("payment_source", struct{ PAN, CVV string }{"4242424242424242", "123"}))
+
+	assert.Equal(t,
+		`{"msg":"pq: invalid input ************4242","http.header":"map[Authorization:[[REDACTED]]]",`+
+			`"payment_source":{"PAN":"************4242","CVV":"***"}}`+"\n",
+		out.String())
+}
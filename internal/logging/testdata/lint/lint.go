@@ -0,0 +1,25 @@
+// Package lint logs payment sources for the lint test of the logging package, it is not built.
+package lint
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+func unmasked(ctx context.Context, authorization *domain.Authorization, source domain.PaymentSource) {
+	logging.Print(ctx, "authorize", zap.Any("authorization", authorization))
+	logging.Print(ctx, "authorize", zap.String("pan", authorization.PaymentSource.PAN))
+	logging.Error(ctx, fmt.Sprintf("card %v declined", source))
+	logging.From(ctx).Info("authorize", zap.Reflect("payment_source", source))
+}
+
+func masked(ctx context.Context, authorization *domain.Authorization, source domain.PaymentSource) {
+	logging.Print(ctx, "authorize", zap.Object("payment_source", authorization.PaymentSource))
+	logging.Print(ctx, "authorize", zap.Any("payment_source", source))
+	logging.Print(ctx, "authorize", zap.String("last4", source.Last4()))
+}
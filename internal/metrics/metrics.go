@@ -0,0 +1,110 @@
+// Package metrics defines the Prometheus metrics of the payment gateway, they are served by Handler.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "payment_gateway"
+
+// StatusRejected is the status of a payment action that has been rejected before it was stored,
+// e.g. an invalid card or an authorization over the velocity limits.
+const StatusRejected = "rejected"
+
+// CurrencyInvalid is the currency label of the payment actions whose currency is not an ISO 4217 code, so that
+// the currencies of the rejected requests cannot add series without bound.
+const CurrencyInvalid = "invalid"
+
+// the outcomes of a config reload
+const (
+	ReloadSuccess = "success"
+	ReloadFailure = "failure"
+)
+
+var (
+	// PaymentActions counts the payment actions by type, status, merchant and currency.
+	// The authorization approval rate is the rate of authorizations with the success status.
+	PaymentActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payment_actions_total",
+		Help:      "Payment actions by type, status, merchant and currency.",
+	}, []string{"type", "status", "merchant", "currency"})
+
+	// HTTPRequestDuration observes the latency of the HTTP handlers by api, method and status code.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP handlers by api, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"api", "method", "status_code"})
+
+	// HTTPRequestsInFlight is the number of HTTP requests being handled by api.
+	HTTPRequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests being handled by api.",
+	}, []string{"api"})
+
+	// StoreQueryDuration observes the latency of the store operations.
+	StoreQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "store_query_duration_seconds",
+		Help:      "Latency of the store operations.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	// ConfigReloads counts the reloads of the config by outcome.
+	ConfigReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "Reloads of the config by outcome.",
+	}, []string{"outcome"})
+
+	// AuditEntriesDropped counts the entries of the audit log which could not be appended.
+	AuditEntriesDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audit_entries_dropped_total",
+		Help:      "Entries of the audit log which could not be appended.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(PaymentActions, HTTPRequestDuration, HTTPRequestsInFlight, StoreQueryDuration, ConfigReloads,
+		AuditEntriesDropped)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDB registers the connection pool stats of the db.
+func RegisterDB(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// ObservePaymentAction counts the payment action.
+func ObservePaymentAction(paymentActionType, status, merchantID, currency string) {
+	PaymentActions.WithLabelValues(paymentActionType, status, merchantID, currency).Inc()
+}
+
+// ObserveConfigReload counts the reload of the config with the outcome.
+func ObserveConfigReload(outcome string) {
+	ConfigReloads.WithLabelValues(outcome).Inc()
+}
+
+// ObserveAuditEntriesDropped counts the entries of the audit log which could not be appended.
+func ObserveAuditEntriesDropped(n int) {
+	AuditEntriesDropped.Add(float64(n))
+}
+
+// ObserveStoreQuery observes the latency of the store operation started at start, it is meant to be deferred.
+func ObserveStoreQuery(operation string, start time.Time) {
+	StoreQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
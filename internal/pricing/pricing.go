@@ -0,0 +1,109 @@
+package pricing
+
+import (
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+// Region indicates if the card is issued in the same country as the merchant.
+type Region string
+
+const (
+	// RegionDomestic is for cards issued in the country of the merchant.
+	RegionDomestic Region = "domestic"
+	// RegionInternational is for cards issued outside of the country of the merchant,
+	// or when the issuing country is unknown.
+	RegionInternational Region = "international"
+)
+
+const basisPointsPerUnit = 10000
+
+// Rule is a single fee rule of a Schedule. Empty Action, Scheme, Currency and Region
+// match any value. The fee is BasisPoints of the amount plus Fixed minor units of
+// the amount currency.
+type Rule struct {
+	Action      domain.PaymentActionType
+	Scheme      domain.CardScheme
+	Currency    string
+	Region      Region
+	BasisPoints uint64
+	Fixed       uint64
+}
+
+// Schedule is the fee schedule of a merchant.
+type Schedule struct {
+	// Country is the ISO 3166-1 alpha-2 code of the merchant, used to derive the Region.
+	Country string
+	Rules   []Rule
+}
+
+// Calculator calculates the fees of payment actions given the fee schedules of the merchants.
+type Calculator struct {
+	schedules map[string]Schedule
+}
+
+// NewCalculator initialises a new Calculator with the fee schedules keyed by merchant.
+func NewCalculator(schedules map[string]Schedule) *Calculator {
+	return &Calculator{schedules: schedules}
+}
+
+// Fee returns the fee of the payment action in the currency of the amount.
+// The most specific matching rule of the merchant schedule is used, ties are
+// resolved by the order of the rules. A zero fee is returned if no rule matches.
+func (c *Calculator) Fee(merchantID string, paymentActionType domain.PaymentActionType, card domain.CardInfo, amount domain.Amount) domain.Amount {
+	fee := domain.Amount{
+		Currency: amount.Currency,
+		Exponent: amount.Exponent,
+	}
+
+	schedule, ok := c.schedules[merchantID]
+	if !ok {
+		return fee
+	}
+
+	region := RegionInternational
+	if card.Country != "" && card.Country == schedule.Country {
+		region = RegionDomestic
+	}
+
+	var (
+		rule      *Rule
+		bestScore = -1
+	)
+	for i := range schedule.Rules {
+		r := &schedule.Rules[i]
+		score, ok := r.match(paymentActionType, card.Scheme, amount.Currency, region)
+		if ok && score > bestScore {
+			rule, bestScore = r, score
+		}
+	}
+	if rule == nil {
+		return fee
+	}
+
+	// round half up to the nearest minor unit
+	fee.MinorUnits = (amount.MinorUnits*rule.BasisPoints+basisPointsPerUnit/2)/basisPointsPerUnit + rule.Fixed
+	return fee
+}
+
+// match returns the number of fields the rule matches explicitly, and false if any
+// of the fields doesn't match.
+func (r Rule) match(paymentActionType domain.PaymentActionType, scheme domain.CardScheme, currency string, region Region) (int, bool) {
+	var score int
+	for _, f := range []struct {
+		rule, value string
+	}{
+		{string(r.Action), string(paymentActionType)},
+		{string(r.Scheme), string(scheme)},
+		{r.Currency, currency},
+		{string(r.Region), string(region)},
+	} {
+		if f.rule == "" {
+			continue
+		}
+		if f.rule != f.value {
+			return 0, false
+		}
+		score++
+	}
+	return score, true
+}
@@ -0,0 +1,118 @@
+package pricing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/pricing"
+)
+
+func TestCalculator_Fee(t *testing.T) {
+	calculator := pricing.NewCalculator(map[string]pricing.Schedule{
+		"merchant-1": {
+			Country: "GB",
+			Rules: []pricing.Rule{
+				{Action: domain.PaymentActionTypeCapture, Scheme: domain.CardSchemeAmex, BasisPoints: 350},
+				{Action: domain.PaymentActionTypeCapture, Region: pricing.RegionDomestic, BasisPoints: 140, Fixed: 20},
+				{Action: domain.PaymentActionTypeCapture, Region: pricing.RegionInternational, BasisPoints: 290, Fixed: 20},
+				{Action: domain.PaymentActionTypeCapture, Currency: "EUR", Region: pricing.RegionInternational, BasisPoints: 190, Fixed: 25},
+				{Action: domain.PaymentActionTypeRefund, Fixed: 10},
+			},
+		},
+	})
+
+	gbp := func(minorUnits uint64) domain.Amount {
+		return domain.Amount{MinorUnits: minorUnits, Currency: "GBP", Exponent: 2}
+	}
+
+	testCases := []struct {
+		description       string
+		merchantID        string
+		paymentActionType domain.PaymentActionType
+		card              domain.CardInfo
+		amount            domain.Amount
+		expectedFee       domain.Amount
+	}{
+		{
+			"domestic capture",
+			"merchant-1",
+			domain.PaymentActionTypeCapture,
+			domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "GB"},
+			gbp(10000),
+			gbp(160),
+		},
+		{
+			"international capture",
+			"merchant-1",
+			domain.PaymentActionTypeCapture,
+			domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "US"},
+			gbp(10000),
+			gbp(310),
+		},
+		{
+			"unknown issuing country is international",
+			"merchant-1",
+			domain.PaymentActionTypeCapture,
+			domain.CardInfo{Scheme: domain.CardSchemeMastercard},
+			gbp(10000),
+			gbp(310),
+		},
+		{
+			"more specific currency rule wins",
+			"merchant-1",
+			domain.PaymentActionTypeCapture,
+			domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "FR"},
+			domain.Amount{MinorUnits: 10000, Currency: "EUR", Exponent: 2},
+			domain.Amount{MinorUnits: 215, Currency: "EUR", Exponent: 2},
+		},
+		{
+			"first rule wins on tie",
+			"merchant-1",
+			domain.PaymentActionTypeCapture,
+			domain.CardInfo{Scheme: domain.CardSchemeAmex, Country: "GB"},
+			gbp(10000),
+			gbp(350),
+		},
+		{
+			"percentage fee is rounded half up",
+			"merchant-1",
+			domain.PaymentActionTypeCapture,
+			domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "GB"},
+			gbp(1250),
+			gbp(38),
+		},
+		{
+			"refund",
+			"merchant-1",
+			domain.PaymentActionTypeRefund,
+			domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "GB"},
+			gbp(10000),
+			gbp(10),
+		},
+		{
+			"no matching rule",
+			"merchant-1",
+			domain.PaymentActionTypeVoid,
+			domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "GB"},
+			gbp(10000),
+			gbp(0),
+		},
+		{
+			"unknown merchant",
+			"merchant-2",
+			domain.PaymentActionTypeCapture,
+			domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "GB"},
+			gbp(10000),
+			gbp(0),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			fee := calculator.Fee(tc.merchantID, tc.paymentActionType, tc.card, tc.amount)
+			assert.Equal(t, tc.expectedFee, fee)
+		})
+	}
+}
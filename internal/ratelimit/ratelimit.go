@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+// Limit is a token bucket refilled at RequestsPerSecond up to Burst tokens, a zero RequestsPerSecond doesn't limit the requests.
+type Limit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// unlimited reports whether the limit doesn't limit the requests.
+func (l Limit) unlimited() bool {
+	return l.RequestsPerSecond <= 0
+}
+
+// burst is the capacity of the bucket, at least one request.
+func (l Limit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+	return float64(l.Burst)
+}
+
+// pruneInterval is how often the buckets refilled up to their burst are dropped, they are the same as new buckets.
+const pruneInterval = time.Minute
+
+type bucketKey struct {
+	merchantID string
+	endpoint   string
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter limits the requests of each merchant to each endpoint with a token bucket.
+type Limiter struct {
+	clock clockwork.Clock
+
+	mu sync.Mutex
+	// defaultLimit is the limit of the merchants without one.
+	defaultLimit Limit
+	limits       map[string]Limit
+	buckets      map[bucketKey]*bucket
+	pruned       time.Time
+}
+
+// NewLimiter initialises a new Limiter with the default limit and the limits keyed by merchant.
+func NewLimiter(clock clockwork.Clock, defaultLimit Limit, limits map[string]Limit) *Limiter {
+	return &Limiter{
+		clock:        clock,
+		defaultLimit: defaultLimit,
+		limits:       limits,
+		buckets:      make(map[bucketKey]*bucket),
+	}
+}
+
+// SetLimits replaces the default limit and the limits keyed by merchant, e.g. on a config reload. The buckets are
+// kept so that a reload doesn't refill them, they are capped at the burst of the new limits on the next request.
+func (l *Limiter) SetLimits(defaultLimit Limit, limits map[string]Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.defaultLimit = defaultLimit
+	l.limits = limits
+}
+
+// Allow takes a token from the bucket of the merchant and the endpoint, when the bucket is empty it returns false
+// and how long until a token is available.
+func (l *Limiter) Allow(merchantID, endpoint string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.limit(merchantID)
+	if limit.unlimited() {
+		return true, 0
+	}
+
+	now := l.clock.Now()
+	if now.Sub(l.pruned) >= pruneInterval {
+		l.prune(now)
+	}
+	key := bucketKey{merchantID: merchantID, endpoint: endpoint}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.burst(), updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(limit.burst(), b.tokens+now.Sub(b.updated).Seconds()*limit.RequestsPerSecond)
+	b.updated = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / limit.RequestsPerSecond
+	return false, time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+// limit returns the limit of the merchant, the default limit if it has none.
+func (l *Limiter) limit(merchantID string) Limit {
+	if limit, ok := l.limits[merchantID]; ok {
+		return limit
+	}
+	return l.defaultLimit
+}
+
+// prune drops the buckets refilled up to their burst so that the buckets of the merchants, or the clients,
+// which are gone don't accumulate.
+func (l *Limiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		limit := l.limit(key.merchantID)
+		if limit.unlimited() || b.tokens+now.Sub(b.updated).Seconds()*limit.RequestsPerSecond >= limit.burst() {
+			delete(l.buckets, key)
+		}
+	}
+	l.pruned = now
+}
@@ -0,0 +1,93 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffreyyong/payment-gateway/internal/ratelimit"
+)
+
+var someDate = time.Date(2021, 5, 2, 12, 0, 0, 0, time.UTC)
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Run("should allow the burst then refill at the rate", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(someDate)
+		l := ratelimit.NewLimiter(clock, ratelimit.Limit{}, map[string]ratelimit.Limit{
+			"merchant-1": {RequestsPerSecond: 2, Burst: 3},
+		})
+
+		for i := 0; i < 3; i++ {
+			allowed, _ := l.Allow("merchant-1", "POST /authorize")
+			assert.True(t, allowed)
+		}
+		allowed, retryAfter := l.Allow("merchant-1", "POST /authorize")
+		assert.False(t, allowed)
+		assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+		clock.Advance(500 * time.Millisecond)
+		allowed, _ = l.Allow("merchant-1", "POST /authorize")
+		assert.True(t, allowed)
+		allowed, _ = l.Allow("merchant-1", "POST /authorize")
+		assert.False(t, allowed)
+
+		clock.Advance(time.Hour)
+		for i := 0; i < 3; i++ {
+			allowed, _ := l.Allow("merchant-1", "POST /authorize")
+			assert.True(t, allowed, "the bucket is refilled up to the burst")
+		}
+		allowed, _ = l.Allow("merchant-1", "POST /authorize")
+		assert.False(t, allowed)
+	})
+
+	t.Run("should limit each merchant and endpoint separately", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(someDate)
+		l := ratelimit.NewLimiter(clock, ratelimit.Limit{RequestsPerSecond: 1, Burst: 1}, nil)
+
+		allowed, _ := l.Allow("merchant-1", "POST /authorize")
+		assert.True(t, allowed)
+		allowed, _ = l.Allow("merchant-1", "POST /authorize")
+		assert.False(t, allowed)
+
+		allowed, _ = l.Allow("merchant-1", "POST /capture")
+		assert.True(t, allowed)
+		allowed, _ = l.Allow("merchant-2", "POST /authorize")
+		assert.True(t, allowed)
+	})
+
+	t.Run("should not limit the merchants without limit", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(someDate)
+		l := ratelimit.NewLimiter(clock, ratelimit.Limit{}, map[string]ratelimit.Limit{
+			"merchant-1": {RequestsPerSecond: 1, Burst: 1},
+		})
+
+		for i := 0; i < 100; i++ {
+			allowed, _ := l.Allow("merchant-2", "POST /authorize")
+			assert.True(t, allowed)
+		}
+	})
+}
+
+func TestLimiter_SetLimits(t *testing.T) {
+	clock := clockwork.NewFakeClockAt(someDate)
+	l := ratelimit.NewLimiter(clock, ratelimit.Limit{}, map[string]ratelimit.Limit{
+		"merchant-1": {RequestsPerSecond: 1, Burst: 3},
+	})
+	allowed, _ := l.Allow("merchant-1", "POST /authorize")
+	assert.True(t, allowed)
+
+	l.SetLimits(ratelimit.Limit{RequestsPerSecond: 1, Burst: 1}, map[string]ratelimit.Limit{
+		"merchant-1": {RequestsPerSecond: 1, Burst: 1},
+	})
+
+	allowed, _ = l.Allow("merchant-1", "POST /authorize")
+	assert.True(t, allowed, "the bucket is kept")
+	allowed, _ = l.Allow("merchant-1", "POST /authorize")
+	assert.False(t, allowed, "the bucket is capped at the new burst")
+	allowed, _ = l.Allow("merchant-2", "POST /authorize")
+	assert.True(t, allowed)
+	allowed, _ = l.Allow("merchant-2", "POST /authorize")
+	assert.False(t, allowed, "the merchants without limit get the new default limit")
+}
@@ -0,0 +1,124 @@
+package risk
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultVelocityWindow is the window of the velocity rules when not configured.
+const defaultVelocityWindow = time.Hour
+
+// Config is the configuration of the rules of the Engine, a nil rule config disables the rule.
+type Config struct {
+	ReviewScore      int
+	BlockScore       int
+	CardVelocity     *VelocityConfig
+	RepeatedDeclines *VelocityConfig
+	AmountThreshold  *AmountThresholdConfig
+	BINCountry       *BINCountryConfig
+}
+
+// VelocityConfig configures a rule counting the authorizations of a card within a window.
+type VelocityConfig struct {
+	Max    int
+	Window time.Duration
+	Score  int
+}
+
+// AmountThresholdConfig configures the AmountThresholdRule.
+type AmountThresholdConfig struct {
+	Limits map[string]uint64
+	Score  int
+}
+
+// BINCountryConfig configures the BINCountryRule.
+type BINCountryConfig struct {
+	Countries []string
+	Score     int
+}
+
+// Validate checks the thresholds are consistent.
+func (c Config) Validate() error {
+	if c.ReviewScore <= 0 || c.BlockScore <= 0 {
+		return errors.New("risk review and block scores must be positive")
+	}
+	if c.ReviewScore > c.BlockScore {
+		return errors.New("risk review score must not be greater than the block score")
+	}
+	for name, v := range map[string]*VelocityConfig{
+		RuleCardVelocity:     c.CardVelocity,
+		RuleRepeatedDeclines: c.RepeatedDeclines,
+	} {
+		if v != nil && v.Max <= 0 {
+			return errors.Errorf("risk rule %s max must be positive", name)
+		}
+	}
+	return nil
+}
+
+func (c Config) rules(history History) []Rule {
+	var rules []Rule
+	if v := c.CardVelocity; v != nil {
+		rules = append(rules, CardVelocityRule{
+			History:     history,
+			MaxAttempts: v.Max,
+			Window:      window(v.Window),
+			Score:       v.Score,
+		})
+	}
+	if v := c.RepeatedDeclines; v != nil {
+		rules = append(rules, RepeatedDeclinesRule{
+			History:     history,
+			MaxDeclines: v.Max,
+			Window:      window(v.Window),
+			Score:       v.Score,
+		})
+	}
+	if a := c.AmountThreshold; a != nil {
+		rules = append(rules, AmountThresholdRule{
+			Limits: a.Limits,
+			Score:  a.Score,
+		})
+	}
+	if b := c.BINCountry; b != nil {
+		countries := make(map[string]bool, len(b.Countries))
+		for _, country := range b.Countries {
+			countries[country] = true
+		}
+		rules = append(rules, BINCountryRule{
+			Countries: countries,
+			Score:     b.Score,
+		})
+	}
+	return rules
+}
+
+// windows returns the distinct windows of the velocity rules.
+func (c Config) windows() []time.Duration {
+	var windows []time.Duration
+	for _, v := range []*VelocityConfig{c.CardVelocity, c.RepeatedDeclines} {
+		if v == nil {
+			continue
+		}
+		windows = appendWindow(windows, window(v.Window))
+	}
+	return windows
+}
+
+// appendWindow appends the window to the windows unless it is already in them.
+func appendWindow(windows []time.Duration, w time.Duration) []time.Duration {
+	for _, window := range windows {
+		if window == w {
+			return windows
+		}
+	}
+	return append(windows[:len(windows):len(windows)], w)
+}
+
+func window(w time.Duration) time.Duration {
+	if w <= 0 {
+		return defaultVelocityWindow
+	}
+	return w
+}
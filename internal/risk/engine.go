@@ -0,0 +1,185 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+// History is the authorization history of the cards used by the velocity rules.
+type History interface {
+	// CountAuthorizations counts the authorization attempts and the declined ones of the card identified
+	// by the PAN hash since each of the given times, the counts are in the order of the times.
+	CountAuthorizations(ctx context.Context, panHash string, since ...time.Time) (attempts, declines []int, err error)
+}
+
+// Input is the authorization evaluated by the rules.
+type Input struct {
+	Authorization *domain.Authorization
+	PANHash       string
+	Now           time.Time
+
+	counts *authorizationCounts
+}
+
+// CountAuthorizations counts the authorization attempts and the declined ones of the card within the window.
+// The authorizations are counted once per assessment for the windows of all the velocity rules.
+func (in Input) CountAuthorizations(ctx context.Context, history History, window time.Duration) (int, int, error) {
+	if in.counts == nil {
+		in.counts = &authorizationCounts{}
+	}
+	return in.counts.count(ctx, history, in, window)
+}
+
+// authorizationCounts memoises the counts of the authorizations of the card by window during an assessment.
+type authorizationCounts struct {
+	windows  []time.Duration
+	attempts map[time.Duration]int
+	declines map[time.Duration]int
+}
+
+func (c *authorizationCounts) count(ctx context.Context, history History, in Input, window time.Duration) (int, int, error) {
+	if attempts, ok := c.attempts[window]; ok {
+		return attempts, c.declines[window], nil
+	}
+
+	// the first count is done for the windows of all the velocity rules, the next ones only for the window
+	windows := []time.Duration{window}
+	if c.attempts == nil {
+		windows = appendWindow(c.windows, window)
+		c.attempts = make(map[time.Duration]int, len(windows))
+		c.declines = make(map[time.Duration]int, len(windows))
+	}
+	since := make([]time.Time, len(windows))
+	for i, w := range windows {
+		since[i] = in.Now.Add(-w)
+	}
+
+	attempts, declines, err := history.CountAuthorizations(ctx, in.PANHash, since...)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(attempts) != len(windows) || len(declines) != len(windows) {
+		return 0, 0, errors.Errorf("%d counts of authorizations for %d windows", len(attempts), len(windows))
+	}
+	for i, w := range windows {
+		c.attempts[w] = attempts[i]
+		c.declines[w] = declines[i]
+	}
+	return c.attempts[window], c.declines[window], nil
+}
+
+// Rule is a fraud screening rule, it returns a positive score when the rule is triggered.
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, in Input) (int, error)
+}
+
+// ruleset is the immutable set of rules and thresholds swapped on every Configure.
+type ruleset struct {
+	reviewScore int
+	blockScore  int
+	rules       []Rule
+	// windows are the windows of the velocity rules, counted at once.
+	windows []time.Duration
+}
+
+// Engine evaluates the rules against an authorization and sums up the scores into
+// a domain.RiskAssessment. The rules can be reconfigured at runtime.
+type Engine struct {
+	history     History
+	clock       clockwork.Clock
+	customRules []Rule
+	ruleset     atomic.Value // *ruleset
+}
+
+// Option is a functional configuration of the Engine.
+type Option func(*Engine)
+
+// WithClock configures the engine with a clock.
+func WithClock(clock clockwork.Clock) Option {
+	return func(e *Engine) { e.clock = clock }
+}
+
+// WithRules plugs in custom rules that are evaluated on top of the configured rules.
+func WithRules(rules ...Rule) Option {
+	return func(e *Engine) { e.customRules = append(e.customRules, rules...) }
+}
+
+// NewEngine initialises a new Engine with the authorization history and the config.
+func NewEngine(history History, cfg Config, opts ...Option) (*Engine, error) {
+	if history == nil {
+		return nil, fmt.Errorf("%w: history", errors.New("invalid param"))
+	}
+
+	e := &Engine{
+		history: history,
+		clock:   clockwork.NewRealClock(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if err := e.Configure(cfg); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Configure validates the config and atomically replaces the rules of the engine,
+// assessments in flight keep using the previous rules.
+func (e *Engine) Configure(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	rules := append(cfg.rules(e.history), e.customRules...)
+	e.ruleset.Store(&ruleset{
+		reviewScore: cfg.ReviewScore,
+		blockScore:  cfg.BlockScore,
+		rules:       rules,
+		windows:     cfg.windows(),
+	})
+	return nil
+}
+
+// Assess evaluates all the rules against the authorization, the authorization is blocked
+// when the total score reaches the block score and flagged for review when it reaches the review score.
+func (e *Engine) Assess(ctx context.Context, authorization *domain.Authorization) (domain.RiskAssessment, error) {
+	rs := e.ruleset.Load().(*ruleset)
+
+	in := Input{
+		Authorization: authorization,
+		PANHash:       authorization.PaymentSource.PANHash(),
+		Now:           e.clock.Now(),
+		counts:        &authorizationCounts{windows: rs.windows},
+	}
+
+	assessment := domain.RiskAssessment{Decision: domain.RiskDecisionAllow}
+	for _, rule := range rs.rules {
+		score, err := rule.Evaluate(ctx, in)
+		if err != nil {
+			return domain.RiskAssessment{}, errors.Wrapf(err, "evaluate rule %s", rule.Name())
+		}
+		if score <= 0 {
+			continue
+		}
+		assessment.Score += score
+		assessment.Reasons = append(assessment.Reasons, rule.Name())
+	}
+
+	switch {
+	case assessment.Score >= rs.blockScore:
+		assessment.Decision = domain.RiskDecisionBlock
+	case assessment.Score >= rs.reviewScore:
+		assessment.Decision = domain.RiskDecisionReview
+	}
+
+	return assessment, nil
+}
@@ -0,0 +1,150 @@
+package risk_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/risk"
+)
+
+var someDate = time.Date(2021, 5, 2, 12, 0, 0, 0, time.UTC)
+
+type fakeHistory struct {
+	attempts, declines int
+	since              []time.Time
+	calls              int
+}
+
+func (h *fakeHistory) CountAuthorizations(_ context.Context, _ string, since ...time.Time) ([]int, []int, error) {
+	h.since = since
+	h.calls++
+	attempts, declines := make([]int, len(since)), make([]int, len(since))
+	for i := range since {
+		attempts[i], declines[i] = h.attempts, h.declines
+	}
+	return attempts, declines, nil
+}
+
+func newAuthorization(minorUnits uint64, country string) *domain.Authorization {
+	return &domain.Authorization{
+		PaymentSource: domain.PaymentSource{PAN: "4000000000000259"},
+		Amount:        domain.Amount{MinorUnits: minorUnits, Currency: "GBP", Exponent: 2},
+		Card:          domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: country},
+	}
+}
+
+func TestEngine_Assess(t *testing.T) {
+	cfg := risk.Config{
+		ReviewScore:      50,
+		BlockScore:       100,
+		CardVelocity:     &risk.VelocityConfig{Max: 5, Window: time.Hour, Score: 60},
+		RepeatedDeclines: &risk.VelocityConfig{Max: 3, Window: 24 * time.Hour, Score: 100},
+		AmountThreshold:  &risk.AmountThresholdConfig{Limits: map[string]uint64{"GBP": 500000}, Score: 50},
+		BINCountry:       &risk.BINCountryConfig{Countries: []string{"KP"}, Score: 100},
+	}
+
+	testCases := []struct {
+		description        string
+		history            *fakeHistory
+		authorization      *domain.Authorization
+		expectedAssessment domain.RiskAssessment
+	}{
+		{
+			"allow when no rule is triggered",
+			&fakeHistory{attempts: 1},
+			newAuthorization(10000, "GB"),
+			domain.RiskAssessment{Decision: domain.RiskDecisionAllow},
+		},
+		{
+			"review when over the amount threshold",
+			&fakeHistory{},
+			newAuthorization(500001, "GB"),
+			domain.RiskAssessment{Decision: domain.RiskDecisionReview, Score: 50, Reasons: []string{risk.RuleAmountThreshold}},
+		},
+		{
+			"block when the scores add up",
+			&fakeHistory{attempts: 5},
+			newAuthorization(600000, "GB"),
+			domain.RiskAssessment{Decision: domain.RiskDecisionBlock, Score: 110, Reasons: []string{risk.RuleCardVelocity, risk.RuleAmountThreshold}},
+		},
+		{
+			"block on repeated declines",
+			&fakeHistory{attempts: 3, declines: 3},
+			newAuthorization(10000, "GB"),
+			domain.RiskAssessment{Decision: domain.RiskDecisionBlock, Score: 100, Reasons: []string{risk.RuleRepeatedDeclines}},
+		},
+		{
+			"block on BIN country",
+			&fakeHistory{},
+			newAuthorization(10000, "KP"),
+			domain.RiskAssessment{Decision: domain.RiskDecisionBlock, Score: 100, Reasons: []string{risk.RuleBINCountry}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			engine, err := risk.NewEngine(tc.history, cfg, risk.WithClock(clockwork.NewFakeClockAt(someDate)))
+			require.NoError(t, err)
+
+			assessment, err := engine.Assess(context.Background(), tc.authorization)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAssessment, assessment)
+			// the authorizations are counted once for the windows of both velocity rules
+			assert.Equal(t, 1, tc.history.calls)
+			assert.Equal(t, []time.Time{someDate.Add(-time.Hour), someDate.Add(-24 * time.Hour)}, tc.history.since)
+		})
+	}
+}
+
+func TestEngine_Configure(t *testing.T) {
+	history := &fakeHistory{attempts: 2}
+	engine, err := risk.NewEngine(history, risk.Config{
+		ReviewScore:  50,
+		BlockScore:   100,
+		CardVelocity: &risk.VelocityConfig{Max: 5, Score: 100},
+	}, risk.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	assessment, err := engine.Assess(context.Background(), newAuthorization(10000, "GB"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.RiskDecisionAllow, assessment.Decision)
+	assert.Equal(t, []time.Time{someDate.Add(-time.Hour)}, history.since, "default window is used")
+
+	t.Run("reconfigured rules are used", func(t *testing.T) {
+		require.NoError(t, engine.Configure(risk.Config{
+			ReviewScore:  50,
+			BlockScore:   100,
+			CardVelocity: &risk.VelocityConfig{Max: 2, Window: 10 * time.Minute, Score: 100},
+		}))
+
+		assessment, err := engine.Assess(context.Background(), newAuthorization(10000, "GB"))
+		require.NoError(t, err)
+		assert.Equal(t, domain.RiskDecisionBlock, assessment.Decision)
+		assert.Equal(t, []time.Time{someDate.Add(-10 * time.Minute)}, history.since)
+	})
+
+	t.Run("invalid config keeps the current rules", func(t *testing.T) {
+		require.Error(t, engine.Configure(risk.Config{ReviewScore: 100, BlockScore: 50}))
+
+		assessment, err := engine.Assess(context.Background(), newAuthorization(10000, "GB"))
+		require.NoError(t, err)
+		assert.Equal(t, domain.RiskDecisionBlock, assessment.Decision)
+	})
+}
+
+func TestInput_CountAuthorizations(t *testing.T) {
+	history := &fakeHistory{attempts: 4, declines: 1}
+	in := risk.Input{PANHash: "hash", Now: someDate}
+
+	attempts, declines, err := in.CountAuthorizations(context.Background(), history, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 4, attempts)
+	assert.Equal(t, 1, declines)
+	assert.Equal(t, []time.Time{someDate.Add(-time.Hour)}, history.since)
+}
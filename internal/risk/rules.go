@@ -0,0 +1,101 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// names of the built-in rules, used as the reasons of a domain.RiskAssessment.
+const (
+	RuleCardVelocity     = "card_velocity"
+	RuleAmountThreshold  = "amount_threshold"
+	RuleBINCountry       = "bin_country_blocklist"
+	RuleRepeatedDeclines = "repeated_declines"
+)
+
+// CardVelocityRule scores an authorization when the card has been used for at least
+// MaxAttempts authorizations within the Window.
+type CardVelocityRule struct {
+	History     History
+	MaxAttempts int
+	Window      time.Duration
+	Score       int
+}
+
+// Name implements the Rule interface.
+func (r CardVelocityRule) Name() string { return RuleCardVelocity }
+
+// Evaluate implements the Rule interface.
+func (r CardVelocityRule) Evaluate(ctx context.Context, in Input) (int, error) {
+	attempts, _, err := in.CountAuthorizations(ctx, r.History, r.Window)
+	if err != nil {
+		return 0, errors.Wrap(err, "count authorizations")
+	}
+	if attempts >= r.MaxAttempts {
+		return r.Score, nil
+	}
+	return 0, nil
+}
+
+// RepeatedDeclinesRule scores an authorization when the card has been declined at least
+// MaxDeclines times within the Window.
+type RepeatedDeclinesRule struct {
+	History     History
+	MaxDeclines int
+	Window      time.Duration
+	Score       int
+}
+
+// Name implements the Rule interface.
+func (r RepeatedDeclinesRule) Name() string { return RuleRepeatedDeclines }
+
+// Evaluate implements the Rule interface.
+func (r RepeatedDeclinesRule) Evaluate(ctx context.Context, in Input) (int, error) {
+	_, declines, err := in.CountAuthorizations(ctx, r.History, r.Window)
+	if err != nil {
+		return 0, errors.Wrap(err, "count authorizations")
+	}
+	if declines >= r.MaxDeclines {
+		return r.Score, nil
+	}
+	return 0, nil
+}
+
+// AmountThresholdRule scores an authorization when the amount is over the limit of its currency,
+// currencies without a limit are not scored.
+type AmountThresholdRule struct {
+	// Limits are in minor units keyed by currency.
+	Limits map[string]uint64
+	Score  int
+}
+
+// Name implements the Rule interface.
+func (r AmountThresholdRule) Name() string { return RuleAmountThreshold }
+
+// Evaluate implements the Rule interface.
+func (r AmountThresholdRule) Evaluate(_ context.Context, in Input) (int, error) {
+	amount := in.Authorization.Amount
+	if limit, ok := r.Limits[amount.Currency]; ok && amount.MinorUnits > limit {
+		return r.Score, nil
+	}
+	return 0, nil
+}
+
+// BINCountryRule scores an authorization when the card is issued in one of the blocked countries.
+type BINCountryRule struct {
+	Countries map[string]bool
+	Score     int
+}
+
+// Name implements the Rule interface.
+func (r BINCountryRule) Name() string { return RuleBINCountry }
+
+// Evaluate implements the Rule interface.
+func (r BINCountryRule) Evaluate(_ context.Context, in Input) (int, error) {
+	if r.Countries[in.Authorization.Card.Country] {
+		return r.Score, nil
+	}
+	return 0, nil
+}
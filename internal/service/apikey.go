@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+const (
+	// apiKeyPrefix makes the API keys recognisable, e.g. by secret scanners.
+	apiKeyPrefix = "pgk_"
+	// apiKeyBytes is the number of random bytes of an API key.
+	apiKeyBytes = 32
+	// apiKeyLastUsedResolution is the resolution of the last use of an API key, it is not recorded on every request.
+	apiKeyLastUsedResolution = time.Minute
+	// DefaultRotationOverlap is the time the rotated API key stays valid for when no overlap is requested.
+	DefaultRotationOverlap = 24 * time.Hour
+)
+
+// CreateAPIKey creates an API key of the merchant with the scopes, expiring at expiresAt if not nil.
+// The key is only returned here, only its hash is stored. domain.ErrMerchantNotFound is returned if the merchants are
+// managed with the admin API and the merchant is not one of them.
+func (s *Service) CreateAPIKey(ctx context.Context, merchantID string, scopes []domain.Scope, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	ctx, span := tracing.Start(ctx, "Service.CreateAPIKey")
+	defer span.End()
+
+	const errLogMsg = "unable to create api key"
+	ctx = logging.WithFields(ctx, zap.String(logging.MerchantID, merchantID))
+
+	if err := validateScopes(scopes); err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, "", err
+	}
+	if s.merchants != nil {
+		if _, err := s.GetMerchant(ctx, merchantID); err != nil {
+			logging.Error(ctx, errLogMsg, zap.Error(err))
+			return nil, "", err
+		}
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		err = errors.Wrap(err, "unable to generate api key")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, "", err
+	}
+
+	apiKey, err := s.store.CreateAPIKey(ctx, &domain.APIKey{
+		MerchantID: merchantID,
+		Hash:       domain.HashAPIKey(key),
+		Scopes:     scopes,
+		ExpiresAt:  expiresAt,
+	}, s.clock.Now())
+	if err != nil {
+		err = errors.Wrap(err, "unable to create api key in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, "", err
+	}
+
+	s.appendAuditEntry(ctx, &domain.AuditEntry{MerchantID: merchantID, Operation: "create_api_key", ResourceID: apiKey.ID.String(), Outcome: "success"})
+	return apiKey, key, nil
+}
+
+// AuthenticateAPIKey returns the API key given the key, domain.ErrAPIKeyInvalid is returned if it is unknown, expired or revoked.
+// The last use of the key is recorded.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "Service.AuthenticateAPIKey")
+	defer span.End()
+
+	apiKey, err := s.store.GetAPIKeyByHash(ctx, domain.HashAPIKey(key))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, domain.ErrAPIKeyInvalid
+	}
+	if err != nil {
+		err = errors.Wrap(err, "unable to get api key from store")
+		logging.Error(ctx, "unable to authenticate api key", zap.Error(err))
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if !apiKey.ValidAt(now) {
+		return nil, errors.Wrap(domain.ErrAPIKeyInvalid, "api key expired or revoked")
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyLastUsedResolution {
+		if err := s.store.TouchAPIKey(ctx, apiKey.ID, now); err != nil {
+			// the key is valid, failing to record its use must not fail the request
+			logging.Error(ctx, "unable to record last use of api key", zap.Error(err))
+		} else {
+			apiKey.LastUsedAt = &now
+		}
+	}
+	return apiKey, nil
+}
+
+// RotateAPIKey creates a new API key of the merchant with the scopes of the key, the key stays valid for the overlap,
+// DefaultRotationOverlap if not positive, so that the new key can be rolled out without downtime.
+// The new key expires with the key so that a key can't extend its own lifetime, and a key which is already rotated
+// can't be rotated again.
+func (s *Service) RotateAPIKey(ctx context.Context, merchantID string, keyID uuid.UUID, overlap time.Duration) (*domain.APIKey, string, error) {
+	ctx, span := tracing.Start(ctx, "Service.RotateAPIKey")
+	defer span.End()
+
+	const errLogMsg = "unable to rotate api key"
+	ctx = logging.WithFields(ctx, zap.String(logging.MerchantID, merchantID))
+
+	current, err := s.merchantAPIKey(ctx, merchantID, keyID)
+	if err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, "", err
+	}
+	if current.RotatedAt != nil {
+		err = domain.ErrAPIKeyRotated
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, "", err
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		err = errors.Wrap(err, "unable to generate api key")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, "", err
+	}
+
+	now := s.clock.Now()
+	if overlap <= 0 {
+		overlap = DefaultRotationOverlap
+	}
+	newKey := &domain.APIKey{
+		MerchantID: merchantID,
+		Hash:       domain.HashAPIKey(key),
+		Scopes:     current.Scopes,
+		ExpiresAt:  current.ExpiresAt,
+	}
+
+	apiKey, err := s.store.RotateAPIKey(ctx, keyID, newKey, now.Add(overlap), now)
+	if err != nil {
+		err = errors.Wrap(err, "unable to rotate api key in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, "", err
+	}
+
+	s.appendAuditEntry(ctx, &domain.AuditEntry{MerchantID: merchantID, Operation: "rotate_api_key", ResourceID: keyID.String(), Outcome: "success"})
+	return apiKey, key, nil
+}
+
+// RevokeAPIKey revokes the API key of the merchant, it is invalid immediately.
+func (s *Service) RevokeAPIKey(ctx context.Context, merchantID string, keyID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "Service.RevokeAPIKey")
+	defer span.End()
+
+	const errLogMsg = "unable to revoke api key"
+	ctx = logging.WithFields(ctx, zap.String(logging.MerchantID, merchantID))
+
+	if _, err := s.merchantAPIKey(ctx, merchantID, keyID); err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return err
+	}
+
+	if err := s.store.RevokeAPIKey(ctx, keyID, s.clock.Now()); err != nil {
+		err = errors.Wrap(err, "unable to revoke api key in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return err
+	}
+
+	s.appendAuditEntry(ctx, &domain.AuditEntry{MerchantID: merchantID, Operation: "revoke_api_key", ResourceID: keyID.String(), Outcome: "success"})
+	return nil
+}
+
+// merchantAPIKey returns the API key of the merchant, the keys of the other merchants are not found.
+func (s *Service) merchantAPIKey(ctx context.Context, merchantID string, keyID uuid.UUID) (*domain.APIKey, error) {
+	apiKey, err := s.store.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get api key from store")
+	}
+	if apiKey.MerchantID != merchantID {
+		return nil, errors.Wrap(domain.ErrAPIKeyNotFound, "api key of another merchant")
+	}
+	return apiKey, nil
+}
+
+// validateScopes checks that there is at least one scope and that the scopes are known.
+func validateScopes(scopes []domain.Scope) error {
+	if len(scopes) == 0 {
+		return errors.Wrap(domain.ErrInvalidScope, "at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return errors.Wrapf(domain.ErrInvalidScope, "unknown scope %q", scope)
+		}
+	}
+	return nil
+}
+
+// generateAPIKey returns a new random API key.
+func generateAPIKey() (string, error) {
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/service"
+	"github.com/jeffreyyong/payment-gateway/internal/service/mocks"
+)
+
+func TestService_CreateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	var stored *domain.APIKey
+	store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any(), someDate).
+		DoAndReturn(func(_ context.Context, key *domain.APIKey, _ time.Time) (*domain.APIKey, error) {
+			stored = key
+			k := *key
+			k.ID = uuid.NewV4()
+			return &k, nil
+		})
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	_, _, err = s.CreateAPIKey(ctx, "merchant-1", []domain.Scope{"admin"}, nil)
+	require.ErrorIs(t, err, domain.ErrInvalidScope)
+
+	key, secret, err := s.CreateAPIKey(ctx, "merchant-1", []domain.Scope{domain.ScopeAuthorize}, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "pgk_"))
+	assert.Equal(t, domain.HashAPIKey(secret), stored.Hash, "only the hash of the key is stored")
+	assert.NotContains(t, stored.Hash, secret)
+	assert.Equal(t, []domain.Scope{domain.ScopeAuthorize}, key.Scopes)
+}
+
+func TestService_CreateAPIKey_UnknownMerchant(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	merchants := mocks.NewMockMerchants(ctrl)
+	merchants.EXPECT().GetMerchant(gomock.Any(), "unknown").Return(nil, domain.ErrMerchantNotFound)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithMerchants(merchants))
+	require.NoError(t, err)
+
+	_, _, err = s.CreateAPIKey(ctx, "unknown", []domain.Scope{domain.ScopeAuthorize}, nil)
+	assert.ErrorIs(t, err, domain.ErrMerchantNotFound)
+}
+
+func TestService_AuthenticateAPIKey(t *testing.T) {
+	const key = "pgk_key"
+	var (
+		keyID   = uuid.NewV4()
+		past    = someDate.Add(-time.Hour)
+		recent  = someDate.Add(-time.Second)
+		expired = someDate
+	)
+
+	testCases := []struct {
+		description string
+		stored      *domain.APIKey
+		storeErr    error
+		expectTouch bool
+		expectedErr error
+	}{
+		{
+			description: "should authenticate the key and record its use",
+			stored:      &domain.APIKey{ID: keyID, LastUsedAt: &past},
+			expectTouch: true,
+		},
+		{
+			description: "should not record the use of a key used within the resolution",
+			stored:      &domain.APIKey{ID: keyID, LastUsedAt: &recent},
+		},
+		{
+			description: "should reject an expired key",
+			stored:      &domain.APIKey{ID: keyID, ExpiresAt: &expired},
+			expectedErr: domain.ErrAPIKeyInvalid,
+		},
+		{
+			description: "should reject a revoked key",
+			stored:      &domain.APIKey{ID: keyID, RevokedAt: &past},
+			expectedErr: domain.ErrAPIKeyInvalid,
+		},
+		{
+			description: "should reject an unknown key",
+			storeErr:    domain.ErrAPIKeyNotFound,
+			expectedErr: domain.ErrAPIKeyInvalid,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			store.EXPECT().GetAPIKeyByHash(gomock.Any(), domain.HashAPIKey(key)).Return(tt.stored, tt.storeErr)
+			if tt.expectTouch {
+				store.EXPECT().TouchAPIKey(gomock.Any(), keyID, someDate).Return(nil)
+			}
+
+			s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+			require.NoError(t, err)
+
+			apiKey, err := s.AuthenticateAPIKey(context.Background(), key)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, keyID, apiKey.ID)
+		})
+	}
+}
+
+func TestService_RotateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	keyID := uuid.NewV4()
+	createdDate := someDate.Add(-10 * 24 * time.Hour)
+	expiresAt := createdDate.Add(90 * 24 * time.Hour)
+	current := &domain.APIKey{
+		ID:          keyID,
+		MerchantID:  "merchant-1",
+		Scopes:      []domain.Scope{domain.ScopeRefund, domain.ScopeRead},
+		CreatedDate: createdDate,
+		ExpiresAt:   &expiresAt,
+	}
+
+	t.Run("should keep the rotated key valid for the overlap", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mocks.NewMockStore(ctrl)
+		store.EXPECT().GetAPIKey(gomock.Any(), keyID).Return(current, nil)
+		store.EXPECT().RotateAPIKey(gomock.Any(), keyID, gomock.Any(), someDate.Add(time.Hour), someDate).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, newKey *domain.APIKey, _, _ time.Time) (*domain.APIKey, error) {
+				assert.Equal(t, current.Scopes, newKey.Scopes)
+				assert.Equal(t, expiresAt, *newKey.ExpiresAt, "the new key expires with the key")
+				k := *newKey
+				k.ID = uuid.NewV4()
+				return &k, nil
+			})
+
+		s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+		require.NoError(t, err)
+
+		key, secret, err := s.RotateAPIKey(ctx, "merchant-1", keyID, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, domain.HashAPIKey(secret), key.Hash)
+	})
+
+	t.Run("should not rotate a key which is already rotated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rotatedAt := someDate.Add(-time.Minute)
+		rotated := *current
+		rotated.RotatedAt = &rotatedAt
+		store := mocks.NewMockStore(ctrl)
+		store.EXPECT().GetAPIKey(gomock.Any(), keyID).Return(&rotated, nil)
+
+		s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+		require.NoError(t, err)
+
+		_, _, err = s.RotateAPIKey(ctx, "merchant-1", keyID, time.Hour)
+		assert.ErrorIs(t, err, domain.ErrAPIKeyRotated)
+	})
+
+	t.Run("should not rotate the key of another merchant", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mocks.NewMockStore(ctrl)
+		store.EXPECT().GetAPIKey(gomock.Any(), keyID).Return(current, nil)
+
+		s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+		require.NoError(t, err)
+
+		_, _, err = s.RotateAPIKey(ctx, "merchant-2", keyID, time.Hour)
+		assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+	})
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+// auditVerifyBatchSize is the number of entries of the audit log verified at a time.
+const auditVerifyBatchSize = 1000
+
+// Audit appends the entry to the audit log, the merchant, the token and the request id are taken
+// from the context of the request if they are not set. It is a no op without audit log.
+func (s *Service) Audit(ctx context.Context, entry *domain.AuditEntry) error {
+	ctx, span := tracing.Start(ctx, "Service.Audit")
+	defer span.End()
+
+	if s.auditLog == nil {
+		return nil
+	}
+
+	entry.Time = s.clock.Now()
+	if entry.MerchantID == "" {
+		entry.MerchantID = appcontext.GetMerchant(ctx)
+	}
+	if entry.TokenID == "" {
+		entry.TokenID = appcontext.GetTokenID(ctx)
+	}
+	if entry.RequestID == "" {
+		entry.RequestID = appcontext.GetCorrelationID(ctx)
+	}
+
+	if err := s.auditLog.AppendAuditEntry(ctx, entry); err != nil {
+		return errors.Wrap(err, "unable to append audit entry in store")
+	}
+	return nil
+}
+
+// appendAuditEntry appends the entry of an operation of the service to the audit log,
+// the operation has been done hence a failure is only logged.
+func (s *Service) appendAuditEntry(ctx context.Context, entry *domain.AuditEntry) {
+	if err := s.Audit(ctx, entry); err != nil {
+		logging.Error(ctx, "unable to audit operation", zap.Error(err), zap.String("audit.operation", entry.Operation))
+	}
+}
+
+// VerifyAuditLog verifies the hash chain of the whole audit log and returns the number of entries verified.
+// domain.ErrAuditLogTampered is returned with the first entry that has been modified, deleted or inserted.
+func (s *Service) VerifyAuditLog(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "Service.VerifyAuditLog")
+	defer span.End()
+
+	if s.auditLog == nil {
+		return 0, errors.New("no audit log to verify")
+	}
+
+	var (
+		prev     *domain.AuditEntry
+		verified int64
+	)
+	for {
+		var afterSequence int64
+		if prev != nil {
+			afterSequence = prev.Sequence
+		}
+
+		entries, err := s.auditLog.ListAuditEntries(ctx, afterSequence, auditVerifyBatchSize)
+		if err != nil {
+			return verified, errors.Wrap(err, "unable to list audit entries from store")
+		}
+
+		for _, entry := range entries {
+			if err := entry.Verify(prev); err != nil {
+				logging.Error(ctx, "audit log verification failed", zap.Error(err), zap.Int64("audit.sequence", entry.Sequence))
+				return verified, err
+			}
+			prev = entry
+			verified++
+		}
+
+		if len(entries) < auditVerifyBatchSize {
+			return verified, nil
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/service"
+	"github.com/jeffreyyong/payment-gateway/internal/service/mocks"
+)
+
+func TestService_Authorize_AuditLog(t *testing.T) {
+	ctx := appcontext.WithCorrelationID(appcontext.WithTokenID(context.Background(), "token-1"), "request-1")
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound)
+	store.EXPECT().CreateTransaction(gomock.Any(), gomock.Any(), someDate).Return(&mockAuthorizedTransaction, nil)
+
+	auditLog := mocks.NewMockAuditLog(ctrl)
+	auditLog.EXPECT().AppendAuditEntry(gomock.Any(), &domain.AuditEntry{
+		Time:       someDate,
+		MerchantID: authorization.MerchantID,
+		TokenID:    "token-1",
+		Operation:  domain.PaymentActionTypeAuthorization.String(),
+		ResourceID: mockAuthorizedTransaction.AuthorizationID.String(),
+		RequestID:  "request-1",
+		Outcome:    string(domain.PaymentActionStatusSuccess),
+	}).Return(nil)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithAuditLog(auditLog))
+	require.NoError(t, err)
+
+	_, err = s.Authorize(ctx, authorization)
+	require.NoError(t, err)
+}
+
+func TestService_VerifyAuditLog(t *testing.T) {
+	chain := func() []*domain.AuditEntry {
+		var entries []*domain.AuditEntry
+		var prev *domain.AuditEntry
+		for _, op := range []string{"POST /authorize", "capture", "POST /capture"} {
+			entry := &domain.AuditEntry{Time: someDate, MerchantID: "merchant-1", Operation: op, Outcome: "200"}
+			entry.Chain(prev)
+			entries = append(entries, entry)
+			prev = entry
+		}
+		return entries
+	}
+
+	testCases := []struct {
+		description      string
+		tamper           func(entries []*domain.AuditEntry) []*domain.AuditEntry
+		expectedVerified int64
+		expectedErr      error
+	}{
+		{
+			description:      "should verify the untampered log",
+			tamper:           func(entries []*domain.AuditEntry) []*domain.AuditEntry { return entries },
+			expectedVerified: 3,
+		},
+		{
+			description: "should detect a modified entry",
+			tamper: func(entries []*domain.AuditEntry) []*domain.AuditEntry {
+				entries[1].Outcome = "failed"
+				return entries
+			},
+			expectedVerified: 1,
+			expectedErr:      domain.ErrAuditLogTampered,
+		},
+		{
+			description: "should detect a deleted entry",
+			tamper: func(entries []*domain.AuditEntry) []*domain.AuditEntry {
+				return append(entries[:1], entries[2:]...)
+			},
+			expectedVerified: 1,
+			expectedErr:      domain.ErrAuditLogTampered,
+		},
+		{
+			description: "should detect a rehashed entry",
+			tamper: func(entries []*domain.AuditEntry) []*domain.AuditEntry {
+				entries[0].MerchantID = "merchant-2"
+				entries[0].Hash = entries[0].ComputeHash()
+				return entries
+			},
+			expectedVerified: 1,
+			expectedErr:      domain.ErrAuditLogTampered,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auditLog := mocks.NewMockAuditLog(ctrl)
+			auditLog.EXPECT().ListAuditEntries(gomock.Any(), int64(0), gomock.Any()).Return(tt.tamper(chain()), nil)
+
+			s, err := service.NewService(mocks.NewMockStore(ctrl), service.WithAuditLog(auditLog))
+			require.NoError(t, err)
+
+			verified, err := s.VerifyAuditLog(context.Background())
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedVerified, verified)
+		})
+	}
+}
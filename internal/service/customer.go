@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/luhn"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+// CreateCustomer creates a customer of the merchant.
+func (s *Service) CreateCustomer(ctx context.Context, customer *domain.Customer) (*domain.Customer, error) {
+	ctx, span := tracing.Start(ctx, "Service.CreateCustomer")
+	defer span.End()
+
+	const errLogMsg = "unable to create customer"
+	ctx = logging.WithFields(ctx, zap.String(logging.MerchantID, customer.MerchantID))
+
+	customer, err := s.store.CreateCustomer(ctx, customer, s.clock.Now())
+	if err != nil {
+		err = errors.Wrap(err, "unable to create customer in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	return customer, nil
+}
+
+// GetCustomer returns the customer of the merchant.
+func (s *Service) GetCustomer(ctx context.Context, merchantID string, customerID uuid.UUID) (*domain.Customer, error) {
+	ctx, span := tracing.Start(ctx, "Service.GetCustomer")
+	defer span.End()
+
+	customer, err := s.store.GetCustomer(ctx, customerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get customer from store")
+	}
+	if customer.MerchantID != merchantID {
+		return nil, errors.Wrap(domain.ErrCustomerNotFound, "customer of another merchant")
+	}
+	return customer, nil
+}
+
+// UpdateCustomer updates the email and the name of the customer of the merchant.
+func (s *Service) UpdateCustomer(ctx context.Context, customer *domain.Customer) (*domain.Customer, error) {
+	ctx, span := tracing.Start(ctx, "Service.UpdateCustomer")
+	defer span.End()
+
+	const errLogMsg = "unable to update customer"
+	ctx = logging.WithFields(ctx,
+		zap.String(logging.MerchantID, customer.MerchantID),
+		zap.Stringer(logging.CustomerID, customer.ID))
+
+	current, err := s.GetCustomer(ctx, customer.MerchantID, customer.ID)
+	if err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	current.Email = customer.Email
+	current.Name = customer.Name
+	if err = s.store.UpdateCustomer(ctx, current, s.clock.Now()); err != nil {
+		err = errors.Wrap(err, "unable to update customer in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	return current, nil
+}
+
+// DeleteCustomer deletes the customer of the merchant with its payment methods and cancels its subscriptions,
+// its transactions are kept.
+func (s *Service) DeleteCustomer(ctx context.Context, merchantID string, customerID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "Service.DeleteCustomer")
+	defer span.End()
+
+	const errLogMsg = "unable to delete customer"
+	ctx = logging.WithFields(ctx,
+		zap.String(logging.MerchantID, merchantID),
+		zap.Stringer(logging.CustomerID, customerID))
+
+	if _, err := s.GetCustomer(ctx, merchantID, customerID); err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return err
+	}
+
+	if err := s.store.DeleteCustomer(ctx, customerID, s.clock.Now()); err != nil {
+		err = errors.Wrap(err, "unable to delete customer in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListCustomerTransactions returns the transactions of the customer of the merchant, the latest first.
+func (s *Service) ListCustomerTransactions(ctx context.Context, merchantID string, customerID uuid.UUID) ([]*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Service.ListCustomerTransactions")
+	defer span.End()
+
+	if _, err := s.GetCustomer(ctx, merchantID, customerID); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.store.ListCustomerTransactions(ctx, customerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list customer transactions from store")
+	}
+	return transactions, nil
+}
+
+// CreatePaymentMethod stores the card as a payment method of the customer of the merchant without the CVV.
+// The payment method has no initial customer-initiated authorization, it can't be used for merchant-initiated
+// authorizations until the card is stored by an authorization.
+func (s *Service) CreatePaymentMethod(ctx context.Context, paymentMethod *domain.PaymentMethod) (*domain.PaymentMethod, error) {
+	ctx, span := tracing.Start(ctx, "Service.CreatePaymentMethod")
+	defer span.End()
+
+	const errLogMsg = "unable to create payment method"
+	ctx = logging.WithFields(ctx,
+		zap.String(logging.MerchantID, paymentMethod.MerchantID),
+		zap.Stringer(logging.CustomerID, paymentMethod.CustomerID))
+
+	if _, err := s.GetCustomer(ctx, paymentMethod.MerchantID, paymentMethod.CustomerID); err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	if err := luhn.Validate(paymentMethod.PaymentSource.PAN); err != nil {
+		err = errors.Wrap(domain.ErrUnprocessable, err.Error())
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	paymentMethod.PaymentSource.CVV = ""
+	paymentMethod.Card = s.cards.Lookup(paymentMethod.PaymentSource.PAN)
+	paymentMethod.NetworkTransactionID = ""
+
+	paymentMethod, err := s.store.CreatePaymentMethod(ctx, paymentMethod, uuid.Nil, s.clock.Now())
+	if err != nil {
+		err = errors.Wrap(err, "unable to create payment method in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	return paymentMethod, nil
+}
+
+// ListPaymentMethods returns the payment methods of the customer of the merchant.
+func (s *Service) ListPaymentMethods(ctx context.Context, merchantID string, customerID uuid.UUID) ([]*domain.PaymentMethod, error) {
+	ctx, span := tracing.Start(ctx, "Service.ListPaymentMethods")
+	defer span.End()
+
+	if _, err := s.GetCustomer(ctx, merchantID, customerID); err != nil {
+		return nil, err
+	}
+
+	paymentMethods, err := s.store.ListPaymentMethods(ctx, customerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list payment methods from store")
+	}
+	return paymentMethods, nil
+}
+
+// DeletePaymentMethod deletes the payment method of the customer of the merchant, the subscriptions
+// billed with it are declined from then on.
+func (s *Service) DeletePaymentMethod(ctx context.Context, merchantID string, customerID, paymentMethodID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "Service.DeletePaymentMethod")
+	defer span.End()
+
+	const errLogMsg = "unable to delete payment method"
+	ctx = logging.WithFields(ctx,
+		zap.String(logging.MerchantID, merchantID),
+		zap.Stringer(logging.CustomerID, customerID))
+
+	paymentMethod, err := s.store.GetPaymentMethod(ctx, paymentMethodID)
+	if err != nil {
+		err = errors.Wrap(err, "unable to get payment method from store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return err
+	}
+	if paymentMethod.MerchantID != merchantID || paymentMethod.CustomerID != customerID {
+		err = errors.Wrap(domain.ErrPaymentMethodNotFound, "payment method of another customer")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return err
+	}
+
+	if err = s.store.DeletePaymentMethod(ctx, paymentMethodID, s.clock.Now()); err != nil {
+		err = errors.Wrap(err, "unable to delete payment method in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return err
+	}
+	return nil
+}
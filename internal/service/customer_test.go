@@ -0,0 +1,154 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/service"
+	"github.com/jeffreyyong/payment-gateway/internal/service/mocks"
+)
+
+func TestService_CreatePaymentMethod(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		store.EXPECT().GetCustomer(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID, MerchantID: someMerchant}, nil),
+		store.EXPECT().CreatePaymentMethod(gomock.Any(), gomock.Any(), uuid.Nil, someDate).
+			DoAndReturn(func(_ context.Context, pm *domain.PaymentMethod, _ uuid.UUID, _ time.Time) (*domain.PaymentMethod, error) {
+				assert.Empty(t, pm.PaymentSource.CVV, "the cvv is never stored")
+				assert.Empty(t, pm.NetworkTransactionID, "the card has not been authorized")
+				return pm, nil
+			}),
+	)
+
+	paymentMethod, err := s.CreatePaymentMethod(ctx, &domain.PaymentMethod{
+		CustomerID:    customerID,
+		MerchantID:    someMerchant,
+		PaymentSource: authorization.PaymentSource,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, customerID, paymentMethod.CustomerID)
+}
+
+func TestService_CreatePaymentMethod_CustomerOfAnotherMerchant(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the payment method is not expected to be stored
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().GetCustomer(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID, MerchantID: "merchant-2"}, nil)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	paymentMethod, err := s.CreatePaymentMethod(ctx, &domain.PaymentMethod{
+		CustomerID:    customerID,
+		MerchantID:    someMerchant,
+		PaymentSource: authorization.PaymentSource,
+	})
+	require.ErrorIs(t, err, domain.ErrCustomerNotFound)
+	assert.Nil(t, paymentMethod)
+}
+
+func TestService_DeletePaymentMethod(t *testing.T) {
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		wantErr    error
+	}{
+		{
+			name:       "payment method of the customer",
+			customerID: customerID,
+		},
+		{
+			name:       "payment method of another customer",
+			customerID: uuid.NewV4(),
+			wantErr:    domain.ErrPaymentMethodNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			store.EXPECT().GetPaymentMethod(gomock.Any(), storedPaymentMethod.ID).Return(storedPaymentMethod, nil)
+			if tt.wantErr == nil {
+				store.EXPECT().DeletePaymentMethod(gomock.Any(), storedPaymentMethod.ID, someDate).Return(nil)
+			}
+
+			s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+			require.NoError(t, err)
+
+			err = s.DeletePaymentMethod(ctx, someMerchant, tt.customerID, storedPaymentMethod.ID)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_Authorize_LinksCustomerOfPaymentMethod(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	storedAuthorization := &domain.Authorization{
+		RequestID:       uuid.NewV4(),
+		MerchantID:      someMerchant,
+		Amount:          authorization.Amount,
+		PaymentMethodID: storedPaymentMethod.ID,
+	}
+
+	gomock.InOrder(
+		store.EXPECT().GetPaymentMethod(gomock.Any(), storedPaymentMethod.ID).Return(storedPaymentMethod, nil),
+		store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound),
+		store.EXPECT().CreateTransaction(gomock.Any(), storedAuthorization, someDate).Return(&mockAuthorizedTransaction, nil),
+	)
+
+	_, err = s.Authorize(ctx, storedAuthorization)
+	require.NoError(t, err)
+	assert.Equal(t, customerID, storedAuthorization.CustomerID)
+	assert.Equal(t, storedPaymentMethod.PaymentSource, storedAuthorization.PaymentSource)
+}
+
+func TestService_ListCustomerTransactions_CustomerOfAnotherMerchant(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the transactions are not expected to be listed
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().GetCustomer(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID, MerchantID: "merchant-2"}, nil)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	transactions, err := s.ListCustomerTransactions(ctx, someMerchant, customerID)
+	require.ErrorIs(t, err, domain.ErrCustomerNotFound)
+	assert.Nil(t, transactions)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+// errNoMerchants is returned by the management of the merchants when the service has no merchants.
+var errNoMerchants = errors.New("no merchants configured")
+
+// CreateMerchant creates an active merchant with its settings.
+func (s *Service) CreateMerchant(ctx context.Context, merchant *domain.Merchant) (*domain.Merchant, error) {
+	ctx, span := tracing.Start(ctx, "Service.CreateMerchant")
+	defer span.End()
+
+	const errLogMsg = "unable to create merchant"
+	ctx = logging.WithFields(ctx, zap.String(logging.MerchantID, merchant.ID))
+
+	if s.merchants == nil {
+		return nil, errNoMerchants
+	}
+	if merchant.ID == "" {
+		err := errors.Wrap(domain.ErrUnprocessable, "merchant id is required")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	if err := merchant.Settings.Validate(); err != nil {
+		err = errors.Wrap(domain.ErrUnprocessable, err.Error())
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	merchant.Status = domain.MerchantStatusActive
+	merchant, err := s.merchants.CreateMerchant(ctx, merchant, s.clock.Now())
+	if err != nil {
+		err = errors.Wrap(err, "unable to create merchant in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	s.appendAuditEntry(ctx, &domain.AuditEntry{MerchantID: merchant.ID, Operation: "create_merchant", ResourceID: merchant.ID, Outcome: "success"})
+	return merchant, nil
+}
+
+// GetMerchant returns the merchant.
+func (s *Service) GetMerchant(ctx context.Context, merchantID string) (*domain.Merchant, error) {
+	ctx, span := tracing.Start(ctx, "Service.GetMerchant")
+	defer span.End()
+
+	if s.merchants == nil {
+		return nil, errNoMerchants
+	}
+	merchant, err := s.merchants.GetMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get merchant from store")
+	}
+	return merchant, nil
+}
+
+// SetMerchantStatus suspends or reactivates the merchant, the requests of a suspended merchant are forbidden.
+func (s *Service) SetMerchantStatus(ctx context.Context, merchantID string, status domain.MerchantStatus) (*domain.Merchant, error) {
+	ctx, span := tracing.Start(ctx, "Service.SetMerchantStatus")
+	defer span.End()
+
+	if status != domain.MerchantStatusActive && status != domain.MerchantStatusSuspended {
+		return nil, errors.Wrapf(domain.ErrUnprocessable, "unknown merchant status %q", status)
+	}
+	return s.updateMerchant(ctx, merchantID, "set_merchant_status", func(m *domain.Merchant) error {
+		m.Status = status
+		return nil
+	})
+}
+
+// UpdateMerchantSettings replaces the settings of the merchant.
+func (s *Service) UpdateMerchantSettings(ctx context.Context, merchantID string, settings domain.MerchantSettings) (*domain.Merchant, error) {
+	ctx, span := tracing.Start(ctx, "Service.UpdateMerchantSettings")
+	defer span.End()
+
+	return s.updateMerchant(ctx, merchantID, "update_merchant_settings", func(m *domain.Merchant) error {
+		if err := settings.Validate(); err != nil {
+			return errors.Wrap(domain.ErrUnprocessable, err.Error())
+		}
+		m.Settings = settings
+		return nil
+	})
+}
+
+// updateMerchant updates the merchant with the update and records the operation in the audit log.
+func (s *Service) updateMerchant(ctx context.Context, merchantID, operation string, update func(m *domain.Merchant) error) (*domain.Merchant, error) {
+	const errLogMsg = "unable to update merchant"
+	ctx = logging.WithFields(ctx, zap.String(logging.MerchantID, merchantID))
+
+	merchant, err := s.GetMerchant(ctx, merchantID)
+	if err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	if err := update(merchant); err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if err := s.merchants.UpdateMerchant(ctx, merchant, now); err != nil {
+		err = errors.Wrap(err, "unable to update merchant in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	merchant.UpdatedDate = now
+
+	s.appendAuditEntry(ctx, &domain.AuditEntry{MerchantID: merchantID, Operation: operation, ResourceID: merchantID, Outcome: "success"})
+	return merchant, nil
+}
+
+// ValidateMerchant returns domain.ErrMerchantSuspended if the merchant is suspended, the merchants which are
+// not managed with the admin API are valid.
+func (s *Service) ValidateMerchant(ctx context.Context, merchantID string) error {
+	ctx, span := tracing.Start(ctx, "Service.ValidateMerchant")
+	defer span.End()
+
+	_, err := s.merchantSettings(ctx, merchantID)
+	return err
+}
+
+// merchantSettings returns the settings of the merchant, nil if the merchant is not managed with the admin API.
+// domain.ErrMerchantSuspended is returned if the merchant is suspended.
+func (s *Service) merchantSettings(ctx context.Context, merchantID string) (*domain.MerchantSettings, error) {
+	if s.merchants == nil {
+		return nil, nil
+	}
+
+	merchant, err := s.merchants.GetMerchant(ctx, merchantID)
+	switch {
+	case errors.Is(err, domain.ErrMerchantNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "unable to get merchant from store")
+	case merchant.Suspended():
+		return nil, domain.ErrMerchantSuspended
+	}
+	return &merchant.Settings, nil
+}
+
+// ListAPIKeys returns the API keys of the merchant, the latest first.
+func (s *Service) ListAPIKeys(ctx context.Context, merchantID string) ([]*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "Service.ListAPIKeys")
+	defer span.End()
+
+	keys, err := s.store.ListAPIKeys(ctx, merchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list api keys from store")
+	}
+	return keys, nil
+}
+
+// autoCapture captures the full amount of the authorized transaction of a merchant with the automatic capture policy,
+// the request id of the capture is derived from the one of the authorization so that it is idempotent.
+func (s *Service) autoCapture(ctx context.Context, settings *domain.MerchantSettings, requestID uuid.UUID,
+	transaction *domain.Transaction) (*domain.Transaction, error) {
+	if settings == nil || settings.CapturePolicy != domain.CapturePolicyAutomatic || transaction.AuthorizationDate() == nil {
+		return transaction, nil
+	}
+
+	captured, err := s.Capture(ctx, &domain.Capture{
+		RequestID:       uuid.NewV5(requestID, "capture"),
+		MerchantID:      transaction.MerchantID,
+		AuthorizationID: transaction.AuthorizationID,
+		Amount:          transaction.Amount,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to capture automatically")
+	}
+	return captured, nil
+}
@@ -0,0 +1,155 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/service"
+	"github.com/jeffreyyong/payment-gateway/internal/service/mocks"
+)
+
+func TestService_Authorize_MerchantSettings(t *testing.T) {
+	const merchantID = "merchant-admin"
+	ctx := context.Background()
+
+	merchantAuthorization := *authorization
+	merchantAuthorization.MerchantID = merchantID
+
+	t.Run("should reject a currency which is not allowed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		merchants := mocks.NewMockMerchants(ctrl)
+		merchants.EXPECT().GetMerchant(gomock.Any(), merchantID).Return(&domain.Merchant{
+			ID:       merchantID,
+			Status:   domain.MerchantStatusActive,
+			Settings: domain.MerchantSettings{AllowedCurrencies: []string{"EUR"}},
+		}, nil)
+
+		s, err := service.NewService(mocks.NewMockStore(ctrl),
+			service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithMerchants(merchants))
+		require.NoError(t, err)
+
+		a := merchantAuthorization
+		_, err = s.Authorize(ctx, &a)
+		assert.ErrorIs(t, err, domain.ErrUnprocessable)
+	})
+
+	t.Run("should reject the authorization of a suspended merchant", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		merchants := mocks.NewMockMerchants(ctrl)
+		merchants.EXPECT().GetMerchant(gomock.Any(), merchantID).
+			Return(&domain.Merchant{ID: merchantID, Status: domain.MerchantStatusSuspended}, nil)
+
+		s, err := service.NewService(mocks.NewMockStore(ctrl),
+			service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithMerchants(merchants))
+		require.NoError(t, err)
+
+		a := merchantAuthorization
+		_, err = s.Authorize(ctx, &a)
+		assert.ErrorIs(t, err, domain.ErrMerchantSuspended)
+	})
+
+	t.Run("should capture the authorization with the automatic capture policy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		merchants := mocks.NewMockMerchants(ctrl)
+		merchants.EXPECT().GetMerchant(gomock.Any(), merchantID).Return(&domain.Merchant{
+			ID:       merchantID,
+			Status:   domain.MerchantStatusActive,
+			Settings: domain.MerchantSettings{CapturePolicy: domain.CapturePolicyAutomatic},
+		}, nil)
+
+		merchantTransaction := mockAuthorizedTransaction
+		merchantTransaction.MerchantID = merchantID
+
+		store := mocks.NewMockStore(ctrl)
+		gomock.InOrder(
+			store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound),
+			store.EXPECT().CreateTransaction(gomock.Any(), gomock.Any(), someDate).Return(&merchantTransaction, nil),
+			store.EXPECT().GetMerchantTransaction(gomock.Any(), merchantID, authorizationID).Return(&merchantTransaction, nil),
+			store.EXPECT().CreatePaymentAction(gomock.Any(), transactionID, uuid.NewV5(authorization.RequestID, "capture"),
+				domain.PaymentActionTypeCapture, &authorization.Amount, &authorization.Amount, gomock.Any(), someDate).Return(nil),
+			store.EXPECT().GetMerchantTransaction(gomock.Any(), merchantID, authorizationID).Return(&mockCapturedTransaction, nil),
+		)
+
+		s, err := service.NewService(store,
+			service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithMerchants(merchants))
+		require.NoError(t, err)
+
+		a := merchantAuthorization
+		transaction, err := s.Authorize(ctx, &a)
+		require.NoError(t, err)
+		assert.Equal(t, &mockCapturedTransaction, transaction)
+	})
+
+	t.Run("should capture the replayed authorization with the automatic capture policy unless captured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		merchants := mocks.NewMockMerchants(ctrl)
+		merchants.EXPECT().GetMerchant(gomock.Any(), merchantID).Return(&domain.Merchant{
+			ID:       merchantID,
+			Status:   domain.MerchantStatusActive,
+			Settings: domain.MerchantSettings{CapturePolicy: domain.CapturePolicyAutomatic},
+		}, nil).Times(2)
+
+		merchantTransaction := mockAuthorizedTransaction
+		merchantTransaction.MerchantID = merchantID
+
+		store := mocks.NewMockStore(ctrl)
+		gomock.InOrder(
+			store.EXPECT().GetTransactionByRequestID(gomock.Any(), merchantID, authorization.RequestID).Return(&merchantTransaction, nil),
+			store.EXPECT().GetMerchantTransaction(gomock.Any(), merchantID, authorizationID).Return(&merchantTransaction, nil),
+			store.EXPECT().CreatePaymentAction(gomock.Any(), transactionID, uuid.NewV5(authorization.RequestID, "capture"),
+				domain.PaymentActionTypeCapture, &authorization.Amount, &authorization.Amount, gomock.Any(), someDate).Return(nil),
+			store.EXPECT().GetMerchantTransaction(gomock.Any(), merchantID, authorizationID).Return(&mockCapturedTransaction, nil),
+			store.EXPECT().GetTransactionByRequestID(gomock.Any(), merchantID, authorization.RequestID).Return(&mockCapturedTransaction, nil),
+		)
+
+		s, err := service.NewService(store,
+			service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithMerchants(merchants))
+		require.NoError(t, err)
+
+		a := merchantAuthorization
+		transaction, err := s.Authorize(ctx, &a)
+		require.NoError(t, err)
+		assert.Equal(t, &mockCapturedTransaction, transaction)
+
+		// the captured transaction is returned as is
+		a = merchantAuthorization
+		transaction, err = s.Authorize(ctx, &a)
+		require.NoError(t, err)
+		assert.Equal(t, &mockCapturedTransaction, transaction)
+	})
+}
+
+func TestService_SetMerchantStatus(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	merchants := mocks.NewMockMerchants(ctrl)
+	merchants.EXPECT().GetMerchant(gomock.Any(), "merchant-1").
+		Return(&domain.Merchant{ID: "merchant-1", Status: domain.MerchantStatusActive}, nil)
+	merchants.EXPECT().UpdateMerchant(gomock.Any(), &domain.Merchant{ID: "merchant-1", Status: domain.MerchantStatusSuspended}, someDate).
+		Return(nil)
+
+	s, err := service.NewService(mocks.NewMockStore(ctrl),
+		service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithMerchants(merchants))
+	require.NoError(t, err)
+
+	merchant, err := s.SetMerchantStatus(ctx, "merchant-1", domain.MerchantStatusSuspended)
+	require.NoError(t, err)
+	assert.True(t, merchant.Suspended())
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/service"
+	"github.com/jeffreyyong/payment-gateway/internal/service/mocks"
+)
+
+func TestService_Authorize_PaymentActionsMetric(t *testing.T) {
+	const merchantID = "merchant-metrics"
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound)
+	store.EXPECT().CreateTransaction(gomock.Any(), gomock.Any(), someDate).Return(&mockAuthorizedTransaction, nil)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	approved := *authorization
+	approved.MerchantID = merchantID
+	_, err = s.Authorize(ctx, &approved)
+	require.NoError(t, err)
+
+	// the card is rejected before the authorization is stored
+	rejected := *authorization
+	rejected.MerchantID = merchantID
+	rejected.PaymentSource.PAN = "4242424242424241"
+	_, err = s.Authorize(ctx, &rejected)
+	require.ErrorIs(t, err, domain.ErrUnprocessable)
+
+	currency := authorization.Amount.Currency
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.PaymentActions.WithLabelValues(
+		domain.PaymentActionTypeAuthorization.String(), string(domain.PaymentActionStatusSuccess), merchantID, currency)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.PaymentActions.WithLabelValues(
+		domain.PaymentActionTypeAuthorization.String(), metrics.StatusRejected, merchantID, currency)))
+}
+
+func TestService_CaptureRefund_FailedPaymentActionsMetric(t *testing.T) {
+	const merchantID = "merchant-failures"
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	// the store fails the capture and the refund of their failure cards
+	failed := func(pa *domain.PaymentAction) *domain.PaymentAction {
+		failedPaymentAction := *pa
+		failedPaymentAction.Status = domain.PaymentActionStatusFailed
+		return &failedPaymentAction
+	}
+	authorized := mockAuthorizedTransaction
+	authorized.MerchantID = merchantID
+	captureFailed := appendPaymentAction(authorized, failed(capturePaymentAction))
+	captured := mockCapturedTransaction
+	captured.MerchantID = merchantID
+	refundFailed := appendPaymentAction(captured, failed(refundPaymentAction))
+
+	gomock.InOrder(
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&authorized, nil),
+		store.EXPECT().CreatePaymentAction(gomock.Any(), authorized.ID, captureRequestID, domain.PaymentActionTypeCapture,
+			gomock.Any(), gomock.Any(), gomock.Any(), someDate).Return(nil),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&captureFailed, nil),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&captured, nil),
+		store.EXPECT().CreatePaymentAction(gomock.Any(), captured.ID, refundRequestID, domain.PaymentActionTypeRefund,
+			gomock.Any(), gomock.Any(), gomock.Any(), someDate).Return(nil),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&refundFailed, nil),
+	)
+
+	_, err = s.Capture(ctx, capture)
+	require.NoError(t, err)
+	_, err = s.Refund(ctx, refund)
+	require.NoError(t, err)
+
+	currency := authorization.Amount.Currency
+	for _, pat := range []domain.PaymentActionType{domain.PaymentActionTypeCapture, domain.PaymentActionTypeRefund} {
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.PaymentActions.WithLabelValues(
+			pat.String(), string(domain.PaymentActionStatusFailed), merchantID, currency)), pat.String())
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.PaymentActions.WithLabelValues(
+			pat.String(), string(domain.PaymentActionStatusSuccess), merchantID, currency)), pat.String())
+	}
+}
+
+func TestService_Authorize_InvalidCurrencyMetric(t *testing.T) {
+	const merchantID = "merchant-invalid-currency"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s, err := service.NewService(mocks.NewMockStore(ctrl), service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	// the currency of a request rejected before its validation is not a label of its own
+	rejected := *authorization
+	rejected.MerchantID = merchantID
+	rejected.PaymentSource.PAN = "4242424242424241"
+	rejected.Amount.Currency = "not-a-currency"
+	_, err = s.Authorize(context.Background(), &rejected)
+	require.ErrorIs(t, err, domain.ErrUnprocessable)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.PaymentActions.WithLabelValues(
+		domain.PaymentActionTypeAuthorization.String(), metrics.StatusRejected, merchantID, metrics.CurrencyInvalid)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.PaymentActions.WithLabelValues(
+		domain.PaymentActionTypeAuthorization.String(), metrics.StatusRejected, merchantID, rejected.Amount.Currency)))
+}
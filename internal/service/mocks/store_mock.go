@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/jeffreyyong/payment-gateway/internal/service (interfaces: Store)
+// Source: github.com/jeffreyyong/payment-gateway/internal/service (interfaces: Store,AuditLog,Merchants)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -37,18 +37,107 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// CompleteAuthorization mocks base method.
+func (m *MockStore) CompleteAuthorization(arg0 context.Context, arg1 uuid.UUID, arg2 domain.PaymentActionStatus, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAuthorization", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteAuthorization indicates an expected call of CompleteAuthorization.
+func (mr *MockStoreMockRecorder) CompleteAuthorization(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAuthorization", reflect.TypeOf((*MockStore)(nil).CompleteAuthorization), arg0, arg1, arg2, arg3)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockStore) CreateAPIKey(arg0 context.Context, arg1 *domain.APIKey, arg2 time.Time) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockStoreMockRecorder) CreateAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockStore)(nil).CreateAPIKey), arg0, arg1, arg2)
+}
+
+// CreateCustomer mocks base method.
+func (m *MockStore) CreateCustomer(arg0 context.Context, arg1 *domain.Customer, arg2 time.Time) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCustomer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCustomer indicates an expected call of CreateCustomer.
+func (mr *MockStoreMockRecorder) CreateCustomer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCustomer", reflect.TypeOf((*MockStore)(nil).CreateCustomer), arg0, arg1, arg2)
+}
+
 // CreatePaymentAction mocks base method.
-func (m *MockStore) CreatePaymentAction(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 domain.PaymentActionType, arg4 *domain.Amount, arg5 time.Time) error {
+func (m *MockStore) CreatePaymentAction(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 domain.PaymentActionType, arg4, arg5, arg6 *domain.Amount, arg7 time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePaymentAction", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret := m.ctrl.Call(m, "CreatePaymentAction", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreatePaymentAction indicates an expected call of CreatePaymentAction.
-func (mr *MockStoreMockRecorder) CreatePaymentAction(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) CreatePaymentAction(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentAction", reflect.TypeOf((*MockStore)(nil).CreatePaymentAction), arg0, arg1, arg2, arg3, arg4, arg5)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentAction", reflect.TypeOf((*MockStore)(nil).CreatePaymentAction), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+}
+
+// CreatePaymentMethod mocks base method.
+func (m *MockStore) CreatePaymentMethod(arg0 context.Context, arg1 *domain.PaymentMethod, arg2 uuid.UUID, arg3 time.Time) (*domain.PaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentMethod", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.PaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentMethod indicates an expected call of CreatePaymentMethod.
+func (mr *MockStoreMockRecorder) CreatePaymentMethod(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentMethod", reflect.TypeOf((*MockStore)(nil).CreatePaymentMethod), arg0, arg1, arg2, arg3)
+}
+
+// CreatePlan mocks base method.
+func (m *MockStore) CreatePlan(arg0 context.Context, arg1 *domain.Plan, arg2 time.Time) (*domain.Plan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePlan", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Plan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePlan indicates an expected call of CreatePlan.
+func (mr *MockStoreMockRecorder) CreatePlan(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlan", reflect.TypeOf((*MockStore)(nil).CreatePlan), arg0, arg1, arg2)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockStore) CreateSubscription(arg0 context.Context, arg1 *domain.Subscription, arg2 time.Time) (*domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockStoreMockRecorder) CreateSubscription(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockStore)(nil).CreateSubscription), arg0, arg1, arg2)
 }
 
 // CreateTransaction mocks base method.
@@ -66,6 +155,49 @@ func (mr *MockStoreMockRecorder) CreateTransaction(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockStore)(nil).CreateTransaction), arg0, arg1, arg2)
 }
 
+// DeleteCustomer mocks base method.
+func (m *MockStore) DeleteCustomer(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCustomer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCustomer indicates an expected call of DeleteCustomer.
+func (mr *MockStoreMockRecorder) DeleteCustomer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomer", reflect.TypeOf((*MockStore)(nil).DeleteCustomer), arg0, arg1, arg2)
+}
+
+// DeletePaymentMethod mocks base method.
+func (m *MockStore) DeletePaymentMethod(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePaymentMethod", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePaymentMethod indicates an expected call of DeletePaymentMethod.
+func (mr *MockStoreMockRecorder) DeletePaymentMethod(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePaymentMethod", reflect.TypeOf((*MockStore)(nil).DeletePaymentMethod), arg0, arg1, arg2)
+}
+
+// DueSubscriptions mocks base method.
+func (m *MockStore) DueSubscriptions(arg0 context.Context, arg1 time.Time, arg2 int) ([]*domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueSubscriptions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueSubscriptions indicates an expected call of DueSubscriptions.
+func (mr *MockStoreMockRecorder) DueSubscriptions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueSubscriptions", reflect.TypeOf((*MockStore)(nil).DueSubscriptions), arg0, arg1, arg2)
+}
+
 // Exec mocks base method.
 func (m *MockStore) Exec(arg0 context.Context, arg1 func(context.Context) error) error {
 	m.ctrl.T.Helper()
@@ -94,6 +226,111 @@ func (mr *MockStoreMockRecorder) ExecInTransaction(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecInTransaction", reflect.TypeOf((*MockStore)(nil).ExecInTransaction), arg0, arg1)
 }
 
+// GetAPIKey mocks base method.
+func (m *MockStore) GetAPIKey(arg0 context.Context, arg1 uuid.UUID) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey.
+func (mr *MockStoreMockRecorder) GetAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockStore)(nil).GetAPIKey), arg0, arg1)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockStore) GetAPIKeyByHash(arg0 context.Context, arg1 string) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", arg0, arg1)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockStoreMockRecorder) GetAPIKeyByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockStore)(nil).GetAPIKeyByHash), arg0, arg1)
+}
+
+// GetCustomer mocks base method.
+func (m *MockStore) GetCustomer(arg0 context.Context, arg1 uuid.UUID) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomer", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomer indicates an expected call of GetCustomer.
+func (mr *MockStoreMockRecorder) GetCustomer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomer", reflect.TypeOf((*MockStore)(nil).GetCustomer), arg0, arg1)
+}
+
+// GetMerchantTransaction mocks base method.
+func (m *MockStore) GetMerchantTransaction(arg0 context.Context, arg1 string, arg2 uuid.UUID) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMerchantTransaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMerchantTransaction indicates an expected call of GetMerchantTransaction.
+func (mr *MockStoreMockRecorder) GetMerchantTransaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchantTransaction", reflect.TypeOf((*MockStore)(nil).GetMerchantTransaction), arg0, arg1, arg2)
+}
+
+// GetPaymentMethod mocks base method.
+func (m *MockStore) GetPaymentMethod(arg0 context.Context, arg1 uuid.UUID) (*domain.PaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentMethod", arg0, arg1)
+	ret0, _ := ret[0].(*domain.PaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentMethod indicates an expected call of GetPaymentMethod.
+func (mr *MockStoreMockRecorder) GetPaymentMethod(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentMethod", reflect.TypeOf((*MockStore)(nil).GetPaymentMethod), arg0, arg1)
+}
+
+// GetPlan mocks base method.
+func (m *MockStore) GetPlan(arg0 context.Context, arg1 uuid.UUID) (*domain.Plan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlan", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Plan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlan indicates an expected call of GetPlan.
+func (mr *MockStoreMockRecorder) GetPlan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlan", reflect.TypeOf((*MockStore)(nil).GetPlan), arg0, arg1)
+}
+
+// GetSubscription mocks base method.
+func (m *MockStore) GetSubscription(arg0 context.Context, arg1 uuid.UUID) (*domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscription", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscription indicates an expected call of GetSubscription.
+func (mr *MockStoreMockRecorder) GetSubscription(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockStore)(nil).GetSubscription), arg0, arg1)
+}
+
 // GetTransaction mocks base method.
 func (m *MockStore) GetTransaction(arg0 context.Context, arg1 uuid.UUID) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -108,3 +345,267 @@ func (mr *MockStoreMockRecorder) GetTransaction(arg0, arg1 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockStore)(nil).GetTransaction), arg0, arg1)
 }
+
+// GetTransactionByRequestID mocks base method.
+func (m *MockStore) GetTransactionByRequestID(arg0 context.Context, arg1 string, arg2 uuid.UUID) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionByRequestID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionByRequestID indicates an expected call of GetTransactionByRequestID.
+func (mr *MockStoreMockRecorder) GetTransactionByRequestID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionByRequestID", reflect.TypeOf((*MockStore)(nil).GetTransactionByRequestID), arg0, arg1, arg2)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockStore) ListAPIKeys(arg0 context.Context, arg1 string) ([]*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockStoreMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockStore)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// ListCustomerTransactions mocks base method.
+func (m *MockStore) ListCustomerTransactions(arg0 context.Context, arg1 uuid.UUID) ([]*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCustomerTransactions", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCustomerTransactions indicates an expected call of ListCustomerTransactions.
+func (mr *MockStoreMockRecorder) ListCustomerTransactions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomerTransactions", reflect.TypeOf((*MockStore)(nil).ListCustomerTransactions), arg0, arg1)
+}
+
+// ListPaymentMethods mocks base method.
+func (m *MockStore) ListPaymentMethods(arg0 context.Context, arg1 uuid.UUID) ([]*domain.PaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentMethods", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.PaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentMethods indicates an expected call of ListPaymentMethods.
+func (mr *MockStoreMockRecorder) ListPaymentMethods(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentMethods", reflect.TypeOf((*MockStore)(nil).ListPaymentMethods), arg0, arg1)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockStore) RevokeAPIKey(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockStoreMockRecorder) RevokeAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockStore)(nil).RevokeAPIKey), arg0, arg1, arg2)
+}
+
+// RotateAPIKey mocks base method.
+func (m *MockStore) RotateAPIKey(arg0 context.Context, arg1 uuid.UUID, arg2 *domain.APIKey, arg3, arg4 time.Time) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAPIKey", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateAPIKey indicates an expected call of RotateAPIKey.
+func (mr *MockStoreMockRecorder) RotateAPIKey(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAPIKey", reflect.TypeOf((*MockStore)(nil).RotateAPIKey), arg0, arg1, arg2, arg3, arg4)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockStore) TouchAPIKey(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockStoreMockRecorder) TouchAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockStore)(nil).TouchAPIKey), arg0, arg1, arg2)
+}
+
+// UpdateAuthentication mocks base method.
+func (m *MockStore) UpdateAuthentication(arg0 context.Context, arg1 uuid.UUID, arg2 domain.Authentication, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAuthentication", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAuthentication indicates an expected call of UpdateAuthentication.
+func (mr *MockStoreMockRecorder) UpdateAuthentication(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAuthentication", reflect.TypeOf((*MockStore)(nil).UpdateAuthentication), arg0, arg1, arg2, arg3)
+}
+
+// UpdateCustomer mocks base method.
+func (m *MockStore) UpdateCustomer(arg0 context.Context, arg1 *domain.Customer, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomer indicates an expected call of UpdateCustomer.
+func (mr *MockStoreMockRecorder) UpdateCustomer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomer", reflect.TypeOf((*MockStore)(nil).UpdateCustomer), arg0, arg1, arg2)
+}
+
+// UpdateSubscription mocks base method.
+func (m *MockStore) UpdateSubscription(arg0 context.Context, arg1 *domain.Subscription, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscription", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscription indicates an expected call of UpdateSubscription.
+func (mr *MockStoreMockRecorder) UpdateSubscription(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockStore)(nil).UpdateSubscription), arg0, arg1, arg2)
+}
+
+// MockAuditLog is a mock of AuditLog interface.
+type MockAuditLog struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLogMockRecorder
+}
+
+// MockAuditLogMockRecorder is the mock recorder for MockAuditLog.
+type MockAuditLogMockRecorder struct {
+	mock *MockAuditLog
+}
+
+// NewMockAuditLog creates a new mock instance.
+func NewMockAuditLog(ctrl *gomock.Controller) *MockAuditLog {
+	mock := &MockAuditLog{ctrl: ctrl}
+	mock.recorder = &MockAuditLogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLog) EXPECT() *MockAuditLogMockRecorder {
+	return m.recorder
+}
+
+// AppendAuditEntry mocks base method.
+func (m *MockAuditLog) AppendAuditEntry(arg0 context.Context, arg1 *domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAuditEntry", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendAuditEntry indicates an expected call of AppendAuditEntry.
+func (mr *MockAuditLogMockRecorder) AppendAuditEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuditEntry", reflect.TypeOf((*MockAuditLog)(nil).AppendAuditEntry), arg0, arg1)
+}
+
+// ListAuditEntries mocks base method.
+func (m *MockAuditLog) ListAuditEntries(arg0 context.Context, arg1 int64, arg2 int) ([]*domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEntries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEntries indicates an expected call of ListAuditEntries.
+func (mr *MockAuditLogMockRecorder) ListAuditEntries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEntries", reflect.TypeOf((*MockAuditLog)(nil).ListAuditEntries), arg0, arg1, arg2)
+}
+
+// MockMerchants is a mock of Merchants interface.
+type MockMerchants struct {
+	ctrl     *gomock.Controller
+	recorder *MockMerchantsMockRecorder
+}
+
+// MockMerchantsMockRecorder is the mock recorder for MockMerchants.
+type MockMerchantsMockRecorder struct {
+	mock *MockMerchants
+}
+
+// NewMockMerchants creates a new mock instance.
+func NewMockMerchants(ctrl *gomock.Controller) *MockMerchants {
+	mock := &MockMerchants{ctrl: ctrl}
+	mock.recorder = &MockMerchantsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMerchants) EXPECT() *MockMerchantsMockRecorder {
+	return m.recorder
+}
+
+// CreateMerchant mocks base method.
+func (m *MockMerchants) CreateMerchant(arg0 context.Context, arg1 *domain.Merchant, arg2 time.Time) (*domain.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMerchant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMerchant indicates an expected call of CreateMerchant.
+func (mr *MockMerchantsMockRecorder) CreateMerchant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMerchant", reflect.TypeOf((*MockMerchants)(nil).CreateMerchant), arg0, arg1, arg2)
+}
+
+// GetMerchant mocks base method.
+func (m *MockMerchants) GetMerchant(arg0 context.Context, arg1 string) (*domain.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMerchant", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMerchant indicates an expected call of GetMerchant.
+func (mr *MockMerchantsMockRecorder) GetMerchant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchant", reflect.TypeOf((*MockMerchants)(nil).GetMerchant), arg0, arg1)
+}
+
+// UpdateMerchant mocks base method.
+func (m *MockMerchants) UpdateMerchant(arg0 context.Context, arg1 *domain.Merchant, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMerchant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMerchant indicates an expected call of UpdateMerchant.
+func (mr *MockMerchantsMockRecorder) UpdateMerchant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchant", reflect.TypeOf((*MockMerchants)(nil).UpdateMerchant), arg0, arg1, arg2)
+}
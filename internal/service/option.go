@@ -1,6 +1,10 @@
 package service
 
-import "github.com/jonboulle/clockwork"
+import (
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
 
 type Option func(*Service) error
 
@@ -11,3 +15,77 @@ func WithClock(clock clockwork.Clock) Option {
 		return nil
 	}
 }
+
+// WithCardLookup functionally configure the service with the lookup of card information, e.g. a BIN table.
+func WithCardLookup(cards CardLookup) Option {
+	return func(s *Service) error {
+		s.cards = cards
+		return nil
+	}
+}
+
+// WithFeeCalculator functionally configure the service with the calculator of merchant fees.
+func WithFeeCalculator(fees FeeCalculator) Option {
+	return func(s *Service) error {
+		s.fees = fees
+		return nil
+	}
+}
+
+// WithRateProvider functionally configure the service with the provider of FX rates.
+func WithRateProvider(rates RateProvider) Option {
+	return func(s *Service) error {
+		s.rates = rates
+		return nil
+	}
+}
+
+// WithSettlementCurrencies functionally configure the service with the settlement currencies keyed by merchant.
+func WithSettlementCurrencies(settlementCurrencies map[string]string) Option {
+	return func(s *Service) error {
+		s.settlementCurrencies = settlementCurrencies
+		return nil
+	}
+}
+
+// WithRiskAssessor functionally configure the service with the fraud screening of authorizations.
+func WithRiskAssessor(risk RiskAssessor) Option {
+	return func(s *Service) error {
+		s.risk = risk
+		return nil
+	}
+}
+
+// WithVelocityLimiter functionally configure the service with the velocity limits of authorizations.
+func WithVelocityLimiter(velocity VelocityLimiter) Option {
+	return func(s *Service) error {
+		s.velocity = velocity
+		return nil
+	}
+}
+
+// WithRetryIntervals functionally configure the service with the intervals after which a failed subscription
+// billing is retried, the subscription becomes unpaid once all the retries have failed.
+func WithRetryIntervals(retryIntervals ...time.Duration) Option {
+	return func(s *Service) error {
+		s.retryIntervals = retryIntervals
+		return nil
+	}
+}
+
+// WithAuditLog functionally configure the service with the audit log of the operations and of the API requests.
+func WithAuditLog(auditLog AuditLog) Option {
+	return func(s *Service) error {
+		s.auditLog = auditLog
+		return nil
+	}
+}
+
+// WithMerchants functionally configure the service with the merchants managed with the admin API,
+// their status and settings are enforced.
+func WithMerchants(merchants Merchants) Option {
+	return func(s *Service) error {
+		s.merchants = merchants
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	uuid "github.com/kevinburke/go.uuid"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+)
+
+// recordPaymentAction counts the payment action of the merchant and appends it to the audit log, the status is either
+// the one of the stored payment action or metrics.StatusRejected. The authorization id is nil if it is not stored.
+// The currency of a rejected request is counted as metrics.CurrencyInvalid unless it is an ISO 4217 code.
+func (s *Service) recordPaymentAction(ctx context.Context, paymentActionType domain.PaymentActionType, status, merchantID string,
+	amount domain.Amount, authorizationID uuid.UUID) {
+	currency := amount.Currency
+	if !domain.ValidCurrency(currency) {
+		currency = metrics.CurrencyInvalid
+	}
+	metrics.ObservePaymentAction(paymentActionType.String(), status, merchantID, currency)
+
+	entry := &domain.AuditEntry{
+		MerchantID: merchantID,
+		Operation:  paymentActionType.String(),
+		Outcome:    status,
+	}
+	if authorizationID != uuid.Nil {
+		entry.ResourceID = authorizationID.String()
+	}
+	s.appendAuditEntry(ctx, entry)
+}
+
+// authorizationStatus returns the status of the authorization of the transaction.
+func authorizationStatus(t *domain.Transaction) string {
+	switch {
+	case t.AuthorizationDate() != nil:
+		return string(domain.PaymentActionStatusSuccess)
+	case t.RequiresAction():
+		return string(domain.PaymentActionStatusPending)
+	default:
+		return string(domain.PaymentActionStatusFailed)
+	}
+}
+
+// paymentActionStatus returns the status of the payment action of the request stored for the transaction, e.g. failed
+// when the store fails the capture of its failure card, or failed if the transaction has no such payment action.
+func paymentActionStatus(t *domain.Transaction, paymentActionType domain.PaymentActionType, requestID uuid.UUID) string {
+	for _, pa := range t.PaymentActionSummary {
+		if pa.Type == paymentActionType && pa.RequestID == requestID {
+			return string(pa.Status)
+		}
+	}
+	return string(domain.PaymentActionStatusFailed)
+}
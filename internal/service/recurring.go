@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+const (
+	// billingBatchSize is the maximum number of subscriptions billed by BillDueSubscriptions.
+	billingBatchSize = 100
+	// billingErrorDelay postpones the billing of a subscription that could not be attempted, so that it does
+	// not stay at the head of the due subscriptions.
+	billingErrorDelay = time.Hour
+)
+
+// defaultRetryIntervals retries a failed subscription billing after 1, 3 and 7 days.
+var defaultRetryIntervals = []time.Duration{24 * time.Hour, 72 * time.Hour, 168 * time.Hour}
+
+// CreatePlan validates and creates a subscription plan of the merchant.
+func (s *Service) CreatePlan(ctx context.Context, plan *domain.Plan) (*domain.Plan, error) {
+	ctx, span := tracing.Start(ctx, "Service.CreatePlan")
+	defer span.End()
+
+	const errLogMsg = "unable to create plan"
+	ctx = logging.WithFields(ctx, zap.String(logging.MerchantID, plan.MerchantID))
+
+	if plan.Amount.Exponent == 0 {
+		plan.Amount.Exponent = domain.CurrencyExponent(plan.Amount.Currency)
+	}
+	if err := plan.Validate(); err != nil {
+		err = errors.Wrap(domain.ErrUnprocessable, err.Error())
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	plan, err := s.store.CreatePlan(ctx, plan, s.clock.Now())
+	if err != nil {
+		err = errors.Wrap(err, "unable to create plan in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	return plan, nil
+}
+
+// CreateSubscription subscribes the customer to the plan with the stored payment method, all of the merchant.
+// The first billing happens at the PeriodStart, now if it is not set.
+func (s *Service) CreateSubscription(ctx context.Context, subscription *domain.Subscription) (*domain.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "Service.CreateSubscription")
+	defer span.End()
+
+	const errLogMsg = "unable to create subscription"
+	ctx = logging.WithFields(ctx, zap.String(logging.MerchantID, subscription.MerchantID))
+
+	if err := s.validateSubscription(ctx, subscription); err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if subscription.PeriodStart.IsZero() {
+		subscription.PeriodStart = now
+	}
+	subscription.Status = domain.SubscriptionStatusActive
+	subscription.NextBillingDate = subscription.PeriodStart
+	subscription.FailedAttempts = 0
+
+	subscription, err := s.store.CreateSubscription(ctx, subscription, now)
+	if err != nil {
+		err = errors.Wrap(err, "unable to create subscription in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// validateSubscription checks that the plan, the customer and the payment method of the customer exist for the merchant,
+// and the payment method can be used for merchant-initiated authorizations.
+func (s *Service) validateSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	plan, err := s.store.GetPlan(ctx, subscription.PlanID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get plan from store")
+	}
+	if plan.MerchantID != subscription.MerchantID {
+		return errors.Wrap(domain.ErrPlanNotFound, "plan of another merchant")
+	}
+
+	customer, err := s.store.GetCustomer(ctx, subscription.CustomerID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get customer from store")
+	}
+	if customer.MerchantID != subscription.MerchantID {
+		return errors.Wrap(domain.ErrCustomerNotFound, "customer of another merchant")
+	}
+
+	paymentMethod, err := s.store.GetPaymentMethod(ctx, subscription.PaymentMethodID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get payment method from store")
+	}
+	if paymentMethod.CustomerID != subscription.CustomerID {
+		return errors.Wrap(domain.ErrPaymentMethodNotFound, "payment method of another customer")
+	}
+	if paymentMethod.NetworkTransactionID == "" {
+		return errors.Wrap(domain.ErrUnprocessable, "payment method has no initial customer-initiated authorization")
+	}
+	return nil
+}
+
+// GetSubscription returns the subscription of the merchant.
+func (s *Service) GetSubscription(ctx context.Context, merchantID string, subscriptionID uuid.UUID) (*domain.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "Service.GetSubscription")
+	defer span.End()
+
+	subscription, err := s.store.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get subscription from store")
+	}
+	if subscription.MerchantID != merchantID {
+		return nil, errors.Wrap(domain.ErrSubscriptionNotFound, "subscription of another merchant")
+	}
+	return subscription, nil
+}
+
+// CancelSubscription cancels the subscription of the merchant, it is not billed anymore.
+// Canceling a canceled subscription is a no op.
+func (s *Service) CancelSubscription(ctx context.Context, merchantID string, subscriptionID uuid.UUID) (*domain.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "Service.CancelSubscription")
+	defer span.End()
+
+	const errLogMsg = "unable to cancel subscription"
+	ctx = logging.WithFields(ctx,
+		zap.String(logging.MerchantID, merchantID),
+		zap.Stringer(logging.SubscriptionID, subscriptionID))
+
+	subscription, err := s.GetSubscription(ctx, merchantID, subscriptionID)
+	if err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	if subscription.Status == domain.SubscriptionStatusCanceled {
+		logging.Print(ctx, "subscription is already canceled hence no op")
+		return subscription, nil
+	}
+
+	subscription.Status = domain.SubscriptionStatusCanceled
+	if err = s.store.UpdateSubscription(ctx, subscription, s.clock.Now()); err != nil {
+		err = errors.Wrap(err, "unable to update subscription in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// BillDueSubscriptions bills the subscriptions due now with merchant-initiated authorizations and captures,
+// a subscription that fails to be billed is retried as per the retry intervals. It is called on schedule.
+func (s *Service) BillDueSubscriptions(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "Service.BillDueSubscriptions")
+	defer span.End()
+
+	now := s.clock.Now()
+	subscriptions, err := s.store.DueSubscriptions(ctx, now, billingBatchSize)
+	if err != nil {
+		err = errors.Wrap(err, "unable to get due subscriptions from store")
+		logging.Error(ctx, "unable to bill subscriptions", zap.Error(err))
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		// a subscription that can't be billed is postponed and billed on a later run
+		_ = s.billSubscription(ctx, subscription, now)
+	}
+	return nil
+}
+
+// billSubscription authorizes and captures the amount of the plan for the billing period of the subscription.
+// A declined authorization or capture is a failed attempt, any other error postpones the billing without
+// counting it as a failed attempt.
+func (s *Service) billSubscription(ctx context.Context, subscription *domain.Subscription, now time.Time) error {
+	const errLogMsg = "unable to bill subscription"
+	ctx = logging.WithFields(ctx,
+		zap.String(logging.MerchantID, subscription.MerchantID),
+		zap.Stringer(logging.SubscriptionID, subscription.ID))
+
+	plan, declined, err := s.chargeSubscription(ctx, subscription)
+	switch {
+	case err != nil:
+		subscription.BillingPostponed(now, billingErrorDelay)
+		logging.Error(ctx, errLogMsg, zap.Error(err), zap.Time("next_billing_date", subscription.NextBillingDate))
+	case declined != nil:
+		subscription.BillingFailed(now, s.retryIntervals)
+		logging.Error(ctx, "subscription billing declined", zap.Error(declined),
+			zap.Int("failed_attempts", subscription.FailedAttempts),
+			zap.String("status", string(subscription.Status)))
+	default:
+		subscription.BillingSucceeded(*plan)
+		logging.Print(ctx, "subscription billed", zap.Time("next_billing_date", subscription.NextBillingDate))
+	}
+
+	if updateErr := s.store.UpdateSubscription(ctx, subscription, now); updateErr != nil {
+		updateErr = errors.Wrap(updateErr, "unable to update subscription in store")
+		logging.Error(ctx, errLogMsg, zap.Error(updateErr))
+		return updateErr
+	}
+	return err
+}
+
+// chargeSubscription charges the amount of the plan for the billing period of the subscription.
+// The request IDs are derived from the subscription, the billing period and the attempt to make the billing idempotent.
+func (s *Service) chargeSubscription(ctx context.Context, subscription *domain.Subscription) (plan *domain.Plan, declined, err error) {
+	plan, err = s.store.GetPlan(ctx, subscription.PlanID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to get plan from store")
+	}
+
+	attempt := fmt.Sprintf("%s/%d", subscription.PeriodStart.UTC().Format(time.RFC3339), subscription.FailedAttempts)
+	authorization := &domain.Authorization{
+		RequestID:       uuid.NewV5(subscription.ID, attempt+"/authorization"),
+		MerchantID:      subscription.MerchantID,
+		Amount:          plan.Amount,
+		Initiator:       domain.InitiatorMerchant,
+		PaymentMethodID: subscription.PaymentMethodID,
+	}
+
+	declined, err = s.charge(ctx, authorization, uuid.NewV5(subscription.ID, attempt+"/capture"))
+	return plan, declined, err
+}
+
+// charge authorizes and captures the full amount of the authorization, it returns the reason
+// why the charge has been declined if so, or an error if the charge could not be made.
+// An authorization whose capture is declined is voided.
+func (s *Service) charge(ctx context.Context, authorization *domain.Authorization, captureRequestID uuid.UUID) (declined, err error) {
+	transaction, err := s.Authorize(ctx, authorization)
+	switch {
+	case isDecline(err):
+		return err, nil
+	case err != nil:
+		return nil, err
+	case transaction.AuthorizationDate() == nil:
+		return errors.New("authorization failed"), nil
+	}
+
+	// the transaction is already captured with the automatic capture policy of the merchant
+	if !transaction.Captured() {
+		transaction, err = s.Capture(ctx, &domain.Capture{
+			RequestID:       captureRequestID,
+			MerchantID:      authorization.MerchantID,
+			AuthorizationID: transaction.AuthorizationID,
+			Amount:          authorization.Amount,
+		})
+	}
+	switch {
+	case isDecline(err):
+		return err, nil
+	case err != nil:
+		return nil, err
+	case transaction.CapturedAmount.MinorUnits < authorization.Amount.MinorUnits:
+		_, err = s.Void(ctx, &domain.Void{
+			RequestID:       uuid.NewV5(captureRequestID, "void"),
+			MerchantID:      authorization.MerchantID,
+			AuthorizationID: transaction.AuthorizationID,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to void authorization of failed capture")
+		}
+		return errors.New("capture failed"), nil
+	}
+	return nil, nil
+}
+
+// isDecline indicates that the error is the decline of the payment rather than a failure to make it.
+func isDecline(err error) bool {
+	return errors.Is(err, domain.ErrUnprocessable) ||
+		errors.Is(err, domain.ErrVelocityLimitExceeded) ||
+		errors.Is(err, domain.ErrPaymentMethodNotFound)
+}
@@ -0,0 +1,256 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/service"
+	"github.com/jeffreyyong/payment-gateway/internal/service/mocks"
+)
+
+var (
+	someMerchant = "merchant-1"
+	customerID   = uuid.NewV4()
+
+	storedPaymentMethod = &domain.PaymentMethod{
+		ID:                   uuid.NewV4(),
+		CustomerID:           customerID,
+		MerchantID:           someMerchant,
+		PaymentSource:        domain.PaymentSource{PAN: somePAN, Expiry: domain.Expiry{Month: 1, Year: 23}},
+		NetworkTransactionID: "123456789012345",
+	}
+
+	monthlyPlan = &domain.Plan{
+		ID:            uuid.NewV4(),
+		MerchantID:    someMerchant,
+		Amount:        authorization.Amount,
+		Interval:      domain.IntervalMonth,
+		IntervalCount: 1,
+	}
+)
+
+func TestService_Authorize_StorePaymentMethod(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	storingAuthorization := *authorization
+	storingAuthorization.MerchantID = someMerchant
+	storingAuthorization.CustomerID = customerID
+	storingAuthorization.StorePaymentMethod = true
+
+	authorizedTransaction := mockAuthorizedTransaction
+	authorizedTransaction.NetworkTransactionID = storedPaymentMethod.NetworkTransactionID
+
+	gomock.InOrder(
+		store.EXPECT().GetCustomer(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID, MerchantID: someMerchant}, nil),
+		store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound),
+		store.EXPECT().CreateTransaction(gomock.Any(), &storingAuthorization, someDate).Return(&authorizedTransaction, nil),
+		store.EXPECT().CreatePaymentMethod(gomock.Any(), gomock.Any(), transactionID, someDate).
+			DoAndReturn(func(_ context.Context, pm *domain.PaymentMethod, _ uuid.UUID, _ time.Time) (*domain.PaymentMethod, error) {
+				assert.Empty(t, pm.PaymentSource.CVV, "the cvv is never stored")
+				assert.Equal(t, authorizedTransaction.NetworkTransactionID, pm.NetworkTransactionID)
+				return storedPaymentMethod, nil
+			}),
+	)
+
+	transaction, err := s.Authorize(ctx, &storingAuthorization)
+	require.NoError(t, err)
+	assert.Equal(t, storedPaymentMethod.ID, transaction.PaymentMethodID)
+	assert.Equal(t, domain.InitiatorCustomer, storingAuthorization.Initiator)
+}
+
+func TestService_Authorize_MerchantInitiated(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization domain.Authorization
+		paymentMethod *domain.PaymentMethod
+		wantErr       error
+	}{
+		{
+			name: "without stored payment method",
+			authorization: domain.Authorization{
+				RequestID: uuid.NewV4(), MerchantID: someMerchant, Amount: authorization.Amount,
+				Initiator: domain.InitiatorMerchant, PaymentSource: authorization.PaymentSource,
+			},
+			wantErr: domain.ErrUnprocessable,
+		},
+		{
+			name: "payment method without initial authorization",
+			authorization: domain.Authorization{
+				RequestID: uuid.NewV4(), MerchantID: someMerchant, Amount: authorization.Amount,
+				Initiator: domain.InitiatorMerchant, PaymentMethodID: storedPaymentMethod.ID,
+			},
+			paymentMethod: &domain.PaymentMethod{ID: storedPaymentMethod.ID, MerchantID: someMerchant},
+			wantErr:       domain.ErrUnprocessable,
+		},
+		{
+			name: "payment method of another merchant",
+			authorization: domain.Authorization{
+				RequestID: uuid.NewV4(), MerchantID: "merchant-2", Amount: authorization.Amount,
+				Initiator: domain.InitiatorMerchant, PaymentMethodID: storedPaymentMethod.ID,
+			},
+			paymentMethod: storedPaymentMethod,
+			wantErr:       domain.ErrPaymentMethodNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// no transaction is expected to be created
+			store := mocks.NewMockStore(ctrl)
+			if tt.paymentMethod != nil {
+				store.EXPECT().GetPaymentMethod(gomock.Any(), tt.authorization.PaymentMethodID).Return(tt.paymentMethod, nil)
+			}
+
+			s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+			require.NoError(t, err)
+
+			transaction, err := s.Authorize(ctx, &tt.authorization)
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, transaction)
+		})
+	}
+}
+
+func TestService_BillDueSubscriptions(t *testing.T) {
+	retryIntervals := []time.Duration{24 * time.Hour, 72 * time.Hour}
+
+	dueSubscription := func(status domain.SubscriptionStatus, failedAttempts int) *domain.Subscription {
+		return &domain.Subscription{
+			ID:              uuid.NewV4(),
+			MerchantID:      someMerchant,
+			CustomerID:      customerID,
+			PlanID:          monthlyPlan.ID,
+			PaymentMethodID: storedPaymentMethod.ID,
+			Status:          status,
+			PeriodStart:     someDate.Add(-time.Hour),
+			NextBillingDate: someDate.Add(-time.Hour),
+			FailedAttempts:  failedAttempts,
+		}
+	}
+
+	t.Run("billed", func(t *testing.T) {
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mocks.NewMockStore(ctrl)
+
+		s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithRetryIntervals(retryIntervals...))
+		require.NoError(t, err)
+
+		subscription := dueSubscription(domain.SubscriptionStatusActive, 0)
+		capturedTransaction := mockCapturedTransaction
+
+		gomock.InOrder(
+			store.EXPECT().DueSubscriptions(gomock.Any(), someDate, gomock.Any()).Return([]*domain.Subscription{subscription}, nil),
+			store.EXPECT().GetPlan(gomock.Any(), monthlyPlan.ID).Return(monthlyPlan, nil),
+			store.EXPECT().GetPaymentMethod(gomock.Any(), storedPaymentMethod.ID).Return(storedPaymentMethod, nil),
+			store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound),
+			store.EXPECT().CreateTransaction(gomock.Any(), gomock.Any(), someDate).
+				DoAndReturn(func(_ context.Context, a *domain.Authorization, _ time.Time) (*domain.Transaction, error) {
+					assert.Equal(t, domain.InitiatorMerchant, a.Initiator)
+					assert.Equal(t, storedPaymentMethod.PaymentSource, a.PaymentSource)
+					return &mockAuthorizedTransaction, nil
+				}),
+			store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockAuthorizedTransaction, nil),
+			store.EXPECT().CreatePaymentAction(gomock.Any(), transactionID, gomock.Any(), domain.PaymentActionTypeCapture,
+				gomock.Any(), gomock.Any(), gomock.Any(), someDate).Return(nil),
+			store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&capturedTransaction, nil),
+			store.EXPECT().UpdateSubscription(gomock.Any(), subscription, someDate).Return(nil),
+		)
+
+		require.NoError(t, s.BillDueSubscriptions(ctx))
+		assert.Equal(t, domain.SubscriptionStatusActive, subscription.Status)
+		assert.Equal(t, someDate.Add(-time.Hour).AddDate(0, 1, 0), subscription.NextBillingDate)
+		assert.Zero(t, subscription.FailedAttempts)
+	})
+
+	declined := []struct {
+		name                string
+		subscription        *domain.Subscription
+		wantStatus          domain.SubscriptionStatus
+		wantNextBillingDate time.Time
+	}{
+		{
+			name:                "first decline is retried",
+			subscription:        dueSubscription(domain.SubscriptionStatusActive, 0),
+			wantStatus:          domain.SubscriptionStatusPastDue,
+			wantNextBillingDate: someDate.Add(24 * time.Hour),
+		},
+		{
+			name:                "last retry declined",
+			subscription:        dueSubscription(domain.SubscriptionStatusPastDue, 2),
+			wantStatus:          domain.SubscriptionStatusUnpaid,
+			wantNextBillingDate: someDate.Add(-time.Hour),
+		},
+	}
+	for _, tt := range declined {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+
+			s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithRetryIntervals(retryIntervals...))
+			require.NoError(t, err)
+
+			// the payment method without initial authorization declines the merchant-initiated authorization
+			gomock.InOrder(
+				store.EXPECT().DueSubscriptions(gomock.Any(), someDate, gomock.Any()).Return([]*domain.Subscription{tt.subscription}, nil),
+				store.EXPECT().GetPlan(gomock.Any(), monthlyPlan.ID).Return(monthlyPlan, nil),
+				store.EXPECT().GetPaymentMethod(gomock.Any(), storedPaymentMethod.ID).
+					Return(&domain.PaymentMethod{ID: storedPaymentMethod.ID, MerchantID: someMerchant}, nil),
+				store.EXPECT().UpdateSubscription(gomock.Any(), tt.subscription, someDate).Return(nil),
+			)
+
+			require.NoError(t, s.BillDueSubscriptions(ctx))
+			assert.Equal(t, tt.wantStatus, tt.subscription.Status)
+			assert.Equal(t, tt.wantNextBillingDate, tt.subscription.NextBillingDate)
+		})
+	}
+
+	t.Run("postponed on error", func(t *testing.T) {
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mocks.NewMockStore(ctrl)
+
+		s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithRetryIntervals(retryIntervals...))
+		require.NoError(t, err)
+
+		subscription := dueSubscription(domain.SubscriptionStatusActive, 0)
+
+		gomock.InOrder(
+			store.EXPECT().DueSubscriptions(gomock.Any(), someDate, gomock.Any()).Return([]*domain.Subscription{subscription}, nil),
+			store.EXPECT().GetPlan(gomock.Any(), monthlyPlan.ID).Return(nil, errors.New("kaboom")),
+			store.EXPECT().UpdateSubscription(gomock.Any(), subscription, someDate).Return(nil),
+		)
+
+		require.NoError(t, s.BillDueSubscriptions(ctx))
+		assert.Equal(t, domain.SubscriptionStatusActive, subscription.Status)
+		assert.Equal(t, someDate.Add(time.Hour), subscription.NextBillingDate)
+		assert.Equal(t, someDate.Add(-time.Hour), subscription.PeriodStart)
+		assert.Zero(t, subscription.FailedAttempts)
+	})
+}
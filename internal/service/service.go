@@ -1,4 +1,4 @@
-//go:generate mockgen -destination=./mocks/store_mock.go -package=mocks github.com/jeffreyyong/payment-gateway/internal/service Store
+//go:generate mockgen -destination=./mocks/store_mock.go -package=mocks github.com/jeffreyyong/payment-gateway/internal/service Store,AuditLog,Merchants
 
 package service
 
@@ -12,9 +12,13 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"github.com/jeffreyyong/payment-gateway/internal/bin"
 	"github.com/jeffreyyong/payment-gateway/internal/domain"
 	"github.com/jeffreyyong/payment-gateway/internal/logging"
 	"github.com/jeffreyyong/payment-gateway/internal/luhn"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/pricing"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
 )
 
 // Store is the db interface
@@ -24,14 +28,94 @@ type Store interface {
 
 	CreateTransaction(ctx context.Context, authorization *domain.Authorization, processedDate time.Time) (*domain.Transaction, error)
 	GetTransaction(ctx context.Context, authorizationID uuid.UUID) (*domain.Transaction, error)
+	GetMerchantTransaction(ctx context.Context, merchantID string, authorizationID uuid.UUID) (*domain.Transaction, error)
+	GetTransactionByRequestID(ctx context.Context, merchantID string, requestID uuid.UUID) (*domain.Transaction, error)
 	CreatePaymentAction(ctx context.Context, transactionID, requestID uuid.UUID, paymentActionType domain.PaymentActionType,
-		amount *domain.Amount, processedDate time.Time) error
+		amount, settlementAmount, fee *domain.Amount, processedDate time.Time) error
+	UpdateAuthentication(ctx context.Context, transactionID uuid.UUID, authentication domain.Authentication, processedDate time.Time) error
+	CompleteAuthorization(ctx context.Context, transactionID uuid.UUID, status domain.PaymentActionStatus, processedDate time.Time) error
+
+	CreateCustomer(ctx context.Context, customer *domain.Customer, processedDate time.Time) (*domain.Customer, error)
+	GetCustomer(ctx context.Context, customerID uuid.UUID) (*domain.Customer, error)
+	UpdateCustomer(ctx context.Context, customer *domain.Customer, processedDate time.Time) error
+	DeleteCustomer(ctx context.Context, customerID uuid.UUID, processedDate time.Time) error
+	ListCustomerTransactions(ctx context.Context, customerID uuid.UUID) ([]*domain.Transaction, error)
+	CreatePaymentMethod(ctx context.Context, paymentMethod *domain.PaymentMethod, transactionID uuid.UUID, processedDate time.Time) (*domain.PaymentMethod, error)
+	GetPaymentMethod(ctx context.Context, paymentMethodID uuid.UUID) (*domain.PaymentMethod, error)
+	ListPaymentMethods(ctx context.Context, customerID uuid.UUID) ([]*domain.PaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, paymentMethodID uuid.UUID, processedDate time.Time) error
+	CreatePlan(ctx context.Context, plan *domain.Plan, processedDate time.Time) (*domain.Plan, error)
+	GetPlan(ctx context.Context, planID uuid.UUID) (*domain.Plan, error)
+	CreateSubscription(ctx context.Context, subscription *domain.Subscription, processedDate time.Time) (*domain.Subscription, error)
+	GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*domain.Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription *domain.Subscription, processedDate time.Time) error
+	DueSubscriptions(ctx context.Context, now time.Time, limit int) ([]*domain.Subscription, error)
+
+	CreateAPIKey(ctx context.Context, key *domain.APIKey, processedDate time.Time) (*domain.APIKey, error)
+	GetAPIKey(ctx context.Context, keyID uuid.UUID) (*domain.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	RotateAPIKey(ctx context.Context, keyID uuid.UUID, newKey *domain.APIKey, expiresAt, processedDate time.Time) (*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID uuid.UUID, processedDate time.Time) error
+	TouchAPIKey(ctx context.Context, keyID uuid.UUID, usedAt time.Time) error
+	ListAPIKeys(ctx context.Context, merchantID string) ([]*domain.APIKey, error)
+}
+
+// CardLookup looks up the non sensitive card information of a PAN.
+type CardLookup interface {
+	Lookup(pan string) domain.CardInfo
+}
+
+// RateProvider provides the current exchange rate to convert from one currency to another.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (domain.FXRate, error)
+}
+
+// VelocityLimiter enforces the velocity limits on authorizations, it returns domain.ErrVelocityLimitExceeded
+// when there are too many authorizations.
+type VelocityLimiter interface {
+	Allow(ctx context.Context, authorization *domain.Authorization) error
+}
+
+// AuditLog is the append-only audit log of the operations, see domain.AuditEntry.
+type AuditLog interface {
+	AppendAuditEntry(ctx context.Context, entry *domain.AuditEntry) error
+	ListAuditEntries(ctx context.Context, afterSequence int64, limit int) ([]*domain.AuditEntry, error)
+}
+
+// Merchants stores the merchants managed with the admin API.
+type Merchants interface {
+	CreateMerchant(ctx context.Context, merchant *domain.Merchant, processedDate time.Time) (*domain.Merchant, error)
+	GetMerchant(ctx context.Context, merchantID string) (*domain.Merchant, error)
+	UpdateMerchant(ctx context.Context, merchant *domain.Merchant, processedDate time.Time) error
+}
+
+// RiskAssessor screens an authorization for fraud.
+type RiskAssessor interface {
+	Assess(ctx context.Context, authorization *domain.Authorization) (domain.RiskAssessment, error)
+}
+
+// FeeCalculator calculates the merchant fee of a payment action.
+type FeeCalculator interface {
+	Fee(merchantID string, paymentActionType domain.PaymentActionType, card domain.CardInfo, amount domain.Amount) domain.Amount
 }
 
 // Service is the service struct.
 type Service struct {
-	store Store
-	clock clockwork.Clock
+	store    Store
+	clock    clockwork.Clock
+	cards    CardLookup
+	fees     FeeCalculator
+	rates    RateProvider
+	risk     RiskAssessor
+	velocity VelocityLimiter
+	auditLog AuditLog
+	// merchants are the merchants managed with the admin API, the merchants of the config are not restricted.
+	merchants Merchants
+	// settlementCurrencies are the currencies the merchants settle in keyed by merchant,
+	// merchants without one settle in the currency of the authorization.
+	settlementCurrencies map[string]string
+	// retryIntervals are the intervals after which a failed subscription billing is retried.
+	retryIntervals []time.Duration
 }
 
 // NewService initialises a new service with the store and some opts.
@@ -40,7 +124,12 @@ func NewService(store Store, opts ...Option) (*Service, error) {
 		return nil, fmt.Errorf("%w: store", errors.New("invalid param"))
 	}
 
-	s := &Service{store: store}
+	s := &Service{
+		store:          store,
+		cards:          bin.NewTable(),
+		fees:           pricing.NewCalculator(nil),
+		retryIntervals: defaultRetryIntervals,
+	}
 
 	for _, opt := range opts {
 		if err := opt(s); err != nil {
@@ -51,40 +140,343 @@ func NewService(store Store, opts ...Option) (*Service, error) {
 	return s, nil
 }
 
-// Authorize is the service function to authorize a transaction, it does the luhn validation on the credit card PAN
-// and subsequently create a transaction with the authorization.
+// Now returns the current time of the clock of the service.
+func (s *Service) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Authorize is the service function to authorize a transaction, it does the luhn validation on the credit card PAN,
+// enforces the velocity limits, looks up the card information used for pricing, locks the FX rate if the merchant settles in another currency,
+// screens the authorization for fraud and subsequently create a transaction with the authorization.
+// An authorization blocked by the fraud screening is stored as failed and ErrUnprocessable is returned.
+// When the 3-D Secure authentication is requested the authorization is stored as pending until CompleteAuthorization.
+// With a stored payment method the PaymentSource is taken from it, a merchant-initiated authorization requires one.
+// The transaction is linked to the customer if any, the PaymentSource is stored as a payment method of the customer
+// once authorized if requested. The stored transaction is returned as is when the merchant has already authorized
+// one with the request id, the replay is not counted by the velocity limits.
 func (s *Service) Authorize(ctx context.Context, authorization *domain.Authorization) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Service.Authorize")
+	defer span.End()
+
 	const errLogMsg = "unable to authorize transaction"
 	ctx = logging.WithFields(ctx,
 		zap.Stringer(logging.RequestID, authorization.RequestID),
+		zap.String(logging.MerchantID, authorization.MerchantID),
 		zap.Stringer(logging.PaymentAction, domain.PaymentActionTypeAuthorization))
 
+	if err := s.prepareStoredCredential(ctx, authorization); err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	settings, err := s.merchantSettings(ctx, authorization.MerchantID)
+	if err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	if settings != nil && !settings.AllowsCurrency(authorization.Amount.Currency) {
+		err = domain.NewReasonError(domain.ReasonCurrencyNotAllowed,
+			fmt.Sprintf("currency %s is not allowed for the merchant", authorization.Amount.Currency))
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		s.recordPaymentAction(ctx, domain.PaymentActionTypeAuthorization, metrics.StatusRejected, authorization.MerchantID, authorization.Amount, uuid.Nil)
+		return nil, err
+	}
+
 	if err := luhn.Validate(authorization.PaymentSource.PAN); err != nil {
-		err = errors.Wrap(domain.ErrUnprocessable, err.Error())
+		err = errors.Wrap(domain.ErrInvalidCard, err.Error())
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		s.recordPaymentAction(ctx, domain.PaymentActionTypeAuthorization, metrics.StatusRejected, authorization.MerchantID, authorization.Amount, uuid.Nil)
+		return nil, err
+	}
+
+	transaction, err := s.store.GetTransactionByRequestID(ctx, authorization.MerchantID, authorization.RequestID)
+	switch {
+	case err == nil:
+		logging.Print(ctx, "request is idempotent hence no op")
+		if transaction.Risk.Blocked() {
+			return nil, domain.ErrDeclinedByFraudScreening
+		}
+		if transaction.Voided() || transaction.Captured() {
+			return transaction, nil
+		}
+		// the automatic capture of an authorization which failed or was interrupted before it was captured is done
+		// on the replay of the authorization
+		transaction, err = s.autoCapture(ctx, settings, authorization.RequestID, transaction)
+		if err != nil {
+			logging.Error(ctx, errLogMsg, zap.Error(err))
+			return nil, err
+		}
+		return transaction, nil
+	case !errors.Is(err, domain.ErrTransactionNotFound):
+		err = errors.Wrap(err, "unable to get transaction of request from store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
 		return nil, err
 	}
 
-	transaction, err := s.store.CreateTransaction(ctx, authorization, s.clock.Now())
+	if s.velocity != nil {
+		if err := s.velocity.Allow(ctx, authorization); err != nil {
+			err = errors.Wrap(err, "unable to authorize within velocity limits")
+			logging.Error(ctx, errLogMsg, zap.Error(err))
+			s.recordPaymentAction(ctx, domain.PaymentActionTypeAuthorization, metrics.StatusRejected, authorization.MerchantID, authorization.Amount, uuid.Nil)
+			return nil, err
+		}
+	}
+
+	authorization.Card = s.cards.Lookup(authorization.PaymentSource.PAN)
+
+	now := s.clock.Now()
+	if settlementCurrency, ok := s.settlementCurrencies[authorization.MerchantID]; ok && settlementCurrency != authorization.Amount.Currency {
+		if s.rates == nil {
+			err := errors.New("no fx rate provider to convert to settlement currency")
+			logging.Error(ctx, errLogMsg, zap.Error(err))
+			return nil, err
+		}
+
+		rate, err := s.rates.Rate(ctx, authorization.Amount.Currency, settlementCurrency)
+		if err != nil {
+			err = errors.Wrap(domain.ErrUnprocessable, err.Error())
+			logging.Error(ctx, errLogMsg, zap.Error(err))
+			return nil, err
+		}
+		rate.LockedDate = now
+		authorization.FXRate = &rate
+	}
+
+	if s.risk != nil {
+		assessment, err := s.risk.Assess(ctx, authorization)
+		if err != nil {
+			err = errors.Wrap(err, "unable to screen authorization")
+			logging.Error(ctx, errLogMsg, zap.Error(err))
+			return nil, err
+		}
+		authorization.Risk = &assessment
+	}
+
+	if authorization.Authenticate {
+		authorization.Authentication = &domain.Authentication{Status: domain.AuthenticationStatusChallengeRequired}
+	}
+
+	transaction, err = s.store.CreateTransaction(ctx, authorization, now)
 	if err != nil {
 		err = errors.Wrap(err, "unable to create authorization in store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
 		return nil, err
 	}
+	s.recordPaymentAction(ctx, domain.PaymentActionTypeAuthorization, authorizationStatus(transaction), authorization.MerchantID,
+		authorization.Amount, transaction.AuthorizationID)
+
+	if authorization.Risk.Blocked() {
+		err = domain.ErrDeclinedByFraudScreening
+		logging.Error(ctx, errLogMsg, zap.Error(err),
+			zap.Int("risk.score", authorization.Risk.Score),
+			zap.Strings("risk.reasons", authorization.Risk.Reasons))
+		return nil, err
+	}
+
+	transaction, err = s.autoCapture(ctx, settings, authorization.RequestID, transaction)
+	if err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	if authorization.StorePaymentMethod && transaction.AuthorizationDate() != nil {
+		paymentMethod := &domain.PaymentMethod{
+			CustomerID:           authorization.CustomerID,
+			MerchantID:           authorization.MerchantID,
+			PaymentSource:        authorization.PaymentSource,
+			Card:                 authorization.Card,
+			NetworkTransactionID: transaction.NetworkTransactionID,
+		}
+		paymentMethod.PaymentSource.CVV = ""
+		paymentMethod, err = s.store.CreatePaymentMethod(ctx, paymentMethod, transaction.ID, now)
+		if err != nil {
+			err = errors.Wrap(err, "unable to store payment method in store")
+			logging.Error(ctx, errLogMsg, zap.Error(err))
+			return nil, err
+		}
+		transaction.PaymentMethodID = paymentMethod.ID
+	}
+
+	return transaction, nil
+}
+
+// prepareStoredCredential takes the PaymentSource and the customer of the authorization from the stored payment method
+// if any, and checks the stored credential rules: a merchant-initiated authorization requires a stored payment method
+// with the reference of the initial customer-initiated authorization and can't authenticate the absent cardholder,
+// the transaction can only be linked to a customer of the merchant.
+func (s *Service) prepareStoredCredential(ctx context.Context, authorization *domain.Authorization) error {
+	if authorization.Initiator == "" {
+		authorization.Initiator = domain.InitiatorCustomer
+	}
+
+	if authorization.PaymentMethodID != uuid.Nil {
+		paymentMethod, err := s.store.GetPaymentMethod(ctx, authorization.PaymentMethodID)
+		if err != nil {
+			return errors.Wrap(err, "unable to get payment method from store")
+		}
+		if paymentMethod.MerchantID != authorization.MerchantID {
+			return errors.Wrap(domain.ErrPaymentMethodNotFound, "payment method of another merchant")
+		}
+		if authorization.CustomerID != uuid.Nil && paymentMethod.CustomerID != authorization.CustomerID {
+			return errors.Wrap(domain.ErrPaymentMethodNotFound, "payment method of another customer")
+		}
+		authorization.PaymentSource = paymentMethod.PaymentSource
+		authorization.CustomerID = paymentMethod.CustomerID
+		authorization.StorePaymentMethod = false
+
+		if authorization.Initiator == domain.InitiatorMerchant && paymentMethod.NetworkTransactionID == "" {
+			return errors.Wrap(domain.ErrUnprocessable, "payment method has no initial customer-initiated authorization")
+		}
+	}
+
+	if authorization.Initiator == domain.InitiatorMerchant {
+		if authorization.PaymentMethodID == uuid.Nil {
+			return errors.Wrap(domain.ErrUnprocessable, "merchant-initiated authorization requires a stored payment method")
+		}
+		if authorization.Authenticate {
+			return errors.Wrap(domain.ErrUnprocessable, "merchant-initiated authorization can't authenticate the cardholder")
+		}
+	}
+
+	if authorization.PaymentMethodID == uuid.Nil && authorization.CustomerID != uuid.Nil {
+		customer, err := s.store.GetCustomer(ctx, authorization.CustomerID)
+		if err != nil {
+			return errors.Wrap(err, "unable to get customer from store")
+		}
+		if customer.MerchantID != authorization.MerchantID {
+			return errors.Wrap(domain.ErrCustomerNotFound, "customer of another merchant")
+		}
+	}
+
+	return nil
+}
+
+// SubmitAuthenticationResult stores the result of the 3-D Secure challenge posted by the ACS for the transaction
+// of the authorizationID, the ECI and the liability shift are derived from the status and the card scheme.
+func (s *Service) SubmitAuthenticationResult(ctx context.Context, authorizationID uuid.UUID, status domain.AuthenticationStatus) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Service.SubmitAuthenticationResult")
+	defer span.End()
+
+	const errLogMsg = "unable to submit authentication result"
+	ctx = logging.WithFields(ctx,
+		zap.Stringer(logging.AuthorizationID, authorizationID))
+
+	transaction, err := s.store.GetTransaction(ctx, authorizationID)
+	if err != nil {
+		err = errors.Wrap(err, "unable to get transaction from store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	if !transaction.Authentication.ChallengeRequired() || !transaction.RequiresAction() {
+		err = domain.ErrNotAwaitingAuthentication
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	authentication := domain.NewAuthenticationResult(transaction.Card.Scheme, status)
+	if err = s.store.UpdateAuthentication(ctx, transaction.ID, authentication, s.clock.Now()); err != nil {
+		err = errors.Wrap(err, "unable to update authentication in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	transaction.Authentication = &authentication
+
+	return transaction, nil
+}
+
+// CompleteAuthorization finalises the pending authorization of the transaction once the result of the 3-D Secure
+// challenge has been submitted. The authorization succeeds when the cardholder has been authenticated,
+// otherwise it is stored as failed and ErrUnprocessable is returned.
+// Completing an authorization that is not pending anymore is a no op. The transactions of the other merchants are not found.
+func (s *Service) CompleteAuthorization(ctx context.Context, merchantID string, authorizationID uuid.UUID) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Service.CompleteAuthorization")
+	defer span.End()
+
+	const errLogMsg = "unable to complete authorization"
+	ctx = logging.WithFields(ctx,
+		zap.String(logging.MerchantID, merchantID),
+		zap.Stringer(logging.AuthorizationID, authorizationID),
+		zap.Stringer(logging.PaymentAction, domain.PaymentActionTypeAuthorization))
+
+	transaction, err := s.store.GetMerchantTransaction(ctx, merchantID, authorizationID)
+	if err != nil {
+		err = errors.Wrap(err, "unable to get transaction from store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	if transaction.Authentication == nil {
+		err = domain.NewReasonError(domain.ReasonNotAwaitingAuthentication, "authorization does not require authentication")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	if !transaction.RequiresAction() {
+		logging.Print(ctx, "authorization is already completed hence no op")
+		return transaction, nil
+	}
+
+	if transaction.Authentication.ChallengeRequired() {
+		err = domain.ErrAuthenticationNotCompleted
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	status := domain.PaymentActionStatusSuccess
+	if !transaction.Authentication.Succeeded() {
+		status = domain.PaymentActionStatusFailed
+	}
+
+	if err = s.store.CompleteAuthorization(ctx, transaction.ID, status, s.clock.Now()); err != nil {
+		err = errors.Wrap(err, "unable to complete authorization in store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	s.recordPaymentAction(ctx, domain.PaymentActionTypeAuthorization, string(status), transaction.MerchantID, transaction.Amount, authorizationID)
+
+	if status == domain.PaymentActionStatusFailed {
+		err = domain.NewReasonError(domain.ReasonAuthenticationFailed,
+			fmt.Sprintf("cardholder authentication %s", transaction.Authentication.Status))
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	transaction, err = s.store.GetMerchantTransaction(ctx, merchantID, authorizationID)
+	if err != nil {
+		err = errors.Wrap(err, "unable to get authorized transaction from store")
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+
+	settings, err := s.merchantSettings(ctx, transaction.MerchantID)
+	if err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
+	transaction, err = s.autoCapture(ctx, settings, transaction.RequestID, transaction)
+	if err != nil {
+		logging.Error(ctx, errLogMsg, zap.Error(err))
+		return nil, err
+	}
 
 	return transaction, nil
 }
 
-// Void retrieves the transaction that is in the DB based on authorizationID, checks idempotent requests and validation
+// Void retrieves the transaction of the merchant that is in the DB based on authorizationID, checks idempotent requests and validation
 // and CreatePaymentAction of void for that transaction.
 func (s *Service) Void(ctx context.Context, void *domain.Void) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Service.Void")
+	defer span.End()
+
 	const errLogMsg = "unable to void transaction"
 	ctx = logging.WithFields(ctx,
 		zap.Stringer(logging.RequestID, void.RequestID),
+		zap.String(logging.MerchantID, void.MerchantID),
 		zap.Stringer(logging.AuthorizationID, void.AuthorizationID),
 		zap.Stringer(logging.PaymentAction, domain.PaymentActionTypeVoid))
 
-	transaction, err := s.store.GetTransaction(ctx, void.AuthorizationID)
+	transaction, err := s.store.GetMerchantTransaction(ctx, void.MerchantID, void.AuthorizationID)
 	if err != nil {
 		err = errors.Wrap(err, "unable to get transaction from store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
@@ -97,38 +489,45 @@ func (s *Service) Void(ctx context.Context, void *domain.Void) (*domain.Transact
 	}
 
 	if err := transaction.ValidateVoid(); err != nil {
-		err = errors.Wrap(domain.ErrUnprocessable, err.Error())
 		logging.Error(ctx, errLogMsg, zap.Error(err))
+		s.recordPaymentAction(ctx, domain.PaymentActionTypeVoid, metrics.StatusRejected, transaction.MerchantID, transaction.Amount, transaction.AuthorizationID)
 		return nil, err
 	}
 
-	err = s.store.CreatePaymentAction(ctx, transaction.ID, void.RequestID, domain.PaymentActionTypeVoid, nil, s.clock.Now())
+	err = s.store.CreatePaymentAction(ctx, transaction.ID, void.RequestID, domain.PaymentActionTypeVoid, nil, nil, nil, s.clock.Now())
 	if err != nil {
 		err = errors.Wrap(err, "unable to create void payment action in store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
 		return nil, err
 	}
 
-	transaction, err = s.store.GetTransaction(ctx, void.AuthorizationID)
+	transaction, err = s.store.GetMerchantTransaction(ctx, void.MerchantID, void.AuthorizationID)
 	if err != nil {
 		err = errors.Wrap(err, "unable to get voided transaction from store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
 		return nil, err
 	}
+	s.recordPaymentAction(ctx, domain.PaymentActionTypeVoid, paymentActionStatus(transaction, domain.PaymentActionTypeVoid, void.RequestID),
+		transaction.MerchantID, transaction.Amount, transaction.AuthorizationID)
 
 	return transaction, nil
 }
 
-// Capture retrieves the transaction that is in the DB based on authorizationID, checks idempotent requests and validation
-// and CreatePaymentAction of capture for that transaction.
+// Capture retrieves the transaction of the merchant that is in the DB based on authorizationID, checks idempotent requests and validation,
+// converts the amount with the locked FX rate, calculates the merchant fee and CreatePaymentAction of capture for that transaction.
+// The amount can be either in the currency of the transaction or in the settlement currency.
 func (s *Service) Capture(ctx context.Context, capture *domain.Capture) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Service.Capture")
+	defer span.End()
+
 	const errLogMsg = "unable to capture payment"
 	ctx = logging.WithFields(ctx,
 		zap.Stringer(logging.RequestID, capture.RequestID),
+		zap.String(logging.MerchantID, capture.MerchantID),
 		zap.Stringer(logging.AuthorizationID, capture.AuthorizationID),
 		zap.Stringer(logging.PaymentAction, domain.PaymentActionTypeCapture))
 
-	transaction, err := s.store.GetTransaction(ctx, capture.AuthorizationID)
+	transaction, err := s.store.GetMerchantTransaction(ctx, capture.MerchantID, capture.AuthorizationID)
 	if err != nil {
 		err = errors.Wrap(err, "unable to get transaction from store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
@@ -140,39 +539,49 @@ func (s *Service) Capture(ctx context.Context, capture *domain.Capture) (*domain
 		return transaction, nil
 	}
 
-	if err = transaction.ValidateCapture(capture.Amount); err != nil {
-		err = errors.Wrap(domain.ErrUnprocessable, err.Error())
+	amount := transaction.PresentmentAmount(capture.Amount)
+	if err = transaction.ValidateCapture(amount); err != nil {
 		logging.Error(ctx, errLogMsg, zap.Error(err))
+		s.recordPaymentAction(ctx, domain.PaymentActionTypeCapture, metrics.StatusRejected, transaction.MerchantID, transaction.Amount, transaction.AuthorizationID)
 		return nil, err
 	}
 
-	err = s.store.CreatePaymentAction(ctx, transaction.ID, capture.RequestID, domain.PaymentActionTypeCapture, &capture.Amount, s.clock.Now())
+	settlementAmount := transaction.SettlementAmount(amount)
+	fee := s.fees.Fee(transaction.MerchantID, domain.PaymentActionTypeCapture, transaction.Card, settlementAmount)
+	err = s.store.CreatePaymentAction(ctx, transaction.ID, capture.RequestID, domain.PaymentActionTypeCapture, &amount, &settlementAmount, &fee, s.clock.Now())
 	if err != nil {
 		err = errors.Wrap(err, "unable to create capture payment action in store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
 		return nil, err
 	}
 
-	transaction, err = s.store.GetTransaction(ctx, capture.AuthorizationID)
+	transaction, err = s.store.GetMerchantTransaction(ctx, capture.MerchantID, capture.AuthorizationID)
 	if err != nil {
 		err = errors.Wrap(err, "unable to get transaction with capture from store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
 		return nil, err
 	}
+	s.recordPaymentAction(ctx, domain.PaymentActionTypeCapture, paymentActionStatus(transaction, domain.PaymentActionTypeCapture, capture.RequestID),
+		transaction.MerchantID, transaction.Amount, transaction.AuthorizationID)
 
 	return transaction, nil
 }
 
-// Refund retrieves the transaction that is in the DB based on authorizationID, checks idempotent requests and validation
-// and CreatePaymentAction of refund for that transaction.
+// Refund retrieves the transaction of the merchant that is in the DB based on authorizationID, checks idempotent requests and validation,
+// converts the amount with the locked FX rate, calculates the merchant fee and CreatePaymentAction of refund for that transaction.
+// The amount can be either in the currency of the transaction or in the settlement currency.
 func (s *Service) Refund(ctx context.Context, refund *domain.Refund) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Service.Refund")
+	defer span.End()
+
 	const errLogMsg = "unable to refund payment"
 	ctx = logging.WithFields(ctx,
 		zap.Stringer(logging.RequestID, refund.RequestID),
+		zap.String(logging.MerchantID, refund.MerchantID),
 		zap.Stringer(logging.AuthorizationID, refund.AuthorizationID),
 		zap.Stringer(logging.PaymentAction, domain.PaymentActionTypeRefund))
 
-	transaction, err := s.store.GetTransaction(ctx, refund.AuthorizationID)
+	transaction, err := s.store.GetMerchantTransaction(ctx, refund.MerchantID, refund.AuthorizationID)
 	if err != nil {
 		err = errors.Wrap(err, "unable to get transaction from store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
@@ -184,25 +593,30 @@ func (s *Service) Refund(ctx context.Context, refund *domain.Refund) (*domain.Tr
 		return transaction, nil
 	}
 
-	if err = transaction.ValidateRefund(refund.Amount); err != nil {
-		err = errors.Wrap(domain.ErrUnprocessable, err.Error())
+	amount := transaction.PresentmentAmount(refund.Amount)
+	if err = transaction.ValidateRefund(amount); err != nil {
 		logging.Error(ctx, errLogMsg, zap.Error(err))
+		s.recordPaymentAction(ctx, domain.PaymentActionTypeRefund, metrics.StatusRejected, transaction.MerchantID, transaction.Amount, transaction.AuthorizationID)
 		return nil, err
 	}
 
-	err = s.store.CreatePaymentAction(ctx, transaction.ID, refund.RequestID, domain.PaymentActionTypeRefund, &refund.Amount, s.clock.Now())
+	settlementAmount := transaction.SettlementAmount(amount)
+	fee := s.fees.Fee(transaction.MerchantID, domain.PaymentActionTypeRefund, transaction.Card, settlementAmount)
+	err = s.store.CreatePaymentAction(ctx, transaction.ID, refund.RequestID, domain.PaymentActionTypeRefund, &amount, &settlementAmount, &fee, s.clock.Now())
 	if err != nil {
 		err = errors.Wrap(err, "unable to create refund payment action in store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
 		return nil, err
 	}
 
-	transaction, err = s.store.GetTransaction(ctx, refund.AuthorizationID)
+	transaction, err = s.store.GetMerchantTransaction(ctx, refund.MerchantID, refund.AuthorizationID)
 	if err != nil {
 		err = errors.Wrap(err, "unable to get transaction with capture from store")
 		logging.Error(ctx, errLogMsg, zap.Error(err))
 		return nil, err
 	}
+	s.recordPaymentAction(ctx, domain.PaymentActionTypeRefund, paymentActionStatus(transaction, domain.PaymentActionTypeRefund, refund.RequestID),
+		transaction.MerchantID, transaction.Amount, transaction.AuthorizationID)
 
 	return transaction, nil
 }
@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"math/big"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/pricing"
 	"github.com/jeffreyyong/payment-gateway/internal/service"
 	"github.com/jeffreyyong/payment-gateway/internal/service/mocks"
 )
@@ -31,6 +33,7 @@ var (
 	captureRequestID       = uuid.NewV4()
 	refundRequestID        = uuid.NewV4()
 	fullRefundAmount       = uint64(10000)
+	noFee                  = domain.Amount{Currency: transactionCurrency, Exponent: 2}
 
 	authorization = &domain.Authorization{
 		RequestID: authorizationRequestID,
@@ -51,6 +54,7 @@ var (
 
 	void = &domain.Void{
 		RequestID:       voidRequestID,
+		MerchantID:      "merchant-1",
 		AuthorizationID: authorizationID,
 	}
 
@@ -63,6 +67,7 @@ var (
 
 	capture = &domain.Capture{
 		RequestID:       captureRequestID,
+		MerchantID:      "merchant-1",
 		AuthorizationID: authorizationID,
 		Amount: domain.Amount{
 			MinorUnits: fullCaptureAmount,
@@ -85,6 +90,7 @@ var (
 
 	refund = &domain.Refund{
 		RequestID:       refundRequestID,
+		MerchantID:      "merchant-1",
 		AuthorizationID: authorizationID,
 		Amount: domain.Amount{
 			MinorUnits: fullCaptureAmount,
@@ -138,6 +144,7 @@ func TestService_Authorize(t *testing.T) {
 	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
 	require.NoError(t, err)
 
+	store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound)
 	store.EXPECT().CreateTransaction(gomock.Any(), authorization, someDate).Return(&mockAuthorizedTransaction, nil)
 
 	transaction, err := s.Authorize(ctx, authorization)
@@ -145,6 +152,227 @@ func TestService_Authorize(t *testing.T) {
 	assert.Equal(t, &mockAuthorizedTransaction, transaction)
 }
 
+type riskAssessorFunc func(ctx context.Context, authorization *domain.Authorization) (domain.RiskAssessment, error)
+
+func (f riskAssessorFunc) Assess(ctx context.Context, authorization *domain.Authorization) (domain.RiskAssessment, error) {
+	return f(ctx, authorization)
+}
+
+func TestService_Authorize_BlockedByRisk(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	blocked := domain.RiskAssessment{Decision: domain.RiskDecisionBlock, Score: 100, Reasons: []string{"bin_country_blocklist"}}
+	assessor := riskAssessorFunc(func(_ context.Context, _ *domain.Authorization) (domain.RiskAssessment, error) {
+		return blocked, nil
+	})
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithRiskAssessor(assessor))
+	require.NoError(t, err)
+
+	blockedAuthorization := *authorization
+	store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound)
+	store.EXPECT().CreateTransaction(gomock.Any(), &blockedAuthorization, someDate).Return(&mockAuthorizedTransaction, nil)
+
+	transaction, err := s.Authorize(ctx, &blockedAuthorization)
+	require.ErrorIs(t, err, domain.ErrUnprocessable)
+	assert.Nil(t, transaction)
+	assert.Equal(t, &blocked, blockedAuthorization.Risk, "risk assessment is persisted with the transaction")
+}
+
+type velocityLimiterFunc func(ctx context.Context, authorization *domain.Authorization) error
+
+func (f velocityLimiterFunc) Allow(ctx context.Context, authorization *domain.Authorization) error {
+	return f(ctx, authorization)
+}
+
+func TestService_Authorize_VelocityLimitExceeded(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the transaction is not created for an authorization over the limits
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().GetTransactionByRequestID(gomock.Any(), authorization.MerchantID, authorization.RequestID).Return(nil, domain.ErrTransactionNotFound)
+
+	limiter := velocityLimiterFunc(func(_ context.Context, _ *domain.Authorization) error {
+		return domain.ErrVelocityLimitExceeded
+	})
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithVelocityLimiter(limiter))
+	require.NoError(t, err)
+
+	transaction, err := s.Authorize(ctx, authorization)
+	require.ErrorIs(t, err, domain.ErrVelocityLimitExceeded)
+	assert.Nil(t, transaction)
+}
+
+// pendingTransaction returns the authorized transaction waiting for the 3-D Secure challenge with the authentication.
+func pendingTransaction(authentication domain.Authentication) *domain.Transaction {
+	t := mockAuthorizedTransaction
+	t.Card = domain.CardInfo{Scheme: domain.CardSchemeVisa}
+	t.Authentication = &authentication
+	t.PaymentActionSummary = []*domain.PaymentAction{
+		{
+			Type:          domain.PaymentActionTypeAuthorization,
+			Status:        domain.PaymentActionStatusPending,
+			ProcessedDate: someDate,
+			Amount:        &authorization.Amount,
+			RequestID:     authorization.RequestID,
+		},
+	}
+	t.Amounts()
+	return &t
+}
+
+func TestService_Authorize_Replay(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the replay of an authorization is neither counted by the velocity limits nor stored again
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().GetTransactionByRequestID(gomock.Any(), authorization.MerchantID, authorization.RequestID).Return(&mockAuthorizedTransaction, nil)
+
+	limiter := velocityLimiterFunc(func(_ context.Context, _ *domain.Authorization) error {
+		t.Fatal("the replay must not be counted by the velocity limits")
+		return nil
+	})
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithVelocityLimiter(limiter))
+	require.NoError(t, err)
+
+	transaction, err := s.Authorize(ctx, authorization)
+	require.NoError(t, err)
+	assert.Equal(t, &mockAuthorizedTransaction, transaction)
+}
+
+func TestService_Authorize_WithAuthentication(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	authenticatedAuthorization := *authorization
+	authenticatedAuthorization.Authenticate = true
+	store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound)
+	store.EXPECT().CreateTransaction(gomock.Any(), &authenticatedAuthorization, someDate).
+		Return(pendingTransaction(domain.Authentication{Status: domain.AuthenticationStatusChallengeRequired}), nil)
+
+	transaction, err := s.Authorize(ctx, &authenticatedAuthorization)
+	require.NoError(t, err)
+	assert.True(t, transaction.RequiresAction())
+	assert.Equal(t, &domain.Authentication{Status: domain.AuthenticationStatusChallengeRequired}, authenticatedAuthorization.Authentication)
+}
+
+func TestService_SubmitAuthenticationResult(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate.Add(time.Minute))))
+	require.NoError(t, err)
+
+	want := domain.Authentication{Status: domain.AuthenticationStatusAuthenticated, ECI: "05", LiabilityShift: true}
+	store.EXPECT().GetTransaction(gomock.Any(), authorizationID).
+		Return(pendingTransaction(domain.Authentication{Status: domain.AuthenticationStatusChallengeRequired}), nil)
+	store.EXPECT().UpdateAuthentication(gomock.Any(), transactionID, want, someDate.Add(time.Minute)).Return(nil)
+
+	transaction, err := s.SubmitAuthenticationResult(ctx, authorizationID, domain.AuthenticationStatusAuthenticated)
+	require.NoError(t, err)
+	assert.Equal(t, &want, transaction.Authentication)
+
+	t.Run("should reject the result once the challenge has been completed", func(t *testing.T) {
+		store.EXPECT().GetTransaction(gomock.Any(), authorizationID).Return(pendingTransaction(want), nil)
+
+		_, err := s.SubmitAuthenticationResult(ctx, authorizationID, domain.AuthenticationStatusFailed)
+		assert.ErrorIs(t, err, domain.ErrUnprocessable)
+	})
+}
+
+func TestService_CompleteAuthorization(t *testing.T) {
+	ctx := context.Background()
+	completedDate := someDate.Add(time.Minute)
+
+	testCases := []struct {
+		description    string
+		authentication domain.Authentication
+		setupMocks     func(store *mocks.MockStore)
+		wantErr        error
+	}{
+		{
+			"should authorize when the cardholder has been authenticated",
+			domain.NewAuthenticationResult(domain.CardSchemeVisa, domain.AuthenticationStatusAuthenticated),
+			func(store *mocks.MockStore) {
+				store.EXPECT().CompleteAuthorization(gomock.Any(), transactionID, domain.PaymentActionStatusSuccess, completedDate).Return(nil)
+				store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockAuthorizedTransaction, nil)
+			},
+			nil,
+		},
+		{
+			"should decline when the cardholder failed the challenge",
+			domain.NewAuthenticationResult(domain.CardSchemeVisa, domain.AuthenticationStatusFailed),
+			func(store *mocks.MockStore) {
+				store.EXPECT().CompleteAuthorization(gomock.Any(), transactionID, domain.PaymentActionStatusFailed, completedDate).Return(nil)
+			},
+			domain.ErrAuthenticationFailed,
+		},
+		{
+			"should reject when the challenge has not been completed",
+			domain.Authentication{Status: domain.AuthenticationStatusChallengeRequired},
+			nil,
+			domain.ErrAuthenticationNotCompleted,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(completedDate)))
+			require.NoError(t, err)
+
+			store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(pendingTransaction(tt.authentication), nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(store)
+			}
+
+			transaction, err := s.CompleteAuthorization(ctx, "merchant-1", authorizationID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, transaction)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &mockAuthorizedTransaction, transaction)
+		})
+	}
+}
+
+func TestService_CompleteAuthorization_TransactionOfAnotherMerchant(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-2", authorizationID).Return(nil, domain.ErrTransactionNotFound)
+
+	transaction, err := s.CompleteAuthorization(ctx, "merchant-2", authorizationID)
+	assert.ErrorIs(t, err, domain.ErrTransactionNotFound)
+	assert.Nil(t, transaction)
+}
+
 // TODO: generate test coverage
 func TestService_Void(t *testing.T) {
 	ctx := context.Background()
@@ -157,10 +385,10 @@ func TestService_Void(t *testing.T) {
 	require.NoError(t, err)
 
 	gomock.InOrder(
-		store.EXPECT().GetTransaction(gomock.Any(), authorizationID).Return(&mockAuthorizedTransaction, nil).Times(1),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockAuthorizedTransaction, nil).Times(1),
 		store.EXPECT().CreatePaymentAction(gomock.Any(), mockAuthorizedTransaction.ID, voidRequestID,
-			domain.PaymentActionTypeVoid, nil, someDate).Return(nil).Times(1),
-		store.EXPECT().GetTransaction(gomock.Any(), authorizationID).Return(&mockVoidedTransaction, nil).Times(1),
+			domain.PaymentActionTypeVoid, nil, nil, nil, someDate).Return(nil).Times(1),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockVoidedTransaction, nil).Times(1),
 	)
 
 	transaction, err := s.Void(ctx, void)
@@ -168,6 +396,25 @@ func TestService_Void(t *testing.T) {
 	assert.Equal(t, &mockVoidedTransaction, transaction)
 }
 
+func TestService_Void_TransactionOfAnotherMerchant(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-2", authorizationID).Return(nil, domain.ErrTransactionNotFound)
+
+	otherMerchantVoid := *void
+	otherMerchantVoid.MerchantID = "merchant-2"
+	transaction, err := s.Void(ctx, &otherMerchantVoid)
+	assert.ErrorIs(t, err, domain.ErrTransactionNotFound)
+	assert.Nil(t, transaction)
+}
+
 func TestService_Capture(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -179,10 +426,10 @@ func TestService_Capture(t *testing.T) {
 	require.NoError(t, err)
 
 	gomock.InOrder(
-		store.EXPECT().GetTransaction(gomock.Any(), authorizationID).Return(&mockAuthorizedTransaction, nil).Times(1),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockAuthorizedTransaction, nil).Times(1),
 		store.EXPECT().CreatePaymentAction(gomock.Any(), mockAuthorizedTransaction.ID, captureRequestID,
-			domain.PaymentActionTypeCapture, &capture.Amount, someDate).Return(nil).Times(1),
-		store.EXPECT().GetTransaction(gomock.Any(), authorizationID).Return(&mockVoidedTransaction, nil).Times(1),
+			domain.PaymentActionTypeCapture, &capture.Amount, &capture.Amount, &noFee, someDate).Return(nil).Times(1),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockVoidedTransaction, nil).Times(1),
 	)
 
 	transaction, err := s.Capture(ctx, capture)
@@ -190,6 +437,134 @@ func TestService_Capture(t *testing.T) {
 	assert.Equal(t, &mockVoidedTransaction, transaction)
 }
 
+func TestService_Capture_ZeroAmount(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockAuthorizedTransaction, nil).Times(1)
+
+	zeroCapture := *capture
+	zeroCapture.Amount.MinorUnits = 0
+	transaction, err := s.Capture(ctx, &zeroCapture)
+	assert.ErrorIs(t, err, domain.ErrUnprocessable)
+	assert.Nil(t, transaction)
+}
+
+func TestService_Capture_WithFee(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	fees := pricing.NewCalculator(map[string]pricing.Schedule{
+		"merchant-1": {
+			Country: "GB",
+			Rules: []pricing.Rule{
+				{Action: domain.PaymentActionTypeCapture, Region: pricing.RegionDomestic, BasisPoints: 140, Fixed: 20},
+			},
+		},
+	})
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)), service.WithFeeCalculator(fees))
+	require.NoError(t, err)
+
+	merchantTransaction := mockAuthorizedTransaction
+	merchantTransaction.MerchantID = "merchant-1"
+	merchantTransaction.Card = domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "GB"}
+	wantFee := &domain.Amount{MinorUnits: 160, Currency: transactionCurrency, Exponent: 2}
+
+	gomock.InOrder(
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&merchantTransaction, nil).Times(1),
+		store.EXPECT().CreatePaymentAction(gomock.Any(), merchantTransaction.ID, captureRequestID,
+			domain.PaymentActionTypeCapture, &capture.Amount, &capture.Amount, wantFee, someDate).Return(nil).Times(1),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockCapturedTransaction, nil).Times(1),
+	)
+
+	transaction, err := s.Capture(ctx, capture)
+	require.NoError(t, err)
+	assert.Equal(t, &mockCapturedTransaction, transaction)
+}
+
+type rateProviderFunc func(ctx context.Context, from, to string) (domain.FXRate, error)
+
+func (f rateProviderFunc) Rate(ctx context.Context, from, to string) (domain.FXRate, error) {
+	return f(ctx, from, to)
+}
+
+func TestService_Authorize_WithFXRate(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	rates := rateProviderFunc(func(_ context.Context, from, to string) (domain.FXRate, error) {
+		require.Equal(t, "EUR", from)
+		require.Equal(t, "GBP", to)
+		return domain.FXRate{From: from, To: to, Rate: big.NewRat(85, 100)}, nil
+	})
+	s, err := service.NewService(store,
+		service.WithClock(clockwork.NewFakeClockAt(someDate)),
+		service.WithRateProvider(rates),
+		service.WithSettlementCurrencies(map[string]string{"merchant-1": "GBP"}),
+	)
+	require.NoError(t, err)
+
+	eurAuthorization := *authorization
+	eurAuthorization.MerchantID = "merchant-1"
+	eurAuthorization.Amount.Currency = "EUR"
+
+	store.EXPECT().GetTransactionByRequestID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound)
+	store.EXPECT().CreateTransaction(gomock.Any(), &eurAuthorization, someDate).Return(&mockAuthorizedTransaction, nil)
+
+	_, err = s.Authorize(ctx, &eurAuthorization)
+	require.NoError(t, err)
+	require.NotNil(t, eurAuthorization.FXRate)
+	assert.Equal(t, domain.FXRate{From: "EUR", To: "GBP", Rate: big.NewRat(85, 100), LockedDate: someDate}, *eurAuthorization.FXRate)
+}
+
+func TestService_Capture_InSettlementCurrency(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+
+	s, err := service.NewService(store, service.WithClock(clockwork.NewFakeClockAt(someDate)))
+	require.NoError(t, err)
+
+	eurTransaction := mockAuthorizedTransaction
+	eurTransaction.Amount = domain.Amount{MinorUnits: transactionAmount, Currency: "EUR", Exponent: 2}
+	eurTransaction.FXRate = &domain.FXRate{From: "EUR", To: "GBP", Rate: big.NewRat(85, 100), LockedDate: someDate}
+	eurTransaction.Amounts()
+
+	gbpCapture := &domain.Capture{
+		RequestID:       captureRequestID,
+		MerchantID:      "merchant-1",
+		AuthorizationID: authorizationID,
+		Amount:          domain.Amount{MinorUnits: 5000, Currency: "GBP", Exponent: 2},
+	}
+	wantAmount := &domain.Amount{MinorUnits: 5882, Currency: "EUR", Exponent: 2}
+	wantSettlementAmount := &domain.Amount{MinorUnits: 5000, Currency: "GBP", Exponent: 2}
+	wantFee := &domain.Amount{Currency: "GBP", Exponent: 2}
+
+	gomock.InOrder(
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&eurTransaction, nil).Times(1),
+		store.EXPECT().CreatePaymentAction(gomock.Any(), eurTransaction.ID, captureRequestID,
+			domain.PaymentActionTypeCapture, wantAmount, wantSettlementAmount, wantFee, someDate).Return(nil).Times(1),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&eurTransaction, nil).Times(1),
+	)
+
+	_, err = s.Capture(ctx, gbpCapture)
+	require.NoError(t, err)
+}
+
 func TestService_Refund(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -201,9 +576,9 @@ func TestService_Refund(t *testing.T) {
 	require.NoError(t, err)
 
 	gomock.InOrder(
-		store.EXPECT().GetTransaction(gomock.Any(), authorizationID).Return(&mockCapturedTransaction, nil).Times(1),
-		store.EXPECT().CreatePaymentAction(gomock.Any(), mockAuthorizedTransaction.ID, refundRequestID, domain.PaymentActionTypeRefund, &refund.Amount, someDate).Return(nil).Times(1),
-		store.EXPECT().GetTransaction(gomock.Any(), authorizationID).Return(&mockRefundedTransaction, nil).Times(1),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockCapturedTransaction, nil).Times(1),
+		store.EXPECT().CreatePaymentAction(gomock.Any(), mockAuthorizedTransaction.ID, refundRequestID, domain.PaymentActionTypeRefund, &refund.Amount, &refund.Amount, &noFee, someDate).Return(nil).Times(1),
+		store.EXPECT().GetMerchantTransaction(gomock.Any(), "merchant-1", authorizationID).Return(&mockRefundedTransaction, nil).Times(1),
 	)
 
 	transaction, err := s.Refund(ctx, refund)
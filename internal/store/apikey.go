@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+const apiKeyColumns = `id, merchant_id, key_hash, scopes, created_date, expires_at, revoked_at, last_used_at, rotated_at`
+
+// CreateAPIKey creates the API key with the hash of the key and returns it with its ID.
+func (s *Store) CreateAPIKey(ctx context.Context, key *domain.APIKey, processedDate time.Time) (*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "Store.CreateAPIKey")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("create_api_key", time.Now())
+
+	k, err := createAPIKey(ctx, s.DB, key, processedDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert api key statement")
+	}
+	return k, nil
+}
+
+// GetAPIKey returns the API key given its ID.
+func (s *Store) GetAPIKey(ctx context.Context, keyID uuid.UUID) (*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetAPIKey")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_api_key", time.Now())
+
+	k, err := scanAPIKey(s.QueryRowContext(ctx, `select `+apiKeyColumns+` from api_key where id = $1`, keyID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get api key query")
+	}
+	return k, nil
+}
+
+// GetAPIKeyByHash returns the API key given the hash of the key, including the expired and revoked keys.
+func (s *Store) GetAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetAPIKeyByHash")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_api_key_by_hash", time.Now())
+
+	k, err := scanAPIKey(s.QueryRowContext(ctx, `select `+apiKeyColumns+` from api_key where key_hash = $1`, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get api key by hash query")
+	}
+	return k, nil
+}
+
+// RotateAPIKey creates the new API key replacing the key and expires the key at expiresAt at the latest,
+// both keys are valid until then. A revoked key is not found and a key can only be rotated once.
+func (s *Store) RotateAPIKey(ctx context.Context, keyID uuid.UUID, newKey *domain.APIKey, expiresAt, processedDate time.Time) (*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "Store.RotateAPIKey")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("rotate_api_key", time.Now())
+
+	var (
+		tx  *sql.Tx
+		res sql.Result
+		err error
+	)
+
+	tx, err = s.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if res, err = tx.ExecContext(ctx, `
+		update api_key set expires_at = least(coalesce(expires_at, $2), $2), rotated_at = $3
+		where id = $1 and revoked_at is null and rotated_at is null
+	`, keyID, expiresAt, processedDate); err != nil {
+		return nil, errors.Wrap(err, "expire api key statement")
+	}
+	var n int64
+	if n, err = res.RowsAffected(); err != nil {
+		return nil, errors.Wrap(err, "expire api key rows affected")
+	}
+	if n == 0 {
+		// the key is either not found, revoked or rotated concurrently
+		err = domain.ErrAPIKeyRotated
+		var rotated sql.NullTime
+		if scanErr := tx.QueryRowContext(ctx, `select rotated_at from api_key where id = $1 and revoked_at is null`, keyID).
+			Scan(&rotated); scanErr != nil || !rotated.Valid {
+			err = domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	var k *domain.APIKey
+	if k, err = createAPIKey(ctx, tx, newKey, processedDate); err != nil {
+		return nil, errors.Wrap(err, "insert api key statement")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit rotate api key")
+	}
+	return k, nil
+}
+
+// RevokeAPIKey revokes the API key, it is invalid from then on.
+func (s *Store) RevokeAPIKey(ctx context.Context, keyID uuid.UUID, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.RevokeAPIKey")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("revoke_api_key", time.Now())
+
+	res, err := s.ExecContext(ctx, `
+		update api_key set revoked_at = $2 where id = $1 and revoked_at is null
+	`, keyID, processedDate)
+	if err != nil {
+		return errors.Wrap(err, "revoke api key statement")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey records the last time the API key has been used.
+func (s *Store) TouchAPIKey(ctx context.Context, keyID uuid.UUID, usedAt time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.TouchAPIKey")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("touch_api_key", time.Now())
+
+	if _, err := s.ExecContext(ctx, `update api_key set last_used_at = $2 where id = $1`, keyID, usedAt); err != nil {
+		return errors.Wrap(err, "touch api key statement")
+	}
+	return nil
+}
+
+// ListAPIKeys returns the API keys of the merchant, the latest first.
+func (s *Store) ListAPIKeys(ctx context.Context, merchantID string) ([]*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "Store.ListAPIKeys")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("list_api_keys", time.Now())
+
+	rows, err := s.QueryContext(ctx, `
+		select `+apiKeyColumns+` from api_key where merchant_id = $1 order by created_date desc
+	`, merchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list api keys query")
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "list api keys scan")
+		}
+		keys = append(keys, k)
+	}
+	if rows.Err() != nil {
+		return nil, errors.Wrap(rows.Err(), "list api keys rows err")
+	}
+	return keys, nil
+}
+
+// queryRower is implemented by both sql.DB and sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func createAPIKey(ctx context.Context, q queryRower, key *domain.APIKey, processedDate time.Time) (*domain.APIKey, error) {
+	k := *key
+	scopes := make([]string, 0, len(k.Scopes))
+	for _, s := range k.Scopes {
+		scopes = append(scopes, string(s))
+	}
+
+	err := q.QueryRowContext(ctx, `
+		insert into api_key (merchant_id, key_hash, scopes, created_date, expires_at)
+		values ($1, $2, $3, $4, $5)
+		returning id, created_date
+	`, k.MerchantID, k.Hash, pq.Array(scopes), processedDate, k.ExpiresAt).Scan(&k.ID, &k.CreatedDate)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func scanAPIKey(row scanner) (*domain.APIKey, error) {
+	var (
+		k                              domain.APIKey
+		scopes                         []string
+		expiresAt, revokedAt, lastUsed sql.NullTime
+		rotatedAt                      sql.NullTime
+	)
+	if err := row.Scan(&k.ID, &k.MerchantID, &k.Hash, pq.Array(&scopes), &k.CreatedDate, &expiresAt, &revokedAt, &lastUsed,
+		&rotatedAt); err != nil {
+		return nil, err
+	}
+	for _, s := range scopes {
+		k.Scopes = append(k.Scopes, domain.Scope(s))
+	}
+	k.ExpiresAt = nullTime(expiresAt)
+	k.RevokedAt = nullTime(revokedAt)
+	k.LastUsedAt = nullTime(lastUsed)
+	k.RotatedAt = nullTime(rotatedAt)
+	return &k, nil
+}
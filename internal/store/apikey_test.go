@@ -0,0 +1,62 @@
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+func TestStore_APIKeys(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+
+	key, err := s.CreateAPIKey(ctx, &domain.APIKey{
+		MerchantID: "merchant-1",
+		Hash:       domain.HashAPIKey("pgk_current"),
+		Scopes:     []domain.Scope{domain.ScopeAuthorize, domain.ScopeRead},
+	}, someFakeDate)
+	require.NoError(t, err)
+
+	got, err := s.GetAPIKeyByHash(ctx, domain.HashAPIKey("pgk_current"))
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, []domain.Scope{domain.ScopeAuthorize, domain.ScopeRead}, got.Scopes)
+	assert.Nil(t, got.ExpiresAt)
+
+	usedAt := someFakeDate.Add(time.Minute)
+	require.NoError(t, s.TouchAPIKey(ctx, key.ID, usedAt))
+
+	overlapEnd := someFakeDate.Add(time.Hour)
+	rotated, err := s.RotateAPIKey(ctx, key.ID, &domain.APIKey{
+		MerchantID: "merchant-1",
+		Hash:       domain.HashAPIKey("pgk_new"),
+		Scopes:     got.Scopes,
+	}, overlapEnd, someFakeDate)
+	require.NoError(t, err)
+
+	got, err = s.GetAPIKey(ctx, key.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.ExpiresAt)
+	assert.True(t, overlapEnd.Equal(*got.ExpiresAt))
+	require.NotNil(t, got.LastUsedAt)
+	assert.True(t, usedAt.Equal(*got.LastUsedAt))
+	require.NotNil(t, got.RotatedAt)
+	assert.True(t, someFakeDate.Equal(*got.RotatedAt))
+
+	_, err = s.RotateAPIKey(ctx, key.ID, &domain.APIKey{MerchantID: "merchant-1", Hash: "again"}, overlapEnd, someFakeDate)
+	assert.ErrorIs(t, err, domain.ErrAPIKeyRotated, "a rotated key cannot be rotated again")
+
+	require.NoError(t, s.RevokeAPIKey(ctx, rotated.ID, someFakeDate))
+	assert.ErrorIs(t, s.RevokeAPIKey(ctx, rotated.ID, someFakeDate), domain.ErrAPIKeyNotFound)
+
+	_, err = s.RotateAPIKey(ctx, rotated.ID, &domain.APIKey{MerchantID: "merchant-1", Hash: "other"}, overlapEnd, someFakeDate)
+	assert.ErrorIs(t, err, domain.ErrAPIKeyNotFound, "a revoked key cannot be rotated")
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+// auditLogLockID is the key of the advisory lock serialising the appends to the audit log,
+// an entry can only be chained to the last one.
+const auditLogLockID = 8_000_001
+
+const auditEntryColumns = `sequence, time, merchant_id, token_id, operation, resource_id, request_id, outcome, prev_hash, hash`
+
+// AppendAuditEntry chains the entry to the last entry of the audit log and appends it.
+func (s *Store) AppendAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	return s.AppendAuditEntries(ctx, []*domain.AuditEntry{entry})
+}
+
+// AppendAuditEntries chains the entries in order to the last entry of the audit log and appends them
+// in a single transaction, the audit log is locked once for all of them.
+func (s *Store) AppendAuditEntries(ctx context.Context, entries []*domain.AuditEntry) error {
+	ctx, span := tracing.Start(ctx, "Store.AppendAuditEntries")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("append_audit_entries", time.Now())
+
+	var (
+		tx  *sql.Tx
+		err error
+	)
+
+	tx, err = s.Begin()
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `select pg_advisory_xact_lock($1)`, auditLogLockID); err != nil {
+		return errors.Wrap(err, "lock audit log statement")
+	}
+
+	var prev *domain.AuditEntry
+	prev, err = scanAuditEntry(tx.QueryRowContext(ctx, `
+		select `+auditEntryColumns+` from audit_log order by sequence desc limit 1
+	`))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		prev, err = nil, nil
+	case err != nil:
+		return errors.Wrap(err, "select last audit entry statement")
+	}
+
+	for _, entry := range entries {
+		entry.Chain(prev)
+		if _, err = tx.ExecContext(ctx, `
+			insert into audit_log (`+auditEntryColumns+`) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, entry.Sequence, entry.Time, entry.MerchantID, entry.TokenID, entry.Operation, entry.ResourceID,
+			entry.RequestID, entry.Outcome, entry.PrevHash, entry.Hash); err != nil {
+			return errors.Wrap(err, "insert audit entry statement")
+		}
+		prev = entry
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit transaction")
+	}
+	return nil
+}
+
+// ListAuditEntries returns up to limit entries of the audit log after the sequence, in the order of the log.
+func (s *Store) ListAuditEntries(ctx context.Context, afterSequence int64, limit int) ([]*domain.AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "Store.ListAuditEntries")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("list_audit_entries", time.Now())
+
+	rows, err := s.QueryContext(ctx, `
+		select `+auditEntryColumns+` from audit_log where sequence > $1 order by sequence limit $2
+	`, afterSequence, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "select audit entries statement")
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan audit entry")
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate audit entries")
+	}
+	return entries, nil
+}
+
+func scanAuditEntry(row scanner) (*domain.AuditEntry, error) {
+	var e domain.AuditEntry
+	if err := row.Scan(&e.Sequence, &e.Time, &e.MerchantID, &e.TokenID, &e.Operation, &e.ResourceID,
+		&e.RequestID, &e.Outcome, &e.PrevHash, &e.Hash); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
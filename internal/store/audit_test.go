@@ -0,0 +1,54 @@
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+func TestStore_AuditLog(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+
+	first := &domain.AuditEntry{Time: someFakeDate, MerchantID: "merchant-1", Operation: "POST /authorize", Outcome: "200"}
+	require.NoError(t, s.AppendAuditEntry(ctx, first))
+	second := &domain.AuditEntry{Time: someFakeDate, MerchantID: "merchant-1", Operation: "POST /capture", Outcome: "200"}
+	require.NoError(t, s.AppendAuditEntry(ctx, second))
+
+	assert.Equal(t, int64(1), first.Sequence)
+	assert.Equal(t, int64(2), second.Sequence)
+	assert.Equal(t, first.Hash, second.PrevHash)
+
+	entries, err := s.ListAuditEntries(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.NoError(t, entries[0].Verify(nil))
+	assert.NoError(t, entries[1].Verify(entries[0]))
+
+	entries, err = s.ListAuditEntries(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, second.Hash, entries[0].Hash)
+
+	third := &domain.AuditEntry{Time: someFakeDate, Operation: "POST /refund", Outcome: "401"}
+	fourth := &domain.AuditEntry{Time: someFakeDate, MerchantID: "merchant-1", Operation: "POST /void", Outcome: "200"}
+	require.NoError(t, s.AppendAuditEntries(ctx, []*domain.AuditEntry{third, fourth}))
+	assert.Equal(t, int64(3), third.Sequence)
+	assert.Equal(t, int64(4), fourth.Sequence)
+	assert.Equal(t, second.Hash, third.PrevHash)
+	assert.Equal(t, third.Hash, fourth.PrevHash)
+
+	_, err = s.ExecContext(ctx, `update audit_log set outcome = '500' where sequence = 1`)
+	assert.Error(t, err, "audit_log is append-only")
+	_, err = s.ExecContext(ctx, `delete from audit_log where sequence = 1`)
+	assert.Error(t, err, "audit_log is append-only")
+	_, err = s.ExecContext(ctx, `truncate table audit_log`)
+	assert.Error(t, err, "audit_log is append-only")
+}
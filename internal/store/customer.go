@@ -0,0 +1,326 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+// CreateCustomer creates the customer and returns it with its ID.
+func (s *Store) CreateCustomer(ctx context.Context, customer *domain.Customer, processedDate time.Time) (*domain.Customer, error) {
+	ctx, span := tracing.Start(ctx, "Store.CreateCustomer")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("create_customer", time.Now())
+
+	c := *customer
+	err := s.QueryRowContext(ctx, `
+		insert into customer (merchant_id, email, name, created_date, updated_date)
+		values ($1, $2, $3, $4, $5)
+		returning id, created_date
+	`, c.MerchantID, nullString(c.Email), nullString(c.Name), processedDate, processedDate).
+		Scan(&c.ID, &c.CreatedDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert customer statement")
+	}
+	return &c, nil
+}
+
+// GetCustomer returns the customer given the customerID, a deleted customer is not found.
+func (s *Store) GetCustomer(ctx context.Context, customerID uuid.UUID) (*domain.Customer, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetCustomer")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_customer", time.Now())
+
+	var (
+		c           = domain.Customer{ID: customerID}
+		email, name sql.NullString
+	)
+	err := s.QueryRowContext(ctx, `
+		select merchant_id, email, name, created_date from customer where id = $1 and deleted_date is null
+	`, customerID).Scan(&c.MerchantID, &email, &name, &c.CreatedDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get customer query")
+	}
+	c.Email = email.String
+	c.Name = name.String
+	return &c, nil
+}
+
+// UpdateCustomer updates the email and the name of the customer.
+func (s *Store) UpdateCustomer(ctx context.Context, customer *domain.Customer, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.UpdateCustomer")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("update_customer", time.Now())
+
+	res, err := s.ExecContext(ctx, `
+		update customer set email = $2, name = $3, updated_date = $4 where id = $1 and deleted_date is null
+	`, customer.ID, nullString(customer.Email), nullString(customer.Name), processedDate)
+	if err != nil {
+		return errors.Wrap(err, "update customer statement")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrCustomerNotFound
+	}
+	return nil
+}
+
+// DeleteCustomer deletes the customer with its payment methods and cancels its subscriptions.
+// The customer is kept for its transactions but it is not found anymore.
+func (s *Store) DeleteCustomer(ctx context.Context, customerID uuid.UUID, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.DeleteCustomer")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("delete_customer", time.Now())
+
+	var (
+		tx  *sql.Tx
+		res sql.Result
+		err error
+	)
+
+	tx, err = s.Begin()
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if res, err = tx.ExecContext(ctx, `
+		update customer set deleted_date = $2, updated_date = $2 where id = $1 and deleted_date is null
+	`, customerID, processedDate); err != nil {
+		return errors.Wrap(err, "delete customer statement")
+	}
+	var n int64
+	if n, err = res.RowsAffected(); err != nil {
+		return errors.Wrap(err, "delete customer rows affected")
+	}
+	if n == 0 {
+		err = domain.ErrCustomerNotFound
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		update payment_method set deleted_date = $2, updated_date = $2 where customer_id = $1 and deleted_date is null
+	`, customerID, processedDate); err != nil {
+		return errors.Wrap(err, "delete payment methods statement")
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		update subscription set status = $2, updated_date = $3 where customer_id = $1 and status <> $2
+	`, customerID, domain.SubscriptionStatusCanceled, processedDate); err != nil {
+		return errors.Wrap(err, "cancel subscriptions statement")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit delete customer")
+	}
+	return nil
+}
+
+// ListCustomerTransactions returns the transactions linked to the customer, the latest first.
+func (s *Store) ListCustomerTransactions(ctx context.Context, customerID uuid.UUID) ([]*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Store.ListCustomerTransactions")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("list_customer_transactions", time.Now())
+
+	rows, err := s.QueryContext(ctx, `
+		select authorization_id from transaction where customer_id = $1 order by created_date desc
+	`, customerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "customer transactions query")
+	}
+	defer rows.Close()
+
+	var authorizationIDs []uuid.UUID
+	for rows.Next() {
+		var authorizationID uuid.UUID
+		if err := rows.Scan(&authorizationID); err != nil {
+			return nil, errors.Wrap(err, "customer transactions scanning")
+		}
+		authorizationIDs = append(authorizationIDs, authorizationID)
+	}
+	if rows.Err() != nil {
+		return nil, errors.Wrap(rows.Err(), "customer transactions rows err")
+	}
+
+	transactions := make([]*domain.Transaction, 0, len(authorizationIDs))
+	for _, authorizationID := range authorizationIDs {
+		transaction, err := s.GetTransaction(ctx, authorizationID)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+// CreatePaymentMethod stores the card of the payment method without the CVV and returns it with its ID.
+// The payment method is linked to the transaction of transactionID, if any, which stored it.
+func (s *Store) CreatePaymentMethod(ctx context.Context, paymentMethod *domain.PaymentMethod, transactionID uuid.UUID, processedDate time.Time) (*domain.PaymentMethod, error) {
+	ctx, span := tracing.Start(ctx, "Store.CreatePaymentMethod")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("create_payment_method", time.Now())
+
+	var (
+		tx     *sql.Tx
+		err    error
+		cardID uuid.UUID
+		pm     = *paymentMethod
+	)
+
+	tx, err = s.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	ps := pm.PaymentSource
+	if err = tx.QueryRowContext(ctx, `
+		insert into card (pan, pan_hash, cvv, expiry_month, expiry_year, created_date, updated_date)
+		values ($1, $2, '', $3, $4, $5, $6)
+		on conflict (pan)
+		do update set pan_hash = excluded.pan_hash, expiry_month = excluded.expiry_month, expiry_year = excluded.expiry_year
+		returning id
+	`, strings.ReplaceAll(ps.PAN, " ", ""), ps.PANHash(), strconv.Itoa(ps.Expiry.Month), strconv.Itoa(ps.Expiry.Year),
+		processedDate, processedDate).
+		Scan(&cardID); err != nil {
+		return nil, errors.Wrap(err, "insert card statement")
+	}
+
+	if err = tx.QueryRowContext(ctx, `
+		insert into payment_method (customer_id, card_id, merchant_id, card_scheme, card_country, network_transaction_id, created_date, updated_date)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		returning id, created_date
+	`, pm.CustomerID, cardID, pm.MerchantID, cardScheme(pm.Card.Scheme), pm.Card.Country, nullString(pm.NetworkTransactionID),
+		processedDate, processedDate).
+		Scan(&pm.ID, &pm.CreatedDate); err != nil {
+		return nil, errors.Wrap(err, "insert payment method statement")
+	}
+
+	if transactionID != uuid.Nil {
+		if _, err = tx.ExecContext(ctx, `update transaction set payment_method_id = $2 where id = $1`, transactionID, pm.ID); err != nil {
+			return nil, errors.Wrap(err, "link payment method to transaction statement")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit create payment method")
+	}
+
+	pm.PaymentSource.CVV = ""
+	return &pm, nil
+}
+
+const paymentMethodColumns = `pm.id, pm.customer_id, pm.merchant_id, c.pan, c.expiry_month, c.expiry_year,
+	pm.card_scheme, pm.card_country, pm.network_transaction_id, pm.created_date`
+
+// GetPaymentMethod returns the stored payment method with its card given the paymentMethodID,
+// a deleted payment method is not found.
+func (s *Store) GetPaymentMethod(ctx context.Context, paymentMethodID uuid.UUID) (*domain.PaymentMethod, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetPaymentMethod")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_payment_method", time.Now())
+
+	pm, err := scanPaymentMethod(s.QueryRowContext(ctx, `
+		select `+paymentMethodColumns+`
+		from payment_method pm join card c on c.id = pm.card_id where pm.id = $1 and pm.deleted_date is null
+	`, paymentMethodID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get payment method query")
+	}
+	return pm, nil
+}
+
+// ListPaymentMethods returns the payment methods of the customer, the oldest first.
+func (s *Store) ListPaymentMethods(ctx context.Context, customerID uuid.UUID) ([]*domain.PaymentMethod, error) {
+	ctx, span := tracing.Start(ctx, "Store.ListPaymentMethods")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("list_payment_methods", time.Now())
+
+	rows, err := s.QueryContext(ctx, `
+		select `+paymentMethodColumns+`
+		from payment_method pm join card c on c.id = pm.card_id where pm.customer_id = $1 and pm.deleted_date is null
+		order by pm.created_date
+	`, customerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list payment methods query")
+	}
+	defer rows.Close()
+
+	paymentMethods := make([]*domain.PaymentMethod, 0)
+	for rows.Next() {
+		pm, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "list payment methods scanning")
+		}
+		paymentMethods = append(paymentMethods, pm)
+	}
+	if rows.Err() != nil {
+		return nil, errors.Wrap(rows.Err(), "list payment methods rows err")
+	}
+	return paymentMethods, nil
+}
+
+// DeletePaymentMethod deletes the payment method, it can't be used anymore.
+func (s *Store) DeletePaymentMethod(ctx context.Context, paymentMethodID uuid.UUID, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.DeletePaymentMethod")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("delete_payment_method", time.Now())
+
+	res, err := s.ExecContext(ctx, `
+		update payment_method set deleted_date = $2, updated_date = $2 where id = $1 and deleted_date is null
+	`, paymentMethodID, processedDate)
+	if err != nil {
+		return errors.Wrap(err, "delete payment method statement")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrPaymentMethodNotFound
+	}
+	return nil
+}
+
+func scanPaymentMethod(row scanner) (*domain.PaymentMethod, error) {
+	var (
+		pm                                domain.PaymentMethod
+		expiryMonth, expiryYear           string
+		scheme, country, networkReference sql.NullString
+		err                               error
+	)
+	if err = row.Scan(&pm.ID, &pm.CustomerID, &pm.MerchantID, &pm.PaymentSource.PAN, &expiryMonth, &expiryYear,
+		&scheme, &country, &networkReference, &pm.CreatedDate); err != nil {
+		return nil, err
+	}
+
+	if pm.PaymentSource.Expiry.Month, err = strconv.Atoi(expiryMonth); err != nil {
+		return nil, errors.Wrap(err, "invalid expiry month")
+	}
+	if pm.PaymentSource.Expiry.Year, err = strconv.Atoi(expiryYear); err != nil {
+		return nil, errors.Wrap(err, "invalid expiry year")
+	}
+	pm.Card = domain.CardInfo{Scheme: domain.CardScheme(scheme.String), Country: country.String}
+	pm.NetworkTransactionID = networkReference.String
+	return &pm, nil
+}
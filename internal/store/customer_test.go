@@ -0,0 +1,89 @@
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+func Test_CreatePaymentMethod_Success(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+
+	customer, err := s.CreateCustomer(ctx, &domain.Customer{MerchantID: "merchant-1", Email: "jane@example.com"}, someFakeDate)
+	require.NoError(t, err)
+
+	storing := *authorization
+	storing.MerchantID = "merchant-1"
+	storing.CustomerID = customer.ID
+	storing.StorePaymentMethod = true
+	transaction, err := s.CreateTransaction(ctx, &storing, someFakeDate)
+	require.NoError(t, err)
+	require.NotEmpty(t, transaction.NetworkTransactionID)
+
+	paymentMethod, err := s.CreatePaymentMethod(ctx, &domain.PaymentMethod{
+		CustomerID:           customer.ID,
+		MerchantID:           "merchant-1",
+		PaymentSource:        authorization.PaymentSource,
+		NetworkTransactionID: transaction.NetworkTransactionID,
+	}, transaction.ID, someFakeDate)
+	require.NoError(t, err)
+
+	gotPaymentMethod, err := s.GetPaymentMethod(ctx, paymentMethod.ID)
+	require.NoError(t, err)
+	assert.Equal(t, customer.ID, gotPaymentMethod.CustomerID)
+	assert.Equal(t, transaction.NetworkTransactionID, gotPaymentMethod.NetworkTransactionID)
+	assert.Equal(t, authorization.PaymentSource.Expiry, gotPaymentMethod.PaymentSource.Expiry)
+	assert.Empty(t, gotPaymentMethod.PaymentSource.CVV)
+
+	gotTransaction, err := s.GetTransaction(ctx, transaction.AuthorizationID)
+	require.NoError(t, err)
+	assert.Equal(t, paymentMethod.ID, gotTransaction.PaymentMethodID)
+}
+
+func Test_DeleteCustomer_Success(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+
+	customer, err := s.CreateCustomer(ctx, &domain.Customer{MerchantID: "merchant-1"}, someFakeDate)
+	require.NoError(t, err)
+
+	linked := *authorization
+	linked.MerchantID = "merchant-1"
+	linked.CustomerID = customer.ID
+	transaction, err := s.CreateTransaction(ctx, &linked, someFakeDate)
+	require.NoError(t, err)
+
+	paymentMethod, err := s.CreatePaymentMethod(ctx, &domain.PaymentMethod{
+		CustomerID:    customer.ID,
+		MerchantID:    "merchant-1",
+		PaymentSource: authorization.PaymentSource,
+	}, transaction.ID, someFakeDate)
+	require.NoError(t, err)
+
+	paymentMethods, err := s.ListPaymentMethods(ctx, customer.ID)
+	require.NoError(t, err)
+	require.Len(t, paymentMethods, 1)
+	assert.Equal(t, paymentMethod.ID, paymentMethods[0].ID)
+
+	require.NoError(t, s.DeleteCustomer(ctx, customer.ID, someFakeDate))
+	require.ErrorIs(t, s.DeleteCustomer(ctx, customer.ID, someFakeDate), domain.ErrCustomerNotFound)
+
+	_, err = s.GetCustomer(ctx, customer.ID)
+	require.ErrorIs(t, err, domain.ErrCustomerNotFound)
+	_, err = s.GetPaymentMethod(ctx, paymentMethod.ID)
+	require.ErrorIs(t, err, domain.ErrPaymentMethodNotFound)
+
+	transactions, err := s.ListCustomerTransactions(ctx, customer.ID)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1, "the transactions of a deleted customer are kept")
+	assert.Equal(t, customer.ID, transactions[0].CustomerID)
+}
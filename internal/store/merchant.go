@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+// uniqueViolation is the postgres error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
+// CreateMerchant creates the merchant, domain.ErrMerchantExists is returned if the id is taken.
+func (s *Store) CreateMerchant(ctx context.Context, merchant *domain.Merchant, processedDate time.Time) (*domain.Merchant, error) {
+	ctx, span := tracing.Start(ctx, "Store.CreateMerchant")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("create_merchant", time.Now())
+
+	m := *merchant
+	err := s.QueryRowContext(ctx, `
+		insert into merchant (id, name, status, allowed_currencies, capture_policy, created_date, updated_date)
+		values ($1, $2, $3, $4, $5, $6, $6)
+		returning created_date, updated_date
+	`, m.ID, m.Name, m.Status, pq.Array(nonNilStrings(m.Settings.AllowedCurrencies)), m.Settings.CapturePolicy, processedDate).
+		Scan(&m.CreatedDate, &m.UpdatedDate)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return nil, domain.ErrMerchantExists
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "insert merchant statement")
+	}
+	return &m, nil
+}
+
+// GetMerchant returns the merchant given its id.
+func (s *Store) GetMerchant(ctx context.Context, merchantID string) (*domain.Merchant, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetMerchant")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_merchant", time.Now())
+
+	m := domain.Merchant{ID: merchantID}
+	var currencies []string
+	err := s.QueryRowContext(ctx, `
+		select name, status, allowed_currencies, capture_policy, created_date, updated_date from merchant where id = $1
+	`, merchantID).Scan(&m.Name, &m.Status, pq.Array(&currencies), &m.Settings.CapturePolicy, &m.CreatedDate, &m.UpdatedDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrMerchantNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get merchant query")
+	}
+	m.Settings.AllowedCurrencies = currencies
+	return &m, nil
+}
+
+// UpdateMerchant updates the name, the status and the settings of the merchant.
+func (s *Store) UpdateMerchant(ctx context.Context, merchant *domain.Merchant, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.UpdateMerchant")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("update_merchant", time.Now())
+
+	res, err := s.ExecContext(ctx, `
+		update merchant set name = $2, status = $3, allowed_currencies = $4, capture_policy = $5, updated_date = $6 where id = $1
+	`, merchant.ID, merchant.Name, merchant.Status, pq.Array(nonNilStrings(merchant.Settings.AllowedCurrencies)),
+		merchant.Settings.CapturePolicy, processedDate)
+	if err != nil {
+		return errors.Wrap(err, "update merchant statement")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrMerchantNotFound
+	}
+	return nil
+}
+
+// nonNilStrings returns an empty slice for nil so that it is stored as an empty array rather than null.
+func nonNilStrings(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
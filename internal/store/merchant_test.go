@@ -0,0 +1,48 @@
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+func TestStore_Merchants(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+
+	merchant := &domain.Merchant{
+		ID:     "merchant-5",
+		Name:   "Shop",
+		Status: domain.MerchantStatusActive,
+		Settings: domain.MerchantSettings{
+			AllowedCurrencies: []string{"GBP", "EUR"},
+			CapturePolicy:     domain.CapturePolicyAutomatic,
+		},
+	}
+	created, err := s.CreateMerchant(ctx, merchant, someFakeDate)
+	require.NoError(t, err)
+	assert.True(t, someFakeDate.Equal(created.CreatedDate))
+
+	_, err = s.CreateMerchant(ctx, merchant, someFakeDate)
+	assert.ErrorIs(t, err, domain.ErrMerchantExists)
+
+	created.Status = domain.MerchantStatusSuspended
+	created.Settings.AllowedCurrencies = nil
+	require.NoError(t, s.UpdateMerchant(ctx, created, someFakeDate))
+
+	got, err := s.GetMerchant(ctx, "merchant-5")
+	require.NoError(t, err)
+	assert.True(t, got.Suspended())
+	assert.Empty(t, got.Settings.AllowedCurrencies)
+	assert.Equal(t, domain.CapturePolicyAutomatic, got.Settings.CapturePolicy)
+
+	_, err = s.GetMerchant(ctx, "unknown")
+	assert.ErrorIs(t, err, domain.ErrMerchantNotFound)
+}
@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+// CreatePlan creates the subscription plan and returns it with its ID.
+func (s *Store) CreatePlan(ctx context.Context, plan *domain.Plan, processedDate time.Time) (*domain.Plan, error) {
+	ctx, span := tracing.Start(ctx, "Store.CreatePlan")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("create_plan", time.Now())
+
+	p := *plan
+	err := s.QueryRowContext(ctx, `
+		insert into plan (merchant_id, name, amount, currency, interval, interval_count, created_date, updated_date)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		returning id, created_date
+	`, p.MerchantID, p.Name, p.Amount.MinorUnits, p.Amount.Currency, p.Interval, p.IntervalCount, processedDate, processedDate).
+		Scan(&p.ID, &p.CreatedDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert plan statement")
+	}
+	return &p, nil
+}
+
+// GetPlan returns the subscription plan given the planID.
+func (s *Store) GetPlan(ctx context.Context, planID uuid.UUID) (*domain.Plan, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetPlan")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_plan", time.Now())
+
+	var (
+		p          = domain.Plan{ID: planID}
+		minorUnits int64
+	)
+	err := s.QueryRowContext(ctx, `
+		select merchant_id, name, amount, currency, interval, interval_count, created_date from plan where id = $1
+	`, planID).Scan(&p.MerchantID, &p.Name, &minorUnits, &p.Amount.Currency, &p.Interval, &p.IntervalCount, &p.CreatedDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrPlanNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get plan query")
+	}
+	p.Amount.MinorUnits = uint64(minorUnits)
+	p.Amount.Exponent = domain.CurrencyExponent(p.Amount.Currency)
+	return &p, nil
+}
+
+// CreateSubscription creates the subscription and returns it with its ID.
+func (s *Store) CreateSubscription(ctx context.Context, subscription *domain.Subscription, processedDate time.Time) (*domain.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "Store.CreateSubscription")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("create_subscription", time.Now())
+
+	sub := *subscription
+	err := s.QueryRowContext(ctx, `
+		insert into subscription (merchant_id, customer_id, plan_id, payment_method_id, status, period_start, next_billing_date,
+			failed_attempts, created_date, updated_date)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		returning id, created_date
+	`, sub.MerchantID, sub.CustomerID, sub.PlanID, sub.PaymentMethodID, sub.Status, sub.PeriodStart, sub.NextBillingDate,
+		sub.FailedAttempts, processedDate, processedDate).
+		Scan(&sub.ID, &sub.CreatedDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "insert subscription statement")
+	}
+	return &sub, nil
+}
+
+// UpdateSubscription updates the status and the billing schedule of the subscription.
+func (s *Store) UpdateSubscription(ctx context.Context, subscription *domain.Subscription, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.UpdateSubscription")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("update_subscription", time.Now())
+
+	_, err := s.ExecContext(ctx, `
+		update subscription set status = $2, period_start = $3, next_billing_date = $4, failed_attempts = $5, updated_date = $6
+		where id = $1
+	`, subscription.ID, subscription.Status, subscription.PeriodStart, subscription.NextBillingDate, subscription.FailedAttempts, processedDate)
+	if err != nil {
+		return errors.Wrap(err, "update subscription statement")
+	}
+	return nil
+}
+
+const subscriptionColumns = `id, merchant_id, customer_id, plan_id, payment_method_id, status, period_start, next_billing_date,
+	failed_attempts, created_date`
+
+// GetSubscription returns the subscription given the subscriptionID.
+func (s *Store) GetSubscription(ctx context.Context, subscriptionID uuid.UUID) (*domain.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetSubscription")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_subscription", time.Now())
+
+	sub, err := scanSubscription(s.QueryRowContext(ctx, `select `+subscriptionColumns+` from subscription where id = $1`, subscriptionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get subscription query")
+	}
+	return sub, nil
+}
+
+// DueSubscriptions returns up to limit billable subscriptions whose next billing date is before now,
+// the longest overdue first. The subscriptions of the suspended merchants are not due.
+func (s *Store) DueSubscriptions(ctx context.Context, now time.Time, limit int) ([]*domain.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "Store.DueSubscriptions")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("due_subscriptions", time.Now())
+
+	rows, err := s.QueryContext(ctx, `
+		select `+subscriptionColumns+` from subscription
+		where status in ($1, $2) and next_billing_date <= $3
+		and not exists (select 1 from merchant m where m.id = subscription.merchant_id and m.status = $4)
+		order by next_billing_date
+		limit $5
+	`, domain.SubscriptionStatusActive, domain.SubscriptionStatusPastDue, now, domain.MerchantStatusSuspended, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "due subscriptions query")
+	}
+	defer rows.Close()
+
+	var subscriptions []*domain.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "due subscriptions scanning")
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	if rows.Err() != nil {
+		return nil, errors.Wrap(rows.Err(), "due subscriptions rows err")
+	}
+	return subscriptions, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSubscription(row scanner) (*domain.Subscription, error) {
+	var sub domain.Subscription
+	if err := row.Scan(&sub.ID, &sub.MerchantID, &sub.CustomerID, &sub.PlanID, &sub.PaymentMethodID, &sub.Status,
+		&sub.PeriodStart, &sub.NextBillingDate, &sub.FailedAttempts, &sub.CreatedDate); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
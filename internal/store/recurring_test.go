@@ -0,0 +1,78 @@
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+func Test_DueSubscriptions_Success(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+
+	customer, err := s.CreateCustomer(ctx, &domain.Customer{MerchantID: "merchant-1"}, someFakeDate)
+	require.NoError(t, err)
+	paymentMethod, err := s.CreatePaymentMethod(ctx, &domain.PaymentMethod{
+		CustomerID:    customer.ID,
+		MerchantID:    "merchant-1",
+		PaymentSource: authorization.PaymentSource,
+	}, uuid.Nil, someFakeDate)
+	require.NoError(t, err)
+	plan, err := s.CreatePlan(ctx, &domain.Plan{
+		MerchantID:    "merchant-1",
+		Name:          "monthly",
+		Amount:        authorization.Amount,
+		Interval:      domain.IntervalMonth,
+		IntervalCount: 1,
+	}, someFakeDate)
+	require.NoError(t, err)
+
+	newSubscription := func(merchantID string, status domain.SubscriptionStatus, nextBillingDate time.Time) *domain.Subscription {
+		subscription, err := s.CreateSubscription(ctx, &domain.Subscription{
+			MerchantID:      merchantID,
+			CustomerID:      customer.ID,
+			PlanID:          plan.ID,
+			PaymentMethodID: paymentMethod.ID,
+			Status:          status,
+			PeriodStart:     nextBillingDate,
+			NextBillingDate: nextBillingDate,
+		}, someFakeDate)
+		require.NoError(t, err)
+		return subscription
+	}
+
+	_, err = s.CreateMerchant(ctx, &domain.Merchant{
+		ID:       "merchant-2",
+		Name:     "Suspended",
+		Status:   domain.MerchantStatusSuspended,
+		Settings: domain.MerchantSettings{CapturePolicy: domain.CapturePolicyManual},
+	}, someFakeDate)
+	require.NoError(t, err)
+
+	due := newSubscription("merchant-1", domain.SubscriptionStatusActive, someFakeDate.Add(-time.Hour))
+	pastDue := newSubscription("merchant-1", domain.SubscriptionStatusPastDue, someFakeDate)
+	newSubscription("merchant-1", domain.SubscriptionStatusActive, someFakeDate.Add(time.Hour))
+	newSubscription("merchant-1", domain.SubscriptionStatusCanceled, someFakeDate.Add(-time.Hour))
+	newSubscription("merchant-2", domain.SubscriptionStatusActive, someFakeDate.Add(-2*time.Hour))
+
+	subscriptions, err := s.DueSubscriptions(ctx, someFakeDate, 10)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	assert.Equal(t, due.ID, subscriptions[0].ID)
+	assert.Equal(t, pastDue.ID, subscriptions[1].ID)
+
+	due.Status = domain.SubscriptionStatusUnpaid
+	require.NoError(t, s.UpdateSubscription(ctx, due, someFakeDate))
+	gotSubscription, err := s.GetSubscription(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SubscriptionStatusUnpaid, gotSubscription.Status)
+}
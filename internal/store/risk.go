@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+// CountAuthorizations counts the authorization attempts and the failed ones of the card
+// identified by the PAN hash since each of the given times, in the order of the times.
+func (s *Store) CountAuthorizations(ctx context.Context, panHash string, since ...time.Time) ([]int, []int, error) {
+	ctx, span := tracing.Start(ctx, "Store.CountAuthorizations")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("count_authorizations", time.Now())
+
+	sinceDates := make([]string, len(since))
+	for i, t := range since {
+		sinceDates[i] = t.Format(time.RFC3339Nano)
+	}
+
+	rows, err := s.QueryContext(ctx, `
+		select count(p.id), count(p.id) filter (where p.status = $2)
+		from unnest($4::timestamptz[]) with ordinality as s(since, n)
+		left join (
+			payment_action p
+			join transaction t on t.id = p.transaction_id
+			join card c on c.id = t.card_id
+		) on c.pan_hash = $1 and p.type = $3 and p.created_date >= s.since
+		group by s.n
+		order by s.n
+	`, panHash, domain.PaymentActionStatusFailed, domain.PaymentActionTypeAuthorization, pq.Array(sinceDates))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "count authorizations query")
+	}
+	defer rows.Close()
+
+	attempts := make([]int, 0, len(since))
+	declines := make([]int, 0, len(since))
+	for rows.Next() {
+		var a, d int
+		if err := rows.Scan(&a, &d); err != nil {
+			return nil, nil, errors.Wrap(err, "scan authorization counts")
+		}
+		attempts = append(attempts, a)
+		declines = append(declines, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.Wrap(err, "count authorizations rows err")
+	}
+	return attempts, declines, nil
+}
@@ -0,0 +1,37 @@
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+func Test_CountAuthorizations(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+	approved := *authorization
+	approved.RequestID = uuid.NewV4()
+	_, err := s.CreateTransaction(ctx, &approved, someFakeDate.Add(-2*time.Hour))
+	require.NoError(t, err)
+
+	blocked := *authorization
+	blocked.RequestID = uuid.NewV4()
+	blocked.Risk = &domain.RiskAssessment{Decision: domain.RiskDecisionBlock, Score: 100}
+	_, err = s.CreateTransaction(ctx, &blocked, someFakeDate.Add(-10*time.Minute))
+	require.NoError(t, err)
+
+	attempts, declines, err := s.CountAuthorizations(ctx, authorization.PaymentSource.PANHash(),
+		someFakeDate.Add(-time.Hour), someFakeDate.Add(-24*time.Hour), someFakeDate)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 0}, attempts)
+	assert.Equal(t, []int{1, 1, 0}, declines)
+}
@@ -31,11 +31,11 @@ const (
 	postgresDriver = "postgres"
 	// migrations table name (payment_gateway_schema_migrations)
 	postgresMigrationsTable = "payment_gateway"
-	// postgres connection options
-	maxOpenConnections = 50
-	// must be <= maxOpenConnections
-	maxIdleConnections    = 20
-	maxConnectionLifetime = time.Second * 1800
+	// postgres connection options unless configured WithPool
+	defaultMaxOpenConnections = 50
+	// must be <= defaultMaxOpenConnections
+	defaultMaxIdleConnections    = 20
+	defaultMaxConnectionLifetime = time.Second * 1800
 )
 
 // Store is a database client wrapper
@@ -47,8 +47,33 @@ type Store struct {
 
 type connKey struct{}
 
+// Pool is the configuration of the pool of connections to the database.
+type Pool struct {
+	MaxOpenConnections int
+	// MaxIdleConnections must be <= MaxOpenConnections.
+	MaxIdleConnections    int
+	MaxConnectionLifetime time.Duration
+}
+
+// Option is a function configuration of the Store.
+type Option func(p *Pool)
+
+// WithPool configures the pool of connections to the database.
+func WithPool(pool Pool) Option {
+	return func(p *Pool) { *p = pool }
+}
+
 // New creates the postgres database connection instance
-func New(address string) (*Store, error) {
+func New(address string, opts ...Option) (*Store, error) {
+	pool := Pool{
+		MaxOpenConnections:    defaultMaxOpenConnections,
+		MaxIdleConnections:    defaultMaxIdleConnections,
+		MaxConnectionLifetime: defaultMaxConnectionLifetime,
+	}
+	for _, opt := range opts {
+		opt(&pool)
+	}
+
 	db, err := sql.Open(postgresDriver, address)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", ErrConnect, err)
@@ -59,9 +84,9 @@ func New(address string) (*Store, error) {
 		return nil, fmt.Errorf("%s: %w", ErrPing, err)
 	}
 
-	db.SetMaxIdleConns(maxIdleConnections)
-	db.SetMaxOpenConns(maxOpenConnections)
-	db.SetConnMaxLifetime(maxConnectionLifetime)
+	db.SetMaxIdleConns(pool.MaxIdleConnections)
+	db.SetMaxOpenConns(pool.MaxOpenConnections)
+	db.SetConnMaxLifetime(pool.MaxConnectionLifetime)
 
 	s := &Store{DB: db}
 
@@ -62,4 +62,27 @@ func truncateTables() {
 	if _, err := s.ExecContext(ctx, `truncate table card cascade`); err != nil {
 		log.Fatalf("truncate table card failed: %v", err)
 	}
+	if _, err := s.ExecContext(ctx, `truncate table velocity_event cascade`); err != nil {
+		log.Fatalf("truncate table velocity_event failed: %v", err)
+	}
+	if _, err := s.ExecContext(ctx, `truncate table customer cascade`); err != nil {
+		log.Fatalf("truncate table customer failed: %v", err)
+	}
+	if _, err := s.ExecContext(ctx, `truncate table plan cascade`); err != nil {
+		log.Fatalf("truncate table plan failed: %v", err)
+	}
+	if _, err := s.ExecContext(ctx, `truncate table api_key`); err != nil {
+		log.Fatalf("truncate table api_key failed: %v", err)
+	}
+	if _, err := s.ExecContext(ctx, `truncate table merchant`); err != nil {
+		log.Fatalf("truncate table merchant failed: %v", err)
+	}
+	// audit_log is append-only, its truncate trigger is disabled by the owner of the table to reset it
+	if _, err := s.ExecContext(ctx, `
+		alter table audit_log disable trigger audit_log_no_truncate;
+		truncate table audit_log;
+		alter table audit_log enable trigger audit_log_no_truncate;
+	`); err != nil {
+		log.Fatalf("truncate table audit_log failed: %v", err)
+	}
 }
@@ -2,15 +2,21 @@ package store
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
 
 	uuid "github.com/kevinburke/go.uuid"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
 	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
 )
 
 const (
@@ -28,9 +34,15 @@ var (
 
 // CreateTransaction creates the first ever transaction, it will populate the transaction table, card table and
 // the payment_action table with Authorization type and returns the transaction.
-// All authorization will be PaymentActionStatusSuccess, apart from authorisationFailurePAN.
+// All authorization will be PaymentActionStatusSuccess, apart from authorisationFailurePAN and the ones blocked
+// by the fraud screening, or PaymentActionStatusPending when the cardholder has to complete the 3-D Secure challenge.
+// The transaction of a request id the merchant has already used is returned as stored, the request is not applied.
 // Note: all the operations are executed in transaction.
 func (s *Store) CreateTransaction(ctx context.Context, authorization *domain.Authorization, processedDate time.Time) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Store.CreateTransaction")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("create_transaction", time.Now())
+
 	var (
 		tx                      *sql.Tx
 		stmtCardInsert          *sql.Stmt
@@ -57,10 +69,10 @@ func (s *Store) CreateTransaction(ctx context.Context, authorization *domain.Aut
 
 	// insert card
 	stmtCardInsert, err = tx.PrepareContext(ctx, `
-		insert into card (pan, cvv, expiry_month, expiry_year, created_date, updated_date)
-		values ($1, $2, $3, $4, $5, $6)
+		insert into card (pan, pan_hash, cvv, expiry_month, expiry_year, created_date, updated_date)
+		values ($1, $2, $3, $4, $5, $6, $7)
 		on conflict (pan)
-		do update set pan = excluded.pan
+		do update set pan_hash = excluded.pan_hash
 		returning id
 	`)
 	if err != nil {
@@ -71,42 +83,98 @@ func (s *Store) CreateTransaction(ctx context.Context, authorization *domain.Aut
 	ps := authorization.PaymentSource
 	pan := strings.ReplaceAll(ps.PAN, " ", "")
 	if err = stmtCardInsert.
-		QueryRowContext(ctx, pan, ps.CVV, strconv.Itoa(ps.Expiry.Month), strconv.Itoa(ps.Expiry.Year), processedDate, processedDate).
+		QueryRowContext(ctx, pan, ps.PANHash(), ps.CVV, strconv.Itoa(ps.Expiry.Month), strconv.Itoa(ps.Expiry.Year), processedDate, processedDate).
 		Scan(&cardID); err != nil {
 		return nil, errors.Wrap(err, "execute insert card statement")
 	}
 
-	authorizationID := uuid.NewV4()
+	newAuthorizationID := uuid.NewV4()
+	authorizationID := newAuthorizationID
 
 	// insert transaction
 	stmtTransactionInsert, err = tx.PrepareContext(ctx, `
-		insert into transaction (card_id, authorization_id, request_id, amount, currency, created_date, updated_date)
-		values ($1, $2, $3, $4, $5, $6, $7)
-		on conflict (request_id)
+		insert into transaction (card_id, authorization_id, request_id, amount, currency, merchant_id, card_scheme, card_country,
+			fx_rate, settlement_currency, fx_locked_date, risk_decision, risk_score, risk_reasons,
+			authentication_status, authentication_eci, liability_shift, initiator, payment_method_id, network_transaction_id,
+			customer_id, created_date, updated_date)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+		on conflict (merchant_id, request_id)
 		do update set request_id = excluded.request_id
-		returning id, authorization_id
+		returning id, authorization_id, network_transaction_id
 	`)
 	if err != nil {
 		return nil, errors.Wrap(err, "prepare insert authorization statement")
 	}
 	defer stmtTransactionInsert.Close()
 
+	var fxRate, settlementCurrency, fxLockedDate interface{}
+	settlementAmount := authorization.Amount
+	if r := authorization.FXRate; r != nil {
+		fxRate = r.Rate.FloatString(domain.FXRatePrecision)
+		settlementCurrency = r.To
+		fxLockedDate = r.LockedDate
+		settlementAmount = r.Convert(authorization.Amount)
+	}
+
+	var riskDecision, riskScore, riskReasons interface{}
+	if r := authorization.Risk; r != nil {
+		riskDecision = r.Decision
+		riskScore = r.Score
+		riskReasons = pq.Array(r.Reasons)
+	}
+
+	var authenticationStatus, authenticationECI, liabilityShift interface{}
+	if a := authorization.Authentication; a != nil {
+		authenticationStatus = a.Status
+		authenticationECI = nullString(a.ECI)
+		liabilityShift = a.LiabilityShift
+	}
+
+	initiator := authorization.Initiator
+	if initiator == "" {
+		initiator = domain.InitiatorCustomer
+	}
+	var paymentMethodID, customerID interface{}
+	if authorization.PaymentMethodID != uuid.Nil {
+		paymentMethodID = authorization.PaymentMethodID
+	}
+	if authorization.CustomerID != uuid.Nil {
+		customerID = authorization.CustomerID
+	}
+
+	networkTransactionID, err := newNetworkTransactionID()
+	if err != nil {
+		return nil, errors.Wrap(err, "generate network transaction id")
+	}
+
 	if err = stmtTransactionInsert.
-		QueryRowContext(ctx, cardID, authorizationID, authorization.RequestID, authorization.Amount.MinorUnits, authorization.Amount.Currency, processedDate, processedDate).
-		Scan(&transactionID, &authorizationID); err != nil {
+		QueryRowContext(ctx, cardID, authorizationID, authorization.RequestID, authorization.Amount.MinorUnits, authorization.Amount.Currency,
+			authorization.MerchantID, cardScheme(authorization.Card.Scheme), authorization.Card.Country,
+			fxRate, settlementCurrency, fxLockedDate, riskDecision, riskScore, riskReasons,
+			authenticationStatus, authenticationECI, liabilityShift, initiator, paymentMethodID, networkTransactionID,
+			customerID, processedDate, processedDate).
+		Scan(&transactionID, &authorizationID, &networkTransactionID); err != nil {
 		return nil, errors.Wrap(err, "execute insert authorization statement")
 	}
+	// the conflicting transaction of the request id keeps its authorization id, it is returned as stored
+	if authorizationID != newAuthorizationID {
+		_ = tx.Rollback()
+		return s.GetTransactionByRequestID(ctx, authorization.MerchantID, authorization.RequestID)
+	}
 
 	status := domain.PaymentActionStatusSuccess
-	if pan == strings.ReplaceAll(authorisationFailurePAN, " ", "") {
+	switch {
+	case pan == strings.ReplaceAll(authorisationFailurePAN, " ", "") || authorization.Risk.Blocked():
 		status = domain.PaymentActionStatusFailed
+	case authorization.Authentication.ChallengeRequired():
+		status = domain.PaymentActionStatusPending
 	}
 
 	// insert payment action
 	stmtPaymentActionInsert, err = tx.PrepareContext(ctx, `
-		insert into payment_action (id, type, status, amount, currency, request_id, transaction_id, created_date, updated_date)
-		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		on conflict (request_id)
+		insert into payment_action (id, type, status, amount, currency, settlement_amount, settlement_currency, request_id, transaction_id, created_date, updated_date)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		on conflict (transaction_id, request_id)
 		do update set request_id = excluded.request_id
 		returning id, created_date
 	`)
@@ -118,24 +186,34 @@ func (s *Store) CreateTransaction(ctx context.Context, authorization *domain.Aut
 	if err = stmtPaymentActionInsert.
 		QueryRowContext(ctx, authorizationID, domain.PaymentActionTypeAuthorization,
 			status, authorization.Amount.MinorUnits, authorization.Amount.Currency,
+			settlementAmount.MinorUnits, settlementAmount.Currency,
 			authorization.RequestID, transactionID, processedDate, processedDate).
 		Scan(&paymentActionID, &authorizationDate); err != nil {
 		return nil, errors.Wrap(err, "execute insert payment action statement")
 	}
 
 	paymentAction := &domain.PaymentAction{
-		Type:          domain.PaymentActionTypeAuthorization,
-		Status:        status,
-		ProcessedDate: authorizationDate.Time,
-		Amount:        &authorization.Amount,
-		RequestID:     authorization.RequestID,
+		Type:             domain.PaymentActionTypeAuthorization,
+		Status:           status,
+		ProcessedDate:    authorizationDate.Time,
+		Amount:           &authorization.Amount,
+		SettlementAmount: &settlementAmount,
+		RequestID:        authorization.RequestID,
 	}
 
 	t := &domain.Transaction{
-		ID:              transactionID,
-		RequestID:       authorization.RequestID,
-		AuthorizationID: authorizationID,
-		Amount:          authorization.Amount,
+		ID:                   transactionID,
+		RequestID:            authorization.RequestID,
+		AuthorizationID:      authorizationID,
+		MerchantID:           authorization.MerchantID,
+		Card:                 authorization.Card,
+		Amount:               authorization.Amount,
+		FXRate:               authorization.FXRate,
+		Risk:                 authorization.Risk,
+		Authentication:       authorization.Authentication,
+		Initiator:            initiator,
+		PaymentMethodID:      authorization.PaymentMethodID,
+		NetworkTransactionID: networkTransactionID,
 		PaymentActionSummary: []*domain.PaymentAction{
 			paymentAction,
 		},
@@ -150,11 +228,16 @@ func (s *Store) CreateTransaction(ctx context.Context, authorization *domain.Aut
 	return t, nil
 }
 
-// CreatePaymentAction will create payment action of a type for a particular transaction.
+// CreatePaymentAction will create payment action of a type for a particular transaction, with the amount converted to the
+// settlement currency and the merchant fee if any.
 // All payment actions will be PaymentActionStatusSuccess apart from captureFailurePAN and refundFailurePAN.
 // A small query required to get the card PAN by transaction_id to cross check the PAN.
 func (s *Store) CreatePaymentAction(ctx context.Context, transactionID, requestID uuid.UUID, paymentActionType domain.PaymentActionType,
-	amount *domain.Amount, processedDate time.Time) error {
+	amount, settlementAmount, fee *domain.Amount, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.CreatePaymentAction")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("create_payment_action", time.Now())
+
 	var (
 		tx                      *sql.Tx
 		stmtPaymentActionInsert *sql.Stmt
@@ -183,9 +266,10 @@ func (s *Store) CreatePaymentAction(ctx context.Context, transactionID, requestI
 
 	// insert payment action
 	stmtPaymentActionInsert, err = tx.PrepareContext(ctx, `
-		insert into payment_action (type, status, amount, currency, request_id, transaction_id, created_date, updated_date)
-		values ($1, $2, $3, $4, $5, $6, $7, $8)
-		on conflict (request_id)
+		insert into payment_action (type, status, amount, currency, settlement_amount, settlement_currency, fee_amount, fee_currency,
+			request_id, transaction_id, created_date, updated_date)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		on conflict (transaction_id, request_id)
 		do update set request_id = excluded.request_id
 		returning id
 	`)
@@ -194,11 +278,19 @@ func (s *Store) CreatePaymentAction(ctx context.Context, transactionID, requestI
 	}
 	defer stmtPaymentActionInsert.Close()
 
-	var minorUnits, currency interface{}
+	var minorUnits, currency, settlementMinorUnits, settlementCurrency, feeMinorUnits, feeCurrency interface{}
 	if amount != nil {
 		minorUnits = amount.MinorUnits
 		currency = amount.Currency
 	}
+	if settlementAmount != nil {
+		settlementMinorUnits = settlementAmount.MinorUnits
+		settlementCurrency = settlementAmount.Currency
+	}
+	if fee != nil {
+		feeMinorUnits = fee.MinorUnits
+		feeCurrency = fee.Currency
+	}
 
 	paymentActionStatus := domain.PaymentActionStatusSuccess
 	if pan, ok := panFailureMap[paymentActionType]; ok {
@@ -209,7 +301,7 @@ func (s *Store) CreatePaymentAction(ctx context.Context, transactionID, requestI
 
 	if err = stmtPaymentActionInsert.
 		QueryRowContext(ctx, paymentActionType,
-			paymentActionStatus, minorUnits, currency,
+			paymentActionStatus, minorUnits, currency, settlementMinorUnits, settlementCurrency, feeMinorUnits, feeCurrency,
 			requestID, transactionID, processedDate, processedDate).
 		Scan(&paymentActionID); err != nil {
 		return errors.Wrap(err, "execute insert payment action statement")
@@ -224,11 +316,46 @@ func (s *Store) CreatePaymentAction(ctx context.Context, transactionID, requestI
 }
 
 // GetTransaction returns the transaction given the authorizationID, also with the PaymentActionSummary.
+// It is not scoped to a merchant, the requests of the merchants use GetMerchantTransaction.
 func (s *Store) GetTransaction(ctx context.Context, authorizationID uuid.UUID) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetTransaction")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_transaction", time.Now())
+
+	return s.getTransaction(ctx, `t.authorization_id = $1`, authorizationID)
+}
+
+// GetMerchantTransaction returns the transaction of the merchant given the authorizationID, also with the PaymentActionSummary.
+// domain.ErrTransactionNotFound is returned if the transaction is the one of another merchant.
+func (s *Store) GetMerchantTransaction(ctx context.Context, merchantID string, authorizationID uuid.UUID) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetMerchantTransaction")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_merchant_transaction", time.Now())
+
+	return s.getTransaction(ctx, `t.authorization_id = $1 and t.merchant_id = $2`, authorizationID, merchantID)
+}
+
+// GetTransactionByRequestID returns the transaction the merchant has authorized with the requestID, also with the PaymentActionSummary.
+// domain.ErrTransactionNotFound is returned if the merchant has not authorized any transaction with it.
+func (s *Store) GetTransactionByRequestID(ctx context.Context, merchantID string, requestID uuid.UUID) (*domain.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "Store.GetTransactionByRequestID")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("get_transaction_by_request_id", time.Now())
+
+	return s.getTransaction(ctx, `t.merchant_id = $1 and t.request_id = $2`, merchantID, requestID)
+}
+
+// getTransaction returns the transaction matching the where condition of the args.
+func (s *Store) getTransaction(ctx context.Context, where string, args ...interface{}) (*domain.Transaction, error) {
 	rows, err := s.QueryContext(ctx, `
-		select t.id as t_id, t.request_id as t_request_id, t.amount, t.currency, p.id as p_id, p.type, p.status, p.amount, p.currency, p.request_id as p_request_id, p.updated_date
-		from transaction t JOIN payment_action p ON t.id = p.transaction_id where t.authorization_id = $1 order by p.created_date;
-		`, authorizationID)
+		select t.id as t_id, t.authorization_id, t.request_id as t_request_id, t.amount, t.currency, t.merchant_id, t.card_scheme, t.card_country,
+		t.fx_rate, t.settlement_currency as t_settlement_currency, t.fx_locked_date, t.risk_decision, t.risk_score, t.risk_reasons,
+		t.authentication_status, t.authentication_eci, t.liability_shift,
+		t.initiator, t.payment_method_id, t.network_transaction_id, t.customer_id,
+		p.id as p_id, p.type, p.status, p.amount, p.currency, p.settlement_amount, p.settlement_currency,
+		p.fee_amount, p.fee_currency, p.request_id as p_request_id, p.updated_date
+		from transaction t JOIN payment_action p ON t.id = p.transaction_id where `+where+` order by p.created_date;
+		`, args...)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "get transaction query")
@@ -239,21 +366,48 @@ func (s *Store) GetTransaction(ctx context.Context, authorizationID uuid.UUID) (
 
 	var (
 		transactionID              uuid.UUID
+		authorizationID            uuid.UUID
 		transactionRequestID       uuid.UUID
 		transactionAmount          sql.NullInt64
 		transactionCurrency        sql.NullString
+		transactionMerchantID      sql.NullString
+		transactionCardScheme      sql.NullString
+		transactionCardCountry     sql.NullString
+		transactionFXRate          sql.NullString
+		transactionSettlementCurr  sql.NullString
+		transactionFXLockedDate    sql.NullTime
+		transactionRiskDecision    sql.NullString
+		transactionRiskScore       sql.NullInt64
+		transactionRiskReasons     []string
+		transactionAuthStatus      sql.NullString
+		transactionAuthECI         sql.NullString
+		transactionLiabilityShift  sql.NullBool
+		transactionInitiator       sql.NullString
+		transactionPaymentMethodID uuid.NullUUID
+		transactionNetworkTxnID    sql.NullString
+		transactionCustomerID      uuid.NullUUID
 		paymentActionID            uuid.UUID
 		paymentActionType          sql.NullString
 		paymentActionStatus        sql.NullString
 		paymentActionAmount        sql.NullInt64
 		paymentActionCurrency      sql.NullString
+		paymentActionSettlementAmt sql.NullInt64
+		paymentActionSettlementCur sql.NullString
+		paymentActionFeeAmount     sql.NullInt64
+		paymentActionFeeCurrency   sql.NullString
 		paymentActionRequestID     uuid.UUID
 		paymentActionProcessedDate sql.NullTime
 	)
 
 	for rows.Next() {
-		if err := rows.Scan(&transactionID, &transactionRequestID, &transactionAmount, &transactionCurrency, &paymentActionID,
-			&paymentActionType, &paymentActionStatus, &paymentActionAmount, &paymentActionCurrency, &paymentActionRequestID, &paymentActionProcessedDate); err != nil {
+		if err := rows.Scan(&transactionID, &authorizationID, &transactionRequestID, &transactionAmount, &transactionCurrency,
+			&transactionMerchantID, &transactionCardScheme, &transactionCardCountry,
+			&transactionFXRate, &transactionSettlementCurr, &transactionFXLockedDate,
+			&transactionRiskDecision, &transactionRiskScore, pq.Array(&transactionRiskReasons),
+			&transactionAuthStatus, &transactionAuthECI, &transactionLiabilityShift,
+			&transactionInitiator, &transactionPaymentMethodID, &transactionNetworkTxnID, &transactionCustomerID, &paymentActionID,
+			&paymentActionType, &paymentActionStatus, &paymentActionAmount, &paymentActionCurrency,
+			&paymentActionSettlementAmt, &paymentActionSettlementCur, &paymentActionFeeAmount, &paymentActionFeeCurrency, &paymentActionRequestID, &paymentActionProcessedDate); err != nil {
 			return nil, errors.Wrap(err, "get transaction scanning")
 		}
 		exponent := 2
@@ -266,12 +420,32 @@ func (s *Store) GetTransaction(ctx context.Context, authorizationID uuid.UUID) (
 			}
 		}
 
+		var settlementAmount *domain.Amount
+		if paymentActionSettlementAmt.Valid {
+			settlementAmount = &domain.Amount{
+				MinorUnits: uint64(paymentActionSettlementAmt.Int64),
+				Currency:   paymentActionSettlementCur.String,
+				Exponent:   domain.CurrencyExponent(paymentActionSettlementCur.String),
+			}
+		}
+
+		var fee *domain.Amount
+		if paymentActionFeeAmount.Valid {
+			fee = &domain.Amount{
+				MinorUnits: uint64(paymentActionFeeAmount.Int64),
+				Currency:   paymentActionFeeCurrency.String,
+				Exponent:   domain.CurrencyExponent(paymentActionFeeCurrency.String),
+			}
+		}
+
 		paymentAction := &domain.PaymentAction{
-			Type:          domain.PaymentActionType(paymentActionType.String),
-			Status:        domain.PaymentActionStatus(paymentActionStatus.String),
-			ProcessedDate: paymentActionProcessedDate.Time,
-			Amount:        amount,
-			RequestID:     paymentActionRequestID,
+			Type:             domain.PaymentActionType(paymentActionType.String),
+			Status:           domain.PaymentActionStatus(paymentActionStatus.String),
+			ProcessedDate:    paymentActionProcessedDate.Time,
+			Amount:           amount,
+			SettlementAmount: settlementAmount,
+			Fee:              fee,
+			RequestID:        paymentActionRequestID,
 		}
 
 		paymentActionSummary = append(paymentActionSummary, paymentAction)
@@ -285,18 +459,138 @@ func (s *Store) GetTransaction(ctx context.Context, authorizationID uuid.UUID) (
 		return nil, domain.ErrTransactionNotFound
 	}
 
+	var fxRate *domain.FXRate
+	if transactionFXRate.Valid {
+		rate, ok := new(big.Rat).SetString(transactionFXRate.String)
+		if !ok {
+			return nil, errors.Errorf("invalid fx rate %q", transactionFXRate.String)
+		}
+		fxRate = &domain.FXRate{
+			From:       transactionCurrency.String,
+			To:         transactionSettlementCurr.String,
+			Rate:       rate,
+			LockedDate: transactionFXLockedDate.Time,
+		}
+	}
+
+	var risk *domain.RiskAssessment
+	if transactionRiskDecision.Valid {
+		risk = &domain.RiskAssessment{
+			Decision: domain.RiskDecision(transactionRiskDecision.String),
+			Score:    int(transactionRiskScore.Int64),
+			Reasons:  transactionRiskReasons,
+		}
+	}
+
+	var authentication *domain.Authentication
+	if transactionAuthStatus.Valid {
+		authentication = &domain.Authentication{
+			Status:         domain.AuthenticationStatus(transactionAuthStatus.String),
+			ECI:            transactionAuthECI.String,
+			LiabilityShift: transactionLiabilityShift.Bool,
+		}
+	}
+
 	transaction := &domain.Transaction{
 		ID:              transactionID,
 		RequestID:       transactionRequestID,
 		AuthorizationID: authorizationID,
+		MerchantID:      transactionMerchantID.String,
+		Card: domain.CardInfo{
+			Scheme:  domain.CardScheme(transactionCardScheme.String),
+			Country: transactionCardCountry.String,
+		},
 		Amount: domain.Amount{
 			MinorUnits: uint64(transactionAmount.Int64),
 			Currency:   transactionCurrency.String,
 			Exponent:   2,
 		},
+		FXRate:               fxRate,
+		Risk:                 risk,
+		Authentication:       authentication,
+		Initiator:            domain.Initiator(transactionInitiator.String),
+		PaymentMethodID:      transactionPaymentMethodID.UUID,
+		NetworkTransactionID: transactionNetworkTxnID.String,
+		CustomerID:           transactionCustomerID.UUID,
 		PaymentActionSummary: paymentActionSummary,
 	}
 	transaction.Amounts()
 
 	return transaction, nil
 }
+
+// UpdateAuthentication stores the result of the 3-D Secure challenge of the transaction, only once: the result of
+// a challenge which is not required anymore is rejected with domain.ErrNotAwaitingAuthentication.
+func (s *Store) UpdateAuthentication(ctx context.Context, transactionID uuid.UUID, authentication domain.Authentication, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.UpdateAuthentication")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("update_authentication", time.Now())
+
+	res, err := s.ExecContext(ctx, `
+		update transaction set authentication_status = $2, authentication_eci = $3, liability_shift = $4, updated_date = $5
+		where id = $1 and authentication_status = $6
+	`, transactionID, authentication.Status, nullString(authentication.ECI), authentication.LiabilityShift, processedDate,
+		domain.AuthenticationStatusChallengeRequired)
+	if err != nil {
+		return errors.Wrap(err, "update authentication statement")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "update authentication rows affected")
+	}
+	if n == 0 {
+		return domain.ErrNotAwaitingAuthentication
+	}
+	return nil
+}
+
+// CompleteAuthorization sets the status of the pending authorization of the transaction once the
+// 3-D Secure challenge has been completed, the processed date becomes the authorization date.
+func (s *Store) CompleteAuthorization(ctx context.Context, transactionID uuid.UUID, status domain.PaymentActionStatus, processedDate time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.CompleteAuthorization")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("complete_authorization", time.Now())
+
+	_, err := s.ExecContext(ctx, `
+		update payment_action set status = $2, updated_date = $3
+		where transaction_id = $1 and type = $4 and status = $5
+	`, transactionID, status, processedDate, domain.PaymentActionTypeAuthorization, domain.PaymentActionStatusPending)
+	if err != nil {
+		return errors.Wrap(err, "complete authorization statement")
+	}
+	return nil
+}
+
+// newNetworkTransactionID simulates the 15 digits reference the card network gives to an authorization.
+func newNetworkTransactionID() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1e15))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%015d", n), nil
+}
+
+// nullString stores an empty string as null.
+func nullString(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
+
+// cardScheme defaults the card scheme to domain.CardSchemeUnknown when it has not been looked up.
+func cardScheme(scheme domain.CardScheme) domain.CardScheme {
+	if scheme == "" {
+		return domain.CardSchemeUnknown
+	}
+	return scheme
+}
+
+// nullTime returns the time if it is valid, else nil.
+func nullTime(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := v.Time
+	return &t
+}
@@ -46,11 +46,12 @@ func Test_CreateTransaction_Success(t *testing.T) {
 		Amount:        authorization.Amount,
 		PaymentActionSummary: []*domain.PaymentAction{
 			{
-				Type:          domain.PaymentActionTypeAuthorization,
-				Status:        domain.PaymentActionStatusSuccess,
-				ProcessedDate: someFakeDate,
-				Amount:        &authorization.Amount,
-				RequestID:     authorization.RequestID,
+				Type:             domain.PaymentActionTypeAuthorization,
+				Status:           domain.PaymentActionStatusSuccess,
+				ProcessedDate:    someFakeDate,
+				Amount:           &authorization.Amount,
+				SettlementAmount: &authorization.Amount,
+				RequestID:        authorization.RequestID,
 			},
 		},
 	}
@@ -104,11 +105,12 @@ func Test_GetTransaction_Success(t *testing.T) {
 				Amount:          createdTransaction.Amount,
 				PaymentActionSummary: []*domain.PaymentAction{
 					{
-						Type:          domain.PaymentActionTypeAuthorization,
-						Status:        domain.PaymentActionStatusSuccess,
-						ProcessedDate: someFakeDate,
-						Amount:        &createdTransaction.Amount,
-						RequestID:     createdTransaction.RequestID,
+						Type:             domain.PaymentActionTypeAuthorization,
+						Status:           domain.PaymentActionStatusSuccess,
+						ProcessedDate:    someFakeDate,
+						Amount:           &createdTransaction.Amount,
+						SettlementAmount: &createdTransaction.Amount,
+						RequestID:        createdTransaction.RequestID,
 					},
 				},
 			},
@@ -131,6 +133,77 @@ func Test_GetTransaction_Success(t *testing.T) {
 	}
 }
 
+func Test_GetMerchantTransaction(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+	merchantAuthorization := *authorization
+	merchantAuthorization.MerchantID = "merchant-1"
+	createdTransaction, err := s.CreateTransaction(ctx, &merchantAuthorization, someFakeDate)
+	require.NoError(t, err)
+
+	gotTransaction, err := s.GetMerchantTransaction(ctx, "merchant-1", createdTransaction.AuthorizationID)
+	require.NoError(t, err)
+	assert.Equal(t, createdTransaction.ID, gotTransaction.ID)
+
+	_, err = s.GetMerchantTransaction(ctx, "merchant-2", createdTransaction.AuthorizationID)
+	assert.ErrorIs(t, err, domain.ErrTransactionNotFound)
+}
+
+func Test_GetTransactionByRequestID(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+	merchantAuthorization := *authorization
+	merchantAuthorization.MerchantID = "merchant-1"
+	createdTransaction, err := s.CreateTransaction(ctx, &merchantAuthorization, someFakeDate)
+	require.NoError(t, err)
+
+	gotTransaction, err := s.GetTransactionByRequestID(ctx, "merchant-1", authorization.RequestID)
+	require.NoError(t, err)
+	assert.Equal(t, createdTransaction.AuthorizationID, gotTransaction.AuthorizationID)
+
+	_, err = s.GetTransactionByRequestID(ctx, "merchant-2", authorization.RequestID)
+	assert.ErrorIs(t, err, domain.ErrTransactionNotFound)
+}
+
+func Test_CreateTransaction_RequestIDOfAnotherMerchant(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+	firstAuthorization := *authorization
+	firstAuthorization.MerchantID = "merchant-1"
+	firstTransaction, err := s.CreateTransaction(ctx, &firstAuthorization, someFakeDate)
+	require.NoError(t, err)
+
+	secondAuthorization := *authorization
+	secondAuthorization.MerchantID = "merchant-2"
+	secondTransaction, err := s.CreateTransaction(ctx, &secondAuthorization, someFakeDate)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, firstTransaction.AuthorizationID, secondTransaction.AuthorizationID)
+	assert.NotEqual(t, firstTransaction.ID, secondTransaction.ID)
+}
+
+func Test_CreateTransaction_Replay(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+	firstTransaction, err := s.CreateTransaction(ctx, authorization, someFakeDate)
+	require.NoError(t, err)
+
+	// the replay of the request id returns the stored transaction rather than the one of the new request
+	replay := *authorization
+	replay.Amount.MinorUnits++
+	replayedTransaction, err := s.CreateTransaction(ctx, &replay, someFakeDate.Add(time.Hour))
+	require.NoError(t, err)
+
+	assert.Equal(t, firstTransaction.AuthorizationID, replayedTransaction.AuthorizationID)
+	assert.Equal(t, authorization.Amount.MinorUnits, replayedTransaction.Amount.MinorUnits)
+	require.Len(t, replayedTransaction.PaymentActionSummary, 1)
+	assert.True(t, someFakeDate.Equal(replayedTransaction.PaymentActionSummary[0].ProcessedDate))
+}
+
 func Test_CreatePaymentAction_Success(t *testing.T) {
 	t.Cleanup(truncateTables)
 
@@ -160,11 +233,12 @@ func Test_CreatePaymentAction_Success(t *testing.T) {
 				Amount:          createdTransaction.Amount,
 				PaymentActionSummary: []*domain.PaymentAction{
 					{
-						Type:          domain.PaymentActionTypeAuthorization,
-						Status:        domain.PaymentActionStatusSuccess,
-						ProcessedDate: someFakeDate,
-						Amount:        &createdTransaction.Amount,
-						RequestID:     createdTransaction.RequestID,
+						Type:             domain.PaymentActionTypeAuthorization,
+						Status:           domain.PaymentActionStatusSuccess,
+						ProcessedDate:    someFakeDate,
+						Amount:           &createdTransaction.Amount,
+						SettlementAmount: &createdTransaction.Amount,
+						RequestID:        createdTransaction.RequestID,
 					},
 					{
 						Type:          domain.PaymentActionTypeVoid,
@@ -181,7 +255,7 @@ func Test_CreatePaymentAction_Success(t *testing.T) {
 	for _, testCase := range testCases {
 		tc := testCase
 		t.Run(tc.description, func(t *testing.T) {
-			err := s.CreatePaymentAction(ctx, createdTransaction.ID, voidRequestID, domain.PaymentActionTypeVoid, nil, voidFakeDate)
+			err := s.CreatePaymentAction(ctx, createdTransaction.ID, voidRequestID, domain.PaymentActionTypeVoid, nil, nil, nil, voidFakeDate)
 			require.NoError(t, err)
 
 			gotTransaction, err := s.GetTransaction(ctx, tc.authorizationID)
@@ -196,3 +270,32 @@ func Test_CreatePaymentAction_Success(t *testing.T) {
 		})
 	}
 }
+
+func Test_CompleteAuthorization_Success(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	var (
+		ctx           = context.Background()
+		completedDate = someFakeDate.Add(1 * time.Minute)
+	)
+
+	pending := *authorization
+	pending.Authentication = &domain.Authentication{Status: domain.AuthenticationStatusChallengeRequired}
+	createdTransaction, err := s.CreateTransaction(ctx, &pending, someFakeDate)
+	require.NoError(t, err)
+	require.True(t, createdTransaction.RequiresAction())
+
+	authentication := domain.NewAuthenticationResult(domain.CardSchemeVisa, domain.AuthenticationStatusAuthenticated)
+	require.NoError(t, s.UpdateAuthentication(ctx, createdTransaction.ID, authentication, completedDate))
+	failed := domain.NewAuthenticationResult(domain.CardSchemeVisa, domain.AuthenticationStatusFailed)
+	assert.ErrorIs(t, s.UpdateAuthentication(ctx, createdTransaction.ID, failed, completedDate), domain.ErrNotAwaitingAuthentication,
+		"the result of the challenge can't be overwritten")
+	require.NoError(t, s.CompleteAuthorization(ctx, createdTransaction.ID, domain.PaymentActionStatusSuccess, completedDate))
+
+	gotTransaction, err := s.GetTransaction(ctx, createdTransaction.AuthorizationID)
+	require.NoError(t, err)
+	assert.False(t, gotTransaction.RequiresAction())
+	assert.Equal(t, &authentication, gotTransaction.Authentication)
+	assert.Equal(t, &completedDate, gotTransaction.AuthorizationDate())
+	assert.Equal(t, createdTransaction.Amount, gotTransaction.AuthorizedAmount)
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/metrics"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+	"github.com/jeffreyyong/payment-gateway/internal/velocity"
+)
+
+// ReserveVelocityEvent records an authorization attempt under each of the velocity keys if check allows it given
+// the sums of their events. The keys are locked for the transaction, in order so that the concurrent reservations
+// don't deadlock, so that the concurrent attempts of a key are checked one after the other.
+func (s *Store) ReserveVelocityEvent(ctx context.Context, keys []string, amount domain.Amount, at time.Time,
+	check velocity.CheckFunc) error {
+	ctx, span := tracing.Start(ctx, "Store.ReserveVelocityEvent")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("reserve_velocity_event", time.Now())
+
+	var (
+		tx  *sql.Tx
+		err error
+	)
+
+	tx, err = s.Begin()
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		if _, err = tx.ExecContext(ctx, `select pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+			return errors.Wrap(err, "lock velocity key statement")
+		}
+	}
+
+	if err = check(func(key, currency string, since time.Time) (int, uint64, error) {
+		return sumVelocityEvents(ctx, tx, key, currency, since)
+	}); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if _, err = tx.ExecContext(ctx, `
+			insert into velocity_event (key, amount, currency, created_date)
+			values ($1, $2, $3, $4)
+		`, key, amount.MinorUnits, amount.Currency, at); err != nil {
+			return errors.Wrap(err, "insert velocity event")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit transaction")
+	}
+	return nil
+}
+
+// sumVelocityEvents returns the number of events of the key since the given time,
+// and the sum of their amounts in the currency.
+func sumVelocityEvents(ctx context.Context, tx *sql.Tx, key, currency string, since time.Time) (int, uint64, error) {
+	var (
+		count  int
+		amount int64
+	)
+	if err := tx.QueryRowContext(ctx, `
+		select count(*), coalesce(sum(amount) filter (where currency = $2), 0)
+		from velocity_event
+		where key = $1 and created_date >= $3
+	`, key, currency, since).Scan(&count, &amount); err != nil {
+		return 0, 0, errors.Wrap(err, "sum velocity events query")
+	}
+	return count, uint64(amount), nil
+}
+
+// PruneVelocityEvents deletes the events older than the given time.
+func (s *Store) PruneVelocityEvents(ctx context.Context, before time.Time) error {
+	ctx, span := tracing.Start(ctx, "Store.PruneVelocityEvents")
+	defer span.End()
+	defer metrics.ObserveStoreQuery("prune_velocity_events", time.Now())
+
+	if _, err := s.ExecContext(ctx, `delete from velocity_event where created_date < $1`, before); err != nil {
+		return errors.Wrap(err, "delete velocity events")
+	}
+	return nil
+}
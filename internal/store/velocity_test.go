@@ -0,0 +1,62 @@
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/velocity"
+)
+
+func Test_ReserveVelocityEvent_Concurrent(t *testing.T) {
+	t.Cleanup(truncateTables)
+
+	ctx := context.Background()
+	amount := domain.Amount{MinorUnits: 100, Currency: "GBP", Exponent: 2}
+	keys := []string{"merchant:merchant-1", "card:abc"}
+
+	// the concurrent reservations of the keys are checked one after the other
+	var (
+		wg       sync.WaitGroup
+		reserved int32
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.ReserveVelocityEvent(ctx, keys, amount, someFakeDate, func(sum velocity.SumFunc) error {
+				count, _, err := sum("card:abc", amount.Currency, someFakeDate.Add(-time.Hour))
+				if err != nil {
+					return err
+				}
+				if count >= 3 {
+					return domain.ErrVelocityLimitExceeded
+				}
+				return nil
+			})
+			if err == nil {
+				atomic.AddInt32(&reserved, 1)
+			} else {
+				assert.ErrorIs(t, err, domain.ErrVelocityLimitExceeded)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), reserved)
+
+	require.NoError(t, s.ReserveVelocityEvent(ctx, keys, amount, someFakeDate, func(sum velocity.SumFunc) error {
+		count, total, err := sum("merchant:merchant-1", amount.Currency, someFakeDate.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 3, count, "the rejected reservations are not recorded")
+		assert.Equal(t, uint64(300), total)
+		return nil
+	}))
+}
@@ -0,0 +1,102 @@
+// Package tracing sets up the OpenTelemetry tracing of the payment gateway.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+const instrumentationName = "github.com/jeffreyyong/payment-gateway"
+
+// exporters
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Config is the configuration of the tracing.
+type Config struct {
+	// Exporter is where the spans are exported: otlp, stdout or none when empty.
+	Exporter string
+	// Endpoint is the host:port of the OTLP/HTTP collector, the default of the exporter if empty.
+	Endpoint string
+	// Insecure exports to the OTLP/HTTP collector without TLS.
+	Insecure bool
+	// SampleRatio is the ratio of the traces sampled when they don't have a sampled parent, all of them if zero.
+	SampleRatio float64
+}
+
+// Init sets the global W3C trace context propagator and the global tracer provider exporting the spans
+// of the service with the exporter of the config. The returned func flushes and stops the exporter.
+func Init(ctx context.Context, serviceName string, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+}
+
+// Start starts a span as a child of the span of the context if any. The trace id is added to the logger
+// of the context when the span starts a trace or continues a remote one.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	parent := trace.SpanContextFromContext(ctx)
+
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+
+	if sc := span.SpanContext(); sc.IsValid() && (!parent.IsValid() || parent.IsRemote()) {
+		ctx = logging.WithFields(ctx, zap.Stringer(logging.TraceID, sc.TraceID()))
+	}
+	return ctx, span
+}
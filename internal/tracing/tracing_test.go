@@ -0,0 +1,59 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+	"github.com/jeffreyyong/payment-gateway/internal/tracing"
+)
+
+func TestStart(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := logging.With(context.Background(), zap.New(core))
+
+	ctx, parent := tracing.Start(ctx, "Service.Authorize")
+	childCtx, child := tracing.Start(ctx, "Store.CreateTransaction")
+	logging.Error(childCtx, "unable to create transaction")
+	child.End()
+	parent.End()
+
+	traceID := parent.SpanContext().TraceID()
+	assert.Equal(t, traceID, child.SpanContext().TraceID())
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, traceID.String(), entries[0].ContextMap()[logging.TraceID])
+	assert.Len(t, entries[0].Context, 1, "the trace id is only added by the span starting the trace")
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "Store.CreateTransaction", ended[0].Name())
+	assert.Equal(t, codes.Error, ended[0].Status().Code)
+	assert.Equal(t, "unable to create transaction", ended[0].Status().Description)
+	assert.Equal(t, "Service.Authorize", ended[1].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), ended[0].Parent().SpanID())
+}
+
+func TestInit(t *testing.T) {
+	ctx := context.Background()
+
+	shutdown, err := tracing.Init(ctx, "payment-gateway", tracing.Config{Exporter: tracing.ExporterStdout})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(ctx))
+
+	_, err = tracing.Init(ctx, "payment-gateway", tracing.Config{Exporter: "jaeger"})
+	require.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package transporthttp
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	uuid "github.com/kevinburke/go.uuid"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+const (
+	// EndpointACS is the simulated ACS (Access Control Server) of the issuer where the cardholder completes the 3-D Secure challenge.
+	EndpointACS = "/acs/{authorization_id}"
+
+	formTransStatus = "trans_status"
+)
+
+var (
+	// transStatuses maps the 3-D Secure transStatus values to the authentication statuses.
+	transStatuses = map[string]domain.AuthenticationStatus{
+		"Y": domain.AuthenticationStatusAuthenticated,
+		"A": domain.AuthenticationStatusAttempted,
+		"N": domain.AuthenticationStatusFailed,
+		"U": domain.AuthenticationStatusUnavailable,
+	}
+
+	challengeTemplate = template.Must(template.New("challenge").Parse(`<!DOCTYPE html>
+<html>
+<head><title>3-D Secure challenge</title></head>
+<body>
+<h1>Simulated 3-D Secure challenge</h1>
+<form method="post" action="{{.}}">
+<button name="trans_status" value="Y">Authenticate</button>
+<button name="trans_status" value="A">Attempt</button>
+<button name="trans_status" value="N">Fail</button>
+<button name="trans_status" value="U">Unavailable</button>
+</form>
+</body>
+</html>
+`))
+)
+
+// ACSChallenge handler renders the simulated challenge page, the cardholder picks the result of the challenge.
+func (h *httpHandler) ACSChallenge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := uuid.FromString(mux.Vars(r)["authorization_id"]); err != nil {
+		errMsg := "invalid authorization id"
+		logging.Error(ctx, errMsg, zap.Error(err))
+		_ = WriteError(w, errMsg, CodeBadRequest)
+		return
+	}
+
+	w.Header().Add(ContentType, "text/html; charset=utf-8")
+	if err := challengeTemplate.Execute(w, r.URL.Path); err != nil {
+		logging.Error(ctx, "error rendering challenge page", zap.Error(err))
+	}
+}
+
+// ACSResult handler posts the result of the challenge, the trans_status form value is one of
+// the 3-D Secure transStatus values Y, A, N or U. It returns the authentication response.
+func (h *httpHandler) ACSResult(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure:  "failed to submit authentication result in service",
+		notFound: "unable to find the transaction with the authorization ID",
+		call: func(ctx context.Context) (interface{}, error) {
+			authorizationID, err := pathID(r, "authorization_id")
+			if err != nil {
+				return nil, err
+			}
+			if err = r.ParseForm(); err != nil {
+				return nil, requestError("failed to parse form")
+			}
+			status, ok := transStatuses[strings.ToUpper(r.PostForm.Get(formTransStatus))]
+			if !ok {
+				return nil, requestError(fmt.Sprintf("%s must be one of Y, A, N or U", formTransStatus))
+			}
+
+			t, err := h.service.SubmitAuthenticationResult(ctx, authorizationID, status)
+			if err != nil {
+				return nil, err
+			}
+			return mapToTransactionResp(t).Authentication, nil
+		},
+	}.ServeHTTP(w, r)
+}
+
+// challengeURL returns the URL of the simulated ACS where the cardholder completes the challenge of the authorization.
+func challengeURL(r *http.Request, authorizationID uuid.UUID) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	path := strings.Replace(EndpointACS, "{authorization_id}", authorizationID.String(), 1)
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, path)
+}
@@ -0,0 +1,263 @@
+package transporthttp
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	uuid "github.com/kevinburke/go.uuid"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+const (
+	EndpointAdminMerchants        = "/merchants"
+	EndpointAdminMerchant         = "/merchants/{merchant_id}"
+	EndpointAdminMerchantSettings = "/merchants/{merchant_id}/settings"
+	EndpointAdminMerchantSuspend  = "/merchants/{merchant_id}/suspend"
+	EndpointAdminMerchantActivate = "/merchants/{merchant_id}/activate"
+	EndpointAdminMerchantAPIKeys  = "/merchants/{merchant_id}/api_keys"
+	EndpointAdminMerchantAPIKey   = "/merchants/{merchant_id}/api_keys/{api_key_id}"
+)
+
+// adminTokenIDPrefix prefixes the name of the admin identifying its token in the audit log.
+const adminTokenIDPrefix = "admin:"
+
+// AdminService represents the service layer of the admin API managing the merchants and their API keys.
+type AdminService interface {
+	Auditor
+	CreateMerchant(ctx context.Context, merchant *domain.Merchant) (*domain.Merchant, error)
+	GetMerchant(ctx context.Context, merchantID string) (*domain.Merchant, error)
+	SetMerchantStatus(ctx context.Context, merchantID string, status domain.MerchantStatus) (*domain.Merchant, error)
+	UpdateMerchantSettings(ctx context.Context, merchantID string, settings domain.MerchantSettings) (*domain.Merchant, error)
+	CreateAPIKey(ctx context.Context, merchantID string, scopes []domain.Scope, expiresAt *time.Time) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, merchantID string) ([]*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, merchantID string, keyID uuid.UUID) error
+}
+
+// adminHandler is the http handler of the admin API, it is served on its own listener.
+// Every request is authenticated by an admin token and recorded in the audit log.
+type adminHandler struct {
+	service AdminService
+	// tokens are the sha256 of the admin tokens with the names of the admins.
+	tokens []adminToken
+}
+
+// adminToken is the sha256 of an admin token with the name of its admin, the tokens are compared by their hash in
+// constant time.
+type adminToken struct {
+	hash  [sha256.Size]byte
+	admin string
+}
+
+// NewAdminHandler will create a new instance of adminHandler given the admin tokens.
+func NewAdminHandler(service AdminService, adminTokens map[string]string) (*adminHandler, error) {
+	if service == nil {
+		return nil, errors.New("invalid param: service")
+	}
+	if len(adminTokens) == 0 {
+		return nil, errors.New("invalid param: at least one admin token is required")
+	}
+	tokens := make([]adminToken, 0, len(adminTokens))
+	for token, admin := range adminTokens {
+		tokens = append(tokens, adminToken{hash: sha256.Sum256([]byte(token)), admin: admin})
+	}
+	return &adminHandler{service: service, tokens: tokens}, nil
+}
+
+// admin returns the name of the admin of the token, every admin token is compared so that the time of the
+// comparison does not depend on the token.
+func (h *adminHandler) admin(token string) (string, bool) {
+	hash := sha256.Sum256([]byte(token))
+	var admin string
+	found := false
+	for _, t := range h.tokens {
+		if subtle.ConstantTimeCompare(hash[:], t.hash[:]) == 1 {
+			admin, found = t.admin, true
+		}
+	}
+	return admin, found
+}
+
+// ApplyRoutes will link the HTTP REST endpoint to the corresponding function in this handler
+// The OpenAPI document is not behind the middlewares.
+func (h *adminHandler) ApplyRoutes(m *httplistener.Mux) {
+	m.HandleFunc(EndpointOpenAPI, serveOpenAPI(openAPIDocument("Payment gateway admin API",
+		"The API of the admins to manage the merchants, their settings and their api keys.",
+		adminOperations, adminCommonErrors, false))).Methods(http.MethodGet)
+	m.Group(m.NewRoute(), func(m *httplistener.Mux) {
+		m.HandleFunc(EndpointAdminMerchants, h.CreateMerchant).Methods(http.MethodPost)
+		m.HandleFunc(EndpointAdminMerchant, h.GetMerchant).Methods(http.MethodGet)
+		m.HandleFunc(EndpointAdminMerchantSettings, h.UpdateMerchantSettings).Methods(http.MethodPut)
+		m.HandleFunc(EndpointAdminMerchantSuspend, h.setMerchantStatus(domain.MerchantStatusSuspended)).Methods(http.MethodPost)
+		m.HandleFunc(EndpointAdminMerchantActivate, h.setMerchantStatus(domain.MerchantStatusActive)).Methods(http.MethodPost)
+		m.HandleFunc(EndpointAdminMerchantAPIKeys, h.CreateAPIKey).Methods(http.MethodPost)
+		m.HandleFunc(EndpointAdminMerchantAPIKeys, h.ListAPIKeys).Methods(http.MethodGet)
+		m.HandleFunc(EndpointAdminMerchantAPIKey, h.RevokeAPIKey).Methods(http.MethodDelete)
+		m.Use(newAuditMiddleware(h.service), maxBodyBytesMiddleware(DefaultMaxBodyBytes), h.authorize, auditAuthenticatedMiddleware)
+	})
+}
+
+// authorize authenticates the admin by the token of the Authorization header,
+// the admin is identified in the audit log by its name.
+func (h *adminHandler) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(authorizationHeaderKey)
+		if token == "" {
+			_ = WriteError(w, "Authorization missing", CodeUnauthorized)
+			return
+		}
+		admin, ok := h.admin(token)
+		if !ok {
+			_ = WriteError(w, "invalid token", CodeForbidden)
+			return
+		}
+		ctx := appcontext.WithTokenID(r.Context(), adminTokenIDPrefix+admin)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// CreateMerchant handler to create an active merchant with its settings.
+func (h *adminHandler) CreateMerchant(w http.ResponseWriter, r *http.Request) {
+	var req MerchantRequest
+	endpoint{
+		request: &req,
+		failure: "failed to create merchant in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			return merchantResp(h.service.CreateMerchant(ctx, &domain.Merchant{
+				ID:       req.ID,
+				Name:     req.Name,
+				Settings: mapToMerchantSettings(req.Settings),
+			}))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// GetMerchant handler to get a merchant.
+func (h *adminHandler) GetMerchant(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to get merchant in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			return merchantResp(h.service.GetMerchant(ctx, mux.Vars(r)["merchant_id"]))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// UpdateMerchantSettings handler to replace the settings of a merchant.
+func (h *adminHandler) UpdateMerchantSettings(w http.ResponseWriter, r *http.Request) {
+	var req MerchantSettings
+	endpoint{
+		request: &req,
+		failure: "failed to update merchant settings in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			return merchantResp(h.service.UpdateMerchantSettings(ctx, mux.Vars(r)["merchant_id"], mapToMerchantSettings(req)))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// setMerchantStatus returns the handler to suspend or reactivate a merchant.
+func (h *adminHandler) setMerchantStatus(status domain.MerchantStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		endpoint{
+			failure: "failed to set merchant status in service",
+			call: func(ctx context.Context) (interface{}, error) {
+				return merchantResp(h.service.SetMerchantStatus(ctx, mux.Vars(r)["merchant_id"], status))
+			},
+		}.ServeHTTP(w, r)
+	}
+}
+
+// CreateAPIKey handler to issue an API key of a merchant, the key is only returned in this response.
+func (h *adminHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req APIKeyRequest
+	endpoint{
+		request: &req,
+		status:  http.StatusCreated,
+		failure: "failed to create api key in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			merchantID := mux.Vars(r)["merchant_id"]
+			scopes := make([]domain.Scope, 0, len(req.Scopes))
+			for _, s := range req.Scopes {
+				scopes = append(scopes, domain.Scope(s))
+			}
+			key, secret, err := h.service.CreateAPIKey(ctx, merchantID, scopes, req.ExpiresAt)
+			if err != nil {
+				return nil, err
+			}
+
+			resp := mapToAPIKeyResp(key)
+			resp.Key = secret
+			return resp, nil
+		},
+	}.ServeHTTP(w, r)
+}
+
+// ListAPIKeys handler to list the API keys of a merchant without the keys.
+func (h *adminHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to list api keys in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			keys, err := h.service.ListAPIKeys(ctx, mux.Vars(r)["merchant_id"])
+			if err != nil {
+				return nil, err
+			}
+
+			resp := make([]APIKey, 0, len(keys))
+			for _, k := range keys {
+				resp = append(resp, mapToAPIKeyResp(k))
+			}
+			return resp, nil
+		},
+	}.ServeHTTP(w, r)
+}
+
+// RevokeAPIKey handler to revoke an API key of a merchant.
+func (h *adminHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to revoke api key in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			keyID, err := pathID(r, "api_key_id")
+			if err != nil {
+				return nil, err
+			}
+			return nil, h.service.RevokeAPIKey(ctx, mux.Vars(r)["merchant_id"], keyID)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// merchantResp maps the merchant returned by the service to the response unless the service failed.
+func merchantResp(m *domain.Merchant, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	return mapToMerchantResp(m), nil
+}
+
+// helper mapper function to map to the merchant settings domain.
+func mapToMerchantSettings(s MerchantSettings) domain.MerchantSettings {
+	return domain.MerchantSettings{
+		AllowedCurrencies: s.AllowedCurrencies,
+		CapturePolicy:     domain.CapturePolicy(s.CapturePolicy),
+	}
+}
+
+// helper mapper function to map to merchant response.
+func mapToMerchantResp(m *domain.Merchant) Merchant {
+	return Merchant{
+		ID:     m.ID,
+		Name:   m.Name,
+		Status: string(m.Status),
+		Settings: MerchantSettings{
+			AllowedCurrencies: m.Settings.AllowedCurrencies,
+			CapturePolicy:     string(m.Settings.CapturePolicy),
+		},
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+	}
+}
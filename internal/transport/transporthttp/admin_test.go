@@ -0,0 +1,190 @@
+package transporthttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestAdminHandler(t *testing.T) {
+	const merchantID = "merchant-5"
+	adminTokens := map[string]string{"admin-token": "ops"}
+	createdDate := time.Date(2021, 6, 18, 12, 0, 0, 0, time.UTC)
+	keyID := uuid.NewV4()
+
+	testCases := []struct {
+		description        string
+		token              string
+		method             string
+		path               string
+		body               string
+		expect             func(srv *mocks.MockAdminService)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			description:        "should reject a request without admin token",
+			method:             http.MethodGet,
+			path:               "/merchants/" + merchantID,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			description:        "should reject a merchant token",
+			token:              "checkout-token-1",
+			method:             http.MethodGet,
+			path:               "/merchants/" + merchantID,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description:        "should reject a prefix of an admin token",
+			token:              "admin-tok",
+			method:             http.MethodGet,
+			path:               "/merchants/" + merchantID,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description: "should create a merchant",
+			token:       "admin-token",
+			method:      http.MethodPost,
+			path:        transporthttp.EndpointAdminMerchants,
+			body:        `{"id": "merchant-5", "name": "Shop", "settings": {"allowed_currencies": ["GBP"], "capture_policy": "automatic"}}`,
+			expect: func(srv *mocks.MockAdminService) {
+				srv.EXPECT().CreateMerchant(gomock.Any(), &domain.Merchant{
+					ID:   merchantID,
+					Name: "Shop",
+					Settings: domain.MerchantSettings{
+						AllowedCurrencies: []string{"GBP"},
+						CapturePolicy:     domain.CapturePolicyAutomatic,
+					},
+				}).Return(&domain.Merchant{
+					ID:     merchantID,
+					Name:   "Shop",
+					Status: domain.MerchantStatusActive,
+					Settings: domain.MerchantSettings{
+						AllowedCurrencies: []string{"GBP"},
+						CapturePolicy:     domain.CapturePolicyAutomatic,
+					},
+					CreatedDate: createdDate,
+					UpdatedDate: createdDate,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"id":"merchant-5","name":"Shop","status":"active","settings":{"allowed_currencies":["GBP"],"capture_policy":"automatic"},` +
+				`"created_date":"2021-06-18T12:00:00Z","updated_date":"2021-06-18T12:00:00Z"}`,
+		},
+		{
+			description: "should not create an existing merchant",
+			token:       "admin-token",
+			method:      http.MethodPost,
+			path:        transporthttp.EndpointAdminMerchants,
+			body:        `{"id": "merchant-5"}`,
+			expect: func(srv *mocks.MockAdminService) {
+				srv.EXPECT().CreateMerchant(gomock.Any(), gomock.Any()).Return(nil, domain.ErrMerchantExists)
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			description: "should suspend a merchant",
+			token:       "admin-token",
+			method:      http.MethodPost,
+			path:        "/merchants/" + merchantID + "/suspend",
+			expect: func(srv *mocks.MockAdminService) {
+				srv.EXPECT().SetMerchantStatus(gomock.Any(), merchantID, domain.MerchantStatusSuspended).
+					Return(&domain.Merchant{ID: merchantID, Status: domain.MerchantStatusSuspended}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			description: "should issue an api key of a merchant",
+			token:       "admin-token",
+			method:      http.MethodPost,
+			path:        "/merchants/" + merchantID + "/api_keys",
+			body:        `{"scopes": ["authorize", "read"]}`,
+			expect: func(srv *mocks.MockAdminService) {
+				srv.EXPECT().CreateAPIKey(gomock.Any(), merchantID, []domain.Scope{domain.ScopeAuthorize, domain.ScopeRead}, nil).
+					Return(&domain.APIKey{ID: keyID, Scopes: []domain.Scope{domain.ScopeAuthorize, domain.ScopeRead}, CreatedDate: createdDate}, "pgk_new", nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `{"id":"` + keyID.String() + `","key":"pgk_new","scopes":["authorize","read"],"created_date":"2021-06-18T12:00:00Z"}`,
+		},
+		{
+			description: "should not issue an api key of an unknown merchant",
+			token:       "admin-token",
+			method:      http.MethodPost,
+			path:        "/merchants/unknown/api_keys",
+			body:        `{"scopes": ["all"]}`,
+			expect: func(srv *mocks.MockAdminService) {
+				srv.EXPECT().CreateAPIKey(gomock.Any(), "unknown", []domain.Scope{domain.ScopeAll}, nil).
+					Return(nil, "", domain.ErrMerchantNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			description: "should revoke an api key of a merchant",
+			token:       "admin-token",
+			method:      http.MethodDelete,
+			path:        "/merchants/" + merchantID + "/api_keys/" + keyID.String(),
+			expect: func(srv *mocks.MockAdminService) {
+				srv.EXPECT().RevokeAPIKey(gomock.Any(), merchantID, keyID).Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockAdminService(ctrl)
+			if tt.expect != nil {
+				tt.expect(srv)
+			}
+			// the admin requests are audited with the name of the admin, the rejected ones without
+			srv.EXPECT().Audit(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ *domain.AuditEntry) error {
+				if tt.token == "admin-token" {
+					assert.Equal(t, "admin:ops", appcontext.GetTokenID(ctx))
+				} else {
+					assert.Empty(t, appcontext.GetTokenID(ctx))
+				}
+				return nil
+			})
+
+			h, err := transporthttp.NewAdminHandler(srv, adminTokens)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				r.Header.Set("Authorization", tt.token)
+			}
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewAdminHandler_RequiresTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := transporthttp.NewAdminHandler(mocks.NewMockAdminService(ctrl), nil)
+	assert.Error(t, err)
+}
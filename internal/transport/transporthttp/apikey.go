@@ -0,0 +1,108 @@
+package transporthttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	uuid "github.com/kevinburke/go.uuid"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+const (
+	EndpointAPIKeyRotate = "/api_keys/rotate"
+)
+
+// endpointScopes are the scopes required by the endpoints keyed by method and endpoint, an empty scope is
+// granted to every token. The endpoints which are not listed require domain.ScopeAll.
+var endpointScopes = map[string]domain.Scope{
+	http.MethodPost + " " + EndpointAuthorize:               domain.ScopeAuthorize,
+	http.MethodPost + " " + EndpointAuthorizeComplete:       domain.ScopeAuthorize,
+	http.MethodPost + " " + EndpointCapture:                 domain.ScopeCapture,
+	http.MethodPost + " " + EndpointRefund:                  domain.ScopeRefund,
+	http.MethodPost + " " + EndpointVoid:                    domain.ScopeVoid,
+	http.MethodPost + " " + EndpointCustomers:               domain.ScopeManage,
+	http.MethodGet + " " + EndpointCustomer:                 domain.ScopeRead,
+	http.MethodPut + " " + EndpointCustomer:                 domain.ScopeManage,
+	http.MethodDelete + " " + EndpointCustomer:              domain.ScopeManage,
+	http.MethodPost + " " + EndpointCustomerPaymentMethods:  domain.ScopeManage,
+	http.MethodGet + " " + EndpointCustomerPaymentMethods:   domain.ScopeRead,
+	http.MethodDelete + " " + EndpointCustomerPaymentMethod: domain.ScopeManage,
+	http.MethodGet + " " + EndpointCustomerTransactions:     domain.ScopeRead,
+	http.MethodPost + " " + EndpointPlans:                   domain.ScopeManage,
+	http.MethodPost + " " + EndpointSubscriptions:           domain.ScopeManage,
+	http.MethodGet + " " + EndpointSubscription:             domain.ScopeRead,
+	http.MethodPost + " " + EndpointSubscriptionCancel:      domain.ScopeManage,
+	http.MethodPost + " " + EndpointAPIKeyRotate:            domain.ScopeKeys,
+}
+
+// requiredScope returns the scope required by the endpoint of the request.
+func requiredScope(r *http.Request) domain.Scope {
+	scope, ok := endpointScopes[routeEndpoint(r)]
+	if !ok {
+		return domain.ScopeAll
+	}
+	return scope
+}
+
+// routeEndpoint returns the method and the path template of the route of the request, e.g. "POST /capture",
+// empty outside of a route. The versioned routes are the same endpoints as their deprecated aliases hence
+// the APIVersionPrefix is trimmed.
+func routeEndpoint(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	endpoint, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return r.Method + " " + strings.TrimPrefix(endpoint, APIVersionPrefix)
+}
+
+// RotateAPIKey handler to rotate the API key the request is authenticated with, the new key is returned
+// and the rotated key stays valid for the requested overlap so that the new key can be rolled out.
+func (h *httpHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req RotateAPIKeyRequest
+	endpoint{
+		request:      &req,
+		optionalBody: true,
+		failure:      "failed to rotate api key in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			keyID, err := uuid.FromString(appcontext.GetTokenID(ctx))
+			if err != nil {
+				return nil, requestError("only the api keys can be rotated")
+			}
+
+			key, secret, err := h.service.RotateAPIKey(ctx, appcontext.GetMerchant(ctx), keyID, time.Duration(req.OverlapSeconds)*time.Second)
+			if err != nil {
+				return nil, err
+			}
+
+			resp := mapToAPIKeyResp(key)
+			resp.Key = secret
+			return resp, nil
+		},
+	}.ServeHTTP(w, r)
+}
+
+// helper mapper function to map to api key response, the key itself is never stored.
+func mapToAPIKeyResp(k *domain.APIKey) APIKey {
+	scopes := make([]string, 0, len(k.Scopes))
+	for _, s := range k.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	return APIKey{
+		ID:          k.ID,
+		Scopes:      scopes,
+		CreatedDate: k.CreatedDate,
+		ExpiresAt:   k.ExpiresAt,
+		RevokedAt:   k.RevokedAt,
+		LastUsedAt:  k.LastUsedAt,
+		RotatedAt:   k.RotatedAt,
+	}
+}
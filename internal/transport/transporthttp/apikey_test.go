@@ -0,0 +1,88 @@
+package transporthttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestHandler_RotateAPIKey(t *testing.T) {
+	const merchantID = "merchant-1"
+	keyID := uuid.NewV4()
+	newKey := &domain.APIKey{
+		ID:          uuid.NewV4(),
+		MerchantID:  merchantID,
+		Scopes:      []domain.Scope{domain.ScopeRefund},
+		CreatedDate: time.Date(2021, 6, 18, 12, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		description        string
+		token              string
+		scopes             []domain.Scope
+		body               string
+		err                error
+		expectedStatusCode int
+	}{
+		{"should rotate the api key with the requested overlap", "pgk_current", []domain.Scope{domain.ScopeRefund, domain.ScopeKeys},
+			`{"overlap_seconds": 3600}`, nil, http.StatusOK},
+		{"should not rotate a key without the keys scope", "pgk_current", []domain.Scope{domain.ScopeRefund},
+			`{"overlap_seconds": 3600}`, nil, http.StatusForbidden},
+		{"should not rotate a key which is already rotated", "pgk_current", []domain.Scope{domain.ScopeKeys},
+			`{"overlap_seconds": 3600}`, domain.ErrAPIKeyRotated, http.StatusConflict},
+		{"should not rotate a privileged token", "privileged", nil, "", nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			srv.EXPECT().ValidateMerchant(gomock.Any(), merchantID).Return(nil)
+			if tt.scopes != nil {
+				srv.EXPECT().AuthenticateAPIKey(gomock.Any(), tt.token).
+					Return(&domain.APIKey{ID: keyID, MerchantID: merchantID, Scopes: tt.scopes}, nil)
+			}
+			if tt.expectedStatusCode == http.StatusOK {
+				srv.EXPECT().RotateAPIKey(gomock.Any(), merchantID, keyID, time.Hour).Return(newKey, "pgk_new", nil)
+			}
+			if tt.err != nil {
+				srv.EXPECT().RotateAPIKey(gomock.Any(), merchantID, keyID, time.Hour).Return(nil, "", tt.err)
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv, transporthttp.WithAuth(map[string]string{"privileged": merchantID}))
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, transporthttp.EndpointAPIKeyRotate, strings.NewReader(tt.body))
+			r.Header.Set("Authorization", tt.token)
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatusCode, w.Code)
+
+			if tt.expectedStatusCode == http.StatusOK {
+				var resp transporthttp.APIKey
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, transporthttp.APIKey{
+					ID:          newKey.ID,
+					Key:         "pgk_new",
+					Scopes:      []string{"refund"},
+					CreatedDate: newKey.CreatedDate,
+				}, resp)
+			}
+		})
+	}
+}
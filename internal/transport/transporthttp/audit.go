@@ -0,0 +1,76 @@
+package transporthttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+// Auditor records the entries in the audit log.
+type Auditor interface {
+	Audit(ctx context.Context, entry *domain.AuditEntry) error
+}
+
+// statusRecorder records the status code written by the handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.statusCode = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// auditedRequestKey is the context key of the auditedRequest.
+type auditedRequestKey struct{}
+
+// auditedRequest holds the context of the request once it has been authenticated,
+// the context of the unauthenticated request otherwise.
+type auditedRequest struct {
+	ctx context.Context
+}
+
+// newAuditMiddleware initialises the middleware recording the request, e.g. "POST /capture", and the status code
+// of its response in the audit log. It is the outermost middleware so that the requests rejected by the other
+// middlewares are recorded too, with the merchant recorded by auditAuthenticatedMiddleware, empty if the request
+// has not been authenticated. A failure to record it is only logged as the request has been served.
+func newAuditMiddleware(auditor Auditor) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			audited := &auditedRequest{ctx: r.Context()}
+			r = r.WithContext(context.WithValue(r.Context(), auditedRequestKey{}, audited))
+
+			sr := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(sr, r)
+
+			// the middlewares run before the api is added to the context by the httplistener
+			ctx := audited.ctx
+			entry := &domain.AuditEntry{
+				Operation:  routeEndpoint(r),
+				ResourceID: r.URL.Path,
+				Outcome:    strconv.Itoa(sr.statusCode),
+			}
+			if err := auditor.Audit(ctx, entry); err != nil {
+				logging.Error(ctx, "unable to audit request", zap.Error(err), zap.String("audit.operation", entry.Operation))
+			}
+		})
+	}
+}
+
+// auditAuthenticatedMiddleware records the context of the authenticated request, with its merchant and token,
+// for the audit middleware. It follows the middlewares authenticating the request.
+func auditAuthenticatedMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if audited, ok := r.Context().Value(auditedRequestKey{}).(*auditedRequest); ok {
+			audited.ctx = r.Context()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
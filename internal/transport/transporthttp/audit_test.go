@@ -0,0 +1,73 @@
+package transporthttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestHandler_AuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	customerID := uuid.NewV4()
+	path := "/customers/" + customerID.String()
+
+	srv := mocks.NewMockService(ctrl)
+	srv.EXPECT().DeleteCustomer(gomock.Any(), "merchant-1", customerID).Return(domain.ErrCustomerNotFound)
+	gomock.InOrder(
+		srv.EXPECT().Audit(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, entry *domain.AuditEntry) error {
+			assert.Equal(t, "merchant-1", appcontext.GetMerchant(ctx))
+			assert.Equal(t, &domain.AuditEntry{
+				Operation:  http.MethodDelete + " " + transporthttp.EndpointCustomer,
+				ResourceID: path,
+				Outcome:    "404",
+			}, entry)
+			return nil
+		}),
+		srv.EXPECT().Audit(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, entry *domain.AuditEntry) error {
+			assert.Empty(t, appcontext.GetMerchant(ctx))
+			assert.Equal(t, &domain.AuditEntry{
+				Operation:  http.MethodDelete + " " + transporthttp.EndpointCustomer,
+				ResourceID: path,
+				Outcome:    "403",
+			}, entry)
+			return nil
+		}),
+	)
+
+	srv.EXPECT().ValidateMerchant(gomock.Any(), "merchant-1").Return(nil)
+	srv.EXPECT().AuthenticateAPIKey(gomock.Any(), "unknown").Return(nil, domain.ErrAPIKeyInvalid)
+
+	h, err := transporthttp.NewHTTPHandler(srv,
+		transporthttp.WithAuth(map[string]string{"token-1": "merchant-1"}),
+		transporthttp.WithAuditLog(),
+	)
+	require.NoError(t, err)
+	handler := httplistener.HTTPHandler(h)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodDelete, path, nil)
+	r.Header.Set("Authorization", "token-1")
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// a request rejected by the authorization is audited without merchant
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodDelete, path, nil)
+	r.Header.Set("Authorization", "unknown")
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,213 @@
+package transporthttp
+
+import (
+	"context"
+	"net/http"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+const (
+	EndpointCustomers              = "/customers"
+	EndpointCustomer               = "/customers/{customer_id}"
+	EndpointCustomerPaymentMethods = "/customers/{customer_id}/payment_methods"
+	EndpointCustomerPaymentMethod  = "/customers/{customer_id}/payment_methods/{payment_method_id}"
+	EndpointCustomerTransactions   = "/customers/{customer_id}/transactions"
+)
+
+// CreateCustomer handler to create a customer of the merchant that payment methods are stored for.
+func (h *httpHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	var req CustomerRequest
+	endpoint{
+		request:      &req,
+		optionalBody: true,
+		failure:      "failed to create customer in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			return customerResp(h.service.CreateCustomer(ctx, &domain.Customer{
+				MerchantID: appcontext.GetMerchant(ctx),
+				Email:      req.Email,
+				Name:       req.Name,
+			}))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// GetCustomer handler to get a customer of the merchant.
+func (h *httpHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to get customer in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			customerID, err := pathID(r, "customer_id")
+			if err != nil {
+				return nil, err
+			}
+			return customerResp(h.service.GetCustomer(ctx, appcontext.GetMerchant(ctx), customerID))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// UpdateCustomer handler to replace the email and the name of a customer of the merchant.
+func (h *httpHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	var req CustomerRequest
+	endpoint{
+		request:      &req,
+		optionalBody: true,
+		failure:      "failed to update customer in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			customerID, err := pathID(r, "customer_id")
+			if err != nil {
+				return nil, err
+			}
+			return customerResp(h.service.UpdateCustomer(ctx, &domain.Customer{
+				ID:         customerID,
+				MerchantID: appcontext.GetMerchant(ctx),
+				Email:      req.Email,
+				Name:       req.Name,
+			}))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// DeleteCustomer handler to delete a customer of the merchant with its payment methods, its subscriptions are canceled.
+func (h *httpHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to delete customer in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			customerID, err := pathID(r, "customer_id")
+			if err != nil {
+				return nil, err
+			}
+			return nil, h.service.DeleteCustomer(ctx, appcontext.GetMerchant(ctx), customerID)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// ListCustomerTransactions handler to list the transactions of a customer of the merchant, the latest first.
+func (h *httpHandler) ListCustomerTransactions(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to list customer transactions in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			customerID, err := pathID(r, "customer_id")
+			if err != nil {
+				return nil, err
+			}
+			transactions, err := h.service.ListCustomerTransactions(ctx, appcontext.GetMerchant(ctx), customerID)
+			if err != nil {
+				return nil, err
+			}
+
+			resp := make([]Transaction, 0, len(transactions))
+			for _, t := range transactions {
+				resp = append(resp, mapToTransactionResp(t))
+			}
+			return resp, nil
+		},
+	}.ServeHTTP(w, r)
+}
+
+// CreatePaymentMethod handler to store a card as a payment method of a customer of the merchant.
+func (h *httpHandler) CreatePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	var req PaymentMethodRequest
+	endpoint{
+		request: &req,
+		failure: "failed to create payment method in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			customerID, err := pathID(r, "customer_id")
+			if err != nil {
+				return nil, err
+			}
+			paymentMethod, err := h.service.CreatePaymentMethod(ctx, &domain.PaymentMethod{
+				CustomerID: customerID,
+				MerchantID: appcontext.GetMerchant(ctx),
+				PaymentSource: domain.PaymentSource{
+					PAN: req.PaymentSource.PAN,
+					Expiry: domain.Expiry{
+						Month: req.PaymentSource.ExpiryMonth,
+						Year:  req.PaymentSource.ExpiryYear,
+					},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return mapToPaymentMethodResp(paymentMethod), nil
+		},
+	}.ServeHTTP(w, r)
+}
+
+// ListPaymentMethods handler to list the payment methods of a customer of the merchant.
+func (h *httpHandler) ListPaymentMethods(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to list payment methods in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			customerID, err := pathID(r, "customer_id")
+			if err != nil {
+				return nil, err
+			}
+			paymentMethods, err := h.service.ListPaymentMethods(ctx, appcontext.GetMerchant(ctx), customerID)
+			if err != nil {
+				return nil, err
+			}
+
+			resp := make([]PaymentMethod, 0, len(paymentMethods))
+			for _, pm := range paymentMethods {
+				resp = append(resp, mapToPaymentMethodResp(pm))
+			}
+			return resp, nil
+		},
+	}.ServeHTTP(w, r)
+}
+
+// DeletePaymentMethod handler to delete a payment method of a customer of the merchant.
+func (h *httpHandler) DeletePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to delete payment method in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			customerID, err := pathID(r, "customer_id")
+			if err != nil {
+				return nil, err
+			}
+			paymentMethodID, err := pathID(r, "payment_method_id")
+			if err != nil {
+				return nil, err
+			}
+			return nil, h.service.DeletePaymentMethod(ctx, appcontext.GetMerchant(ctx), customerID, paymentMethodID)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// customerResp maps the customer returned by the service to the response unless the service failed.
+func customerResp(c *domain.Customer, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	return mapToCustomerResp(c), nil
+}
+
+// helper mapper function to map to customer response.
+func mapToCustomerResp(c *domain.Customer) Customer {
+	return Customer{
+		ID:          c.ID,
+		Email:       c.Email,
+		Name:        c.Name,
+		CreatedDate: c.CreatedDate,
+	}
+}
+
+// helper mapper function to map to payment method response.
+func mapToPaymentMethodResp(pm *domain.PaymentMethod) PaymentMethod {
+	return PaymentMethod{
+		ID:         pm.ID,
+		CustomerID: pm.CustomerID,
+		Card: Card{
+			Last4:       pm.PaymentSource.Last4(),
+			ExpiryMonth: pm.PaymentSource.Expiry.Month,
+			ExpiryYear:  pm.PaymentSource.Expiry.Year,
+			Scheme:      string(pm.Card.Scheme),
+			Country:     pm.Card.Country,
+		},
+		CreatedDate:       pm.CreatedDate,
+		MerchantInitiated: pm.NetworkTransactionID != "",
+	}
+}
@@ -0,0 +1,185 @@
+package transporthttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestHandler_ListPaymentMethods(t *testing.T) {
+	merchantID := "merchant-1"
+	customerID := uuid.NewV4()
+	paymentMethod := &domain.PaymentMethod{
+		ID:                   uuid.NewV4(),
+		CustomerID:           customerID,
+		MerchantID:           merchantID,
+		PaymentSource:        domain.PaymentSource{PAN: "4242 4242 4242 4242", Expiry: domain.Expiry{Month: 4, Year: 2030}},
+		Card:                 domain.CardInfo{Scheme: domain.CardSchemeVisa, Country: "GB"},
+		NetworkTransactionID: "123456789012345",
+	}
+
+	testCases := []struct {
+		description        string
+		customerID         string
+		setupMocks         func(srv *mocks.MockService)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			"should list the payment methods with the last 4 digits of the card",
+			customerID.String(),
+			func(srv *mocks.MockService) {
+				srv.EXPECT().ListPaymentMethods(gomock.Any(), merchantID, customerID).Return([]*domain.PaymentMethod{paymentMethod}, nil)
+			},
+			http.StatusOK,
+			"",
+		},
+		{
+			"invalid customer id",
+			"some-id",
+			nil,
+			http.StatusBadRequest,
+			transporthttp.CodeBadRequest,
+		},
+		{
+			"service returns not found",
+			customerID.String(),
+			func(srv *mocks.MockService) {
+				srv.EXPECT().ListPaymentMethods(gomock.Any(), merchantID, customerID).Return(nil, domain.ErrCustomerNotFound)
+			},
+			http.StatusNotFound,
+			transporthttp.CodeNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(srv)
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/customers/"+tt.customerID+"/payment_methods", nil)
+			r = r.WithContext(appcontext.WithMerchant(r.Context(), merchantID))
+			r = mux.SetURLVars(r, map[string]string{"customer_id": tt.customerID})
+
+			h.ListPaymentMethods(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedCode != "" {
+				var out transporthttp.ServerError
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+				assert.Equal(t, tt.expectedCode, out.Code)
+				return
+			}
+
+			var out []transporthttp.PaymentMethod
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+			assert.Equal(t, []transporthttp.PaymentMethod{
+				{
+					ID:         paymentMethod.ID,
+					CustomerID: customerID,
+					Card: transporthttp.Card{
+						Last4:       "4242",
+						ExpiryMonth: 4,
+						ExpiryYear:  2030,
+						Scheme:      string(domain.CardSchemeVisa),
+						Country:     "GB",
+					},
+					CreatedDate:       paymentMethod.CreatedDate,
+					MerchantInitiated: true,
+				},
+			}, out)
+		})
+	}
+}
+
+func TestHandler_DeleteCustomer(t *testing.T) {
+	merchantID := "merchant-1"
+	customerID := uuid.NewV4()
+
+	testCases := []struct {
+		description        string
+		err                error
+		expectedStatusCode int
+	}{
+		{"should delete the customer", nil, http.StatusNoContent},
+		{"service returns not found", domain.ErrCustomerNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			srv.EXPECT().DeleteCustomer(gomock.Any(), merchantID, customerID).Return(tt.err)
+
+			h, err := transporthttp.NewHTTPHandler(srv)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/customers/"+customerID.String(), nil)
+			r = r.WithContext(appcontext.WithMerchant(r.Context(), merchantID))
+			r = mux.SetURLVars(r, map[string]string{"customer_id": customerID.String()})
+
+			h.DeleteCustomer(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestHandler_ListCustomerTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	merchantID := "merchant-1"
+	customerID := uuid.NewV4()
+	transaction := &domain.Transaction{ID: uuid.NewV4(), AuthorizationID: uuid.NewV4(), CustomerID: customerID}
+
+	srv := mocks.NewMockService(ctrl)
+	srv.EXPECT().ListCustomerTransactions(gomock.Any(), merchantID, customerID).Return([]*domain.Transaction{transaction}, nil)
+
+	h, err := transporthttp.NewHTTPHandler(srv)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/customers/"+customerID.String()+"/transactions", nil)
+	r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20211101)
+	r = r.WithContext(appcontext.WithMerchant(r.Context(), merchantID))
+
+	httplistener.HTTPHandler(h).ServeHTTP(w, r)
+	res := w.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var out []transporthttp.Transaction
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+	require.Len(t, out, 1)
+	assert.Equal(t, transaction.AuthorizationID, out[0].AuthorizationID)
+	assert.Equal(t, &customerID, out[0].CustomerID)
+}
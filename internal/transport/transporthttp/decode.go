@@ -0,0 +1,149 @@
+package transporthttp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+// DefaultMaxBodyBytes is the maximum size of a request body unless configured with WithMaxBodyBytes.
+const DefaultMaxBodyBytes = 1 << 20
+
+// FieldError is the error of a field of the request, the field is the JSON path of the field, e.g. amount.currency.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// validator is implemented by the requests validating their fields once decoded.
+type validator interface {
+	Validate() []FieldError
+}
+
+// WithMaxBodyBytes is a function configuration of the maximum size of a request body, DefaultMaxBodyBytes if not set.
+func WithMaxBodyBytes(n int64) MiddlewareFunc {
+	return func(h *httpHandler) error {
+		if n <= 0 {
+			return errors.New("max body bytes must be positive")
+		}
+		h.maxBodyBytes = n
+		return nil
+	}
+}
+
+// errBodyTooLarge is the error of the reads of a request body past its maximum size.
+var errBodyTooLarge = errors.New("request body too large")
+
+// maxBodyBytesMiddleware caps the size of the request bodies, reading past it fails with errBodyTooLarge.
+func maxBodyBytesMiddleware(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = &maxBytesReader{ReadCloser: r.Body, remaining: n}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maxBytesReader reads up to remaining bytes of the body, it fails with errBodyTooLarge once the body is over.
+type maxBytesReader struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// one more byte than remaining is read to tell a body of the maximum size from a larger one
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if int64(n) <= r.remaining {
+		r.remaining -= int64(n)
+		r.err = err
+		return n, err
+	}
+	n = int(r.remaining)
+	r.remaining = 0
+	r.err = errBodyTooLarge
+	return n, r.err
+}
+
+// decodeJSON decodes the request body into v rejecting the unknown fields and validates it, it writes the error
+// if the body is missing, too large, invalid or fails the validation. An optional body may be empty.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}, optional bool) bool {
+	ctx := r.Context()
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the request body")
+	}
+	switch {
+	case errors.Is(err, io.EOF) && optional:
+	case errors.Is(err, io.EOF):
+		errMsg := "missing request body"
+		logging.Error(ctx, errMsg)
+		_ = WriteError(w, errMsg, CodeBadRequest)
+		return false
+	case errors.Is(err, errBodyTooLarge):
+		errMsg := "request body too large"
+		logging.Error(ctx, errMsg, zap.Error(err))
+		_ = WriteError(w, errMsg, CodePayloadTooLarge)
+		return false
+	case err != nil:
+		errMsg := "failed to unmarshal request body"
+		logging.Error(ctx, errMsg, zap.Error(err))
+		_ = WriteErrorWithFields(w, errMsg, CodeBadRequest, unmarshalFieldErrors(err))
+		return false
+	}
+
+	if val, ok := v.(validator); ok {
+		if fieldErrors := val.Validate(); len(fieldErrors) > 0 {
+			errMsg := "invalid request"
+			logging.Error(ctx, errMsg, zap.Any("errors", fieldErrors))
+			_ = WriteErrorWithFields(w, errMsg, CodeBadRequest, fieldErrors)
+			return false
+		}
+	}
+	return true
+}
+
+// unmarshalFieldErrors returns the field error of an unknown field or of a field of the wrong type, if any.
+func unmarshalFieldErrors(err error) []FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()}}
+	}
+	const unknownField = "json: unknown field "
+	if msg := err.Error(); strings.HasPrefix(msg, unknownField) {
+		return []FieldError{{Field: strings.Trim(strings.TrimPrefix(msg, unknownField), `"`), Message: "is unknown"}}
+	}
+	return nil
+}
+
+// fieldErrors accumulates the field errors of a request.
+type fieldErrors []FieldError
+
+func (e *fieldErrors) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// required adds the error of a missing field unless present.
+func (e *fieldErrors) required(field string, present bool) {
+	if !present {
+		e.add(field, "is required")
+	}
+}
@@ -0,0 +1,118 @@
+package transporthttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestHandler_DecodeRequest(t *testing.T) {
+	testCases := []struct {
+		description        string
+		endpoint           string
+		body               string
+		expectedStatusCode int
+		expectedCode       string
+		expectedErrors     []transporthttp.FieldError
+	}{
+		{
+			description: "should reject a capture of 0 minor units",
+			endpoint:    transporthttp.EndpointCapture,
+			body: `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06", "authorization_id": "a3b250ae-7b26-4cad-8332-f13a599b0824",
+				"amount": {"minor_units": 0, "currency": "GBP", "exponent": 2}}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       transporthttp.CodeBadRequest,
+			expectedErrors:     []transporthttp.FieldError{{Field: "amount.minor_units", Message: "must be greater than 0"}},
+		},
+		{
+			description:        "should list the errors of every invalid field",
+			endpoint:           transporthttp.EndpointAuthorize,
+			body:               `{"amount": {"minor_units": 100}}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       transporthttp.CodeBadRequest,
+			expectedErrors: []transporthttp.FieldError{
+				{Field: "request_id", Message: "is required"},
+				{Field: "amount.currency", Message: "is required"},
+				{Field: "payment_source.pan", Message: "is required"},
+			},
+		},
+		{
+			description: "should reject a shopper ip which is not an IP address",
+			endpoint:    transporthttp.EndpointAuthorize,
+			body: `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06", "payment_source": {"pan": "4242424242424242"},
+				"amount": {"minor_units": 100, "currency": "GBP", "exponent": 2}, "shopper_ip": "localhost"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       transporthttp.CodeBadRequest,
+			expectedErrors:     []transporthttp.FieldError{{Field: "shopper_ip", Message: "must be an IP address"}},
+		},
+		{
+			description:        "should reject an unknown field",
+			endpoint:           transporthttp.EndpointVoid,
+			body:               `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06", "authorisation_id": "a3b250ae-7b26-4cad-8332-f13a599b0824"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       transporthttp.CodeBadRequest,
+			expectedErrors:     []transporthttp.FieldError{{Field: "authorisation_id", Message: "is unknown"}},
+		},
+		{
+			description:        "should reject a field of the wrong type",
+			endpoint:           transporthttp.EndpointRefund,
+			body:               `{"amount": {"minor_units": "100"}}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       transporthttp.CodeBadRequest,
+			expectedErrors:     []transporthttp.FieldError{{Field: "amount.minor_units", Message: "must be of type uint64"}},
+		},
+		{
+			description:        "should reject the data after the request body",
+			endpoint:           transporthttp.EndpointVoid,
+			body:               `{} {}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       transporthttp.CodeBadRequest,
+		},
+		{
+			description:        "should reject a missing request body",
+			endpoint:           transporthttp.EndpointCapture,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       transporthttp.CodeBadRequest,
+		},
+		{
+			description:        "should reject a request body over the maximum size",
+			endpoint:           transporthttp.EndpointCapture,
+			body:               `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06", "description": "` + strings.Repeat("a", 1024) + `"}`,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedCode:       transporthttp.CodePayloadTooLarge,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			h, err := transporthttp.NewHTTPHandler(mocks.NewMockService(ctrl), transporthttp.WithMaxBodyBytes(1024))
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, tt.endpoint, strings.NewReader(tt.body))
+			r = r.WithContext(appcontext.WithMerchant(context.Background(), "merchant-1"))
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			var serverError transporthttp.ServerError
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&serverError))
+			assert.Equal(t, tt.expectedCode, serverError.Code)
+			assert.Equal(t, tt.expectedErrors, serverError.Errors)
+		})
+	}
+}
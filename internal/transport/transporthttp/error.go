@@ -4,12 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
 )
 
 // ServerError encodes a consistent form of error JSON response.
 type ServerError struct {
 	Code    string `json:"code"`
 	Message string `json:"message,omitempty"`
+	// Reason is the stable reason of an unprocessable request, e.g. already_voided, to match on rather than the message.
+	Reason string `json:"reason,omitempty"`
+	// CorrelationID is the id of the request in the log lines, it is also echoed in the X-Request-ID header.
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Errors are the errors of the fields of an invalid request.
+	Errors []FieldError `json:"errors,omitempty"`
 }
 
 const (
@@ -23,6 +32,9 @@ const (
 	CodeBadRequest         = "bad_request"
 	CodePreconditionFailed = "failed_precondition"
 	CodeUnprocessable      = "unprocessable"
+	CodeVelocityLimit      = "velocity_limit_exceeded"
+	CodeRateLimit          = "rate_limit_exceeded"
+	CodePayloadTooLarge    = "payload_too_large"
 )
 
 var (
@@ -38,16 +50,29 @@ var (
 		CodeConflict:           http.StatusConflict,
 		CodeUnprocessable:      http.StatusUnprocessableEntity,
 		CodePreconditionFailed: http.StatusPreconditionFailed,
+		CodeVelocityLimit:      http.StatusTooManyRequests,
+		CodeRateLimit:          http.StatusTooManyRequests,
+		CodePayloadTooLarge:    http.StatusRequestEntityTooLarge,
 	}
 )
 
 // WriteError writes a json response and pre-registered http status error
 // always writes response even when producing an error
+// The correlation id is taken from the response header set by the httplistener.CorrelationMiddleware.
+// The message is redacted as it can contain the error of the store.
 func WriteError(w http.ResponseWriter, message, code string) error {
-	serverError := ServerError{
-		Code:    code,
-		Message: message,
-	}
+	return WriteErrorWithFields(w, message, code, nil)
+}
+
+// WriteErrorWithFields writes the error as WriteError along with the errors of the fields of the request.
+func WriteErrorWithFields(w http.ResponseWriter, message, code string, fieldErrors []FieldError) error {
+	return writeServerError(w, ServerError{Code: code, Message: message, Errors: fieldErrors})
+}
+
+// writeServerError writes the server error as WriteError, the message is redacted and the correlation id is set.
+func writeServerError(w http.ResponseWriter, serverError ServerError) error {
+	serverError.Message = logging.Redact(serverError.Message)
+	serverError.CorrelationID = w.Header().Get(httplistener.HeaderRequestID)
 	var err error
 	w.Header().Set("Content-Type", "application/json")
 	sc, ok := codeMap[serverError.Code]
@@ -0,0 +1,33 @@
+package transporthttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+)
+
+func TestWriteError_CorrelationID(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(httplistener.HeaderRequestID, "req-123")
+
+	require.NoError(t, transporthttp.WriteError(w, "transaction not found", transporthttp.CodeNotFound))
+
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	var out transporthttp.ServerError
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+	assert.Equal(t, transporthttp.ServerError{
+		Code:          transporthttp.CodeNotFound,
+		Message:       "transaction not found",
+		CorrelationID: "req-123",
+	}, out)
+}
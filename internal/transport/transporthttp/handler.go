@@ -1,30 +1,28 @@
-//go:generate mockgen -destination=./mocks/handler_mock.go -package=mocks github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp Service
+//go:generate mockgen -destination=./mocks/handler_mock.go -package=mocks github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp Service,AdminService
 
 package transporthttp
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-
-	uuid "github.com/kevinburke/go.uuid"
+	"time"
 
 	"github.com/gorilla/mux"
-	"go.uber.org/zap"
+	uuid "github.com/kevinburke/go.uuid"
 
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
 	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
 	"github.com/jeffreyyong/payment-gateway/internal/domain"
-	"github.com/jeffreyyong/payment-gateway/internal/logging"
 )
 
 const (
-	EndpointAuthorize = "/authorize"
-	EndpointCapture   = "/capture"
-	EndpointRefund    = "/refund"
-	EndpointVoid      = "/void"
+	EndpointAuthorize         = "/authorize"
+	EndpointAuthorizeComplete = "/authorize/{authorization_id}/complete"
+	EndpointCapture           = "/capture"
+	EndpointRefund            = "/refund"
+	EndpointVoid              = "/void"
 
 	ContentType     = "Content-Type"
 	ApplicationJSON = "application/json"
@@ -36,6 +34,26 @@ type Service interface {
 	Capture(ctx context.Context, capture *domain.Capture) (*domain.Transaction, error)
 	Refund(ctx context.Context, refund *domain.Refund) (*domain.Transaction, error)
 	Void(ctx context.Context, void *domain.Void) (*domain.Transaction, error)
+	CompleteAuthorization(ctx context.Context, merchantID string, authorizationID uuid.UUID) (*domain.Transaction, error)
+	SubmitAuthenticationResult(ctx context.Context, authorizationID uuid.UUID, status domain.AuthenticationStatus) (*domain.Transaction, error)
+	Audit(ctx context.Context, entry *domain.AuditEntry) error
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.APIKey, error)
+	ValidateMerchant(ctx context.Context, merchantID string) error
+	RotateAPIKey(ctx context.Context, merchantID string, keyID uuid.UUID, overlap time.Duration) (*domain.APIKey, string, error)
+	Now() time.Time
+
+	CreateCustomer(ctx context.Context, customer *domain.Customer) (*domain.Customer, error)
+	GetCustomer(ctx context.Context, merchantID string, customerID uuid.UUID) (*domain.Customer, error)
+	UpdateCustomer(ctx context.Context, customer *domain.Customer) (*domain.Customer, error)
+	DeleteCustomer(ctx context.Context, merchantID string, customerID uuid.UUID) error
+	ListCustomerTransactions(ctx context.Context, merchantID string, customerID uuid.UUID) ([]*domain.Transaction, error)
+	CreatePaymentMethod(ctx context.Context, paymentMethod *domain.PaymentMethod) (*domain.PaymentMethod, error)
+	ListPaymentMethods(ctx context.Context, merchantID string, customerID uuid.UUID) ([]*domain.PaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, merchantID string, customerID, paymentMethodID uuid.UUID) error
+	CreatePlan(ctx context.Context, plan *domain.Plan) (*domain.Plan, error)
+	CreateSubscription(ctx context.Context, subscription *domain.Subscription) (*domain.Subscription, error)
+	GetSubscription(ctx context.Context, merchantID string, subscriptionID uuid.UUID) (*domain.Subscription, error)
+	CancelSubscription(ctx context.Context, merchantID string, subscriptionID uuid.UUID) (*domain.Subscription, error)
 }
 
 // httpHandler is the http handler that will enable
@@ -43,6 +61,22 @@ type Service interface {
 type httpHandler struct {
 	service         Service
 	middlewareFuncs []mux.MiddlewareFunc
+	// simulatedACS serves the ACS endpoint to complete the 3-D Secure challenges for testing.
+	simulatedACS bool
+	// auditLog records the requests in the audit log of the service.
+	auditLog bool
+	// signing verifies the signed requests of the merchants, nil if the requests are not signed.
+	signing *requestSigning
+	// clientCertificates are the merchants keyed by the subject common name of their client certificate.
+	clientCertificates map[string]string
+	// rateLimiter limits the requests of the merchants, nil if they are not limited.
+	rateLimiter RateLimiter
+	// clientRateLimiter limits the requests of the client addresses before the authentication, nil if they are not limited.
+	clientRateLimiter RateLimiter
+	// maxBodyBytes is the maximum size of a request body.
+	maxBodyBytes int64
+	// apiVersions are the versions of the responses the merchants are pinned to.
+	apiVersions map[string]string
 }
 
 // NewHTTPHandler will create a new instance of httpHandler
@@ -51,7 +85,7 @@ func NewHTTPHandler(service Service, opts ...MiddlewareFunc) (*httpHandler, erro
 		return nil, fmt.Errorf("%w: service", errors.New("some error"))
 	}
 
-	h := &httpHandler{service: service}
+	h := &httpHandler{service: service, maxBodyBytes: DefaultMaxBodyBytes}
 	for _, opt := range opts {
 		if err := opt(h); err != nil {
 			return nil, err
@@ -62,323 +96,252 @@ func NewHTTPHandler(service Service, opts ...MiddlewareFunc) (*httpHandler, erro
 }
 
 // ApplyRoutes will link the HTTP REST endpoint to the corresponding function in this handler
+// The ACS endpoint is called by the cardholder rather than the merchant hence it is not behind the middlewares,
+// neither is the OpenAPI document. The endpoints are served under the APIVersionPrefix, the routes without it
+// are the deprecated aliases the merchants integrated with before the API was versioned.
 func (h *httpHandler) ApplyRoutes(m *httplistener.Mux) {
+	m.HandleFunc(EndpointOpenAPI, serveOpenAPI(openAPIDocument("Payment gateway API",
+		"The API of the merchants to authorize, capture, refund and void the transactions of the cards.",
+		operations, commonErrors, true))).Methods(http.MethodGet)
+	if h.simulatedACS {
+		m.HandleFunc(EndpointACS, h.ACSChallenge).Methods(http.MethodGet)
+		m.HandleFunc(EndpointACS, h.ACSResult).Methods(http.MethodPost)
+	}
+
+	m.Group(m.PathPrefix(APIVersionPrefix), h.applyEndpoints)
+	m.Group(m.NewRoute(), func(m *httplistener.Mux) {
+		h.applyEndpoints(m)
+		m.Use(deprecatedMiddleware)
+	})
+}
+
+// applyEndpoints links the endpoints of the merchants behind the middlewares.
+func (h *httpHandler) applyEndpoints(m *httplistener.Mux) {
 	m.HandleFunc(EndpointAuthorize, h.Authorize).Methods(http.MethodPost)
+	m.HandleFunc(EndpointAuthorizeComplete, h.CompleteAuthorization).Methods(http.MethodPost)
 	m.HandleFunc(EndpointCapture, h.Capture).Methods(http.MethodPost)
 	m.HandleFunc(EndpointRefund, h.Refund).Methods(http.MethodPost)
 	m.HandleFunc(EndpointVoid, h.Void).Methods(http.MethodPost)
+	m.HandleFunc(EndpointCustomers, h.CreateCustomer).Methods(http.MethodPost)
+	m.HandleFunc(EndpointCustomer, h.GetCustomer).Methods(http.MethodGet)
+	m.HandleFunc(EndpointCustomer, h.UpdateCustomer).Methods(http.MethodPut)
+	m.HandleFunc(EndpointCustomer, h.DeleteCustomer).Methods(http.MethodDelete)
+	m.HandleFunc(EndpointCustomerPaymentMethods, h.CreatePaymentMethod).Methods(http.MethodPost)
+	m.HandleFunc(EndpointCustomerPaymentMethods, h.ListPaymentMethods).Methods(http.MethodGet)
+	m.HandleFunc(EndpointCustomerPaymentMethod, h.DeletePaymentMethod).Methods(http.MethodDelete)
+	m.HandleFunc(EndpointCustomerTransactions, h.ListCustomerTransactions).Methods(http.MethodGet)
+	m.HandleFunc(EndpointPlans, h.CreatePlan).Methods(http.MethodPost)
+	m.HandleFunc(EndpointSubscriptions, h.CreateSubscription).Methods(http.MethodPost)
+	m.HandleFunc(EndpointSubscription, h.GetSubscription).Methods(http.MethodGet)
+	m.HandleFunc(EndpointSubscriptionCancel, h.CancelSubscription).Methods(http.MethodPost)
+	m.HandleFunc(EndpointAPIKeyRotate, h.RotateAPIKey).Methods(http.MethodPost)
+	if h.auditLog {
+		m.Use(newAuditMiddleware(h.service))
+	}
+	if h.clientRateLimiter != nil {
+		m.Use(clientRateLimitMiddleware(h.clientRateLimiter))
+	}
+	m.Use(maxBodyBytesMiddleware(h.maxBodyBytes))
+	if len(h.clientCertificates) > 0 {
+		m.Use(clientCertificateMiddleware(h.clientCertificates))
+	}
+	if h.signing != nil {
+		m.Use(h.signing.verify)
+	}
 	m.Use(h.middlewareFuncs...)
+	if h.signing != nil {
+		m.Use(h.signing.enforce)
+	}
+	if h.auditLog {
+		m.Use(auditAuthenticatedMiddleware)
+	}
+	m.Use(apiVersionMiddleware(h.apiVersions))
+	if h.rateLimiter != nil {
+		m.Use(rateLimitMiddleware(h.rateLimiter))
+	}
 }
 
 // Authorize handler to authorize transaction. It always return the transaction response if there's no error.
+// When the 3-D Secure authentication is requested the transaction requires action and the response has the challenge URL.
 func (h *httpHandler) Authorize(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		errMsg := "error reading request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	if len(body) == 0 {
-		errMsg := "missing request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
 	var req AuthorizeRequest
-	err = json.Unmarshal(body, &req)
-	if err != nil {
-		errMsg := "failed to unmarshal request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	if req.RequestID == uuid.Nil {
-		errMsg := "request id is not provided"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	authorization := &domain.Authorization{
-		RequestID: req.RequestID,
-		PaymentSource: domain.PaymentSource{
-			PAN: req.PaymentSource.PAN,
-			CVV: req.PaymentSource.CVV,
-			Expiry: domain.Expiry{
-				Month: req.PaymentSource.ExpiryMonth,
-				Year:  req.PaymentSource.ExpiryYear,
-			},
-		},
-		Amount: domain.Amount{
-			MinorUnits: req.Amount.MinorUnits,
-			Currency:   req.Amount.Currency,
-			Exponent:   req.Amount.Exponent,
+	endpoint{
+		request: &req,
+		failure: "failed to authorize transaction in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			authenticate := req.ThreeDSecure != nil && req.ThreeDSecure.Enabled
+			// the challenge url the cardholder completes the authentication at is not in the older versions
+			if authenticate && apiVersion(ctx) < APIVersion20211101 {
+				return nil, requestError(fmt.Sprintf("three_d_secure requires api version %s or later", APIVersion20211101))
+			}
+			if authenticate && !h.simulatedACS {
+				return nil, endpointError{"3-D Secure authentication is not available", domain.ErrUnprocessable}
+			}
+
+			t, err := h.service.Authorize(ctx, &domain.Authorization{
+				RequestID:  req.RequestID,
+				MerchantID: appcontext.GetMerchant(ctx),
+				ShopperIP:  req.ShopperIP,
+				PaymentSource: domain.PaymentSource{
+					PAN: req.PaymentSource.PAN,
+					CVV: req.PaymentSource.CVV,
+					Expiry: domain.Expiry{
+						Month: req.PaymentSource.ExpiryMonth,
+						Year:  req.PaymentSource.ExpiryYear,
+					},
+				},
+				Amount:             mapToAmount(req.Amount),
+				Authenticate:       authenticate,
+				Initiator:          domain.Initiator(req.Initiator),
+				PaymentMethodID:    req.PaymentMethodID,
+				StorePaymentMethod: req.StorePaymentMethod,
+				CustomerID:         req.CustomerID,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			resp := mapToTransactionResp(t)
+			if t.RequiresAction() && t.Authentication.ChallengeRequired() {
+				resp.Authentication.ChallengeURL = challengeURL(r, t.AuthorizationID)
+			}
+			return resp, nil
 		},
-	}
-
-	t, err := h.service.Authorize(ctx, authorization)
-	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrUnprocessable):
-			_ = WriteError(w, err.Error(), CodeUnprocessable)
-			return
-		default:
-			errMsg := "failed to authorize transaction in service"
-			_ = WriteError(w, errMsg, CodeUnknownFailure)
-			return
-		}
-	}
+	}.ServeHTTP(w, r)
+}
 
-	w.Header().Add(ContentType, ApplicationJSON)
-	err = json.NewEncoder(w).Encode(mapToTransactionResp(t))
-	if err != nil {
-		errMsg := "error encoding json response"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeUnknownFailure)
-		return
-	}
+// CompleteAuthorization handler to complete the authorization once the cardholder has completed the 3-D Secure challenge.
+// It always return the transaction response if there's no error.
+func (h *httpHandler) CompleteAuthorization(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure:  "failed to complete authorization in service",
+		notFound: "unable to find the transaction with the authorization ID",
+		call: func(ctx context.Context) (interface{}, error) {
+			authorizationID, err := pathID(r, "authorization_id")
+			if err != nil {
+				return nil, err
+			}
+			return transactionResp(h.service.CompleteAuthorization(ctx, appcontext.GetMerchant(ctx), authorizationID))
+		},
+	}.ServeHTTP(w, r)
 }
 
 // Capture handler to capture transaction. It always return the transaction response if there's no error.
 func (h *httpHandler) Capture(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		errMsg := "error reading request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	if len(body) == 0 {
-		errMsg := "missing request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
 	var req CaptureRequest
-	err = json.Unmarshal(body, &req)
-	if err != nil {
-		errMsg := "failed to unmarshal request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	if req.RequestID == uuid.Nil {
-		errMsg := "request id is not provided"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	if req.AuthorizationID == uuid.Nil {
-		errMsg := "authorization id is not provided"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	capture := &domain.Capture{
-		RequestID:       req.RequestID,
-		AuthorizationID: req.AuthorizationID,
-		Amount: domain.Amount{
-			MinorUnits: req.Amount.MinorUnits,
-			Currency:   req.Amount.Currency,
-			Exponent:   req.Amount.Exponent,
+	endpoint{
+		request:  &req,
+		failure:  "failed to capture transaction in service",
+		notFound: "unable to find the transaction with the authorization ID",
+		call: func(ctx context.Context) (interface{}, error) {
+			return transactionResp(h.service.Capture(ctx, &domain.Capture{
+				RequestID:       req.RequestID,
+				MerchantID:      appcontext.GetMerchant(ctx),
+				AuthorizationID: req.AuthorizationID,
+				Amount:          mapToAmount(req.Amount),
+			}))
 		},
-	}
-
-	t, err := h.service.Capture(ctx, capture)
-	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrTransactionNotFound):
-			errMsg := "unable to find the transaction with the authorization ID"
-			_ = WriteError(w, errMsg, CodeNotFound)
-			return
-		case errors.Is(err, domain.ErrUnprocessable):
-			_ = WriteError(w, err.Error(), CodeUnprocessable)
-			return
-		default:
-			errMsg := "failed to capture transaction in service"
-			_ = WriteError(w, errMsg, CodeUnknownFailure)
-			return
-		}
-	}
-
-	w.Header().Add(ContentType, ApplicationJSON)
-	err = json.NewEncoder(w).Encode(mapToTransactionResp(t))
-	if err != nil {
-		errMsg := "error encoding json response"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeUnknownFailure)
-		return
-	}
+	}.ServeHTTP(w, r)
 }
 
 // Refund handler to refund transaction. It always return the transaction response if there's no error.
 func (h *httpHandler) Refund(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		errMsg := "error reading request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	if len(body) == 0 {
-		errMsg := "missing request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
 	var req RefundRequest
-	err = json.Unmarshal(body, &req)
-	if err != nil {
-		errMsg := "failed to unmarshal request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	if req.RequestID == uuid.Nil {
-		errMsg := "request id is not provided"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	if req.AuthorizationID == uuid.Nil {
-		errMsg := "authorization id is not provided"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
-	}
-
-	refund := &domain.Refund{
-		RequestID:       req.RequestID,
-		AuthorizationID: req.AuthorizationID,
-		Amount: domain.Amount{
-			MinorUnits: req.Amount.MinorUnits,
-			Currency:   req.Amount.Currency,
-			Exponent:   req.Amount.Exponent,
+	endpoint{
+		request:  &req,
+		failure:  "failed to refund transaction in service",
+		notFound: "unable to find the transaction with the authorization ID",
+		call: func(ctx context.Context) (interface{}, error) {
+			return transactionResp(h.service.Refund(ctx, &domain.Refund{
+				RequestID:       req.RequestID,
+				MerchantID:      appcontext.GetMerchant(ctx),
+				AuthorizationID: req.AuthorizationID,
+				Amount:          mapToAmount(req.Amount),
+			}))
 		},
-	}
-
-	t, err := h.service.Refund(ctx, refund)
-	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrTransactionNotFound):
-			errMsg := "unable to find the transaction with the authorization ID"
-			_ = WriteError(w, errMsg, CodeNotFound)
-			return
-		case errors.Is(err, domain.ErrUnprocessable):
-			_ = WriteError(w, err.Error(), CodeUnprocessable)
-			return
-		default:
-			errMsg := "failed to refund transaction in service"
-			_ = WriteError(w, errMsg, CodeUnknownFailure)
-			return
-		}
-	}
-
-	w.Header().Add(ContentType, ApplicationJSON)
-	err = json.NewEncoder(w).Encode(mapToTransactionResp(t))
-	if err != nil {
-		errMsg := "error encoding json response"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeUnknownFailure)
-		return
-	}
+	}.ServeHTTP(w, r)
 }
 
 // Void handler to void transaction. It always return the transaction response if there's no error.
 func (h *httpHandler) Void(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	var req VoidRequest
+	endpoint{
+		request:  &req,
+		failure:  "failed to void transaction in service",
+		notFound: "unable to find the transaction with the authorization ID",
+		call: func(ctx context.Context) (interface{}, error) {
+			return transactionResp(h.service.Void(ctx, &domain.Void{
+				RequestID:       req.RequestID,
+				MerchantID:      appcontext.GetMerchant(ctx),
+				AuthorizationID: req.AuthorizationID,
+			}))
+		},
+	}.ServeHTTP(w, r)
+}
 
-	body, err := ioutil.ReadAll(r.Body)
+// transactionResp maps the transaction returned by the service to the response unless the service failed.
+func transactionResp(t *domain.Transaction, err error) (interface{}, error) {
 	if err != nil {
-		errMsg := "error reading request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
+		return nil, err
 	}
+	return mapToTransactionResp(t), nil
+}
 
-	if len(body) == 0 {
-		errMsg := "missing request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
+// helper mapper function to map to the amount domain.
+func mapToAmount(a Amount) domain.Amount {
+	return domain.Amount{
+		MinorUnits: a.MinorUnits,
+		Currency:   a.Currency,
+		Exponent:   a.Exponent,
 	}
+}
 
-	var req VoidRequest
-	err = json.Unmarshal(body, &req)
-	if err != nil {
-		errMsg := "failed to unmarshal request body"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
+// helper mapper function to map to transaction response.
+func mapToTransactionResp(t *domain.Transaction) Transaction {
+	var fxRate *FXRate
+	if t.FXRate != nil {
+		fxRate = &FXRate{
+			From:       t.FXRate.From,
+			To:         t.FXRate.To,
+			Rate:       t.FXRate.Rate.FloatString(domain.FXRatePrecision),
+			LockedDate: t.FXRate.LockedDate,
+		}
 	}
 
-	if req.RequestID == uuid.Nil {
-		errMsg := "request id is not provided"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
+	var risk *Risk
+	if t.Risk != nil {
+		risk = &Risk{
+			Decision: string(t.Risk.Decision),
+			Score:    t.Risk.Score,
+			Reasons:  t.Risk.Reasons,
+		}
 	}
 
-	if req.AuthorizationID == uuid.Nil {
-		errMsg := "authorization id is not provided"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeBadRequest)
-		return
+	var authentication *Authentication
+	if t.Authentication != nil {
+		authentication = &Authentication{
+			Status:         string(t.Authentication.Status),
+			ECI:            t.Authentication.ECI,
+			LiabilityShift: t.Authentication.LiabilityShift,
+		}
 	}
 
-	void := &domain.Void{
-		RequestID:       req.RequestID,
-		AuthorizationID: req.AuthorizationID,
+	var paymentMethodID, customerID *uuid.UUID
+	if t.PaymentMethodID != uuid.Nil {
+		paymentMethodID = &t.PaymentMethodID
 	}
-
-	t, err := h.service.Void(ctx, void)
-	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrTransactionNotFound):
-			errMsg := "unable to find the transaction with the authorization ID"
-			_ = WriteError(w, errMsg, CodeNotFound)
-			return
-		case errors.Is(err, domain.ErrUnprocessable):
-			_ = WriteError(w, err.Error(), CodeUnprocessable)
-			return
-		default:
-			errMsg := "failed to void transaction in service"
-			_ = WriteError(w, errMsg, CodeUnknownFailure)
-			return
-		}
+	if t.CustomerID != uuid.Nil {
+		customerID = &t.CustomerID
 	}
 
-	w.Header().Add(ContentType, ApplicationJSON)
-	err = json.NewEncoder(w).Encode(mapToTransactionResp(t))
-	if err != nil {
-		errMsg := "error encoding json response"
-		logging.Error(ctx, errMsg, zap.Error(err))
-		_ = WriteError(w, errMsg, CodeUnknownFailure)
-		return
+	status := TransactionStatusDeclined
+	switch {
+	case t.RequiresAction():
+		status = TransactionStatusRequiresAction
+	case t.AuthorizationDate() != nil:
+		status = TransactionStatusAuthorized
 	}
-}
 
-// helper mapper function to map to transaction response.
-func mapToTransactionResp(t *domain.Transaction) Transaction {
 	return Transaction{
 		ID:              t.ID,
 		AuthorizationID: t.AuthorizationID,
@@ -398,6 +361,47 @@ func mapToTransactionResp(t *domain.Transaction) Transaction {
 			Exponent:   t.RefundedAmount.Exponent,
 			Currency:   t.RefundedAmount.Currency,
 		},
-		IsVoided: t.Voided(),
+		FeeAmount: Amount{
+			MinorUnits: t.FeeAmount.MinorUnits,
+			Exponent:   t.FeeAmount.Exponent,
+			Currency:   t.FeeAmount.Currency,
+		},
+		NetAmount: Amount{
+			MinorUnits: t.NetAmount.MinorUnits,
+			Exponent:   t.NetAmount.Exponent,
+			Currency:   t.NetAmount.Currency,
+		},
+		FXRate:               fxRate,
+		Risk:                 risk,
+		Authentication:       authentication,
+		Initiator:            string(t.Initiator),
+		PaymentMethodID:      paymentMethodID,
+		CustomerID:           customerID,
+		NetworkTransactionID: t.NetworkTransactionID,
+		Status:               status,
+		IsVoided:             t.Voided(),
+		State:                transactionState(t),
+	}
+}
+
+// transactionState returns the state of the lifecycle of the transaction.
+func transactionState(t *domain.Transaction) string {
+	switch {
+	case t.RequiresAction():
+		return TransactionStateRequiresAction
+	case t.AuthorizationDate() == nil:
+		return TransactionStateDeclined
+	case t.Voided():
+		return TransactionStateVoided
+	case t.Refunded() && t.RefundedAmount.MinorUnits < t.CapturedAmount.MinorUnits:
+		return TransactionStatePartiallyRefunded
+	case t.Refunded():
+		return TransactionStateRefunded
+	case t.Captured() && t.CapturedAmount.MinorUnits < t.AuthorizedAmount.MinorUnits:
+		return TransactionStatePartiallyCaptured
+	case t.Captured():
+		return TransactionStateCaptured
+	default:
+		return TransactionStateAuthorized
 	}
 }
@@ -2,21 +2,25 @@ package transporthttp_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
 	uuid "github.com/kevinburke/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
 	"github.com/jeffreyyong/payment-gateway/internal/domain"
 	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
 	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
@@ -146,7 +150,9 @@ func TestHandler_Authorize(t *testing.T) {
 				bytes.NewReader([]byte(validReqBody)),
 			)
 
-			h.Authorize(w, r)
+			// the responses of the first version are the ones of the baseline
+			r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20210618)
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
 			res := w.Result()
 			defer res.Body.Close()
 			assert.Equal(t, http.StatusOK, res.StatusCode)
@@ -193,6 +199,15 @@ func TestHandler_Authorize(t *testing.T) {
 				http.StatusInternalServerError,
 				`{"code":"unknown_failure","message":"failed to authorize transaction in service"}`,
 			},
+			{
+				"service returns velocity limit exceeded",
+				bytes.NewReader([]byte(validReqBody)),
+				func(m *handlerMocks) {
+					m.service.EXPECT().Authorize(gomock.Any(), authorization).Return(nil, domain.ErrVelocityLimitExceeded)
+				},
+				http.StatusTooManyRequests,
+				`{"code":"velocity_limit_exceeded","message":"velocity limit exceeded"}`,
+			},
 		}
 
 		for _, tt := range failureCases {
@@ -324,7 +339,9 @@ func TestHandler_Void(t *testing.T) {
 				bytes.NewReader([]byte(validReqBody)),
 			)
 
-			h.Void(w, r)
+			// the responses of the first version are the ones of the baseline
+			r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20210618)
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
 			res := w.Result()
 			defer res.Body.Close()
 			assert.Equal(t, http.StatusOK, res.StatusCode)
@@ -535,7 +552,9 @@ func TestHandler_Capture(t *testing.T) {
 				bytes.NewReader([]byte(validReqBody)),
 			)
 
-			h.Capture(w, r)
+			// the responses of the first version are the ones of the baseline
+			r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20210618)
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
 			res := w.Result()
 			defer res.Body.Close()
 			assert.Equal(t, http.StatusOK, res.StatusCode)
@@ -768,7 +787,9 @@ func TestHandler_Refund(t *testing.T) {
 				bytes.NewReader([]byte(validReqBody)),
 			)
 
-			h.Refund(w, r)
+			// the responses of the first version are the ones of the baseline
+			r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20210618)
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
 			res := w.Result()
 			defer res.Body.Close()
 			assert.Equal(t, http.StatusOK, res.StatusCode)
@@ -869,3 +890,300 @@ func TestHandler_Refund(t *testing.T) {
 		}
 	})
 }
+
+func TestHandler_Authorize_ThreeDSecure(t *testing.T) {
+	var (
+		mockAuthorizationID = uuid.NewV4()
+		reqBody             = `
+	{
+		"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06",
+		"payment_source": {
+			"pan": "5159640776411853",
+			"cvv": "123",
+			"expiry_month": 1,
+			"expiry_year": 21
+		},
+		"amount": {
+			"minor_units": 10555,
+			"currency": "GBP",
+			"exponent": 2
+		},
+		"three_d_secure": {
+			"enabled": true
+		}
+	}`
+
+		mockTransaction = &domain.Transaction{
+			ID:              uuid.NewV4(),
+			AuthorizationID: mockAuthorizationID,
+			Amount:          domain.Amount{MinorUnits: 10555, Currency: "GBP", Exponent: 2},
+			Authentication:  &domain.Authentication{Status: domain.AuthenticationStatusChallengeRequired},
+			PaymentActionSummary: []*domain.PaymentAction{
+				{
+					Type:   domain.PaymentActionTypeAuthorization,
+					Status: domain.PaymentActionStatusPending,
+				},
+			},
+		}
+	)
+
+	t.Run("should return the challenge url when the transaction requires action", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		srv := mocks.NewMockService(ctrl)
+		srv.EXPECT().Authorize(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, authorization *domain.Authorization) (*domain.Transaction, error) {
+				assert.True(t, authorization.Authenticate)
+				return mockTransaction, nil
+			})
+
+		h, err := transporthttp.NewHTTPHandler(srv, transporthttp.WithSimulatedACS())
+		require.NoError(t, err)
+
+		// the status and the authentication of the transactions are in the responses of APIVersion20211101
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, transporthttp.EndpointAuthorize, strings.NewReader(reqBody))
+		r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20211101)
+
+		httplistener.HTTPHandler(h).ServeHTTP(w, r)
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var out transporthttp.Transaction
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+		assert.Equal(t, transporthttp.TransactionStatusRequiresAction, out.Status)
+		assert.Nil(t, out.AuthorizedTime)
+		assert.Equal(t, &transporthttp.Authentication{
+			Status:       string(domain.AuthenticationStatusChallengeRequired),
+			ChallengeURL: "http://example.com/acs/" + mockAuthorizationID.String(),
+		}, out.Authentication)
+	})
+
+	t.Run("should reject the authentication in a version without the challenge url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		h, err := transporthttp.NewHTTPHandler(mocks.NewMockService(ctrl), transporthttp.WithSimulatedACS())
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, transporthttp.EndpointAuthorize, strings.NewReader(reqBody))
+		r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20210618)
+
+		httplistener.HTTPHandler(h).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var out transporthttp.ServerError
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&out))
+		assert.Equal(t, "three_d_secure requires api version 2021-11-01 or later", out.Message)
+	})
+
+	t.Run("should reject the authentication without an ACS", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		h, err := transporthttp.NewHTTPHandler(mocks.NewMockService(ctrl))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, transporthttp.EndpointAuthorize, strings.NewReader(reqBody))
+
+		h.Authorize(w, r)
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+		respBody, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"code":"unprocessable","message":"3-D Secure authentication is not available"}`, strings.TrimSuffix(string(respBody), "\n"))
+	})
+}
+
+func TestHandler_ACSResult(t *testing.T) {
+	authorizationID := uuid.NewV4()
+	authenticated := domain.NewAuthenticationResult(domain.CardSchemeVisa, domain.AuthenticationStatusAuthenticated)
+
+	testCases := []struct {
+		description          string
+		authorizationID      string
+		form                 url.Values
+		setupMocks           func(srv *mocks.MockService)
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			"should submit the result of the challenge",
+			authorizationID.String(),
+			url.Values{"trans_status": {"Y"}},
+			func(srv *mocks.MockService) {
+				srv.EXPECT().SubmitAuthenticationResult(gomock.Any(), authorizationID, domain.AuthenticationStatusAuthenticated).
+					Return(&domain.Transaction{AuthorizationID: authorizationID, Authentication: &authenticated}, nil)
+			},
+			http.StatusOK,
+			`{"status":"authenticated","eci":"05","liability_shift":true}`,
+		},
+		{
+			"invalid authorization id",
+			"some-id",
+			url.Values{"trans_status": {"Y"}},
+			nil,
+			http.StatusBadRequest,
+			`{"code":"bad_request","message":"invalid authorization_id"}`,
+		},
+		{
+			"invalid trans status",
+			authorizationID.String(),
+			url.Values{"trans_status": {"X"}},
+			nil,
+			http.StatusBadRequest,
+			`{"code":"bad_request","message":"trans_status must be one of Y, A, N or U"}`,
+		},
+		{
+			"service returns unprocessable",
+			authorizationID.String(),
+			url.Values{"trans_status": {"N"}},
+			func(srv *mocks.MockService) {
+				srv.EXPECT().SubmitAuthenticationResult(gomock.Any(), authorizationID, domain.AuthenticationStatusFailed).
+					Return(nil, domain.ErrUnprocessable)
+			},
+			http.StatusUnprocessableEntity,
+			`{"code":"unprocessable","message":"unprocessable"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(srv)
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv, transporthttp.WithSimulatedACS())
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/acs/"+tt.authorizationID, strings.NewReader(tt.form.Encode()))
+			r.Header.Set(transporthttp.ContentType, "application/x-www-form-urlencoded")
+			r = mux.SetURLVars(r, map[string]string{"authorization_id": tt.authorizationID})
+
+			h.ACSResult(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			respBody, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedResponseBody, strings.TrimSuffix(string(respBody), "\n"))
+		})
+	}
+}
+
+func TestHandler_CompleteAuthorization(t *testing.T) {
+	authorizationID := uuid.NewV4()
+	authorizationDate := time.Date(2021, 06, 18, 12, 31, 0, 0, time.UTC)
+	authenticated := domain.NewAuthenticationResult(domain.CardSchemeMastercard, domain.AuthenticationStatusAuthenticated)
+	amount := domain.Amount{MinorUnits: 10555, Currency: "GBP", Exponent: 2}
+
+	mockTransaction := &domain.Transaction{
+		AuthorizationID: authorizationID,
+		Amount:          amount,
+		Authentication:  &authenticated,
+		PaymentActionSummary: []*domain.PaymentAction{
+			{
+				Type:          domain.PaymentActionTypeAuthorization,
+				Status:        domain.PaymentActionStatusSuccess,
+				ProcessedDate: authorizationDate,
+				Amount:        &amount,
+			},
+		},
+	}
+	mockTransaction.Amounts()
+
+	testCases := []struct {
+		description        string
+		authorizationID    string
+		setupMocks         func(srv *mocks.MockService)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			"should complete the authorization",
+			authorizationID.String(),
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CompleteAuthorization(gomock.Any(), "", authorizationID).Return(mockTransaction, nil)
+			},
+			http.StatusOK,
+			"",
+		},
+		{
+			"invalid authorization id",
+			"some-id",
+			nil,
+			http.StatusBadRequest,
+			transporthttp.CodeBadRequest,
+		},
+		{
+			"service returns not found",
+			authorizationID.String(),
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CompleteAuthorization(gomock.Any(), "", authorizationID).Return(nil, domain.ErrTransactionNotFound)
+			},
+			http.StatusNotFound,
+			transporthttp.CodeNotFound,
+		},
+		{
+			"service returns unprocessable when the authentication failed",
+			authorizationID.String(),
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CompleteAuthorization(gomock.Any(), "", authorizationID).Return(nil, domain.ErrUnprocessable)
+			},
+			http.StatusUnprocessableEntity,
+			transporthttp.CodeUnprocessable,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(srv)
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/authorize/"+tt.authorizationID+"/complete", nil)
+			r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20211101)
+
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedCode != "" {
+				var out transporthttp.ServerError
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+				assert.Equal(t, tt.expectedCode, out.Code)
+				return
+			}
+
+			var out transporthttp.Transaction
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+			assert.Equal(t, transporthttp.TransactionStatusAuthorized, out.Status)
+			assert.Equal(t, &authorizationDate, out.AuthorizedTime)
+			assert.Equal(t, &transporthttp.Authentication{
+				Status:         string(domain.AuthenticationStatusAuthenticated),
+				ECI:            "02",
+				LiabilityShift: true,
+			}, out.Authentication)
+		})
+	}
+}
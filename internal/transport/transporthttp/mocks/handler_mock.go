@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp (interfaces: Service)
+// Source: github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp (interfaces: Service,AdminService)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -7,9 +7,11 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/jeffreyyong/payment-gateway/internal/domain"
+	uuid "github.com/kevinburke/go.uuid"
 )
 
 // MockService is a mock of Service interface.
@@ -35,6 +37,35 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// Audit mocks base method.
+func (m *MockService) Audit(arg0 context.Context, arg1 *domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Audit indicates an expected call of Audit.
+func (mr *MockServiceMockRecorder) Audit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockService)(nil).Audit), arg0, arg1)
+}
+
+// AuthenticateAPIKey mocks base method.
+func (m *MockService) AuthenticateAPIKey(arg0 context.Context, arg1 string) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockServiceMockRecorder) AuthenticateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockService)(nil).AuthenticateAPIKey), arg0, arg1)
+}
+
 // Authorize mocks base method.
 func (m *MockService) Authorize(arg0 context.Context, arg1 *domain.Authorization) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +81,21 @@ func (mr *MockServiceMockRecorder) Authorize(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockService)(nil).Authorize), arg0, arg1)
 }
 
+// CancelSubscription mocks base method.
+func (m *MockService) CancelSubscription(arg0 context.Context, arg1 string, arg2 uuid.UUID) (*domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSubscription", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelSubscription indicates an expected call of CancelSubscription.
+func (mr *MockServiceMockRecorder) CancelSubscription(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSubscription", reflect.TypeOf((*MockService)(nil).CancelSubscription), arg0, arg1, arg2)
+}
+
 // Capture mocks base method.
 func (m *MockService) Capture(arg0 context.Context, arg1 *domain.Capture) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +111,183 @@ func (mr *MockServiceMockRecorder) Capture(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockService)(nil).Capture), arg0, arg1)
 }
 
+// CompleteAuthorization mocks base method.
+func (m *MockService) CompleteAuthorization(arg0 context.Context, arg1 string, arg2 uuid.UUID) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAuthorization", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteAuthorization indicates an expected call of CompleteAuthorization.
+func (mr *MockServiceMockRecorder) CompleteAuthorization(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAuthorization", reflect.TypeOf((*MockService)(nil).CompleteAuthorization), arg0, arg1, arg2)
+}
+
+// CreateCustomer mocks base method.
+func (m *MockService) CreateCustomer(arg0 context.Context, arg1 *domain.Customer) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCustomer", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCustomer indicates an expected call of CreateCustomer.
+func (mr *MockServiceMockRecorder) CreateCustomer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCustomer", reflect.TypeOf((*MockService)(nil).CreateCustomer), arg0, arg1)
+}
+
+// CreatePaymentMethod mocks base method.
+func (m *MockService) CreatePaymentMethod(arg0 context.Context, arg1 *domain.PaymentMethod) (*domain.PaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentMethod", arg0, arg1)
+	ret0, _ := ret[0].(*domain.PaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentMethod indicates an expected call of CreatePaymentMethod.
+func (mr *MockServiceMockRecorder) CreatePaymentMethod(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentMethod", reflect.TypeOf((*MockService)(nil).CreatePaymentMethod), arg0, arg1)
+}
+
+// CreatePlan mocks base method.
+func (m *MockService) CreatePlan(arg0 context.Context, arg1 *domain.Plan) (*domain.Plan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePlan", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Plan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePlan indicates an expected call of CreatePlan.
+func (mr *MockServiceMockRecorder) CreatePlan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlan", reflect.TypeOf((*MockService)(nil).CreatePlan), arg0, arg1)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockService) CreateSubscription(arg0 context.Context, arg1 *domain.Subscription) (*domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockServiceMockRecorder) CreateSubscription(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockService)(nil).CreateSubscription), arg0, arg1)
+}
+
+// DeleteCustomer mocks base method.
+func (m *MockService) DeleteCustomer(arg0 context.Context, arg1 string, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCustomer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCustomer indicates an expected call of DeleteCustomer.
+func (mr *MockServiceMockRecorder) DeleteCustomer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomer", reflect.TypeOf((*MockService)(nil).DeleteCustomer), arg0, arg1, arg2)
+}
+
+// DeletePaymentMethod mocks base method.
+func (m *MockService) DeletePaymentMethod(arg0 context.Context, arg1 string, arg2, arg3 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePaymentMethod", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePaymentMethod indicates an expected call of DeletePaymentMethod.
+func (mr *MockServiceMockRecorder) DeletePaymentMethod(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePaymentMethod", reflect.TypeOf((*MockService)(nil).DeletePaymentMethod), arg0, arg1, arg2, arg3)
+}
+
+// GetCustomer mocks base method.
+func (m *MockService) GetCustomer(arg0 context.Context, arg1 string, arg2 uuid.UUID) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomer indicates an expected call of GetCustomer.
+func (mr *MockServiceMockRecorder) GetCustomer(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomer", reflect.TypeOf((*MockService)(nil).GetCustomer), arg0, arg1, arg2)
+}
+
+// GetSubscription mocks base method.
+func (m *MockService) GetSubscription(arg0 context.Context, arg1 string, arg2 uuid.UUID) (*domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscription", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscription indicates an expected call of GetSubscription.
+func (mr *MockServiceMockRecorder) GetSubscription(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockService)(nil).GetSubscription), arg0, arg1, arg2)
+}
+
+// ListCustomerTransactions mocks base method.
+func (m *MockService) ListCustomerTransactions(arg0 context.Context, arg1 string, arg2 uuid.UUID) ([]*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCustomerTransactions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCustomerTransactions indicates an expected call of ListCustomerTransactions.
+func (mr *MockServiceMockRecorder) ListCustomerTransactions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomerTransactions", reflect.TypeOf((*MockService)(nil).ListCustomerTransactions), arg0, arg1, arg2)
+}
+
+// ListPaymentMethods mocks base method.
+func (m *MockService) ListPaymentMethods(arg0 context.Context, arg1 string, arg2 uuid.UUID) ([]*domain.PaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentMethods", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.PaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentMethods indicates an expected call of ListPaymentMethods.
+func (mr *MockServiceMockRecorder) ListPaymentMethods(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentMethods", reflect.TypeOf((*MockService)(nil).ListPaymentMethods), arg0, arg1, arg2)
+}
+
+// Now mocks base method.
+func (m *MockService) Now() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Now")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// Now indicates an expected call of Now.
+func (mr *MockServiceMockRecorder) Now() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Now", reflect.TypeOf((*MockService)(nil).Now))
+}
+
 // Refund mocks base method.
 func (m *MockService) Refund(arg0 context.Context, arg1 *domain.Refund) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +303,66 @@ func (mr *MockServiceMockRecorder) Refund(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockService)(nil).Refund), arg0, arg1)
 }
 
+// RotateAPIKey mocks base method.
+func (m *MockService) RotateAPIKey(arg0 context.Context, arg1 string, arg2 uuid.UUID, arg3 time.Duration) (*domain.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAPIKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RotateAPIKey indicates an expected call of RotateAPIKey.
+func (mr *MockServiceMockRecorder) RotateAPIKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAPIKey", reflect.TypeOf((*MockService)(nil).RotateAPIKey), arg0, arg1, arg2, arg3)
+}
+
+// SubmitAuthenticationResult mocks base method.
+func (m *MockService) SubmitAuthenticationResult(arg0 context.Context, arg1 uuid.UUID, arg2 domain.AuthenticationStatus) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitAuthenticationResult", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitAuthenticationResult indicates an expected call of SubmitAuthenticationResult.
+func (mr *MockServiceMockRecorder) SubmitAuthenticationResult(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitAuthenticationResult", reflect.TypeOf((*MockService)(nil).SubmitAuthenticationResult), arg0, arg1, arg2)
+}
+
+// UpdateCustomer mocks base method.
+func (m *MockService) UpdateCustomer(arg0 context.Context, arg1 *domain.Customer) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomer", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCustomer indicates an expected call of UpdateCustomer.
+func (mr *MockServiceMockRecorder) UpdateCustomer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomer", reflect.TypeOf((*MockService)(nil).UpdateCustomer), arg0, arg1)
+}
+
+// ValidateMerchant mocks base method.
+func (m *MockService) ValidateMerchant(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateMerchant", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateMerchant indicates an expected call of ValidateMerchant.
+func (mr *MockServiceMockRecorder) ValidateMerchant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateMerchant", reflect.TypeOf((*MockService)(nil).ValidateMerchant), arg0, arg1)
+}
+
 // Void mocks base method.
 func (m *MockService) Void(arg0 context.Context, arg1 *domain.Void) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -94,3 +377,145 @@ func (mr *MockServiceMockRecorder) Void(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockService)(nil).Void), arg0, arg1)
 }
+
+// MockAdminService is a mock of AdminService interface.
+type MockAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceMockRecorder
+}
+
+// MockAdminServiceMockRecorder is the mock recorder for MockAdminService.
+type MockAdminServiceMockRecorder struct {
+	mock *MockAdminService
+}
+
+// NewMockAdminService creates a new mock instance.
+func NewMockAdminService(ctrl *gomock.Controller) *MockAdminService {
+	mock := &MockAdminService{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// Audit mocks base method.
+func (m *MockAdminService) Audit(arg0 context.Context, arg1 *domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Audit indicates an expected call of Audit.
+func (mr *MockAdminServiceMockRecorder) Audit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockAdminService)(nil).Audit), arg0, arg1)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAdminService) CreateAPIKey(arg0 context.Context, arg1 string, arg2 []domain.Scope, arg3 *time.Time) (*domain.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAdminServiceMockRecorder) CreateAPIKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAdminService)(nil).CreateAPIKey), arg0, arg1, arg2, arg3)
+}
+
+// CreateMerchant mocks base method.
+func (m *MockAdminService) CreateMerchant(arg0 context.Context, arg1 *domain.Merchant) (*domain.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMerchant", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMerchant indicates an expected call of CreateMerchant.
+func (mr *MockAdminServiceMockRecorder) CreateMerchant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMerchant", reflect.TypeOf((*MockAdminService)(nil).CreateMerchant), arg0, arg1)
+}
+
+// GetMerchant mocks base method.
+func (m *MockAdminService) GetMerchant(arg0 context.Context, arg1 string) (*domain.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMerchant", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMerchant indicates an expected call of GetMerchant.
+func (mr *MockAdminServiceMockRecorder) GetMerchant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchant", reflect.TypeOf((*MockAdminService)(nil).GetMerchant), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAdminService) ListAPIKeys(arg0 context.Context, arg1 string) ([]*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAdminServiceMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAdminService)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAdminService) RevokeAPIKey(arg0 context.Context, arg1 string, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAdminServiceMockRecorder) RevokeAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAdminService)(nil).RevokeAPIKey), arg0, arg1, arg2)
+}
+
+// SetMerchantStatus mocks base method.
+func (m *MockAdminService) SetMerchantStatus(arg0 context.Context, arg1 string, arg2 domain.MerchantStatus) (*domain.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMerchantStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMerchantStatus indicates an expected call of SetMerchantStatus.
+func (mr *MockAdminServiceMockRecorder) SetMerchantStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMerchantStatus", reflect.TypeOf((*MockAdminService)(nil).SetMerchantStatus), arg0, arg1, arg2)
+}
+
+// UpdateMerchantSettings mocks base method.
+func (m *MockAdminService) UpdateMerchantSettings(arg0 context.Context, arg1 string, arg2 domain.MerchantSettings) (*domain.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMerchantSettings", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMerchantSettings indicates an expected call of UpdateMerchantSettings.
+func (mr *MockAdminServiceMockRecorder) UpdateMerchantSettings(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchantSettings", reflect.TypeOf((*MockAdminService)(nil).UpdateMerchantSettings), arg0, arg1, arg2)
+}
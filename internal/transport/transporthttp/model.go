@@ -12,6 +12,30 @@ type AuthorizeRequest struct {
 	Amount        Amount        `json:"amount"`
 	RequestID     uuid.UUID     `json:"request_id"`
 	Description   string        `json:"description"`
+	ThreeDSecure  *ThreeDSecure `json:"three_d_secure,omitempty"`
+	// Initiator is customer (default) or merchant for a merchant-initiated authorization with a stored payment method.
+	Initiator string `json:"initiator,omitempty"`
+	// PaymentMethodID is the stored payment method to authorize instead of the payment source.
+	PaymentMethodID uuid.UUID `json:"payment_method_id"`
+	// StorePaymentMethod stores the payment source for the customer once authorized.
+	StorePaymentMethod bool `json:"store_payment_method,omitempty"`
+	// CustomerID is the customer the transaction is linked to, it is required to store the payment source.
+	CustomerID uuid.UUID `json:"customer_id"`
+	// ShopperIP is the IP address of the device of the shopper, the authorizations are limited per shopper IP with it.
+	ShopperIP string `json:"shopper_ip,omitempty"`
+	// Recipient is accepted for the merchants which sent it before the unknown fields were rejected, it is not used.
+	Recipient *Recipient `json:"recipient,omitempty"`
+}
+
+// Recipient of the payment sent by the merchants integrated with the first version of the API.
+type Recipient struct {
+	Postcode string `json:"postcode,omitempty"`
+	LastName string `json:"last_name,omitempty"`
+}
+
+// ThreeDSecure request to authenticate the cardholder before the authorization
+type ThreeDSecure struct {
+	Enabled bool `json:"enabled"`
 }
 
 // CaptureRequest to unmarshal capture request into
@@ -49,13 +73,199 @@ type Amount struct {
 	Currency   string `json:"currency"`
 }
 
-// Transaction response
+// Transaction response, the versions before APIVersion20211101 only have the fields of baselineTransactionFields.
 type Transaction struct {
-	ID               uuid.UUID  `json:"id"`
-	AuthorizationID  uuid.UUID  `json:"authorization_id"`
-	AuthorizedTime   *time.Time `json:"authorization_date,omitempty"`
-	AuthorizedAmount Amount     `json:"authorized_amount"`
-	CapturedAmount   Amount     `json:"captured_amount"`
-	RefundedAmount   Amount     `json:"refunded_amount"`
-	IsVoided         bool       `json:"is_voided"`
+	ID               uuid.UUID       `json:"id"`
+	AuthorizationID  uuid.UUID       `json:"authorization_id"`
+	AuthorizedTime   *time.Time      `json:"authorization_date,omitempty"`
+	AuthorizedAmount Amount          `json:"authorized_amount"`
+	CapturedAmount   Amount          `json:"captured_amount"`
+	RefundedAmount   Amount          `json:"refunded_amount"`
+	FeeAmount        Amount          `json:"fee_amount"`
+	NetAmount        Amount          `json:"net_amount"`
+	FXRate           *FXRate         `json:"fx_rate,omitempty"`
+	Risk             *Risk           `json:"risk,omitempty"`
+	Authentication   *Authentication `json:"authentication,omitempty"`
+	Initiator        string          `json:"initiator,omitempty"`
+	PaymentMethodID  *uuid.UUID      `json:"payment_method_id,omitempty"`
+	CustomerID       *uuid.UUID      `json:"customer_id,omitempty"`
+	// NetworkTransactionID is the reference of the authorization given by the card network.
+	NetworkTransactionID string `json:"network_transaction_id,omitempty"`
+	Status               string `json:"status"`
+	IsVoided             bool   `json:"is_voided"`
+	// State is the state of the lifecycle of the transaction.
+	State string `json:"state"`
+}
+
+const (
+	// TransactionStatusAuthorized is the status of a transaction that has been authorized.
+	TransactionStatusAuthorized = "authorized"
+	// TransactionStatusRequiresAction is the status of a transaction waiting for the cardholder to complete
+	// the 3-D Secure challenge at the challenge_url before the authorization can be completed.
+	TransactionStatusRequiresAction = "requires_action"
+	// TransactionStatusDeclined is the status of a transaction whose authorization has failed.
+	TransactionStatusDeclined = "declined"
+)
+
+// states of the lifecycle of the transactions, a transaction is in the state of its last successful payment action.
+const (
+	TransactionStateRequiresAction    = "requires_action"
+	TransactionStateDeclined          = "declined"
+	TransactionStateAuthorized        = "authorized"
+	TransactionStateVoided            = "voided"
+	TransactionStatePartiallyCaptured = "partially_captured"
+	TransactionStateCaptured          = "captured"
+	TransactionStatePartiallyRefunded = "partially_refunded"
+	TransactionStateRefunded          = "refunded"
+)
+
+// Authentication response of the 3-D Secure cardholder authentication
+type Authentication struct {
+	Status         string `json:"status"`
+	ECI            string `json:"eci,omitempty"`
+	LiabilityShift bool   `json:"liability_shift"`
+	ChallengeURL   string `json:"challenge_url,omitempty"`
+}
+
+// Risk response of the fraud screening of the authorization
+type Risk struct {
+	Decision string   `json:"decision"`
+	Score    int      `json:"score"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+// FXRate response of the rate locked at authorization to convert to the settlement currency
+type FXRate struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Rate       string    `json:"rate"`
+	LockedDate time.Time `json:"locked_date"`
+}
+
+// CustomerRequest to unmarshal create customer request into
+type CustomerRequest struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// Customer response
+type Customer struct {
+	ID          uuid.UUID `json:"id"`
+	Email       string    `json:"email,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+// PaymentMethodRequest to unmarshal create payment method request into
+type PaymentMethodRequest struct {
+	PaymentSource PaymentSource `json:"payment_source"`
+}
+
+// PaymentMethod response, only the last 4 digits of the card are shown
+type PaymentMethod struct {
+	ID          uuid.UUID `json:"id"`
+	CustomerID  uuid.UUID `json:"customer_id"`
+	Card        Card      `json:"card"`
+	CreatedDate time.Time `json:"created_date"`
+	// MerchantInitiated indicates that the payment method can be used for merchant-initiated authorizations.
+	MerchantInitiated bool `json:"merchant_initiated"`
+}
+
+// Card response of a stored card
+type Card struct {
+	Last4       string `json:"last4"`
+	ExpiryMonth int    `json:"expiry_month"`
+	ExpiryYear  int    `json:"expiry_year"`
+	Scheme      string `json:"scheme,omitempty"`
+	Country     string `json:"country,omitempty"`
+}
+
+// PlanRequest to unmarshal create plan request into
+type PlanRequest struct {
+	Name          string `json:"name"`
+	Amount        Amount `json:"amount"`
+	Interval      string `json:"interval"`
+	IntervalCount int    `json:"interval_count"`
+}
+
+// Plan response
+type Plan struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Amount        Amount    `json:"amount"`
+	Interval      string    `json:"interval"`
+	IntervalCount int       `json:"interval_count"`
+	CreatedDate   time.Time `json:"created_date"`
+}
+
+// SubscriptionRequest to unmarshal create subscription request into
+type SubscriptionRequest struct {
+	CustomerID      uuid.UUID `json:"customer_id"`
+	PlanID          uuid.UUID `json:"plan_id"`
+	PaymentMethodID uuid.UUID `json:"payment_method_id"`
+	// StartDate is the date of the first billing, now if not provided.
+	StartDate *time.Time `json:"start_date,omitempty"`
+}
+
+// Subscription response
+type Subscription struct {
+	ID              uuid.UUID `json:"id"`
+	CustomerID      uuid.UUID `json:"customer_id"`
+	PlanID          uuid.UUID `json:"plan_id"`
+	PaymentMethodID uuid.UUID `json:"payment_method_id"`
+	Status          string    `json:"status"`
+	PeriodStart     time.Time `json:"period_start"`
+	NextBillingDate time.Time `json:"next_billing_date"`
+	FailedAttempts  int       `json:"failed_attempts"`
+	CreatedDate     time.Time `json:"created_date"`
+}
+
+// RotateAPIKeyRequest to unmarshal rotate api key request into
+type RotateAPIKeyRequest struct {
+	// OverlapSeconds is the time the rotated key stays valid for, one day if not provided.
+	OverlapSeconds int64 `json:"overlap_seconds,omitempty"`
+}
+
+// APIKey response, the key is only returned when it is created
+type APIKey struct {
+	ID          uuid.UUID  `json:"id"`
+	Key         string     `json:"key,omitempty"`
+	Scopes      []string   `json:"scopes"`
+	CreatedDate time.Time  `json:"created_date"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RotatedAt   *time.Time `json:"rotated_at,omitempty"`
+}
+
+// MerchantRequest to unmarshal create merchant request of the admin API into
+type MerchantRequest struct {
+	ID       string           `json:"id"`
+	Name     string           `json:"name"`
+	Settings MerchantSettings `json:"settings"`
+}
+
+// MerchantSettings request and response of the admin API
+type MerchantSettings struct {
+	// AllowedCurrencies are the currencies the merchant can authorize in, any currency if empty.
+	AllowedCurrencies []string `json:"allowed_currencies,omitempty"`
+	// CapturePolicy is either manual, the default, or automatic to capture the authorizations as soon as they are authorized.
+	CapturePolicy string `json:"capture_policy,omitempty"`
+}
+
+// Merchant response of the admin API
+type Merchant struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Status      string           `json:"status"`
+	Settings    MerchantSettings `json:"settings"`
+	CreatedDate time.Time        `json:"created_date"`
+	UpdatedDate time.Time        `json:"updated_date"`
+}
+
+// APIKeyRequest to unmarshal create api key request of the admin API into
+type APIKeyRequest struct {
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is the expiry of the key, it does not expire if not provided.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
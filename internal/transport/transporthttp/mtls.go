@@ -0,0 +1,39 @@
+package transporthttp
+
+import (
+	"net/http"
+)
+
+// schemeClientCertificate identifies the requests of a merchant authenticated with a client certificate in the audit log.
+const schemeClientCertificate = "mtls"
+
+// WithClientCertificates is a function configuration for authentication with the client certificates verified
+// by the mutual TLS of the listener, given the merchants keyed by the common name of the subject of their certificate.
+// A request with the client certificate of a merchant is granted every scope of its merchant, the requests without one
+// are left to be authenticated with their token.
+func WithClientCertificates(merchants map[string]string) MiddlewareFunc {
+	return func(h *httpHandler) error {
+		h.clientCertificates = merchants
+		return nil
+	}
+}
+
+// clientCertificateMiddleware authenticates the requests with a verified client certificate before the authorization middleware.
+func clientCertificateMiddleware(merchants map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			subject := r.TLS.VerifiedChains[0][0].Subject.CommonName
+			merchant, ok := merchants[subject]
+			if !ok {
+				_ = WriteError(w, "client certificate is not registered for a merchant", CodeForbidden)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(withAuthenticatedMerchant(r.Context(), schemeClientCertificate, merchant)))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package transporthttp_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestClientCertificates(t *testing.T) {
+	const merchantID = "merchant-1"
+	customerID := uuid.NewV4()
+
+	testCases := []struct {
+		description        string
+		subject            string
+		token              string
+		expectedStatusCode int
+	}{
+		{
+			description:        "should authenticate the merchant of the client certificate without token",
+			subject:            "merchant-1.example.com",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			description:        "should reject a client certificate not registered for a merchant",
+			subject:            "unknown.example.com",
+			token:              "privileged",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description:        "should authenticate the token without client certificate",
+			token:              "privileged",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			description:        "should reject a request without client certificate nor token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			if tt.expectedStatusCode == http.StatusNoContent {
+				srv.EXPECT().ValidateMerchant(gomock.Any(), merchantID).Return(nil)
+				srv.EXPECT().DeleteCustomer(gomock.Any(), merchantID, customerID).DoAndReturn(func(ctx context.Context, _ string, _ uuid.UUID) error {
+					if tt.subject != "" {
+						assert.Equal(t, "mtls:"+merchantID, appcontext.GetTokenID(ctx))
+					}
+					return nil
+				})
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv,
+				transporthttp.WithAuth(map[string]string{"privileged": merchantID}),
+				transporthttp.WithClientCertificates(map[string]string{"merchant-1.example.com": merchantID}),
+			)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest(http.MethodDelete, "/customers/"+customerID.String(), nil)
+			if tt.subject != "" {
+				r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: tt.subject}}}}}
+			}
+			if tt.token != "" {
+				r.Header.Set("Authorization", tt.token)
+			}
+
+			w := httptest.NewRecorder()
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
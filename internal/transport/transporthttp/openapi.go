@@ -0,0 +1,361 @@
+package transporthttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	uuid "github.com/kevinburke/go.uuid"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+// EndpointOpenAPI serves the OpenAPI 3 document of the API, it is not authenticated.
+const EndpointOpenAPI = "/openapi.json"
+
+// operation describes an endpoint in the OpenAPI document.
+type operation struct {
+	method  string
+	path    string
+	summary string
+	// request is the model of the request body, the endpoint has no body if nil.
+	request interface{}
+	// optionalBody documents the request body as optional.
+	optionalBody bool
+	// response is the model of the response body, the response has no body if nil.
+	response interface{}
+	// status is the status of a successful response.
+	status int
+	// errors are the codes of the errors of the endpoint besides the ones of every endpoint.
+	errors []string
+}
+
+// operations are the endpoints of the API documented in its OpenAPI document, the simulated ACS is not documented.
+var operations = []operation{
+	{http.MethodPost, EndpointAuthorize, "Authorize a transaction", AuthorizeRequest{}, false, Transaction{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable, CodeVelocityLimit}},
+	{http.MethodPost, EndpointAuthorizeComplete, "Complete the authorization once the cardholder is authenticated", nil, false, Transaction{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodPost, EndpointCapture, "Capture an authorized transaction", CaptureRequest{}, false, Transaction{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodPost, EndpointRefund, "Refund a captured transaction", RefundRequest{}, false, Transaction{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodPost, EndpointVoid, "Void an authorized transaction", VoidRequest{}, false, Transaction{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodPost, EndpointCustomers, "Create a customer", CustomerRequest{}, true, Customer{}, http.StatusOK,
+		[]string{CodeUnprocessable}},
+	{http.MethodGet, EndpointCustomer, "Get a customer", nil, false, Customer{}, http.StatusOK,
+		[]string{CodeNotFound}},
+	{http.MethodPut, EndpointCustomer, "Update a customer", CustomerRequest{}, true, Customer{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodDelete, EndpointCustomer, "Delete a customer", nil, false, nil, http.StatusNoContent,
+		[]string{CodeNotFound}},
+	{http.MethodPost, EndpointCustomerPaymentMethods, "Store a payment method of a customer", PaymentMethodRequest{}, false, PaymentMethod{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodGet, EndpointCustomerPaymentMethods, "List the payment methods of a customer", nil, false, []PaymentMethod{}, http.StatusOK,
+		[]string{CodeNotFound}},
+	{http.MethodDelete, EndpointCustomerPaymentMethod, "Delete a payment method of a customer", nil, false, nil, http.StatusNoContent,
+		[]string{CodeNotFound}},
+	{http.MethodGet, EndpointCustomerTransactions, "List the transactions of a customer", nil, false, []Transaction{}, http.StatusOK,
+		[]string{CodeNotFound}},
+	{http.MethodPost, EndpointPlans, "Create a plan", PlanRequest{}, false, Plan{}, http.StatusOK,
+		[]string{CodeUnprocessable}},
+	{http.MethodPost, EndpointSubscriptions, "Subscribe a customer to a plan", SubscriptionRequest{}, false, Subscription{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodGet, EndpointSubscription, "Get a subscription", nil, false, Subscription{}, http.StatusOK,
+		[]string{CodeNotFound}},
+	{http.MethodPost, EndpointSubscriptionCancel, "Cancel a subscription", nil, false, Subscription{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodPost, EndpointAPIKeyRotate, "Rotate the api key of the request", RotateAPIKeyRequest{}, true, APIKey{}, http.StatusOK,
+		[]string{CodeNotFound, CodeConflict}},
+}
+
+// adminOperations are the endpoints of the admin API documented in its OpenAPI document.
+var adminOperations = []operation{
+	{http.MethodPost, EndpointAdminMerchants, "Create a merchant", MerchantRequest{}, false, Merchant{}, http.StatusOK,
+		[]string{CodeConflict, CodeUnprocessable}},
+	{http.MethodGet, EndpointAdminMerchant, "Get a merchant", nil, false, Merchant{}, http.StatusOK,
+		[]string{CodeNotFound}},
+	{http.MethodPut, EndpointAdminMerchantSettings, "Update the settings of a merchant", MerchantSettings{}, false, Merchant{}, http.StatusOK,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodPost, EndpointAdminMerchantSuspend, "Suspend a merchant", nil, false, Merchant{}, http.StatusOK,
+		[]string{CodeNotFound}},
+	{http.MethodPost, EndpointAdminMerchantActivate, "Activate a merchant", nil, false, Merchant{}, http.StatusOK,
+		[]string{CodeNotFound}},
+	{http.MethodPost, EndpointAdminMerchantAPIKeys, "Create an api key of a merchant", APIKeyRequest{}, false, APIKey{}, http.StatusCreated,
+		[]string{CodeNotFound, CodeUnprocessable}},
+	{http.MethodGet, EndpointAdminMerchantAPIKeys, "List the api keys of a merchant", nil, false, []APIKey{}, http.StatusOK,
+		[]string{CodeNotFound}},
+	{http.MethodDelete, EndpointAdminMerchantAPIKey, "Revoke an api key of a merchant", nil, false, nil, http.StatusNoContent,
+		[]string{CodeNotFound}},
+}
+
+// commonErrors are the codes of the errors of every endpoint of the API, e.g. of its middlewares.
+var commonErrors = []string{CodeBadRequest, CodeUnauthorized, CodeForbidden, CodePayloadTooLarge, CodeRateLimit, CodeUnknownFailure}
+
+// adminCommonErrors are the codes of the errors of every endpoint of the admin API.
+var adminCommonErrors = []string{CodeBadRequest, CodeUnauthorized, CodeForbidden, CodePayloadTooLarge, CodeUnknownFailure}
+
+var pathParamRegexp = regexp.MustCompile(`{([a-z_]+)}`)
+
+// serveOpenAPI returns the handler serving the OpenAPI document, it is generated once from the operations.
+func serveOpenAPI(document map[string]interface{}) http.HandlerFunc {
+	b, err := json.MarshalIndent(document, "", "  ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			logging.Error(r.Context(), "error encoding openapi document", zap.Error(err))
+			_ = WriteError(w, "failed to encode openapi document", CodeUnknownFailure)
+			return
+		}
+		w.Header().Add(ContentType, ApplicationJSON)
+		_, _ = w.Write(b)
+	}
+}
+
+// openAPIDocument generates the OpenAPI 3 document of the operations, the schemas are generated from the models
+// by their json tags. The fields of a response are required unless omitempty, the requests are validated by the API
+// and the invalid fields returned as the errors of a bad_request. The operations of a versioned API are served
+// under the APIVersionPrefix and their responses are the models of the LatestAPIVersion.
+func openAPIDocument(title, description string, ops []operation, errorCodes []string, versioned bool) map[string]interface{} {
+	g := schemaGenerator{schemas: map[string]interface{}{}}
+	g.schemas["ServerError"] = g.serverErrorSchema()
+
+	// the responses are generated before the requests so that the models of both have their required fields.
+	responses := make([]map[string]interface{}, len(ops))
+	for i, op := range ops {
+		responses[i] = g.responses(op, errorCodes)
+	}
+
+	paths := map[string]interface{}{}
+	for i, op := range ops {
+		item, ok := paths[op.path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[op.path] = item
+		}
+		o := map[string]interface{}{
+			"summary":     op.summary,
+			"operationId": operationID(op),
+			"responses":   responses[i],
+		}
+		params := pathParameters(op.path)
+		if versioned {
+			params = append(params, apiVersionParameter())
+		}
+		if len(params) > 0 {
+			o["parameters"] = params
+		}
+		if op.request != nil {
+			o["requestBody"] = map[string]interface{}{
+				"required": !op.optionalBody,
+				"content": map[string]interface{}{
+					ApplicationJSON: map[string]interface{}{"schema": g.schema(reflect.TypeOf(op.request), false)},
+				},
+			}
+		}
+		item[strings.ToLower(op.method)] = o
+	}
+
+	document := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       title,
+			"description": description,
+			"version":     "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]interface{}{
+				"token": map[string]interface{}{"type": "apiKey", "in": "header", "name": authorizationHeaderKey},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"token": []string{}}},
+	}
+	if versioned {
+		document["info"].(map[string]interface{})["version"] = LatestAPIVersion
+		document["servers"] = []interface{}{map[string]interface{}{"url": APIVersionPrefix}}
+	}
+	return document
+}
+
+// operationID returns the id of the operation from its method and path, e.g. post_customers_payment_methods.
+func operationID(op operation) string {
+	path := pathParamRegexp.ReplaceAllString(op.path, "")
+	parts := []string{strings.ToLower(op.method)}
+	for _, p := range strings.Split(path, "/") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// pathParameters returns the parameters of the path, the ids are uuids but the one of the merchant.
+func pathParameters(path string) []interface{} {
+	var params []interface{}
+	for _, m := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		schema := map[string]interface{}{"type": "string"}
+		if m[1] != "merchant_id" {
+			schema["format"] = "uuid"
+		}
+		params = append(params, map[string]interface{}{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   schema,
+		})
+	}
+	return params
+}
+
+// apiVersionParameter returns the header of the version of the responses, the version the merchant is pinned to
+// or DefaultAPIVersion if not set.
+func apiVersionParameter() map[string]interface{} {
+	versions := make([]interface{}, len(apiVersions))
+	for i, v := range apiVersions {
+		versions[i] = v
+	}
+	return map[string]interface{}{
+		"name": APIVersionHeaderKey,
+		"in":   "header",
+		"description": "The version of the response, the version the merchant is pinned to or " + DefaultAPIVersion +
+			" if not set. The responses documented are the ones of " + LatestAPIVersion + ".",
+		"schema": map[string]interface{}{"type": "string", "enum": versions},
+	}
+}
+
+// schemaGenerator generates the schemas of the models, the structs are components referenced by their name.
+type schemaGenerator struct {
+	schemas map[string]interface{}
+}
+
+// responses returns the successful response and the error responses of the operation by status.
+func (g *schemaGenerator) responses(op operation, errorCodes []string) map[string]interface{} {
+	ok := map[string]interface{}{"description": http.StatusText(op.status)}
+	if op.response != nil {
+		ok["content"] = map[string]interface{}{
+			ApplicationJSON: map[string]interface{}{"schema": g.schema(reflect.TypeOf(op.response), true)},
+		}
+	}
+	responses := map[string]interface{}{strconv.Itoa(op.status): ok}
+
+	codesByStatus := map[int][]string{}
+	for _, code := range append(append([]string{}, errorCodes...), op.errors...) {
+		status := codeMap[code]
+		codesByStatus[status] = append(codesByStatus[status], code)
+	}
+	for status, codes := range codesByStatus {
+		responses[strconv.Itoa(status)] = map[string]interface{}{
+			"description": http.StatusText(status) + ": " + strings.Join(codes, ", "),
+			"content": map[string]interface{}{
+				ApplicationJSON: map[string]interface{}{"schema": ref("ServerError")},
+			},
+		}
+	}
+	return responses
+}
+
+// serverErrorSchema returns the schema of ServerError with the codes and the reasons.
+func (g *schemaGenerator) serverErrorSchema() map[string]interface{} {
+	codes := make([]string, 0, len(codeMap))
+	for code := range codeMap {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	reasons := make([]string, 0, len(domain.Reasons))
+	for _, r := range domain.Reasons {
+		reasons = append(reasons, string(r))
+	}
+
+	schema := g.structSchema(reflect.TypeOf(ServerError{}), true)
+	properties := schema["properties"].(map[string]interface{})
+	properties["code"].(map[string]interface{})["enum"] = codes
+	properties["reason"].(map[string]interface{})["enum"] = reasons
+	return schema
+}
+
+var (
+	uuidType = reflect.TypeOf(uuid.UUID{})
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// schema returns the schema of the type, the structs are referenced. The required fields are only set for responses.
+func (g *schemaGenerator) schema(t reflect.Type, response bool) map[string]interface{} {
+	switch t {
+	case uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem(), response)
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem(), response)}
+	case reflect.Struct:
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = nil // breaks the recursion
+			g.schemas[t.Name()] = g.structSchema(t, response)
+		}
+		return ref(t.Name())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema returns the schema of the fields of the struct by their json tags.
+func (g *schemaGenerator) structSchema(t reflect.Type, response bool) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || f.PkgPath != "" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i:]
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = g.schema(f.Type, response)
+		if response && !strings.Contains(opts, ",omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
@@ -0,0 +1,402 @@
+package transporthttp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+var (
+	openAPIDate     = time.Date(2021, 6, 18, 12, 0, 0, 0, time.UTC)
+	openAPIID       = uuid.NewV4()
+	openAPIResource = openAPIID.String()
+
+	openAPITransaction = &domain.Transaction{
+		ID:              uuid.NewV4(),
+		AuthorizationID: openAPIID,
+		MerchantID:      "merchant-1",
+		Amount:          domain.Amount{MinorUnits: 1000, Currency: "EUR", Exponent: 2},
+		FXRate:          &domain.FXRate{From: "EUR", To: "GBP", Rate: big.NewRat(86, 100), LockedDate: openAPIDate},
+		Risk:            &domain.RiskAssessment{Decision: domain.RiskDecisionAllow, Score: 10, Reasons: []string{"new_card"}},
+		Authentication:  &domain.Authentication{Status: domain.AuthenticationStatusAuthenticated, ECI: "05", LiabilityShift: true},
+		Initiator:       domain.InitiatorCustomer,
+		CustomerID:      uuid.NewV4(),
+		AuthorizedAmount: domain.Amount{
+			MinorUnits: 1000, Currency: "EUR", Exponent: 2,
+		},
+		PaymentActionSummary: []*domain.PaymentAction{{
+			Type: domain.PaymentActionTypeAuthorization, Status: domain.PaymentActionStatusSuccess, ProcessedDate: openAPIDate,
+		}},
+	}
+	openAPICustomer      = &domain.Customer{ID: openAPIID, Email: "jane@example.com", CreatedDate: openAPIDate}
+	openAPIPaymentMethod = &domain.PaymentMethod{
+		ID:            openAPIID,
+		CustomerID:    uuid.NewV4(),
+		PaymentSource: domain.PaymentSource{PAN: "4242424242424242", Expiry: domain.Expiry{Month: 1, Year: 30}},
+		CreatedDate:   openAPIDate,
+	}
+	openAPIPlan = &domain.Plan{
+		ID: openAPIID, Name: "Gold", Amount: domain.Amount{MinorUnits: 999, Currency: "GBP", Exponent: 2},
+		Interval: domain.IntervalMonth, IntervalCount: 1, CreatedDate: openAPIDate,
+	}
+	openAPISubscription = &domain.Subscription{
+		ID: openAPIID, CustomerID: uuid.NewV4(), PlanID: uuid.NewV4(), PaymentMethodID: uuid.NewV4(),
+		Status: domain.SubscriptionStatusActive, PeriodStart: openAPIDate, NextBillingDate: openAPIDate, CreatedDate: openAPIDate,
+	}
+	openAPIAPIKey   = &domain.APIKey{ID: openAPIID, Scopes: []domain.Scope{domain.ScopeRead}, CreatedDate: openAPIDate, ExpiresAt: &openAPIDate}
+	openAPIMerchant = &domain.Merchant{ID: "merchant-1", Name: "Shop", Status: domain.MerchantStatusActive, CreatedDate: openAPIDate, UpdatedDate: openAPIDate}
+)
+
+func TestOpenAPI_Handler(t *testing.T) {
+	authorizeBody := `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06",
+		"payment_source": {"pan": "4242424242424242", "cvv": "123", "expiry_month": 1, "expiry_year": 30},
+		"amount": {"minor_units": 1000, "currency": "EUR", "exponent": 2}}`
+	actionBody := `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06", "authorization_id": "` + openAPIResource + `",
+		"amount": {"minor_units": 1000, "currency": "EUR", "exponent": 2}}`
+
+	testCases := []struct {
+		method string
+		path   string
+		body   string
+		mock   func(srv *mocks.MockService)
+	}{
+		{http.MethodPost, transporthttp.EndpointAuthorize, authorizeBody, func(srv *mocks.MockService) {
+			srv.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(openAPITransaction, nil)
+		}},
+		{http.MethodPost, transporthttp.EndpointAuthorize, authorizeBody, func(srv *mocks.MockService) {
+			srv.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, domain.ErrDeclinedByFraudScreening)
+		}},
+		{http.MethodPost, transporthttp.EndpointAuthorize, `{"amount": {}}`, func(srv *mocks.MockService) {}},
+		{http.MethodPost, "/authorize/" + openAPIResource + "/complete", "", func(srv *mocks.MockService) {
+			srv.EXPECT().CompleteAuthorization(gomock.Any(), gomock.Any(), openAPIID).Return(openAPITransaction, nil)
+		}},
+		{http.MethodPost, transporthttp.EndpointCapture, actionBody, func(srv *mocks.MockService) {
+			srv.EXPECT().Capture(gomock.Any(), gomock.Any()).Return(nil, domain.ErrTransactionNotFound)
+		}},
+		{http.MethodPost, transporthttp.EndpointRefund, actionBody, func(srv *mocks.MockService) {
+			srv.EXPECT().Refund(gomock.Any(), gomock.Any()).Return(openAPITransaction, nil)
+		}},
+		{http.MethodPost, transporthttp.EndpointVoid, `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06", "authorization_id": "` + openAPIResource + `"}`,
+			func(srv *mocks.MockService) {
+				srv.EXPECT().Void(gomock.Any(), gomock.Any()).Return(nil, domain.ErrAlreadyCaptured)
+			}},
+		{http.MethodPost, transporthttp.EndpointCustomers, "", func(srv *mocks.MockService) {
+			srv.EXPECT().CreateCustomer(gomock.Any(), gomock.Any()).Return(openAPICustomer, nil)
+		}},
+		{http.MethodGet, "/customers/" + openAPIResource, "", func(srv *mocks.MockService) {
+			srv.EXPECT().GetCustomer(gomock.Any(), gomock.Any(), openAPIID).Return(openAPICustomer, nil)
+		}},
+		{http.MethodPut, "/customers/" + openAPIResource, `{"name": "Jane"}`, func(srv *mocks.MockService) {
+			srv.EXPECT().UpdateCustomer(gomock.Any(), gomock.Any()).Return(openAPICustomer, nil)
+		}},
+		{http.MethodDelete, "/customers/" + openAPIResource, "", func(srv *mocks.MockService) {
+			srv.EXPECT().DeleteCustomer(gomock.Any(), gomock.Any(), openAPIID).Return(nil)
+		}},
+		{http.MethodPost, "/customers/" + openAPIResource + "/payment_methods", `{"payment_source": {"pan": "4242424242424242"}}`,
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CreatePaymentMethod(gomock.Any(), gomock.Any()).Return(openAPIPaymentMethod, nil)
+			}},
+		{http.MethodGet, "/customers/" + openAPIResource + "/payment_methods", "", func(srv *mocks.MockService) {
+			srv.EXPECT().ListPaymentMethods(gomock.Any(), gomock.Any(), openAPIID).Return([]*domain.PaymentMethod{openAPIPaymentMethod}, nil)
+		}},
+		{http.MethodDelete, "/customers/" + openAPIResource + "/payment_methods/" + openAPIResource, "", func(srv *mocks.MockService) {
+			srv.EXPECT().DeletePaymentMethod(gomock.Any(), gomock.Any(), openAPIID, openAPIID).Return(domain.ErrPaymentMethodNotFound)
+		}},
+		{http.MethodGet, "/customers/" + openAPIResource + "/transactions", "", func(srv *mocks.MockService) {
+			srv.EXPECT().ListCustomerTransactions(gomock.Any(), gomock.Any(), openAPIID).Return(nil, nil)
+		}},
+		{http.MethodPost, transporthttp.EndpointPlans, `{"name": "Gold", "amount": {"minor_units": 999, "currency": "GBP", "exponent": 2},
+			"interval": "month", "interval_count": 1}`, func(srv *mocks.MockService) {
+			srv.EXPECT().CreatePlan(gomock.Any(), gomock.Any()).Return(openAPIPlan, nil)
+		}},
+		{http.MethodPost, transporthttp.EndpointSubscriptions, `{"customer_id": "` + openAPIResource + `", "plan_id": "` + openAPIResource +
+			`", "payment_method_id": "` + openAPIResource + `"}`, func(srv *mocks.MockService) {
+			srv.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Return(openAPISubscription, nil)
+		}},
+		{http.MethodGet, "/subscriptions/" + openAPIResource, "", func(srv *mocks.MockService) {
+			srv.EXPECT().GetSubscription(gomock.Any(), gomock.Any(), openAPIID).Return(openAPISubscription, nil)
+		}},
+		{http.MethodPost, "/subscriptions/" + openAPIResource + "/cancel", "", func(srv *mocks.MockService) {
+			srv.EXPECT().CancelSubscription(gomock.Any(), gomock.Any(), openAPIID).Return(openAPISubscription, nil)
+		}},
+		{http.MethodPost, transporthttp.EndpointAPIKeyRotate, "", func(srv *mocks.MockService) {
+			srv.EXPECT().RotateAPIKey(gomock.Any(), gomock.Any(), openAPIID, gomock.Any()).Return(openAPIAPIKey, "pk_new", nil)
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockService(ctrl)
+	h, err := transporthttp.NewHTTPHandler(srv)
+	require.NoError(t, err)
+	handler := httplistener.HTTPHandler(h)
+	document := getOpenAPIDocument(t, handler)
+
+	assertOperationsDocumented(t, h, document)
+
+	for _, tt := range testCases {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			tt.mock(srv)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, transporthttp.APIVersionPrefix+tt.path, strings.NewReader(tt.body))
+			r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.LatestAPIVersion)
+			ctx := appcontext.WithMerchant(context.Background(), "merchant-1")
+			r = r.WithContext(appcontext.WithTokenID(ctx, openAPIResource))
+			handler.ServeHTTP(w, r)
+
+			assertDocumentedResponse(t, document, handler, r, w)
+		})
+	}
+}
+
+func TestOpenAPI_AdminHandler(t *testing.T) {
+	testCases := []struct {
+		method string
+		path   string
+		body   string
+		mock   func(srv *mocks.MockAdminService)
+	}{
+		{http.MethodPost, transporthttp.EndpointAdminMerchants, `{"id": "merchant-1", "name": "Shop"}`, func(srv *mocks.MockAdminService) {
+			srv.EXPECT().CreateMerchant(gomock.Any(), gomock.Any()).Return(nil, domain.ErrMerchantExists)
+		}},
+		{http.MethodGet, "/merchants/merchant-1", "", func(srv *mocks.MockAdminService) {
+			srv.EXPECT().GetMerchant(gomock.Any(), "merchant-1").Return(openAPIMerchant, nil)
+		}},
+		{http.MethodPut, "/merchants/merchant-1/settings", `{"allowed_currencies": ["GBP"]}`, func(srv *mocks.MockAdminService) {
+			srv.EXPECT().UpdateMerchantSettings(gomock.Any(), "merchant-1", gomock.Any()).Return(openAPIMerchant, nil)
+		}},
+		{http.MethodPost, "/merchants/merchant-1/suspend", "", func(srv *mocks.MockAdminService) {
+			srv.EXPECT().SetMerchantStatus(gomock.Any(), "merchant-1", domain.MerchantStatusSuspended).Return(openAPIMerchant, nil)
+		}},
+		{http.MethodPost, "/merchants/merchant-1/activate", "", func(srv *mocks.MockAdminService) {
+			srv.EXPECT().SetMerchantStatus(gomock.Any(), "merchant-1", domain.MerchantStatusActive).Return(nil, domain.ErrMerchantNotFound)
+		}},
+		{http.MethodPost, "/merchants/merchant-1/api_keys", `{"scopes": ["read"]}`, func(srv *mocks.MockAdminService) {
+			srv.EXPECT().CreateAPIKey(gomock.Any(), "merchant-1", gomock.Any(), gomock.Any()).Return(openAPIAPIKey, "pk_new", nil)
+		}},
+		{http.MethodGet, "/merchants/merchant-1/api_keys", "", func(srv *mocks.MockAdminService) {
+			srv.EXPECT().ListAPIKeys(gomock.Any(), "merchant-1").Return([]*domain.APIKey{openAPIAPIKey}, nil)
+		}},
+		{http.MethodDelete, "/merchants/merchant-1/api_keys/" + openAPIResource, "", func(srv *mocks.MockAdminService) {
+			srv.EXPECT().RevokeAPIKey(gomock.Any(), "merchant-1", openAPIID).Return(nil)
+		}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockAdminService(ctrl)
+	srv.EXPECT().Audit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	h, err := transporthttp.NewAdminHandler(srv, map[string]string{"admin-token": "ops"})
+	require.NoError(t, err)
+	handler := httplistener.HTTPHandler(h)
+	document := getOpenAPIDocument(t, handler)
+
+	assertOperationsDocumented(t, h, document)
+
+	for _, tt := range testCases {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			tt.mock(srv)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("Authorization", "admin-token")
+			handler.ServeHTTP(w, r)
+
+			assertDocumentedResponse(t, document, handler, r, w)
+		})
+	}
+}
+
+func getOpenAPIDocument(t *testing.T, handler http.Handler) map[string]interface{} {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, transporthttp.EndpointOpenAPI, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var document map[string]interface{}
+	dec := json.NewDecoder(w.Body)
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&document))
+	assert.Equal(t, "3.0.3", document["openapi"])
+	return document
+}
+
+// assertOperationsDocumented asserts that the routes of the handler under the server of the document
+// and the operations of the document are the same.
+func assertOperationsDocumented(t *testing.T, h httplistener.Handler, document map[string]interface{}) {
+	t.Helper()
+	router := mux.NewRouter()
+	h.ApplyRoutes(&httplistener.Mux{Router: router})
+
+	server := documentServer(document)
+	var routes []string
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		methods, _ := route.GetMethods()
+		if err != nil || route.GetHandler() == nil || !strings.HasPrefix(path, server) {
+			return nil
+		}
+		path = strings.TrimPrefix(path, server)
+		if path == transporthttp.EndpointOpenAPI || path == transporthttp.EndpointACS {
+			return nil
+		}
+		for _, m := range methods {
+			routes = append(routes, m+" "+path)
+		}
+		return nil
+	})
+
+	var operations []string
+	for path, item := range document["paths"].(map[string]interface{}) {
+		for method := range item.(map[string]interface{}) {
+			operations = append(operations, strings.ToUpper(method)+" "+path)
+		}
+	}
+	assert.ElementsMatch(t, routes, operations)
+}
+
+// assertDocumentedResponse asserts that the status of the response is documented for the operation of the request
+// and that the body is valid against the schema of the response.
+func assertDocumentedResponse(t *testing.T, document map[string]interface{}, handler http.Handler, r *http.Request, w *httptest.ResponseRecorder) {
+	t.Helper()
+	var match mux.RouteMatch
+	require.True(t, handler.(*mux.Router).Match(r, &match), "the request matches no route")
+	path, err := match.Route.GetPathTemplate()
+	require.NoError(t, err)
+	path = strings.TrimPrefix(path, documentServer(document))
+
+	op, ok := document["paths"].(map[string]interface{})[path].(map[string]interface{})[strings.ToLower(r.Method)].(map[string]interface{})
+	require.True(t, ok, "%s %s is not documented", r.Method, path)
+	response, ok := op["responses"].(map[string]interface{})[strconv.Itoa(w.Code)].(map[string]interface{})
+	require.True(t, ok, "status %d of %s %s is not documented: %s", w.Code, r.Method, path, w.Body.String())
+
+	content, ok := response["content"].(map[string]interface{})
+	if !ok {
+		assert.Empty(t, w.Body.String(), "the response has no body")
+		return
+	}
+	schema := content[transporthttp.ApplicationJSON].(map[string]interface{})["schema"].(map[string]interface{})
+
+	var body interface{}
+	dec := json.NewDecoder(bytes.NewReader(w.Body.Bytes()))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&body))
+	assert.Empty(t, validateSchema(document, schema, body, "body"), w.Body.String())
+}
+
+// documentServer returns the url of the server of the document the paths are relative to, empty if not set.
+func documentServer(document map[string]interface{}) string {
+	servers, ok := document["servers"].([]interface{})
+	if !ok || len(servers) == 0 {
+		return ""
+	}
+	return servers[0].(map[string]interface{})["url"].(string)
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// validateSchema returns the errors of the value against the schema, it validates the subset of the JSON schema
+// the document is generated with.
+func validateSchema(document, schema map[string]interface{}, v interface{}, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		schemas := document["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+		s, ok := schemas[name].(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %s", path, ref)}
+		}
+		return validateSchema(document, s, v, path)
+	}
+
+	var errs []string
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: must be an object", path)}
+		}
+		properties := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := obj[name.(string)]; !ok {
+					errs = append(errs, fmt.Sprintf("%s.%s: is required", path, name))
+				}
+			}
+		}
+		for name, value := range obj {
+			s, ok := properties[name].(map[string]interface{})
+			if !ok {
+				errs = append(errs, fmt.Sprintf("%s.%s: is not documented", path, name))
+				continue
+			}
+			errs = append(errs, validateSchema(document, s, value, path+"."+name)...)
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: must be an array", path)}
+		}
+		for i, value := range arr {
+			errs = append(errs, validateSchema(document, schema["items"].(map[string]interface{}), value, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: must be a string", path)}
+		}
+		switch schema["format"] {
+		case "uuid":
+			if !uuidRegexp.MatchString(s) {
+				errs = append(errs, fmt.Sprintf("%s: must be a uuid", path))
+			}
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: must be a date-time", path))
+			}
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, s) {
+			errs = append(errs, fmt.Sprintf("%s: must be one of %v", path, enum))
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if _, err := n.Int64(); !ok || err != nil {
+			return []string{fmt.Sprintf("%s: must be an integer", path)}
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return []string{fmt.Sprintf("%s: must be a boolean", path)}
+		}
+	}
+	return errs
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
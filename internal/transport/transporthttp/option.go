@@ -1,54 +1,214 @@
 package transporthttp
 
 import (
-	"github.com/gorilla/mux"
-
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
+	"sync/atomic"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
 )
 
 const (
 	authorizationHeaderKey = "Authorization"
+	// tokenIDLength is the number of hex characters of the hash of a privileged token identifying it in the audit log.
+	tokenIDLength = 12
 )
 
 // MiddlewareFunc type
 type MiddlewareFunc func(c *httpHandler) error
 
-// WithAuth is a function configuration for authorization
+// WithAuth is a function configuration for authorization with the privileged tokens of the config,
+// which are granted every scope, and with the API keys of the service.
 func WithAuth(privilegedTokens map[string]string) MiddlewareFunc {
+	return WithReloadableAuth(NewPrivilegedTokens(privilegedTokens))
+}
+
+// WithReloadableAuth is WithAuth with the privileged tokens of a snapshot which is replaced when the config
+// is reloaded.
+func WithReloadableAuth(privilegedTokens *PrivilegedTokens) MiddlewareFunc {
 	return func(h *httpHandler) error {
-		h.middlewareFuncs = []mux.MiddlewareFunc{NewAuthorizationMiddleware(privilegedTokens)}
+		h.middlewareFuncs = append(h.middlewareFuncs, newAuthorizationMiddleware(privilegedTokens, h.service))
 		return nil
 	}
 }
 
+// PrivilegedTokens is the snapshot of the privileged tokens keyed by token, it is replaced atomically so that
+// the tokens can be rotated without a restart, the requests in flight keep the snapshot they have read.
+type PrivilegedTokens struct {
+	tokens atomic.Value
+}
+
+// NewPrivilegedTokens initialises the snapshot of the privileged tokens keyed by token.
+func NewPrivilegedTokens(tokens map[string]string) *PrivilegedTokens {
+	p := &PrivilegedTokens{}
+	p.Store(tokens)
+	return p
+}
+
+// Store replaces the snapshot with a copy of the tokens.
+func (p *PrivilegedTokens) Store(tokens map[string]string) {
+	snapshot := make(map[string]string, len(tokens))
+	for token, merchant := range tokens {
+		snapshot[token] = merchant
+	}
+	p.tokens.Store(snapshot)
+}
+
+// merchant returns the merchant of the token in the current snapshot.
+func (p *PrivilegedTokens) merchant(token string) (string, bool) {
+	merchant, ok := p.tokens.Load().(map[string]string)[token]
+	return merchant, ok
+}
+
+// Authenticator authenticates the API keys, it returns domain.ErrAPIKeyInvalid for an unknown, expired or revoked key,
+// and validates the merchants, it returns domain.ErrMerchantSuspended for a suspended merchant.
+type Authenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*domain.APIKey, error)
+	ValidateMerchant(ctx context.Context, merchantID string) error
+}
+
 // HTTPAuthorizeRequest is the type to handles authorization of request
 type HTTPAuthorizeRequest struct {
 	next             http.Handler
-	privilegedTokens map[string]string
+	privilegedTokens *PrivilegedTokens
+	authenticator    Authenticator
+}
+
+// NewAuthorizationMiddleware initialises a http.Handler implementation of authorization given the privileged tokens
+// and the authenticator of the API keys and of the merchants, nil to only accept the privileged tokens.
+func NewAuthorizationMiddleware(privilegedTokens map[string]string, authenticator Authenticator) func(http.Handler) http.Handler {
+	return newAuthorizationMiddleware(NewPrivilegedTokens(privilegedTokens), authenticator)
 }
 
-// NewAuthorizationMiddleware initialises a http.Handler implementation of authorization given the privileged tokens.
-func NewAuthorizationMiddleware(privilegedTokens map[string]string) func(http.Handler) http.Handler {
+func newAuthorizationMiddleware(privilegedTokens *PrivilegedTokens, authenticator Authenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return &HTTPAuthorizeRequest{
 			next:             next,
 			privilegedTokens: privilegedTokens,
+			authenticator:    authenticator,
 		}
 	}
 }
 
-// ServeHTTP chains the middlewares and does the corresponding authorization for the incoming request.
+// ServeHTTP chains the middlewares and does the corresponding authorization for the incoming request,
+// the token must be granted the scope of the endpoint and its merchant must not be suspended.
+// A request authenticated beforehand, by its signature or its client certificate, is granted every scope of its merchant
+// without a token.
 func (a HTTPAuthorizeRequest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	apiKey := r.Header.Get(authorizationHeaderKey)
-	if apiKey == "" {
-		_ = WriteError(w, "Authorization missing", CodeUnauthorized)
-		return
+	var (
+		key *domain.APIKey
+		id  string
+		err error
+	)
+	if authenticated, ok := authenticatedMerchant(r.Context()); ok {
+		key, id = &domain.APIKey{MerchantID: authenticated.merchant, Scopes: []domain.Scope{domain.ScopeAll}}, authenticated.id()
+	} else {
+		token := r.Header.Get(authorizationHeaderKey)
+		if token == "" {
+			_ = WriteError(w, "Authorization missing", CodeUnauthorized)
+			return
+		}
+		key, id, err = a.authenticate(r.Context(), token)
 	}
 
-	if _, ok := a.privilegedTokens[apiKey]; !ok {
+	switch {
+	case errors.Is(err, domain.ErrAPIKeyInvalid):
 		_ = WriteError(w, "invalid token", CodeForbidden)
 		return
+	case err != nil:
+		_ = WriteError(w, "unable to authenticate token", CodeUnknownFailure)
+		return
 	}
-	ctx := r.Context()
+
+	if a.authenticator != nil {
+		err := a.authenticator.ValidateMerchant(r.Context(), key.MerchantID)
+		switch {
+		case errors.Is(err, domain.ErrMerchantSuspended):
+			_ = WriteError(w, err.Error(), CodeForbidden)
+			return
+		case err != nil:
+			_ = WriteError(w, "unable to validate merchant", CodeUnknownFailure)
+			return
+		}
+	}
+
+	if scope := requiredScope(r); scope != "" && !key.HasScope(scope) {
+		_ = WriteError(w, fmt.Sprintf("token is not granted the %s scope", scope), CodeForbidden)
+		return
+	}
+
+	ctx := appcontext.WithMerchant(r.Context(), key.MerchantID)
+	ctx = appcontext.WithTokenID(ctx, id)
 	a.next.ServeHTTP(w, r.WithContext(ctx))
 }
+
+// authenticate returns the API key of the token and its id, a privileged token is granted every scope
+// and it is identified by the prefix of its hash.
+func (a HTTPAuthorizeRequest) authenticate(ctx context.Context, token string) (*domain.APIKey, string, error) {
+	if merchant, ok := a.privilegedTokens.merchant(token); ok {
+		return &domain.APIKey{MerchantID: merchant, Scopes: []domain.Scope{domain.ScopeAll}}, tokenID(token), nil
+	}
+	if a.authenticator == nil {
+		return nil, "", domain.ErrAPIKeyInvalid
+	}
+
+	key, err := a.authenticator.AuthenticateAPIKey(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, key.ID.String(), nil
+}
+
+// WithSimulatedACS serves a simulated ACS to complete the 3-D Secure challenges for testing,
+// the 3-D Secure authentication is not available without an ACS.
+func WithSimulatedACS() MiddlewareFunc {
+	return func(h *httpHandler) error {
+		h.simulatedACS = true
+		return nil
+	}
+}
+
+type authenticatedMerchantKey struct{}
+
+// authentication is the merchant a request is authenticated as before the authorization middleware and the scheme
+// it is authenticated with.
+type authentication struct {
+	scheme   string
+	merchant string
+}
+
+// id identifies the authentication in the audit log in lieu of a token id.
+func (a authentication) id() string {
+	return a.scheme + ":" + a.merchant
+}
+
+// withAuthenticatedMerchant authenticates the request of the context as the merchant.
+func withAuthenticatedMerchant(ctx context.Context, scheme, merchant string) context.Context {
+	return context.WithValue(ctx, authenticatedMerchantKey{}, authentication{scheme: scheme, merchant: merchant})
+}
+
+// authenticatedMerchant returns the merchant the request of the context is authenticated as, if any.
+func authenticatedMerchant(ctx context.Context) (authentication, bool) {
+	a, ok := ctx.Value(authenticatedMerchantKey{}).(authentication)
+	return a, ok
+}
+
+// tokenID identifies a token without revealing it by the prefix of its hash.
+func tokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:tokenIDLength]
+}
+
+// WithAuditLog records every request to the routes behind the middlewares in the audit log of the service
+// with its outcome, after the authorization so that the merchant and the token are known.
+func WithAuditLog() MiddlewareFunc {
+	return func(h *httpHandler) error {
+		h.auditLog = true
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package transporthttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestAuthorizationMiddleware(t *testing.T) {
+	const merchantID = "merchant-1"
+	customerID := uuid.NewV4()
+	keyID := uuid.NewV4()
+
+	testCases := []struct {
+		description        string
+		token              string
+		key                *domain.APIKey
+		err                error
+		merchantErr        error
+		expectedStatusCode int
+	}{
+		{
+			description:        "should accept a privileged token",
+			token:              "privileged",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			description:        "should accept an api key granted the scope of the endpoint",
+			token:              "pgk_manage",
+			key:                &domain.APIKey{ID: keyID, MerchantID: merchantID, Scopes: []domain.Scope{domain.ScopeManage}},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			description:        "should reject an api key not granted the scope of the endpoint",
+			token:              "pgk_read",
+			key:                &domain.APIKey{ID: keyID, MerchantID: merchantID, Scopes: []domain.Scope{domain.ScopeRead}},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description:        "should reject the token of a suspended merchant",
+			token:              "privileged",
+			merchantErr:        domain.ErrMerchantSuspended,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description:        "should reject an invalid api key",
+			token:              "pgk_expired",
+			err:                domain.ErrAPIKeyInvalid,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description:        "should fail when the api key cannot be authenticated",
+			token:              "pgk_any",
+			err:                errors.New("connection refused"),
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			description:        "should reject a request without token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			if tt.key != nil || tt.err != nil {
+				srv.EXPECT().AuthenticateAPIKey(gomock.Any(), tt.token).Return(tt.key, tt.err)
+			}
+			if tt.err == nil && tt.token != "" {
+				srv.EXPECT().ValidateMerchant(gomock.Any(), merchantID).Return(tt.merchantErr)
+			}
+			if tt.expectedStatusCode == http.StatusNoContent {
+				srv.EXPECT().DeleteCustomer(gomock.Any(), merchantID, customerID).Return(nil)
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv, transporthttp.WithAuth(map[string]string{"privileged": merchantID}))
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/customers/"+customerID.String(), nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", tt.token)
+			}
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestWithReloadableAuth(t *testing.T) {
+	const merchantID = "merchant-1"
+	customerID := uuid.NewV4()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockService(ctrl)
+	tokens := transporthttp.NewPrivilegedTokens(map[string]string{"token-1": merchantID})
+	h, err := transporthttp.NewHTTPHandler(srv, transporthttp.WithReloadableAuth(tokens))
+	require.NoError(t, err)
+	handler := httplistener.HTTPHandler(h)
+
+	deleteCustomer := func(token string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/customers/"+customerID.String(), nil)
+		r.Header.Set("Authorization", token)
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	srv.EXPECT().ValidateMerchant(gomock.Any(), merchantID).Return(nil).Times(2)
+	srv.EXPECT().DeleteCustomer(gomock.Any(), merchantID, customerID).Return(nil).Times(2)
+	assert.Equal(t, http.StatusNoContent, deleteCustomer("token-1"))
+
+	tokens.Store(map[string]string{"token-2": merchantID})
+
+	srv.EXPECT().AuthenticateAPIKey(gomock.Any(), "token-1").Return(nil, domain.ErrAPIKeyInvalid)
+	assert.Equal(t, http.StatusForbidden, deleteCustomer("token-1"), "the rotated token is rejected")
+	assert.Equal(t, http.StatusNoContent, deleteCustomer("token-2"))
+}
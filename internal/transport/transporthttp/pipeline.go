@@ -0,0 +1,146 @@
+package transporthttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	uuid "github.com/kevinburke/go.uuid"
+	"go.uber.org/zap"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/logging"
+)
+
+// domainErrors maps the errors of the service to the codes of the responses, the first match wins
+// so that the specific errors come before the generic ones. The errors not in the table are unknown failures.
+// A domain.ReasonError is ErrUnprocessable, its reason is returned along with the code.
+var domainErrors = []struct {
+	err  error
+	code string
+}{
+	{domain.ErrTransactionNotFound, CodeNotFound},
+	{domain.ErrCustomerNotFound, CodeNotFound},
+	{domain.ErrPaymentMethodNotFound, CodeNotFound},
+	{domain.ErrPlanNotFound, CodeNotFound},
+	{domain.ErrSubscriptionNotFound, CodeNotFound},
+	{domain.ErrMerchantNotFound, CodeNotFound},
+	{domain.ErrAPIKeyNotFound, CodeNotFound},
+	{domain.ErrMerchantExists, CodeConflict},
+	{domain.ErrAPIKeyRotated, CodeConflict},
+	{domain.ErrMerchantSuspended, CodeForbidden},
+	{domain.ErrVelocityLimitExceeded, CodeVelocityLimit},
+	{domain.ErrInvalidScope, CodeUnprocessable},
+	{domain.ErrUnprocessable, CodeUnprocessable},
+}
+
+// requestError is an error of the request rather than of the service, e.g. an invalid path variable.
+type requestError string
+
+func (e requestError) Error() string { return string(e) }
+
+// endpointError is an error of the endpoint with its own message, it is mapped as the domain error it wraps.
+type endpointError struct {
+	message string
+	err     error
+}
+
+func (e endpointError) Error() string { return e.message }
+
+func (e endpointError) Unwrap() error { return e.err }
+
+// endpoint is the pipeline every endpoint goes through: the request body is decoded and validated,
+// the service is called, its error is mapped with domainErrors and the response is encoded.
+type endpoint struct {
+	// request is where the request body is decoded into, the endpoint has no body if nil.
+	request interface{}
+	// optionalBody accepts an empty request body.
+	optionalBody bool
+	// status is the status of a successful response, http.StatusOK if not set or http.StatusNoContent without response.
+	status int
+	// failure is the message of the errors of the service which are not in domainErrors.
+	failure string
+	// notFound is the message of the errors mapped to CodeNotFound, the message of the error if not set.
+	notFound string
+	// call calls the service once the request is decoded and returns the response.
+	call func(ctx context.Context) (interface{}, error)
+}
+
+// ServeHTTP runs the pipeline of the endpoint.
+func (e endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if e.request != nil && !decodeJSON(w, r, e.request, e.optionalBody) {
+		return
+	}
+
+	resp, err := e.call(ctx)
+	if err != nil {
+		e.writeError(ctx, w, err)
+		return
+	}
+
+	if resp == nil {
+		status := e.status
+		if status == 0 {
+			status = http.StatusNoContent
+		}
+		w.WriteHeader(status)
+		return
+	}
+
+	resp, err = downgradeResponse(resp, apiVersion(ctx))
+	if err != nil {
+		e.writeError(ctx, w, err)
+		return
+	}
+
+	status := e.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.Header().Add(ContentType, ApplicationJSON)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logging.Error(ctx, "error encoding json response", zap.Error(err))
+	}
+}
+
+// writeError writes the error with the code of the domain error it wraps, the errors which are not
+// domain errors are unknown failures written with the failure message.
+func (e endpoint) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	var reqErr requestError
+	if errors.As(err, &reqErr) {
+		logging.Error(ctx, reqErr.Error())
+		_ = WriteError(w, reqErr.Error(), CodeBadRequest)
+		return
+	}
+
+	for _, de := range domainErrors {
+		if errors.Is(err, de.err) {
+			serverError := ServerError{Code: de.code, Message: err.Error()}
+			if de.code == CodeNotFound && e.notFound != "" {
+				serverError.Message = e.notFound
+			}
+			var reasonErr *domain.ReasonError
+			if errors.As(err, &reasonErr) {
+				serverError.Reason = string(reasonErr.Reason)
+			}
+			_ = writeServerError(w, serverError)
+			return
+		}
+	}
+	logging.Error(ctx, e.failure, zap.Error(err))
+	_ = WriteError(w, e.failure, CodeUnknownFailure)
+}
+
+// pathID parses the uuid of the path variable, it returns a requestError if it is invalid.
+func pathID(r *http.Request, name string) (uuid.UUID, error) {
+	id, err := uuid.FromString(mux.Vars(r)[name])
+	if err != nil {
+		return uuid.Nil, requestError("invalid " + name)
+	}
+	return id, nil
+}
@@ -0,0 +1,122 @@
+package transporthttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestHandler_ServiceErrors(t *testing.T) {
+	authorizeBody := `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06", "payment_method_id": "a3b250ae-7b26-4cad-8332-f13a599b0824",
+		"amount": {"minor_units": 100, "currency": "GBP", "exponent": 2}}`
+
+	testCases := []struct {
+		description        string
+		method             string
+		endpoint           string
+		body               string
+		mock               func(srv *mocks.MockService)
+		expectedStatusCode int
+		expectedCode       string
+		expectedMessage    string
+		expectedReason     string
+	}{
+		{
+			description: "should map a not found error of the authorization",
+			method:      http.MethodPost,
+			endpoint:    transporthttp.EndpointAuthorize,
+			body:        authorizeBody,
+			mock: func(srv *mocks.MockService) {
+				srv.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, domain.ErrPaymentMethodNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedCode:       transporthttp.CodeNotFound,
+			expectedMessage:    domain.ErrPaymentMethodNotFound.Error(),
+		},
+		{
+			description: "should return the reason of an unprocessable request",
+			method:      http.MethodPost,
+			endpoint:    transporthttp.EndpointVoid,
+			body:        `{"request_id": "79fec15e-a3ea-49b8-989d-6a9ceac77d06", "authorization_id": "a3b250ae-7b26-4cad-8332-f13a599b0824"}`,
+			mock: func(srv *mocks.MockService) {
+				srv.EXPECT().Void(gomock.Any(), gomock.Any()).Return(nil, domain.ErrAlreadyVoided)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedCode:       transporthttp.CodeUnprocessable,
+			expectedMessage:    "transaction is already voided",
+			expectedReason:     "already_voided",
+		},
+		{
+			description: "should map a wrapped domain error",
+			method:      http.MethodDelete,
+			endpoint:    "/customers/a3b250ae-7b26-4cad-8332-f13a599b0824",
+			mock: func(srv *mocks.MockService) {
+				srv.EXPECT().DeleteCustomer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(fmt.Errorf("failed to delete customer: %w", domain.ErrCustomerNotFound))
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedCode:       transporthttp.CodeNotFound,
+			expectedMessage:    "failed to delete customer: " + domain.ErrCustomerNotFound.Error(),
+		},
+		{
+			description: "should map an unknown error to the failure of the endpoint",
+			method:      http.MethodPost,
+			endpoint:    transporthttp.EndpointAuthorize,
+			body:        authorizeBody,
+			mock: func(srv *mocks.MockService) {
+				srv.EXPECT().Authorize(gomock.Any(), gomock.Any()).Return(nil, errors.New("kaboom"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCode:       transporthttp.CodeUnknownFailure,
+			expectedMessage:    "failed to authorize transaction in service",
+		},
+		{
+			description:        "should reject an invalid path variable",
+			method:             http.MethodGet,
+			endpoint:           "/subscriptions/not-a-uuid",
+			mock:               func(srv *mocks.MockService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       transporthttp.CodeBadRequest,
+			expectedMessage:    "invalid subscription_id",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			tt.mock(srv)
+			h, err := transporthttp.NewHTTPHandler(srv)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.endpoint, strings.NewReader(tt.body))
+			r = r.WithContext(appcontext.WithMerchant(context.Background(), "merchant-1"))
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			var serverError transporthttp.ServerError
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&serverError))
+			assert.Equal(t, tt.expectedCode, serverError.Code)
+			assert.Equal(t, tt.expectedMessage, serverError.Message)
+			assert.Equal(t, tt.expectedReason, serverError.Reason)
+		})
+	}
+}
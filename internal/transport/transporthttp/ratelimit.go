@@ -0,0 +1,82 @@
+package transporthttp
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+)
+
+const retryAfterHeaderKey = "Retry-After"
+
+// RateLimiter limits the requests of each merchant to each endpoint, it returns false and how long until
+// the request can be retried when the merchant is over its limit.
+type RateLimiter interface {
+	Allow(merchantID, endpoint string) (bool, time.Duration)
+}
+
+// WithRateLimit is a function configuration limiting the requests of the merchants with the rate limiter,
+// after the authorization so that the requests are limited by the merchant they are authenticated as.
+func WithRateLimit(limiter RateLimiter) MiddlewareFunc {
+	return func(h *httpHandler) error {
+		h.rateLimiter = limiter
+		return nil
+	}
+}
+
+// WithClientRateLimit is a function configuration limiting the requests of each client address with the rate limiter,
+// before they are authenticated so that a client sending invalid tokens is limited before they are looked up.
+// The limit must allow the requests of all the merchants sharing an address.
+func WithClientRateLimit(limiter RateLimiter) MiddlewareFunc {
+	return func(h *httpHandler) error {
+		h.clientRateLimiter = limiter
+		return nil
+	}
+}
+
+// clientRateLimitMiddleware rejects the requests of a client address over its limit with the Retry-After header
+// in seconds, the address takes the place of the merchant and the limit applies to all the endpoints.
+func clientRateLimitMiddleware(limiter RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter := limiter.Allow(clientAddress(r), "")
+			if !allowed {
+				writeRateLimitExceeded(w, retryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientAddress returns the IP address of the client connected to the gateway.
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimitMiddleware rejects the requests of a merchant over its limit with the Retry-After header in seconds.
+func rateLimitMiddleware(limiter RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter := limiter.Allow(appcontext.GetMerchant(r.Context()), routeEndpoint(r))
+			if !allowed {
+				writeRateLimitExceeded(w, retryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeRateLimitExceeded writes the rate limit error with the Retry-After header in seconds.
+func writeRateLimitExceeded(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set(retryAfterHeaderKey, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	_ = WriteError(w, "rate limit exceeded", CodeRateLimit)
+}
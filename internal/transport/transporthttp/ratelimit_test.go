@@ -0,0 +1,101 @@
+package transporthttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jonboulle/clockwork"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/ratelimit"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	customerID := uuid.NewV4()
+	srv := mocks.NewMockService(ctrl)
+	srv.EXPECT().ValidateMerchant(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	srv.EXPECT().DeleteCustomer(gomock.Any(), gomock.Any(), customerID).Return(nil).Times(4)
+
+	clock := clockwork.NewFakeClockAt(time.Date(2021, 5, 2, 12, 0, 0, 0, time.UTC))
+	limiter := ratelimit.NewLimiter(clock, ratelimit.Limit{}, map[string]ratelimit.Limit{
+		"merchant-1": {RequestsPerSecond: 0.5, Burst: 2},
+	})
+	h, err := transporthttp.NewHTTPHandler(srv,
+		transporthttp.WithAuth(map[string]string{"token-1": "merchant-1", "token-2": "merchant-2"}),
+		transporthttp.WithRateLimit(limiter),
+	)
+	require.NoError(t, err)
+	handler := httplistener.HTTPHandler(h)
+
+	deleteCustomer := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/customers/"+customerID.String(), nil)
+		r.Header.Set("Authorization", token)
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNoContent, deleteCustomer("token-1").Code)
+	assert.Equal(t, http.StatusNoContent, deleteCustomer("token-1").Code)
+
+	w := deleteCustomer("token-1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	var serverError transporthttp.ServerError
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&serverError))
+	assert.Equal(t, transporthttp.CodeRateLimit, serverError.Code)
+
+	assert.Equal(t, http.StatusNoContent, deleteCustomer("token-2").Code, "the other merchants are not limited")
+
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, http.StatusNoContent, deleteCustomer("token-1").Code)
+}
+
+func TestClientRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	customerID := uuid.NewV4()
+	srv := mocks.NewMockService(ctrl)
+	// the requests over the limit of the client address are rejected before their token is looked up
+	srv.EXPECT().AuthenticateAPIKey(gomock.Any(), "unknown").Return(nil, domain.ErrAPIKeyInvalid).Times(3)
+
+	clock := clockwork.NewFakeClockAt(time.Date(2021, 5, 2, 12, 0, 0, 0, time.UTC))
+	h, err := transporthttp.NewHTTPHandler(srv,
+		transporthttp.WithAuth(map[string]string{"token-1": "merchant-1"}),
+		transporthttp.WithClientRateLimit(ratelimit.NewLimiter(clock, ratelimit.Limit{RequestsPerSecond: 1, Burst: 2}, nil)),
+	)
+	require.NoError(t, err)
+	handler := httplistener.HTTPHandler(h)
+
+	deleteCustomer := func(remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/customers/"+customerID.String(), nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("Authorization", "unknown")
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, deleteCustomer("192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusForbidden, deleteCustomer("192.0.2.1:1235").Code)
+
+	w := deleteCustomer("192.0.2.1:1236")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusForbidden, deleteCustomer("192.0.2.2:1234").Code, "the other addresses are not limited")
+}
@@ -0,0 +1,122 @@
+package transporthttp
+
+import (
+	"context"
+	"net/http"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+const (
+	EndpointPlans              = "/plans"
+	EndpointSubscriptions      = "/subscriptions"
+	EndpointSubscription       = "/subscriptions/{subscription_id}"
+	EndpointSubscriptionCancel = "/subscriptions/{subscription_id}/cancel"
+)
+
+// CreatePlan handler to create a subscription plan of the merchant.
+func (h *httpHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
+	var req PlanRequest
+	endpoint{
+		request: &req,
+		failure: "failed to create plan in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			plan, err := h.service.CreatePlan(ctx, &domain.Plan{
+				MerchantID:    appcontext.GetMerchant(ctx),
+				Name:          req.Name,
+				Amount:        mapToAmount(req.Amount),
+				Interval:      domain.Interval(req.Interval),
+				IntervalCount: req.IntervalCount,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return Plan{
+				ID:   plan.ID,
+				Name: plan.Name,
+				Amount: Amount{
+					MinorUnits: plan.Amount.MinorUnits,
+					Exponent:   plan.Amount.Exponent,
+					Currency:   plan.Amount.Currency,
+				},
+				Interval:      string(plan.Interval),
+				IntervalCount: plan.IntervalCount,
+				CreatedDate:   plan.CreatedDate,
+			}, nil
+		},
+	}.ServeHTTP(w, r)
+}
+
+// CreateSubscription handler to subscribe a customer to a plan with a stored payment method.
+func (h *httpHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req SubscriptionRequest
+	endpoint{
+		request: &req,
+		failure: "failed to create subscription in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			subscription := &domain.Subscription{
+				MerchantID:      appcontext.GetMerchant(ctx),
+				CustomerID:      req.CustomerID,
+				PlanID:          req.PlanID,
+				PaymentMethodID: req.PaymentMethodID,
+			}
+			if req.StartDate != nil {
+				subscription.PeriodStart = *req.StartDate
+			}
+			return subscriptionResp(h.service.CreateSubscription(ctx, subscription))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// GetSubscription handler to get a subscription of the merchant.
+func (h *httpHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to get subscription in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			subscriptionID, err := pathID(r, "subscription_id")
+			if err != nil {
+				return nil, err
+			}
+			return subscriptionResp(h.service.GetSubscription(ctx, appcontext.GetMerchant(ctx), subscriptionID))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// CancelSubscription handler to cancel a subscription of the merchant.
+func (h *httpHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	endpoint{
+		failure: "failed to cancel subscription in service",
+		call: func(ctx context.Context) (interface{}, error) {
+			subscriptionID, err := pathID(r, "subscription_id")
+			if err != nil {
+				return nil, err
+			}
+			return subscriptionResp(h.service.CancelSubscription(ctx, appcontext.GetMerchant(ctx), subscriptionID))
+		},
+	}.ServeHTTP(w, r)
+}
+
+// subscriptionResp maps the subscription returned by the service to the response unless the service failed.
+func subscriptionResp(s *domain.Subscription, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	return mapToSubscriptionResp(s), nil
+}
+
+// helper mapper function to map to subscription response.
+func mapToSubscriptionResp(s *domain.Subscription) Subscription {
+	return Subscription{
+		ID:              s.ID,
+		CustomerID:      s.CustomerID,
+		PlanID:          s.PlanID,
+		PaymentMethodID: s.PaymentMethodID,
+		Status:          string(s.Status),
+		PeriodStart:     s.PeriodStart,
+		NextBillingDate: s.NextBillingDate,
+		FailedAttempts:  s.FailedAttempts,
+		CreatedDate:     s.CreatedDate,
+	}
+}
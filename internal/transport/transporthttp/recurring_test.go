@@ -0,0 +1,198 @@
+package transporthttp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestHandler_CreateSubscription(t *testing.T) {
+	merchantID := "merchant-1"
+	customerID, planID, paymentMethodID := uuid.NewV4(), uuid.NewV4(), uuid.NewV4()
+	startDate := time.Date(2021, 07, 01, 0, 0, 0, 0, time.UTC)
+
+	subscription := &domain.Subscription{
+		MerchantID:      merchantID,
+		CustomerID:      customerID,
+		PlanID:          planID,
+		PaymentMethodID: paymentMethodID,
+		PeriodStart:     startDate,
+	}
+	created := *subscription
+	created.ID = uuid.NewV4()
+	created.Status = domain.SubscriptionStatusActive
+	created.NextBillingDate = startDate
+
+	testCases := []struct {
+		description        string
+		body               interface{}
+		setupMocks         func(srv *mocks.MockService)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			"should create the subscription",
+			transporthttp.SubscriptionRequest{CustomerID: customerID, PlanID: planID, PaymentMethodID: paymentMethodID, StartDate: &startDate},
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CreateSubscription(gomock.Any(), subscription).Return(&created, nil)
+			},
+			http.StatusOK,
+			"",
+		},
+		{
+			"missing payment method id",
+			transporthttp.SubscriptionRequest{CustomerID: customerID, PlanID: planID},
+			nil,
+			http.StatusBadRequest,
+			transporthttp.CodeBadRequest,
+		},
+		{
+			"service returns plan not found",
+			transporthttp.SubscriptionRequest{CustomerID: customerID, PlanID: planID, PaymentMethodID: paymentMethodID, StartDate: &startDate},
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CreateSubscription(gomock.Any(), subscription).Return(nil, domain.ErrPlanNotFound)
+			},
+			http.StatusNotFound,
+			transporthttp.CodeNotFound,
+		},
+		{
+			"service returns unprocessable for a payment method without initial authorization",
+			transporthttp.SubscriptionRequest{CustomerID: customerID, PlanID: planID, PaymentMethodID: paymentMethodID, StartDate: &startDate},
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CreateSubscription(gomock.Any(), subscription).Return(nil, domain.ErrUnprocessable)
+			},
+			http.StatusUnprocessableEntity,
+			transporthttp.CodeUnprocessable,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(srv)
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv)
+			require.NoError(t, err)
+
+			body, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, transporthttp.EndpointSubscriptions, bytes.NewReader(body))
+			r = r.WithContext(appcontext.WithMerchant(r.Context(), merchantID))
+
+			h.CreateSubscription(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedCode != "" {
+				var out transporthttp.ServerError
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+				assert.Equal(t, tt.expectedCode, out.Code)
+				return
+			}
+
+			var out transporthttp.Subscription
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+			assert.Equal(t, created.ID, out.ID)
+			assert.Equal(t, string(domain.SubscriptionStatusActive), out.Status)
+			assert.Equal(t, startDate, out.NextBillingDate)
+		})
+	}
+}
+
+func TestHandler_CancelSubscription(t *testing.T) {
+	merchantID := "merchant-1"
+	subscriptionID := uuid.NewV4()
+
+	testCases := []struct {
+		description        string
+		subscriptionID     string
+		setupMocks         func(srv *mocks.MockService)
+		expectedStatusCode int
+		expectedCode       string
+	}{
+		{
+			"should cancel the subscription",
+			subscriptionID.String(),
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CancelSubscription(gomock.Any(), merchantID, subscriptionID).
+					Return(&domain.Subscription{ID: subscriptionID, Status: domain.SubscriptionStatusCanceled}, nil)
+			},
+			http.StatusOK,
+			"",
+		},
+		{
+			"invalid subscription id",
+			"some-id",
+			nil,
+			http.StatusBadRequest,
+			transporthttp.CodeBadRequest,
+		},
+		{
+			"service returns not found",
+			subscriptionID.String(),
+			func(srv *mocks.MockService) {
+				srv.EXPECT().CancelSubscription(gomock.Any(), merchantID, subscriptionID).Return(nil, domain.ErrSubscriptionNotFound)
+			},
+			http.StatusNotFound,
+			transporthttp.CodeNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(srv)
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/subscriptions/"+tt.subscriptionID+"/cancel", nil)
+			r = r.WithContext(appcontext.WithMerchant(r.Context(), merchantID))
+			r = mux.SetURLVars(r, map[string]string{"subscription_id": tt.subscriptionID})
+
+			h.CancelSubscription(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.expectedStatusCode, res.StatusCode)
+
+			if tt.expectedCode != "" {
+				var out transporthttp.ServerError
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+				assert.Equal(t, tt.expectedCode, out.Code)
+				return
+			}
+
+			var out transporthttp.Subscription
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+			assert.Equal(t, string(domain.SubscriptionStatusCanceled), out.Status)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package transporthttp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+)
+
+// headers of the signed requests
+const (
+	SignatureMerchantHeaderKey  = "X-Signature-Merchant"
+	SignatureTimestampHeaderKey = "X-Signature-Timestamp"
+	SignatureHeaderKey          = "X-Signature"
+)
+
+const (
+	// DefaultSignatureWindow is how far the timestamp of a signed request may be from the clock of the service.
+	DefaultSignatureWindow = 5 * time.Minute
+	// schemeSignature identifies the signed requests of a merchant in the audit log.
+	schemeSignature = "hmac"
+)
+
+// SigningConfig is the request signing configuration of a merchant.
+type SigningConfig struct {
+	// Secret is the HMAC-SHA256 key the merchant signs its requests with.
+	Secret string
+	// Required rejects the requests of the merchant authenticated with a token rather than signed.
+	Required bool
+}
+
+// requestSigning verifies the signatures of the requests and enforces them for the merchants requiring them.
+type requestSigning struct {
+	merchants map[string]SigningConfig
+	window    time.Duration
+	clock     interface{ Now() time.Time }
+	// seen are the signatures verified within the window, a signed request is only accepted once.
+	seen *signatureCache
+}
+
+// signatureCache records the signatures of the requests until their timestamp is outside of the window.
+type signatureCache struct {
+	mu         sync.Mutex
+	expiries   map[string]time.Time
+	lastPruned time.Time
+}
+
+// add records the signature of the merchant until the expiry, it returns false if it is already recorded.
+// The expired signatures are pruned at most once per window.
+func (c *signatureCache) add(merchant, signature string, now, expiry time.Time, window time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastPruned) > window {
+		for key, e := range c.expiries {
+			if !e.After(now) {
+				delete(c.expiries, key)
+			}
+		}
+		c.lastPruned = now
+	}
+
+	key := merchant + "\n" + signature
+	if e, ok := c.expiries[key]; ok && e.After(now) {
+		return false
+	}
+	c.expiries[key] = expiry
+	return true
+}
+
+// WithRequestSigning is a function configuration for authentication with a HMAC-SHA256 signature of the request
+// as an alternative to the tokens, given the signing configuration keyed by merchant. A signed request is granted
+// every scope of its merchant. The timestamp of the request must be within the window of the clock of the service,
+// DefaultSignatureWindow if not set, and a signature is only accepted once, so that a captured request cannot be replayed.
+func WithRequestSigning(merchants map[string]SigningConfig, window time.Duration) MiddlewareFunc {
+	return func(h *httpHandler) error {
+		if window <= 0 {
+			window = DefaultSignatureWindow
+		}
+		h.signing = &requestSigning{
+			merchants: merchants,
+			window:    window,
+			clock:     h.service,
+			seen:      &signatureCache{expiries: make(map[string]time.Time)},
+		}
+		return nil
+	}
+}
+
+// SignRequest returns the hex encoded HMAC-SHA256 signature of the method, the request URI, the unix timestamp
+// and the body of a request, each separated by a new line.
+func SignRequest(secret, method, requestURI string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n", method, requestURI, timestamp.Unix())
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify authenticates the signed requests before the authorization middleware, the requests without a signature
+// are left to be authenticated with their token.
+func (s *requestSigning) verify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		merchant := r.Header.Get(SignatureMerchantHeaderKey)
+		if merchant == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		config, ok := s.merchants[merchant]
+		if !ok || config.Secret == "" {
+			_ = WriteError(w, "request signing is not enabled for the merchant", CodeForbidden)
+			return
+		}
+
+		unix, err := strconv.ParseInt(r.Header.Get(SignatureTimestampHeaderKey), 10, 64)
+		if err != nil {
+			_ = WriteError(w, "invalid signature timestamp", CodeUnauthorized)
+			return
+		}
+		timestamp := time.Unix(unix, 0)
+		now := s.clock.Now()
+		if age := now.Sub(timestamp); age > s.window || age < -s.window {
+			_ = WriteError(w, "signature timestamp outside of the replay window", CodeUnauthorized)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		switch {
+		case errors.Is(err, errBodyTooLarge):
+			_ = WriteError(w, "request body too large", CodePayloadTooLarge)
+			return
+		case err != nil:
+			_ = WriteError(w, "error reading request body", CodeBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		signature, err := hex.DecodeString(r.Header.Get(SignatureHeaderKey))
+		expected, _ := hex.DecodeString(SignRequest(config.Secret, r.Method, r.URL.RequestURI(), timestamp, body))
+		if err != nil || !hmac.Equal(signature, expected) {
+			_ = WriteError(w, "invalid signature", CodeForbidden)
+			return
+		}
+		// the signature covers the timestamp hence it can only be replayed until the timestamp is outside of the window
+		if !s.seen.add(merchant, hex.EncodeToString(signature), now, timestamp.Add(s.window), s.window) {
+			_ = WriteError(w, "signature already used", CodeUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(withAuthenticatedMerchant(r.Context(), schemeSignature, merchant)))
+	})
+}
+
+// enforce rejects the requests authenticated with a token of the merchants requiring signed requests,
+// after the authorization middleware so that the merchant is known.
+func (s *requestSigning) enforce(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if authenticated, ok := authenticatedMerchant(ctx); (!ok || authenticated.scheme != schemeSignature) &&
+			s.merchants[appcontext.GetMerchant(ctx)].Required {
+			_ = WriteError(w, "the merchant requires signed requests", CodeForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
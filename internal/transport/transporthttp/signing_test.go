@@ -0,0 +1,177 @@
+package transporthttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestRequestSigning(t *testing.T) {
+	const (
+		secret = "signing-secret"
+		body   = `{"email":"jane@example.com","name":"Jane"}`
+	)
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description        string
+		merchant           string
+		token              string
+		timestamp          time.Time
+		signature          string
+		sentBody           string
+		expectedStatusCode int
+	}{
+		{
+			description:        "should accept a signed request",
+			merchant:           "merchant-1",
+			timestamp:          now.Add(-time.Minute),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			description:        "should reject a request signed outside of the replay window",
+			merchant:           "merchant-1",
+			timestamp:          now.Add(-10 * time.Minute),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			description:        "should reject a request with a timestamp in the future outside of the replay window",
+			merchant:           "merchant-1",
+			timestamp:          now.Add(10 * time.Minute),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			description:        "should reject a request with a tampered body",
+			merchant:           "merchant-1",
+			timestamp:          now,
+			sentBody:           `{"email":"mallory@example.com","name":"Jane"}`,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description:        "should reject a request with an invalid signature",
+			merchant:           "merchant-1",
+			timestamp:          now,
+			signature:          "not-hex",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description:        "should reject a signed request of a merchant without signing secret",
+			merchant:           "merchant-2",
+			timestamp:          now,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			description:        "should accept the token of a merchant not requiring signed requests",
+			token:              "token-1",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			description:        "should reject the token of a merchant requiring signed requests",
+			token:              "token-3",
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			srv := mocks.NewMockService(ctrl)
+			srv.EXPECT().Now().Return(now).AnyTimes()
+			srv.EXPECT().ValidateMerchant(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			if tt.expectedStatusCode == http.StatusOK {
+				srv.EXPECT().CreateCustomer(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, customer *domain.Customer) (*domain.Customer, error) {
+					assert.Equal(t, appcontext.GetMerchant(ctx), customer.MerchantID)
+					assert.Equal(t, "jane@example.com", customer.Email)
+					return customer, nil
+				})
+			}
+
+			h, err := transporthttp.NewHTTPHandler(srv,
+				transporthttp.WithAuth(map[string]string{"token-1": "merchant-1", "token-3": "merchant-3"}),
+				transporthttp.WithRequestSigning(map[string]transporthttp.SigningConfig{
+					"merchant-1": {Secret: secret},
+					"merchant-3": {Secret: secret, Required: true},
+				}, 0),
+			)
+			require.NoError(t, err)
+
+			sentBody := body
+			if tt.sentBody != "" {
+				sentBody = tt.sentBody
+			}
+			r := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(sentBody))
+			if tt.token != "" {
+				r.Header.Set("Authorization", tt.token)
+			}
+			if tt.merchant != "" {
+				signature := tt.signature
+				if signature == "" {
+					signature = transporthttp.SignRequest(secret, http.MethodPost, "/customers", tt.timestamp, []byte(body))
+				}
+				r.Header.Set(transporthttp.SignatureMerchantHeaderKey, tt.merchant)
+				r.Header.Set(transporthttp.SignatureTimestampHeaderKey, strconv.FormatInt(tt.timestamp.Unix(), 10))
+				r.Header.Set(transporthttp.SignatureHeaderKey, signature)
+			}
+
+			w := httptest.NewRecorder()
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestRequestSigning_Replay(t *testing.T) {
+	const (
+		secret = "signing-secret"
+		body   = `{"email":"jane@example.com","name":"Jane"}`
+	)
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := mocks.NewMockService(ctrl)
+	srv.EXPECT().Now().Return(now).AnyTimes()
+	srv.EXPECT().ValidateMerchant(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	srv.EXPECT().CreateCustomer(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, customer *domain.Customer) (*domain.Customer, error) {
+		return customer, nil
+	})
+
+	h, err := transporthttp.NewHTTPHandler(srv,
+		transporthttp.WithMaxBodyBytes(int64(len(body))),
+		transporthttp.WithRequestSigning(map[string]transporthttp.SigningConfig{"merchant-1": {Secret: secret}}, 0),
+	)
+	require.NoError(t, err)
+	handler := httplistener.HTTPHandler(h)
+
+	send := func(body string) int {
+		r := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(body))
+		r.Header.Set(transporthttp.SignatureMerchantHeaderKey, "merchant-1")
+		r.Header.Set(transporthttp.SignatureTimestampHeaderKey, strconv.FormatInt(now.Unix(), 10))
+		r.Header.Set(transporthttp.SignatureHeaderKey, transporthttp.SignRequest(secret, http.MethodPost, "/customers", now, []byte(body)))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(body))
+	assert.Equal(t, http.StatusUnauthorized, send(body), "a signed request is only accepted once")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send(body+" "))
+}
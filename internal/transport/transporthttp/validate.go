@@ -0,0 +1,97 @@
+package transporthttp
+
+import (
+	"net"
+
+	uuid "github.com/kevinburke/go.uuid"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+// Validate validates the authorization request, the payment source is not required with a stored payment method.
+func (r AuthorizeRequest) Validate() []FieldError {
+	var e fieldErrors
+	e.required("request_id", r.RequestID != uuid.Nil)
+	r.Amount.validate(&e, "amount")
+	if r.PaymentMethodID == uuid.Nil {
+		e.required("payment_source.pan", r.PaymentSource.PAN != "")
+	}
+	switch domain.Initiator(r.Initiator) {
+	case "", domain.InitiatorCustomer, domain.InitiatorMerchant:
+	default:
+		e.add("initiator", "must be customer or merchant")
+	}
+	if r.StorePaymentMethod {
+		e.required("customer_id", r.CustomerID != uuid.Nil)
+	}
+	if r.ShopperIP != "" && net.ParseIP(r.ShopperIP) == nil {
+		e.add("shopper_ip", "must be an IP address")
+	}
+	return e
+}
+
+// Validate validates the capture request.
+func (r CaptureRequest) Validate() []FieldError {
+	var e fieldErrors
+	e.required("request_id", r.RequestID != uuid.Nil)
+	e.required("authorization_id", r.AuthorizationID != uuid.Nil)
+	r.Amount.validate(&e, "amount")
+	return e
+}
+
+// Validate validates the refund request.
+func (r RefundRequest) Validate() []FieldError {
+	var e fieldErrors
+	e.required("request_id", r.RequestID != uuid.Nil)
+	e.required("authorization_id", r.AuthorizationID != uuid.Nil)
+	r.Amount.validate(&e, "amount")
+	return e
+}
+
+// Validate validates the void request.
+func (r VoidRequest) Validate() []FieldError {
+	var e fieldErrors
+	e.required("request_id", r.RequestID != uuid.Nil)
+	e.required("authorization_id", r.AuthorizationID != uuid.Nil)
+	return e
+}
+
+// Validate validates the payment method request.
+func (r PaymentMethodRequest) Validate() []FieldError {
+	var e fieldErrors
+	e.required("payment_source.pan", r.PaymentSource.PAN != "")
+	return e
+}
+
+// Validate validates the plan request, the interval is validated by the service.
+func (r PlanRequest) Validate() []FieldError {
+	var e fieldErrors
+	r.Amount.validate(&e, "amount")
+	return e
+}
+
+// Validate validates the subscription request.
+func (r SubscriptionRequest) Validate() []FieldError {
+	var e fieldErrors
+	e.required("customer_id", r.CustomerID != uuid.Nil)
+	e.required("plan_id", r.PlanID != uuid.Nil)
+	e.required("payment_method_id", r.PaymentMethodID != uuid.Nil)
+	return e
+}
+
+// Validate validates the rotate api key request.
+func (r RotateAPIKeyRequest) Validate() []FieldError {
+	var e fieldErrors
+	if r.OverlapSeconds < 0 {
+		e.add("overlap_seconds", "must not be negative")
+	}
+	return e
+}
+
+// validate validates the amount of the field, it must be positive and have a currency.
+func (a Amount) validate(e *fieldErrors, field string) {
+	if a.MinorUnits == 0 {
+		e.add(field+".minor_units", "must be greater than 0")
+	}
+	e.required(field+".currency", a.Currency != "")
+}
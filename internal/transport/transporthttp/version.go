@@ -0,0 +1,185 @@
+package transporthttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+)
+
+const (
+	// APIVersionPrefix is the prefix of the routes of the major version of the API, the routes without it
+	// are deprecated aliases of the same version.
+	APIVersionPrefix = "/v1"
+	// APIVersionHeaderKey is the header of the version of the responses of a request, it is echoed in the response.
+	APIVersionHeaderKey = "X-API-Version"
+	// deprecationHeaderKey flags the responses of the deprecated routes without APIVersionPrefix.
+	deprecationHeaderKey = "Deprecation"
+)
+
+// the versions of the responses of the API
+const (
+	// APIVersion20210618 is the first version of the API, its transactions have the fields of baselineTransactionFields.
+	APIVersion20210618 = "2021-06-18"
+	// APIVersion20211101 adds the status, the state of the lifecycle, the fees, the fx rate, the risk, the 3-D Secure
+	// authentication, the initiator, the payment method, the customer and the network transaction id of the transactions.
+	APIVersion20211101 = "2021-11-01"
+
+	// DefaultAPIVersion is the version of the merchants which are not pinned to a version, the merchants
+	// integrated with an older version are pinned to it so that the changes of the responses don't break them.
+	DefaultAPIVersion = LatestAPIVersion
+	// LatestAPIVersion is the version of the response models.
+	LatestAPIVersion = APIVersion20211101
+)
+
+// apiVersions are the versions of the API from the oldest.
+var apiVersions = []string{APIVersion20210618, APIVersion20211101}
+
+// versionChange is a change of a response model in a version, it is reverted for the requests of an older version.
+type versionChange struct {
+	version string
+	// model is the type of the changed response model.
+	model reflect.Type
+	// downgrade reverts the change of the model encoded as a JSON object.
+	downgrade func(obj map[string]interface{})
+}
+
+// versionChanges are the changes of the response models from the newest, they are reverted in order
+// from the latest version down to the version of the request.
+var versionChanges = []versionChange{
+	{
+		version: APIVersion20211101,
+		model:   reflect.TypeOf(Transaction{}),
+		downgrade: func(obj map[string]interface{}) {
+			for field := range obj {
+				if !baselineTransactionFields[field] {
+					delete(obj, field)
+				}
+			}
+		},
+	},
+}
+
+// baselineTransactionFields are the fields of the transactions in APIVersion20210618.
+var baselineTransactionFields = map[string]bool{
+	"id":                 true,
+	"authorization_id":   true,
+	"authorization_date": true,
+	"authorized_amount":  true,
+	"captured_amount":    true,
+	"refunded_amount":    true,
+	"is_voided":          true,
+}
+
+type apiVersionKey struct{}
+
+// WithAPIVersions is a function configuration of the versions the merchants are pinned to, keyed by merchant.
+// The merchants which are not pinned get DefaultAPIVersion, a request can ask for another version with the
+// APIVersionHeaderKey header.
+func WithAPIVersions(merchants map[string]string) MiddlewareFunc {
+	return func(h *httpHandler) error {
+		for merchant, version := range merchants {
+			if !supportedAPIVersion(version) {
+				return fmt.Errorf("unsupported api version %q of merchant %s", version, merchant)
+			}
+		}
+		h.apiVersions = merchants
+		return nil
+	}
+}
+
+// apiVersionMiddleware negotiates the version of the responses of the request after the authorization,
+// the version of the header over the one the merchant is pinned to.
+func apiVersionMiddleware(merchants map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version := r.Header.Get(APIVersionHeaderKey)
+			switch {
+			case version != "" && !supportedAPIVersion(version):
+				_ = WriteError(w, fmt.Sprintf("unsupported api version %q", version), CodeBadRequest)
+				return
+			case version == "":
+				version = merchants[appcontext.GetMerchant(r.Context())]
+			}
+			if version == "" {
+				version = DefaultAPIVersion
+			}
+
+			w.Header().Set(APIVersionHeaderKey, version)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version)))
+		})
+	}
+}
+
+// deprecatedMiddleware flags the responses of the deprecated routes.
+func deprecatedMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(deprecationHeaderKey, "true")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiVersion returns the version of the responses of the request, DefaultAPIVersion if not negotiated.
+func apiVersion(ctx context.Context) string {
+	if version, ok := ctx.Value(apiVersionKey{}).(string); ok {
+		return version
+	}
+	return DefaultAPIVersion
+}
+
+func supportedAPIVersion(version string) bool {
+	for _, v := range apiVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// downgradeResponse transforms the response, or the elements of a list, to the version by reverting the changes
+// of the newer versions. The response is returned as is if none of them changed its model.
+func downgradeResponse(resp interface{}, version string) (interface{}, error) {
+	t := reflect.TypeOf(resp)
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	var changes []versionChange
+	for _, c := range versionChanges {
+		if c.version > version && c.model == t {
+			changes = append(changes, c)
+		}
+	}
+	if len(changes) == 0 {
+		return resp, nil
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	// the numbers are decoded as json.Number so that the amounts in minor units keep their precision
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	objects, ok := v.([]interface{})
+	if !ok {
+		objects = []interface{}{v}
+	}
+	for _, c := range changes {
+		for _, o := range objects {
+			if obj, ok := o.(map[string]interface{}); ok {
+				c.downgrade(obj)
+			}
+		}
+	}
+	return v, nil
+}
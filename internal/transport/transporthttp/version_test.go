@@ -0,0 +1,237 @@
+package transporthttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	uuid "github.com/kevinburke/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcontext "github.com/jeffreyyong/payment-gateway/internal/app/context"
+	"github.com/jeffreyyong/payment-gateway/internal/app/listeners/httplistener"
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp"
+	"github.com/jeffreyyong/payment-gateway/internal/transport/transporthttp/mocks"
+)
+
+func TestHandler_APIVersion(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		path            string
+		merchant        string
+		header          string
+		expectedStatus  int
+		expectedVersion string
+		expectedLatest  bool
+	}{
+		{
+			desc:            "should serve the latest version to a merchant which is not pinned",
+			path:            transporthttp.APIVersionPrefix + "/customers/" + openAPIResource,
+			merchant:        "merchant-1",
+			expectedStatus:  http.StatusOK,
+			expectedVersion: transporthttp.LatestAPIVersion,
+			expectedLatest:  true,
+		},
+		{
+			desc:            "should serve the version the merchant is pinned to",
+			path:            transporthttp.APIVersionPrefix + "/customers/" + openAPIResource,
+			merchant:        "merchant-2",
+			expectedStatus:  http.StatusOK,
+			expectedVersion: transporthttp.APIVersion20210618,
+		},
+		{
+			desc:            "should serve the version of the header over the one the merchant is pinned to",
+			path:            transporthttp.APIVersionPrefix + "/customers/" + openAPIResource,
+			merchant:        "merchant-2",
+			header:          transporthttp.APIVersion20211101,
+			expectedStatus:  http.StatusOK,
+			expectedVersion: transporthttp.APIVersion20211101,
+			expectedLatest:  true,
+		},
+		{
+			desc:            "should serve the negotiated version on the deprecated routes",
+			path:            "/customers/" + openAPIResource,
+			merchant:        "merchant-1",
+			header:          transporthttp.APIVersion20210618,
+			expectedStatus:  http.StatusOK,
+			expectedVersion: transporthttp.APIVersion20210618,
+		},
+		{
+			desc:           "should reject an unsupported version",
+			path:           transporthttp.APIVersionPrefix + "/customers/" + openAPIResource,
+			merchant:       "merchant-1",
+			header:         "2020-01-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			srv := mocks.NewMockService(ctrl)
+			h, err := transporthttp.NewHTTPHandler(srv, transporthttp.WithAPIVersions(map[string]string{
+				"merchant-2": transporthttp.APIVersion20210618,
+			}))
+			require.NoError(t, err)
+
+			if tt.expectedStatus == http.StatusOK {
+				srv.EXPECT().ListCustomerTransactions(gomock.Any(), tt.merchant, openAPIID).
+					Return([]*domain.Transaction{openAPITransaction, openAPITransaction}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tt.path+"/transactions", nil)
+			if tt.header != "" {
+				r.Header.Set(transporthttp.APIVersionHeaderKey, tt.header)
+			}
+			r = r.WithContext(appcontext.WithMerchant(context.Background(), tt.merchant))
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.expectedVersion, w.Header().Get(transporthttp.APIVersionHeaderKey))
+
+			var transactions []map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &transactions))
+			require.Len(t, transactions, 2)
+			for _, transaction := range transactions {
+				if tt.expectedLatest {
+					assert.Equal(t, transporthttp.TransactionStatusAuthorized, transaction["status"])
+					assert.Equal(t, transporthttp.TransactionStateAuthorized, transaction["state"])
+					continue
+				}
+				// the transactions of the first version only have the fields of the baseline
+				var fields []string
+				for field := range transaction {
+					fields = append(fields, field)
+				}
+				assert.ElementsMatch(t, []string{"id", "authorization_id", "authorization_date", "authorized_amount",
+					"captured_amount", "refunded_amount", "is_voided"}, fields)
+			}
+		})
+	}
+}
+
+func TestHandler_APIVersion_AmountPrecision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockService(ctrl)
+	h, err := transporthttp.NewHTTPHandler(srv)
+	require.NoError(t, err)
+
+	// 2^53 + 1 is not representable as a float64
+	transaction := *openAPITransaction
+	transaction.AuthorizedAmount.MinorUnits = 1<<53 + 1
+	srv.EXPECT().ListCustomerTransactions(gomock.Any(), "merchant-1", openAPIID).Return([]*domain.Transaction{&transaction}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, transporthttp.APIVersionPrefix+"/customers/"+openAPIResource+"/transactions", nil)
+	r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.APIVersion20210618)
+	r = r.WithContext(appcontext.WithMerchant(context.Background(), "merchant-1"))
+	httplistener.HTTPHandler(h).ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"minor_units":9007199254740993}`)
+}
+
+func TestHandler_DeprecatedRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockService(ctrl)
+	h, err := transporthttp.NewHTTPHandler(srv)
+	require.NoError(t, err)
+	handler := httplistener.HTTPHandler(h)
+
+	srv.EXPECT().DeleteCustomer(gomock.Any(), "merchant-1", openAPIID).Return(nil).Times(2)
+
+	for path, deprecated := range map[string]bool{
+		transporthttp.APIVersionPrefix + "/customers/" + openAPIResource: false,
+		"/customers/" + openAPIResource:                                  true,
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, path, nil)
+		r = r.WithContext(appcontext.WithMerchant(context.Background(), "merchant-1"))
+		handler.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, path)
+		assert.Equal(t, deprecated, w.Header().Get("Deprecation") == "true", path)
+	}
+}
+
+func TestHandler_TransactionState(t *testing.T) {
+	authorized := &domain.PaymentAction{Type: domain.PaymentActionTypeAuthorization, Status: domain.PaymentActionStatusSuccess, ProcessedDate: openAPIDate}
+	amount := func(minorUnits uint64) domain.Amount {
+		return domain.Amount{MinorUnits: minorUnits, Currency: "GBP", Exponent: 2}
+	}
+	success := func(pat domain.PaymentActionType) *domain.PaymentAction {
+		return &domain.PaymentAction{Type: pat, Status: domain.PaymentActionStatusSuccess, ProcessedDate: openAPIDate}
+	}
+
+	testCases := []struct {
+		transaction   *domain.Transaction
+		expectedState string
+	}{
+		{&domain.Transaction{}, transporthttp.TransactionStateDeclined},
+		{&domain.Transaction{
+			AuthorizedAmount: amount(1000), PaymentActionSummary: []*domain.PaymentAction{authorized},
+		}, transporthttp.TransactionStateAuthorized},
+		{&domain.Transaction{
+			AuthorizedAmount: amount(1000), PaymentActionSummary: []*domain.PaymentAction{authorized, success(domain.PaymentActionTypeVoid)},
+		}, transporthttp.TransactionStateVoided},
+		{&domain.Transaction{
+			AuthorizedAmount: amount(1000), CapturedAmount: amount(500),
+			PaymentActionSummary: []*domain.PaymentAction{authorized, success(domain.PaymentActionTypeCapture)},
+		}, transporthttp.TransactionStatePartiallyCaptured},
+		{&domain.Transaction{
+			AuthorizedAmount: amount(1000), CapturedAmount: amount(1000),
+			PaymentActionSummary: []*domain.PaymentAction{authorized, success(domain.PaymentActionTypeCapture)},
+		}, transporthttp.TransactionStateCaptured},
+		{&domain.Transaction{
+			AuthorizedAmount: amount(1000), CapturedAmount: amount(1000), RefundedAmount: amount(200),
+			PaymentActionSummary: []*domain.PaymentAction{authorized, success(domain.PaymentActionTypeCapture), success(domain.PaymentActionTypeRefund)},
+		}, transporthttp.TransactionStatePartiallyRefunded},
+		{&domain.Transaction{
+			AuthorizedAmount: amount(1000), CapturedAmount: amount(1000), RefundedAmount: amount(1000),
+			PaymentActionSummary: []*domain.PaymentAction{authorized, success(domain.PaymentActionTypeCapture), success(domain.PaymentActionTypeRefund)},
+		}, transporthttp.TransactionStateRefunded},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	srv := mocks.NewMockService(ctrl)
+	h, err := transporthttp.NewHTTPHandler(srv)
+	require.NoError(t, err)
+
+	for _, tt := range testCases {
+		t.Run(tt.expectedState, func(t *testing.T) {
+			customerID := uuid.NewV4()
+			srv.EXPECT().ListCustomerTransactions(gomock.Any(), "merchant-1", customerID).Return([]*domain.Transaction{tt.transaction}, nil)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, transporthttp.APIVersionPrefix+"/customers/"+customerID.String()+"/transactions", nil)
+			r.Header.Set(transporthttp.APIVersionHeaderKey, transporthttp.LatestAPIVersion)
+			r = r.WithContext(appcontext.WithMerchant(context.Background(), "merchant-1"))
+			httplistener.HTTPHandler(h).ServeHTTP(w, r)
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var transactions []transporthttp.Transaction
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &transactions))
+			require.Len(t, transactions, 1)
+			assert.Equal(t, tt.expectedState, transactions[0].State)
+		})
+	}
+}
+
+func TestWithAPIVersions(t *testing.T) {
+	_, err := transporthttp.NewHTTPHandler(mocks.NewMockService(gomock.NewController(t)),
+		transporthttp.WithAPIVersions(map[string]string{"merchant-1": "2020-01-01"}))
+	assert.EqualError(t, err, `unsupported api version "2020-01-01" of merchant merchant-1`)
+}
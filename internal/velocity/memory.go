@@ -0,0 +1,79 @@
+package velocity
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+type event struct {
+	amount domain.Amount
+	at     time.Time
+}
+
+// MemoryCounter is an in-process Counter, the counts are not shared between instances
+// of the service and are lost on restart.
+type MemoryCounter struct {
+	mu     sync.Mutex
+	events map[string][]event
+}
+
+// NewMemoryCounter initialises a new MemoryCounter.
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{events: make(map[string][]event)}
+}
+
+// ReserveVelocityEvent implements the Counter interface, the mutex is held across the check and the records.
+func (c *MemoryCounter) ReserveVelocityEvent(_ context.Context, keys []string, amount domain.Amount, at time.Time, check CheckFunc) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := check(c.sum); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		c.events[key] = append(c.events[key], event{amount: amount, at: at})
+	}
+	return nil
+}
+
+// sum implements SumFunc, the mutex must be held.
+func (c *MemoryCounter) sum(key, currency string, since time.Time) (int, uint64, error) {
+	var (
+		count  int
+		amount uint64
+	)
+	for _, e := range c.events[key] {
+		if e.at.Before(since) {
+			continue
+		}
+		count++
+		if e.amount.Currency == currency {
+			amount += e.amount.MinorUnits
+		}
+	}
+	return count, amount, nil
+}
+
+// PruneVelocityEvents implements the Counter interface.
+func (c *MemoryCounter) PruneVelocityEvents(_ context.Context, before time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, events := range c.events {
+		kept := events[:0]
+		for _, e := range events {
+			if !e.at.Before(before) {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == 0 {
+			delete(c.events, key)
+			continue
+		}
+		c.events[key] = kept
+	}
+	return nil
+}
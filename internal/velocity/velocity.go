@@ -0,0 +1,153 @@
+package velocity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/pkg/errors"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+)
+
+// Dimension is what the authorizations are counted by.
+type Dimension string
+
+const (
+	// DimensionCard counts the authorizations by card, identified by the PAN hash.
+	DimensionCard Dimension = "card"
+	// DimensionMerchant counts the authorizations by merchant.
+	DimensionMerchant Dimension = "merchant"
+	// DimensionIP counts the authorizations by the IP address of the shopper, the authorizations
+	// without one are not counted.
+	DimensionIP Dimension = "ip"
+)
+
+// Limit is a sliding window limit of a Dimension. A zero MaxCount doesn't limit the count
+// and currencies without a MaxAmount are not limited by amount.
+type Limit struct {
+	Dimension Dimension
+	Window    time.Duration
+	MaxCount  int
+	// MaxAmount are in minor units keyed by currency.
+	MaxAmount map[string]uint64
+}
+
+// Counter records the authorization attempts and sums them up within a window.
+type Counter interface {
+	// ReserveVelocityEvent records an event of the amount under each of the keys if check allows it given the sums
+	// of the events of the keys. The sums and the records are atomic with the reservations of the same keys so that
+	// concurrent attempts cannot all be allowed under a limit.
+	ReserveVelocityEvent(ctx context.Context, keys []string, amount domain.Amount, at time.Time, check CheckFunc) error
+	PruneVelocityEvents(ctx context.Context, before time.Time) error
+}
+
+// SumFunc returns the number of events of the key since the given time, and the sum of their amounts in the currency.
+type SumFunc func(key, currency string, since time.Time) (int, uint64, error)
+
+// CheckFunc checks an attempt against the sums of the events, the attempt is not recorded if it returns an error.
+type CheckFunc func(sum SumFunc) error
+
+// Limiter enforces the velocity limits on authorizations.
+type Limiter struct {
+	counter Counter
+	clock   clockwork.Clock
+	limits  []Limit
+
+	// retention is the longest window of the limits, older events are pruned.
+	retention  time.Duration
+	pruneLock  sync.Mutex
+	lastPruned time.Time
+}
+
+// NewLimiter initialises a new Limiter counting the authorizations with the counter.
+func NewLimiter(counter Counter, clock clockwork.Clock, limits ...Limit) (*Limiter, error) {
+	if counter == nil {
+		return nil, fmt.Errorf("%w: counter", errors.New("invalid param"))
+	}
+
+	l := &Limiter{
+		counter: counter,
+		clock:   clock,
+		limits:  limits,
+	}
+	for _, limit := range limits {
+		if limit.Window <= 0 {
+			return nil, errors.Errorf("velocity limit %s window must be positive", limit.Dimension)
+		}
+		if limit.Window > l.retention {
+			l.retention = limit.Window
+		}
+	}
+	return l, nil
+}
+
+// Allow checks the authorization attempt against the limits and records it once allowed, atomically with the
+// concurrent attempts of the same card, merchant or IP. An attempt over the limits is not counted so that a merchant
+// is unblocked as soon as the window slides past its allowed attempts. It returns domain.ErrVelocityLimitExceeded if any of the limits is exceeded.
+func (l *Limiter) Allow(ctx context.Context, authorization *domain.Authorization) error {
+	now := l.clock.Now()
+	if err := l.prune(ctx, now); err != nil {
+		return err
+	}
+
+	keys := make(map[Dimension]string, 3)
+	for dimension, value := range map[Dimension]string{
+		DimensionCard:     authorization.PaymentSource.PANHash(),
+		DimensionMerchant: authorization.MerchantID,
+		DimensionIP:       authorization.ShopperIP,
+	} {
+		if value != "" {
+			keys[dimension] = fmt.Sprintf("%s:%s", dimension, value)
+		}
+	}
+
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, key)
+	}
+
+	check := func(sum SumFunc) error {
+		for _, limit := range l.limits {
+			key, ok := keys[limit.Dimension]
+			if !ok {
+				continue
+			}
+
+			currency := authorization.Amount.Currency
+			count, amount, err := sum(key, currency, now.Add(-limit.Window))
+			if err != nil {
+				return errors.Wrap(err, "sum velocity events")
+			}
+
+			if limit.MaxCount > 0 && count+1 > limit.MaxCount {
+				return errors.Wrapf(domain.ErrVelocityLimitExceeded, "more than %d authorizations per %s within %s",
+					limit.MaxCount, limit.Dimension, limit.Window)
+			}
+			if maxAmount, ok := limit.MaxAmount[currency]; ok && amount+authorization.Amount.MinorUnits > maxAmount {
+				return errors.Wrapf(domain.ErrVelocityLimitExceeded, "more than %d %s authorized per %s within %s",
+					maxAmount, currency, limit.Dimension, limit.Window)
+			}
+		}
+		return nil
+	}
+
+	return l.counter.ReserveVelocityEvent(ctx, values, authorization.Amount, now, check)
+}
+
+// prune deletes the events older than the retention at most once per retention period.
+func (l *Limiter) prune(ctx context.Context, now time.Time) error {
+	l.pruneLock.Lock()
+	defer l.pruneLock.Unlock()
+
+	if now.Sub(l.lastPruned) < l.retention {
+		return nil
+	}
+	if err := l.counter.PruneVelocityEvents(ctx, now.Add(-l.retention)); err != nil {
+		return errors.Wrap(err, "prune velocity events")
+	}
+	l.lastPruned = now
+	return nil
+}
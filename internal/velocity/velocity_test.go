@@ -0,0 +1,158 @@
+package velocity_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeffreyyong/payment-gateway/internal/domain"
+	"github.com/jeffreyyong/payment-gateway/internal/velocity"
+)
+
+var someDate = time.Date(2021, 5, 2, 12, 0, 0, 0, time.UTC)
+
+func newAuthorization(pan, merchantID, ip string, minorUnits uint64) *domain.Authorization {
+	return &domain.Authorization{
+		MerchantID:    merchantID,
+		ShopperIP:     ip,
+		PaymentSource: domain.PaymentSource{PAN: pan},
+		Amount:        domain.Amount{MinorUnits: minorUnits, Currency: "GBP", Exponent: 2},
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should block the card over the max count within the window", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(someDate)
+		l, err := velocity.NewLimiter(velocity.NewMemoryCounter(), clock,
+			velocity.Limit{Dimension: velocity.DimensionCard, Window: time.Hour, MaxCount: 2},
+		)
+		require.NoError(t, err)
+
+		auth := newAuthorization("4000000000000259", "merchant-1", "192.0.2.1", 100)
+		require.NoError(t, l.Allow(ctx, auth))
+		require.NoError(t, l.Allow(ctx, auth))
+		assert.ErrorIs(t, l.Allow(ctx, auth), domain.ErrVelocityLimitExceeded)
+
+		// another card is counted separately
+		assert.NoError(t, l.Allow(ctx, newAuthorization("5159640776411853", "merchant-1", "192.0.2.1", 100)))
+	})
+
+	t.Run("should allow the card again once the window slides past the attempts", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(someDate)
+		l, err := velocity.NewLimiter(velocity.NewMemoryCounter(), clock,
+			velocity.Limit{Dimension: velocity.DimensionCard, Window: time.Hour, MaxCount: 1},
+		)
+		require.NoError(t, err)
+
+		auth := newAuthorization("4000000000000259", "merchant-1", "", 100)
+		require.NoError(t, l.Allow(ctx, auth))
+		clock.Advance(30 * time.Minute)
+		assert.ErrorIs(t, l.Allow(ctx, auth), domain.ErrVelocityLimitExceeded)
+		clock.Advance(61 * time.Minute)
+		assert.NoError(t, l.Allow(ctx, auth))
+	})
+
+	t.Run("should not count the rejected attempts", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(someDate)
+		l, err := velocity.NewLimiter(velocity.NewMemoryCounter(), clock,
+			velocity.Limit{Dimension: velocity.DimensionMerchant, Window: time.Hour, MaxCount: 1},
+		)
+		require.NoError(t, err)
+
+		auth := newAuthorization("4000000000000259", "merchant-1", "", 100)
+		require.NoError(t, l.Allow(ctx, auth))
+		clock.Advance(30 * time.Minute)
+		assert.ErrorIs(t, l.Allow(ctx, auth), domain.ErrVelocityLimitExceeded)
+		clock.Advance(31 * time.Minute)
+		assert.NoError(t, l.Allow(ctx, auth))
+	})
+
+	t.Run("should block the merchant over the max amount of the currency", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(someDate)
+		l, err := velocity.NewLimiter(velocity.NewMemoryCounter(), clock,
+			velocity.Limit{Dimension: velocity.DimensionMerchant, Window: 24 * time.Hour, MaxAmount: map[string]uint64{"GBP": 1000}},
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, l.Allow(ctx, newAuthorization("4000000000000259", "merchant-1", "", 600)))
+		require.NoError(t, l.Allow(ctx, newAuthorization("5159640776411853", "merchant-1", "", 400)))
+		assert.ErrorIs(t, l.Allow(ctx, newAuthorization("5159640776411853", "merchant-1", "", 1)), domain.ErrVelocityLimitExceeded)
+
+		// amounts in other currencies are not limited
+		eur := newAuthorization("5159640776411853", "merchant-1", "", 5000)
+		eur.Amount.Currency = "EUR"
+		assert.NoError(t, l.Allow(ctx, eur))
+	})
+
+	t.Run("should block the IP over the max count", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(someDate)
+		l, err := velocity.NewLimiter(velocity.NewMemoryCounter(), clock,
+			velocity.Limit{Dimension: velocity.DimensionIP, Window: time.Minute, MaxCount: 1},
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, l.Allow(ctx, newAuthorization("4000000000000259", "merchant-1", "192.0.2.1", 100)))
+		assert.ErrorIs(t, l.Allow(ctx, newAuthorization("5159640776411853", "merchant-2", "192.0.2.1", 100)), domain.ErrVelocityLimitExceeded)
+		assert.NoError(t, l.Allow(ctx, newAuthorization("5159640776411853", "merchant-2", "192.0.2.2", 100)))
+	})
+
+	t.Run("should fail to create a limiter without a window", func(t *testing.T) {
+		_, err := velocity.NewLimiter(velocity.NewMemoryCounter(), clockwork.NewFakeClock(),
+			velocity.Limit{Dimension: velocity.DimensionCard, MaxCount: 1},
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestLimiter_Allow_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	l, err := velocity.NewLimiter(velocity.NewMemoryCounter(), clockwork.NewFakeClockAt(someDate),
+		velocity.Limit{Dimension: velocity.DimensionCard, Window: time.Hour, MaxCount: 3},
+	)
+	require.NoError(t, err)
+
+	// the concurrent attempts of a card cannot all see the count under the limit
+	var (
+		wg      sync.WaitGroup
+		allowed int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if l.Allow(ctx, newAuthorization("4000000000000259", "merchant-1", "", 100)) == nil {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), allowed)
+}
+
+func TestMemoryCounter_PruneVelocityEvents(t *testing.T) {
+	ctx := context.Background()
+	c := velocity.NewMemoryCounter()
+	amount := domain.Amount{MinorUnits: 100, Currency: "GBP", Exponent: 2}
+	allow := func(velocity.SumFunc) error { return nil }
+
+	require.NoError(t, c.ReserveVelocityEvent(ctx, []string{"card:abc"}, amount, someDate, allow))
+	require.NoError(t, c.ReserveVelocityEvent(ctx, []string{"card:abc"}, amount, someDate.Add(time.Hour), allow))
+	require.NoError(t, c.PruneVelocityEvents(ctx, someDate.Add(time.Minute)))
+
+	require.NoError(t, c.ReserveVelocityEvent(ctx, []string{"card:abc"}, amount, someDate.Add(time.Hour),
+		func(sum velocity.SumFunc) error {
+			count, total, err := sum("card:abc", "GBP", time.Time{})
+			require.NoError(t, err)
+			assert.Equal(t, 1, count)
+			assert.Equal(t, uint64(100), total)
+			return nil
+		}))
+}
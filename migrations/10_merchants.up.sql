@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS merchant
+(
+    id                 VARCHAR(64) PRIMARY KEY,
+    name               TEXT        NOT NULL,
+    status             VARCHAR(16) NOT NULL,
+    allowed_currencies TEXT[]      NOT NULL,
+    capture_policy     VARCHAR(16) NOT NULL,
+    created_date       TIMESTAMPTZ NOT NULL,
+    updated_date       TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE api_key ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMPTZ;
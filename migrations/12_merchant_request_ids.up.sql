@@ -0,0 +1,7 @@
+ALTER TABLE transaction
+    DROP CONSTRAINT IF EXISTS transaction_request_id_key,
+    ADD CONSTRAINT transaction_merchant_id_request_id_key UNIQUE (merchant_id, request_id);
+
+ALTER TABLE payment_action
+    DROP CONSTRAINT IF EXISTS payment_action_request_id_key,
+    ADD CONSTRAINT payment_action_transaction_id_request_id_key UNIQUE (transaction_id, request_id);
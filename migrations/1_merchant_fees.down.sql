@@ -0,0 +1,8 @@
+ALTER TABLE payment_action
+    DROP COLUMN fee_currency,
+    DROP COLUMN fee_amount;
+
+ALTER TABLE transaction
+    DROP COLUMN card_country,
+    DROP COLUMN card_scheme,
+    DROP COLUMN merchant_id;
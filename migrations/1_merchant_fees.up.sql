@@ -0,0 +1,8 @@
+ALTER TABLE transaction
+    ADD COLUMN merchant_id  VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN card_scheme  VARCHAR(16) NOT NULL DEFAULT 'unknown',
+    ADD COLUMN card_country VARCHAR(2)  NOT NULL DEFAULT '';
+
+ALTER TABLE payment_action
+    ADD COLUMN fee_amount   BIGINT,
+    ADD COLUMN fee_currency VARCHAR(4);
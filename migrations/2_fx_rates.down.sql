@@ -0,0 +1,8 @@
+ALTER TABLE payment_action
+    DROP COLUMN settlement_currency,
+    DROP COLUMN settlement_amount;
+
+ALTER TABLE transaction
+    DROP COLUMN fx_locked_date,
+    DROP COLUMN settlement_currency,
+    DROP COLUMN fx_rate;
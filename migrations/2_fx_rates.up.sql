@@ -0,0 +1,8 @@
+ALTER TABLE transaction
+    ADD COLUMN fx_rate             NUMERIC(24, 12),
+    ADD COLUMN settlement_currency VARCHAR(4),
+    ADD COLUMN fx_locked_date      TIMESTAMPTZ;
+
+ALTER TABLE payment_action
+    ADD COLUMN settlement_amount   BIGINT,
+    ADD COLUMN settlement_currency VARCHAR(4);
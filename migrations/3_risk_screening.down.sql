@@ -0,0 +1,10 @@
+ALTER TABLE transaction
+    DROP COLUMN risk_reasons,
+    DROP COLUMN risk_score,
+    DROP COLUMN risk_decision;
+
+DROP INDEX IF EXISTS payment_action_transaction_id_idx;
+DROP INDEX IF EXISTS card_pan_hash_idx;
+
+ALTER TABLE card
+    DROP COLUMN pan_hash;
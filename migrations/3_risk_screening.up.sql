@@ -0,0 +1,10 @@
+ALTER TABLE card
+    ADD COLUMN pan_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS card_pan_hash_idx ON card (pan_hash);
+CREATE INDEX IF NOT EXISTS payment_action_transaction_id_idx ON payment_action (transaction_id);
+
+ALTER TABLE transaction
+    ADD COLUMN risk_decision VARCHAR(8),
+    ADD COLUMN risk_score    INTEGER,
+    ADD COLUMN risk_reasons  TEXT[];
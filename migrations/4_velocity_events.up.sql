@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS velocity_event
+(
+    id           UUID         NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key          VARCHAR(128) NOT NULL,
+    amount       BIGINT       NOT NULL,
+    currency     VARCHAR(4)   NOT NULL,
+    created_date TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS velocity_event_key_created_date_idx ON velocity_event (key, created_date);
@@ -0,0 +1,4 @@
+ALTER TABLE transaction
+    DROP COLUMN liability_shift,
+    DROP COLUMN authentication_eci,
+    DROP COLUMN authentication_status;
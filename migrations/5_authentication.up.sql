@@ -0,0 +1,4 @@
+ALTER TABLE transaction
+    ADD COLUMN authentication_status VARCHAR(24),
+    ADD COLUMN authentication_eci    VARCHAR(2),
+    ADD COLUMN liability_shift       BOOLEAN;
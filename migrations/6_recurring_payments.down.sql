@@ -0,0 +1,9 @@
+ALTER TABLE transaction
+    DROP COLUMN network_transaction_id,
+    DROP COLUMN payment_method_id,
+    DROP COLUMN initiator;
+
+DROP TABLE IF EXISTS subscription;
+DROP TABLE IF EXISTS plan;
+DROP TABLE IF EXISTS payment_method;
+DROP TABLE IF EXISTS customer;
@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS customer
+(
+    id           UUID         NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id  VARCHAR(64)  NOT NULL,
+    email        VARCHAR(254),
+    name         VARCHAR(256),
+    created_date TIMESTAMPTZ  NOT NULL,
+    updated_date TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_merchant_id_idx ON customer (merchant_id);
+
+CREATE TABLE IF NOT EXISTS payment_method
+(
+    id                     UUID        NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id            UUID        NOT NULL REFERENCES customer (id),
+    card_id                UUID        NOT NULL REFERENCES card (id),
+    merchant_id            VARCHAR(64) NOT NULL,
+    card_scheme            VARCHAR(16),
+    card_country           VARCHAR(2),
+    network_transaction_id VARCHAR(32),
+    created_date           TIMESTAMPTZ NOT NULL,
+    updated_date           TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS payment_method_customer_id_idx ON payment_method (customer_id);
+
+CREATE TABLE IF NOT EXISTS plan
+(
+    id             UUID         NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id    VARCHAR(64)  NOT NULL,
+    name           VARCHAR(256) NOT NULL,
+    amount         BIGINT       NOT NULL,
+    currency       VARCHAR(4)   NOT NULL,
+    interval       VARCHAR(8)   NOT NULL,
+    interval_count INTEGER      NOT NULL,
+    created_date   TIMESTAMPTZ  NOT NULL,
+    updated_date   TIMESTAMPTZ  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS subscription
+(
+    id                UUID        NOT NULL PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id       VARCHAR(64) NOT NULL,
+    customer_id       UUID        NOT NULL REFERENCES customer (id),
+    plan_id           UUID        NOT NULL REFERENCES plan (id),
+    payment_method_id UUID        NOT NULL REFERENCES payment_method (id),
+    status            VARCHAR(16) NOT NULL,
+    period_start      TIMESTAMPTZ NOT NULL,
+    next_billing_date TIMESTAMPTZ NOT NULL,
+    failed_attempts   INTEGER     NOT NULL DEFAULT 0,
+    created_date      TIMESTAMPTZ NOT NULL,
+    updated_date      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS subscription_status_next_billing_date_idx ON subscription (status, next_billing_date);
+
+ALTER TABLE transaction
+    ADD COLUMN initiator              VARCHAR(8),
+    ADD COLUMN payment_method_id      UUID REFERENCES payment_method (id),
+    ADD COLUMN network_transaction_id VARCHAR(32);
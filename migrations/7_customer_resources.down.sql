@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS transaction_customer_id_idx;
+
+ALTER TABLE transaction
+    DROP COLUMN customer_id;
+
+ALTER TABLE payment_method
+    DROP COLUMN deleted_date;
+
+ALTER TABLE customer
+    DROP COLUMN deleted_date;
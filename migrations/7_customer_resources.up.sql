@@ -0,0 +1,10 @@
+ALTER TABLE customer
+    ADD COLUMN deleted_date TIMESTAMPTZ;
+
+ALTER TABLE payment_method
+    ADD COLUMN deleted_date TIMESTAMPTZ;
+
+ALTER TABLE transaction
+    ADD COLUMN customer_id UUID REFERENCES customer (id);
+
+CREATE INDEX IF NOT EXISTS transaction_customer_id_idx ON transaction (customer_id, created_date);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    sequence    BIGINT PRIMARY KEY,
+    time        TIMESTAMPTZ NOT NULL,
+    merchant_id VARCHAR(64) NOT NULL,
+    token_id    VARCHAR(64) NOT NULL,
+    operation   TEXT        NOT NULL,
+    resource_id TEXT        NOT NULL,
+    request_id  TEXT        NOT NULL,
+    outcome     TEXT        NOT NULL,
+    prev_hash   VARCHAR(64) NOT NULL,
+    hash        VARCHAR(64) NOT NULL
+);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE PROCEDURE audit_log_append_only();
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS api_key
+(
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id  VARCHAR(64) NOT NULL,
+    key_hash     VARCHAR(64) NOT NULL UNIQUE,
+    scopes       TEXT[]      NOT NULL,
+    created_date TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS api_key_merchant_id_idx ON api_key (merchant_id);